@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/term"
+)
+
+func TestPrintCIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result mcpconfig.SyncResult
+		want   string
+	}{
+		{"success", mcpconfig.SyncResult{Success: true}, ""},
+		{"sync failed", mcpconfig.SyncResult{Err: errors.New("dial tcp: connection refused")},
+			"zeude: sync failed: dial tcp: connection refused\n"},
+		{"partial install", mcpconfig.SyncResult{Success: true, HooksFailed: 1, SkillsFailed: 2, FirstError: "permission denied"},
+			"zeude: 1 hooks and 2 skills could not be installed: permission denied\n"},
+		{"failure wins over partial install", mcpconfig.SyncResult{Err: errors.New("timeout"), HooksFailed: 1},
+			"zeude: sync failed: timeout\n"},
+		{"missing interpreters", mcpconfig.SyncResult{Success: true, HooksUnrunnable: 2, MissingInterpreters: []string{"python3", "node"}},
+			"zeude: 2 hooks can't run: python3, node not found on PATH\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Plain text even where colors would be used
+			setColors(t, term.ANSI)
+			if got := string(captureOutput(t, func() { printCIErrors(tt.result) })); got != tt.want {
+				t.Errorf("output %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/term"
+)
+
+// setColors switches the shim's color codes to p for the rest of the test.
+func setColors(t *testing.T, p term.Palette) {
+	t.Helper()
+	old := term.Palette{Reset: colorReset, Blue: colorBlue, Green: colorGreen, Yellow: colorYellow, Red: colorRed, Gray: colorGray}
+	colorReset, colorBlue, colorGreen, colorYellow, colorRed, colorGray = p.Reset, p.Blue, p.Green, p.Yellow, p.Red, p.Gray
+	t.Cleanup(func() {
+		colorReset, colorBlue, colorGreen, colorYellow, colorRed, colorGray = old.Reset, old.Blue, old.Green, old.Yellow, old.Red, old.Gray
+	})
+}
+
+// captureOutput returns what fn writes to stdout and stderr.
+func captureOutput(t *testing.T, fn func()) []byte {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "output"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = f, f
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+	fn()
+	data, _ := os.ReadFile(f.Name())
+	return data
+}
+
+func TestNoEscapesWithoutColor(t *testing.T) {
+	outputs := map[string]func(){
+		"banner": func() {
+			showStartupBanner(mcpconfig.SyncResult{
+				Success:    true,
+				UserEmail:  "dev@example.com",
+				NoAgentKey: true,
+				MOTD:       &mcpconfig.MOTD{ID: "m1", Text: "Maintenance tonight", Severity: mcpconfig.MOTDCritical},
+			})
+		},
+		"quiet errors": func() {
+			printQuietErrors(mcpconfig.SyncResult{
+				Err:     &mcpconfig.AuthError{StatusCode: 401},
+				Overdue: &mcpconfig.UpdateOverdue{Version: "1.3.0", Since: time.Now().Add(-72 * time.Hour), Expired: true},
+			})
+		},
+		"update overdue warning": func() {
+			warnUpdateOverdue(&mcpconfig.UpdateOverdue{Version: "1.3.0", Since: time.Now().Add(-72 * time.Hour), Deadline: time.Now().Add(24 * time.Hour)})
+		},
+		"status": printShimStatus,
+	}
+	for name, fn := range outputs {
+		t.Run(name, func(t *testing.T) {
+			for _, colored := range []bool{true, false} {
+				useTestHome(t)
+				setColors(t, term.Colors(colored))
+				out := captureOutput(t, fn)
+				if len(out) == 0 {
+					t.Fatal("nothing printed")
+				}
+				// Colored output proves the codes pass through here at all
+				if hasEscape := bytes.IndexByte(out, 0x1b) >= 0; hasEscape != colored {
+					t.Errorf("colors %v: escape bytes %v in\n%q", colored, hasEscape, out)
+				}
+			}
+		})
+	}
+}
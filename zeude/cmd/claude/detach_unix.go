@@ -0,0 +1,11 @@
+//go:build unix || darwin || linux
+
+package main
+
+import "syscall"
+
+// detachedProcAttr starts the background sync in its own session so it
+// outlives the terminal claude runs in.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// detachedProcess is DETACHED_PROCESS: no console for the background sync.
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts the background sync without a console, in its
+// own process group so Ctrl-C in claude's window doesn't reach it.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/zeude/zeude/internal/resolver"
+)
+
+// execClaude replaces the shim with realClaude. If that fails, typically
+// because an upgrade is replacing the binary (ETXTBSY) or moved it
+// (ENOENT), the recorded path is dropped and every other claude on PATH
+// is tried, recording each before its exec since a successful one never
+// returns. It exits only after all of them failed.
+func execClaude(realClaude string, args []string) {
+	err := syscall.Exec(realClaude, args, os.Environ())
+	logger.Warn("exec failed", "path", realClaude, "error", err)
+	tried := []string{realClaude}
+
+	opts := resolver.Options{}
+	if err := resolver.ForgetRealBinary(opts); err != nil {
+		logger.Warn("can't forget real binary path", "error", err)
+	}
+	for _, candidate := range resolver.SearchPATHAll(opts) {
+		if contains(tried, candidate) {
+			continue
+		}
+		tried = append(tried, candidate)
+		if err := resolver.SaveRealBinary(opts, candidate); err != nil {
+			logger.Warn("can't save real binary path", "path", candidate, "error", err)
+		}
+		err = syscall.Exec(candidate, args, os.Environ())
+		logger.Warn("exec failed", "path", candidate, "error", err)
+	}
+	resolver.ForgetRealBinary(opts)
+
+	fmt.Fprintf(os.Stderr, "zeude: failed to exec claude: %v\n", err)
+	fmt.Fprintf(os.Stderr, "zeude: tried %s\n", strings.Join(tried, ", "))
+	os.Exit(1)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package main
+
+import "strings"
+
+// invocation is what kind of claude run the arguments ask for, which
+// decides how much startup work the shim does first.
+type invocation int
+
+const (
+	// invocationSession is an interactive session: full init.
+	invocationSession invocation = iota
+	// invocationPrint is a one-shot -p/--print run: full init, no banner.
+	invocationPrint
+	// invocationManage reads or edits the MCP config (claude mcp ...), so
+	// the sync must finish first; the update check is skipped.
+	invocationManage
+	// invocationTrivial (claude --version, claude config get, ...) uses
+	// nothing Zeude provides and goes straight to claude.
+	invocationTrivial
+)
+
+// trivialFlags answer without starting a session, wherever they appear.
+var trivialFlags = map[string]bool{
+	"-v": true, "--version": true,
+	"-h": true, "--help": true,
+}
+
+// subcommandInvocations classifies claude's subcommands. Anything not
+// listed, including new subcommands, gets the full init.
+var subcommandInvocations = map[string]invocation{
+	"mcp":               invocationManage,
+	"config":            invocationTrivial,
+	"doctor":            invocationTrivial,
+	"update":            invocationTrivial,
+	"install":           invocationTrivial,
+	"migrate-installer": invocationTrivial,
+	"setup-token":       invocationTrivial,
+	"completion":        invocationTrivial,
+}
+
+// globalFlags are the flags without a value that may come before a
+// subcommand. A flag that takes a value hides the subcommand after it, so
+// such runs fall back to the full init.
+var globalFlags = map[string]bool{
+	"-d": true, "--debug": true,
+	"--verbose": true,
+}
+
+// flagValues says how many values claude's flags take, so a value that
+// looks like a flag (--append-system-prompt "-p style") or a subcommand
+// isn't read as one. Flags not listed take none.
+var flagValues = map[string]flagArity{
+	"--append-system-prompt":   oneValue,
+	"--system-prompt":          oneValue,
+	"--output-format":          oneValue,
+	"--input-format":           oneValue,
+	"--model":                  oneValue,
+	"--fallback-model":         oneValue,
+	"--permission-mode":        oneValue,
+	"--permission-prompt-tool": oneValue,
+	"--max-turns":              oneValue,
+	"--settings":               oneValue,
+	"--setting-sources":        oneValue,
+	"--session-id":             oneValue,
+	"--agents":                 oneValue,
+	"-r":                       optionalValue,
+	"--resume":                 optionalValue,
+	"--add-dir":                manyValues,
+	"--allowedTools":           manyValues,
+	"--allowed-tools":          manyValues,
+	"--disallowedTools":        manyValues,
+	"--disallowed-tools":       manyValues,
+	"--mcp-config":             manyValues,
+	"--betas":                  manyValues,
+}
+
+// flagArity is how many arguments after a flag are its values.
+type flagArity int
+
+const (
+	oneValue      flagArity = iota + 1
+	optionalValue           // the next argument, unless it is a flag
+	manyValues              // every argument up to the next flag
+)
+
+// classifyArgs classifies a claude command line (args[0] is the program).
+// Flags are only recognized where a flag can be, not as another flag's
+// value, and arguments after "--" are never inspected.
+func classifyArgs(args []string) invocation {
+	kind := invocationSession
+	sawPositional := false
+	args = args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") && arg != "-" {
+			if trivialFlags[arg] {
+				return invocationTrivial
+			}
+			if arg == "-p" || arg == "--print" {
+				kind = invocationPrint
+			}
+			i += flagValueCount(args[i+1:], arg)
+			continue
+		}
+		if sawPositional {
+			continue
+		}
+		// The first positional argument is a subcommand only if all that
+		// came before it were value-less flags; otherwise it's a prompt.
+		// The subcommand's own arguments are its business (claude mcp add
+		// x -- npx -p ...).
+		sawPositional = true
+		if sub, ok := subcommandInvocations[arg]; ok && onlyGlobalFlags(args[:i]) {
+			return sub
+		}
+	}
+	return kind
+}
+
+// flagValueCount returns how many of rest are values of flag. A value
+// given as --flag=value is part of the flag itself.
+func flagValueCount(rest []string, flag string) int {
+	if strings.Contains(flag, "=") {
+		return 0
+	}
+	isValue := func(arg string) bool { return arg != "--" && (!strings.HasPrefix(arg, "-") || arg == "-") }
+	switch flagValues[flag] {
+	case oneValue:
+		if len(rest) > 0 && rest[0] != "--" {
+			return 1
+		}
+	case optionalValue:
+		if len(rest) > 0 && isValue(rest[0]) {
+			return 1
+		}
+	case manyValues:
+		n := 0
+		for n < len(rest) && isValue(rest[n]) {
+			n++
+		}
+		return n
+	}
+	return 0
+}
+
+func onlyGlobalFlags(args []string) bool {
+	for _, arg := range args {
+		if !globalFlags[arg] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want invocation
+	}{
+		{"claude", invocationSession},
+		{"claude fix the failing test", invocationSession},
+		{"claude -p hello", invocationPrint},
+		{"claude --print hello", invocationPrint},
+		{"claude hello -p", invocationPrint},
+		{"claude --version", invocationTrivial},
+		{"claude -v", invocationTrivial},
+		{"claude --help", invocationTrivial},
+		{"claude -p --version", invocationTrivial},
+		{"claude config get theme", invocationTrivial},
+		{"claude config list", invocationTrivial},
+		{"claude doctor", invocationTrivial},
+		{"claude update", invocationTrivial},
+		{"claude install stable", invocationTrivial},
+		{"claude migrate-installer", invocationTrivial},
+		{"claude setup-token", invocationTrivial},
+		{"claude completion zsh", invocationTrivial},
+		{"claude mcp list", invocationManage},
+		{"claude mcp get github", invocationManage},
+		{"claude mcp add fs -- npx -y @modelcontextprotocol/server-filesystem -p", invocationManage},
+		{"claude --debug mcp list", invocationManage},
+		// The subcommand's own arguments are left to it
+		{"claude mcp --help", invocationManage},
+		{"claude -d --verbose config get theme", invocationTrivial},
+		{"claude plugin list", invocationSession},
+		// A subcommand name later on the line is part of the prompt
+		{"claude explain mcp", invocationSession},
+		{"claude explain config", invocationSession},
+		// After a flag that takes a value, the word is the prompt
+		{"claude --model opus mcp", invocationSession},
+		{"claude --add-dir ../lib config", invocationSession},
+	}
+	for _, tt := range tests {
+		if got := classifyArgs(strings.Fields(tt.line)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+// TestClassifyArgsFlagValues covers flag values and arguments after "--"
+// that look like flags or subcommands.
+func TestClassifyArgsFlagValues(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want invocation
+	}{
+		{"value looks like -p", []string{"claude", "--append-system-prompt", "use -p style output"}, invocationSession},
+		{"value is -p", []string{"claude", "--system-prompt", "-p"}, invocationSession},
+		{"value is --version", []string{"claude", "--model", "--version"}, invocationSession},
+		{"value is a subcommand", []string{"claude", "--permission-mode", "plan", "config"}, invocationSession},
+		{"-p after --", []string{"claude", "--", "-p"}, invocationSession},
+		{"--version after --", []string{"claude", "--", "--version"}, invocationSession},
+		{"-p before --", []string{"claude", "-p", "--", "-v"}, invocationPrint},
+		{"inline value", []string{"claude", "--model=opus", "mcp", "list"}, invocationSession},
+		{"inline value hides nothing", []string{"claude", "--output-format=json", "-p", "hi"}, invocationPrint},
+		{"output format", []string{"claude", "-p", "--output-format", "stream-json", "summarize"}, invocationPrint},
+		{"output format before print", []string{"claude", "--output-format", "json", "--print", "hi"}, invocationPrint},
+		{"input format", []string{"claude", "-p", "--input-format", "stream-json"}, invocationPrint},
+		{"resume with id", []string{"claude", "--resume", "0b7c3f", "continue"}, invocationSession},
+		{"resume picker", []string{"claude", "--resume"}, invocationSession},
+		{"resume then print", []string{"claude", "--resume", "-p", "status?"}, invocationPrint},
+		{"short resume", []string{"claude", "-r", "0b7c3f", "-p", "go on"}, invocationPrint},
+		{"continue", []string{"claude", "-c", "-p", "and then?"}, invocationPrint},
+		{"tool list", []string{"claude", "--allowedTools", "Read", "Bash(git:*)", "-p", "review"}, invocationPrint},
+		{"tool list swallows words", []string{"claude", "--allowed-tools", "Read", "mcp"}, invocationSession},
+		{"add dir then --", []string{"claude", "--add-dir", "../a", "../b", "--", "-p"}, invocationSession},
+		{"mcp config files", []string{"claude", "--mcp-config", "a.json", "b.json", "--print", "hi"}, invocationPrint},
+		{"stdin dash", []string{"claude", "-p", "-"}, invocationPrint},
+		{"dangling value flag", []string{"claude", "-p", "--model"}, invocationPrint},
+		{"max turns", []string{"claude", "--max-turns", "3", "-p", "fix it"}, invocationPrint},
+		{"value flag before --", []string{"claude", "--model", "--", "-p"}, invocationSession},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyArgs(tt.args); got != tt.want {
+				t.Errorf("%q: got %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlagValueCount(t *testing.T) {
+	tests := []struct {
+		flag string
+		rest []string
+		want int
+	}{
+		{"--model", []string{"opus", "hi"}, 1},
+		{"--model", []string{"-p"}, 1},
+		{"--model", []string{"--"}, 0},
+		{"--model", nil, 0},
+		{"--model=opus", []string{"hi"}, 0},
+		{"--resume", []string{"abc", "hi"}, 1},
+		{"--resume", []string{"-p"}, 0},
+		{"--resume", []string{"-"}, 1},
+		{"--add-dir", []string{"a", "b", "-p", "c"}, 2},
+		{"--add-dir", []string{"a", "--", "b"}, 1},
+		{"--verbose", []string{"hi"}, 0},
+		{"--some-new-flag", []string{"hi"}, 0},
+	}
+	for _, tt := range tests {
+		if got := flagValueCount(tt.rest, tt.flag); got != tt.want {
+			t.Errorf("flagValueCount(%q, %s) = %d, want %d", tt.rest, tt.flag, got, tt.want)
+		}
+	}
+}
@@ -4,75 +4,273 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
+	"unicode"
 
 	"github.com/zeude/zeude/internal/autoupdate"
 	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/crashreport"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/logging"
 	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/project"
+	"github.com/zeude/zeude/internal/provider"
 	"github.com/zeude/zeude/internal/resolver"
+	"github.com/zeude/zeude/internal/telemetry"
+	"github.com/zeude/zeude/internal/term"
 )
 
-// ANSI color codes
-const (
-	colorReset  = "\033[0m"
-	colorBlue   = "\033[1;34m"
-	colorGreen  = "\033[1;32m"
-	colorYellow = "\033[1;33m"
-	colorRed    = "\033[1;31m"
-	colorGray   = "\033[0;90m"
+// Colors are blanked by NO_COLOR, ZEUDE_NO_COLOR and TERM=dumb; see package term.
+var (
+	colors      = shimColors()
+	colorReset  = colors.Reset
+	colorBlue   = colors.Blue
+	colorGreen  = colors.Green
+	colorYellow = colors.Yellow
+	colorRed    = colors.Red
+	colorGray   = colors.Gray
 )
 
+// shimColors returns the palette for the shim's decoration. All of it goes
+// to stderr, so only stderr being a terminal matters, not stdin.
+func shimColors() term.Palette {
+	return term.Colors(term.ColorEnabled(env.OS{}) && stderrIsTerminal())
+}
+
+// logger tags the shim's own records so `zeude logs --component shim` finds them.
+var logger = logging.Default().Component("shim")
+
+// bypassArg is the command-line form of ZEUDE_SKIP. The shim removes it,
+// so claude never sees it.
+const bypassArg = "--zeude-bypass"
+
+// syncConfig is the sync startup runs; tests replace it.
+var syncConfig = mcpconfig.Sync
+
 func main() {
-	// Check if running interactively (show progress only in interactive mode)
-	interactive := isInteractive()
+	httpclient.SetVersion(autoupdate.Version)
+	if wantsStatus(os.Args) {
+		printShimStatus()
+		return
+	}
+	// Escape hatch: straight to claude, before anything that touches disk or network
+	if args, bypass := bypassArgs(os.Args); bypass || config.Skip(env.OS{}) {
+		execBypass(args)
+	}
+	if len(os.Args) > 1 && os.Args[1] == backgroundSyncArg {
+		runBackgroundSync()
+		return
+	}
+	kind := classifyArgs(os.Args)
+	if kind == invocationTrivial {
+		// Nothing Zeude syncs changes what these print
+		execBypass(os.Args)
+	}
+
+	realClaude := safeStartup(kind)
+	trace.report()
+
+	// 9. Exec real claude (replaces this process - no PTY needed!)
+	execClaude(realClaude, os.Args)
+}
+
+// startup does everything before the exec: update check, sync, status
+// line, telemetry and pre-launch scripts. It returns the claude to run.
+func startup(kind invocation) string {
+	// claude mcp shows the MCP config, so it has to be current
+	manage := kind == invocationManage
+
+	// Move ~/.zeude into the XDG data dir once the user has opted in
+	if m, err := paths.MigrateToXDG(env.OS{}); err != nil {
+		logger.Warn("XDG migration failed", "error", err)
+	} else if m != nil {
+		logger.Info("migrated data dir", "from", m.From, "to", m.To, "copied", m.Copied)
+	}
+
+	// A CI job log gets at most one plain line from Zeude, printed after
+	// the sync, unless debug logging was asked for
+	ci := config.CI(env.OS{})
+	if ci && os.Getenv("ZEUDE_DEBUG") == "" && os.Getenv("ZEUDE_LOG_LEVEL") == "" {
+		logging.Default().SetStderrLevel(logging.LevelOff)
+	}
+
+	newSession := startSession()
+
+	// Opt-in crash/error reporting: capture panics and error logs locally
+	errorReporting := crashreport.Enabled(cachedErrorReportingPolicy())
+	if errorReporting {
+		crashreport.Install() // safeStartup captures panics
+	}
+
+	// Prompts and the banner need someone at the terminal; the status line
+	// and warnings only need stderr to be one, so `echo x | claude -p`
+	// still shows them and `claude 2>log` doesn't. Quiet hides all of it.
+	interactive := isInteractive(kind)
+	quiet := config.Quiet(env.OS{})
+	verbose := stderrIsTerminal() && !ci && !quiet
+
+	// A repository's trusted .zeude can point this launch at another dashboard
+	projectConfig := applyProjectConfig(verbose)
 
 	// Helper to print status
 	printStatus := func(msg string) {
-		if interactive {
+		if verbose {
 			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s", colorBlue, colorReset, msg)
 		}
 	}
 	printOK := func() {
-		if interactive {
+		if verbose {
 			fmt.Fprintf(os.Stderr, " %s✓%s\n", colorGreen, colorReset)
 		}
 	}
 	printInfo := func(info string) {
-		if interactive {
+		if verbose {
 			fmt.Fprintf(os.Stderr, " %s%s%s\n", colorGray, info, colorReset)
 		}
 	}
 
 	// 1. Start parallel initialization (update check + config sync)
 	printStatus("Initializing...")
+	// Animated like the banner: only with someone at the terminal
+	var spin *spinner
+	if interactive && !quiet && !term.Dumb(env.OS{}) {
+		phases := []string{phaseUpdate, phaseSync}
+		if manage {
+			phases = phases[1:]
+		}
+		spin = startSpinner(colorBlue+"[zeude]"+colorReset+" Initializing...", len("[zeude] Initializing..."), phases...)
+		defer spin.stop() // a panic must not leave it drawing
+	}
+
+	// One context bounds all startup work; Ctrl-C cancels it
+	ctx, cancel := context.WithTimeout(context.Background(), startupBudget)
+	defer cancel()
+	interrupted, stopSignals := cancelOnSignal(cancel)
 
 	var updateResult autoupdate.UpdateResult
 	var syncResult mcpconfig.SyncResult
+	var updateDuration, syncDuration time.Duration
 	var wg sync.WaitGroup
 
-	wg.Add(2)
+	if !manage {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer recoverStartup()
+			start := time.Now()
+			updateResult = autoupdate.CheckWithContext(ctx)
+			updateDuration = time.Since(start)
+			trace.add("update check", updateDuration)
+			spin.finish(phaseUpdate)
+		}()
+	}
+	synced := make(chan struct{})
+	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		updateResult = autoupdate.CheckWithResult()
+		defer close(synced)
+		defer recoverStartup()
+		start := time.Now()
+		syncResult = syncConfig(ctx, mcpconfig.SyncOptions{Version: autoupdate.Version})
+		syncDuration = time.Since(start)
+		trace.addSync(syncResult.Timings)
+		spin.finish(phaseSync)
 	}()
+	if !manage && mcpconfig.SessionReportsEnabled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer recoverStartup()
+			reportSessionStart(ctx, synced, &syncResult)
+		}()
+	}
+	// Claude's exporter fails silently, so look for a dead collector here
+	var collector *telemetry.CollectorStatus
+	if !manage && !config.TelemetryDisabled(env.OS{}) && !config.Offline(env.OS{}) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer recoverStartup()
+			endpoint, _ := collectorEndpoint(ctx)
+			status := telemetry.CheckCollector(ctx, env.OS{}, endpoint)
+			collector = &status
+		}()
+	}
+	if errorReporting && !config.Offline(env.OS{}) && !mcpconfig.DashboardFromProject() {
+		// Upload reports queued by earlier runs while the network is warm;
+		// they are this machine's, for the global dashboard only
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer recoverStartup()
+			if err := crashreport.Flush(ctx, mcpconfig.DashboardURL(), mcpconfig.AgentKey()); err != nil {
+				logger.Debug("error report flush failed", "error", err)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		syncResult = mcpconfig.Sync()
+		wg.Wait()
+		close(done)
 	}()
 
 	// 2. Find real claude binary (while HTTP requests are in progress)
+	resolveStart := time.Now()
 	realClaude, err := resolver.FindRealBinary()
+	trace.add("binary resolution", time.Since(resolveStart))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "zeude: %v\n", err)
-		os.Exit(1)
+		spin.stop()
+		if !interactive {
+			fmt.Fprintf(os.Stderr, "zeude: %v\n", err)
+			os.Exit(1)
+		}
+		// Ctrl-C at the prompt should quit, not just cancel the sync
+		stopSignals()
+		fmt.Fprintln(os.Stderr)
+		realClaude = recoverRealBinary(err)
+		printStatus("Initializing...")
 	}
 
-	// 3. Wait for parallel tasks to complete
-	wg.Wait()
+	// 3. Wait for parallel tasks to complete, within the init budget. Past
+	// it, cancel them and hand the work to a detached process instead.
+	budget := initBudget()
+	if manage {
+		budget = startupBudget
+	}
+	background := !waitFor(done, budget)
+	finished := !background
+	if background {
+		cancel()
+		finished = waitFor(done, backgroundGrace)
+	}
+	spin.stop()
+	stopSignals()
+	if interrupted() {
+		fmt.Fprintln(os.Stderr)
+		os.Exit(130)
+	}
+	reraiseStartupPanic()
+	if background {
+		startBackgroundSync()
+		// A cancelled sync's result is partial, and if it hasn't returned
+		// the goroutines still own it; use what the last sync left
+		updateResult, syncResult = autoupdate.UpdateResult{}, mcpconfig.CachedResult()
+	}
 
 	// 4. Display results
 	// Build status parts
@@ -83,27 +281,62 @@ func main() {
 		statusParts = append(statusParts, fmt.Sprintf("%s↑%s%s", colorGreen, updateResult.NewVersion, colorGray))
 	} else if updateResult.NewVersionAvailable {
 		statusParts = append(statusParts, fmt.Sprintf("%supdate: %s%s", colorYellow, updateResult.NewVersion, colorGray))
+	} else if updateResult.Held {
+		statusParts = append(statusParts, fmt.Sprintf("%sversion held%s", colorYellow, colorGray))
 	}
 
 	// Sync status
-	if syncResult.NoAgentKey {
+	if background {
+		statusParts = append(statusParts, "syncing in background…")
+	} else if syncResult.Paused {
+		statusParts = append(statusParts, fmt.Sprintf("%ssync paused%s", colorYellow, colorGray))
+	} else if syncResult.NoAgentKey {
 		statusParts = append(statusParts, fmt.Sprintf("%sno agent key%s", colorYellow, colorGray))
+	} else if syncResult.TornDown {
+		statusParts = append(statusParts, fmt.Sprintf("%sremoved by your organization%s", colorYellow, colorGray))
 	} else if syncResult.Success {
 		if syncResult.HookCount > 0 {
 			statusParts = append(statusParts, fmt.Sprintf("%d hooks", syncResult.HookCount))
 		}
+		if syncResult.HooksFailed > 0 {
+			statusParts = append(statusParts, fmt.Sprintf("%s%d hooks failed%s", colorYellow, syncResult.HooksFailed, colorGray))
+		}
+		if syncResult.HooksUnrunnable > 0 {
+			statusParts = append(statusParts, fmt.Sprintf("%s%d hooks need %s%s", colorYellow, syncResult.HooksUnrunnable, strings.Join(syncResult.MissingInterpreters, ", "), colorGray))
+		}
 		if syncResult.SkillCount > 0 {
 			statusParts = append(statusParts, fmt.Sprintf("%d skills", syncResult.SkillCount))
 		}
-		if syncResult.ServerCount > 0 {
-			statusParts = append(statusParts, fmt.Sprintf("%d servers", syncResult.ServerCount))
+		if syncResult.SkillsFailed > 0 {
+			statusParts = append(statusParts, fmt.Sprintf("%s%d skills failed%s", colorYellow, syncResult.SkillsFailed, colorGray))
 		}
-		if syncResult.FromCache {
+		if syncResult.ServerCount > 0 || syncResult.DisabledServerCount > 0 {
+			servers := fmt.Sprintf("%d servers", syncResult.ServerCount)
+			if syncResult.DisabledServerCount > 0 {
+				servers += fmt.Sprintf(" (%d disabled)", syncResult.DisabledServerCount)
+			}
+			statusParts = append(statusParts, servers)
+		}
+		if syncResult.Offline {
+			statusParts = append(statusParts, fmt.Sprintf("%soffline%s", colorYellow, colorGray))
+		} else if !syncResult.RateLimitedUntil.IsZero() {
+			statusParts = append(statusParts, fmt.Sprintf("%srate limited, using cache%s", colorYellow, colorGray))
+		} else if syncResult.FromCache {
 			statusParts = append(statusParts, "cached")
 		}
+	} else if syncResult.Offline {
+		statusParts = append(statusParts, fmt.Sprintf("%soffline, nothing cached yet%s", colorYellow, colorGray))
+	} else if !syncResult.RateLimitedUntil.IsZero() {
+		statusParts = append(statusParts, fmt.Sprintf("%srate limited, nothing cached yet%s", colorYellow, colorGray))
 	} else if !syncResult.NoAgentKey {
 		statusParts = append(statusParts, fmt.Sprintf("%ssync failed%s", colorRed, colorGray))
 	}
+	if projectConfig != nil {
+		statusParts = append(statusParts, "project config")
+	}
+	if finished && collector != nil && !collector.Reachable {
+		statusParts = append(statusParts, fmt.Sprintf("%scollector unreachable%s", colorYellow, colorGray))
+	}
 
 	// Print combined status
 	if len(statusParts) > 0 {
@@ -111,26 +344,211 @@ func main() {
 	} else {
 		printOK()
 	}
+	switch {
+	case ci:
+		printCIErrors(syncResult)
+	case quiet:
+		printQuietErrors(syncResult)
+	default:
+		if verbose && syncResult.HooksFailed+syncResult.SkillsFailed > 0 {
+			// The first error was logged above; this says what to do about it
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ Some hooks or skills could not be installed; run 'zeude doctor'%s\n", colorBlue, colorReset, colorYellow, colorReset)
+		}
+		warnUpdateOverdue(syncResult.Overdue)
+	}
 
-	// 5. Show welcome message
-	if interactive {
-		showStartupBanner(syncResult)
+	// 5. Offer first-run setup, then show welcome message
+	if interactive && kind == invocationSession {
+		if syncResult.NoAgentKey && !config.Offline(env.OS{}) {
+			syncResult = onboard(syncResult)
+		}
+		if !quiet {
+			showStartupBanner(syncResult)
+		}
 	}
 
-	// 6. Inject telemetry environment variables (only if not already set)
-	injectTelemetryEnv(syncResult)
+	// 6. Inject telemetry and provider environment variables (only if not
+	// already set)
+	injectTelemetryEnv(syncResult, projectConfig)
+	injectProviderEnv(syncResult)
+	if newSession {
+		// A claude run from inside the session leaves the outer one's file
+		writeSessionFile(syncResult, realClaude)
+	}
+
+	// 7. Report Zeude's own metrics (opt-in, bounded, never fatal); a run
+	// that ran out of budget has nothing complete to report
+	if !background && telemetry.SelfTelemetryEnabled(syncResult.SelfTelemetry) {
+		reportSelfTelemetry(syncResult, syncDuration, updateResult, updateDuration)
+	}
 
-	// 7. Exec real claude (replaces this process - no PTY needed!)
-	err = syscall.Exec(realClaude, os.Args, os.Environ())
+	// 8. Run local pre-launch scripts; one may veto the launch
+	if !runPreLaunch(syncResult, background) {
+		os.Exit(1)
+	}
+	return realClaude
+}
+
+// bypassArgs returns args without --zeude-bypass and whether it was
+// there. Arguments after a "--" belong to claude and are left alone.
+func bypassArgs(args []string) ([]string, bool) {
+	out := make([]string, 0, len(args))
+	found := false
+	for i, arg := range args {
+		if arg == "--" {
+			out = append(out, args[i:]...)
+			break
+		}
+		if i > 0 && arg == bypassArg {
+			found = true
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out, found
+}
+
+// execBypass runs the real claude with args and nothing else.
+func execBypass(args []string) {
+	realClaude, err := resolver.FindRealBinary()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "zeude: failed to exec claude: %v\n", err)
+		fmt.Fprintf(os.Stderr, "zeude: %v\n", err)
 		os.Exit(1)
 	}
+	execClaude(realClaude, args)
+}
+
+// startupBudget caps everything the shim does before exec'ing claude.
+// It leaves room for a full update download (autoupdate's own 30s timeout).
+const startupBudget = 40 * time.Second
+
+const (
+	// initBudgetKey sets how long a launch waits for the update check and
+	// sync in ~/.zeude/config, as a Go duration (init_budget=3s); 0 waits
+	// for them to finish, up to startupBudget.
+	initBudgetKey = "init_budget"
+	// defaultInitBudget keeps a slow dashboard from delaying every launch.
+	defaultInitBudget = 1500 * time.Millisecond
+	// backgroundGrace is how long cancelled startup work gets to unwind
+	// before claude is exec'd regardless.
+	backgroundGrace = 300 * time.Millisecond
+	// backgroundSyncArg re-invokes the shim as the detached process that
+	// finishes the startup work a launch ran out of budget for.
+	backgroundSyncArg = "--zeude-background-sync"
+)
+
+// initBudget returns the configured init budget.
+func initBudget() time.Duration {
+	v := config.Get(initBudgetKey)
+	switch v {
+	case "":
+		return defaultInitBudget
+	case "0":
+		return startupBudget
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Debug("invalid init budget, using default", "value", v)
+		return defaultInitBudget
+	}
+	return d
+}
+
+// waitFor reports whether done closes within d.
+func waitFor(done <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// startBackgroundSync re-runs the shim detached with backgroundSyncArg, in
+// the same directory and environment so project settings still apply.
+func startBackgroundSync() {
+	self, err := os.Executable()
+	if err != nil {
+		logger.Warn("can't start background sync", "error", err)
+		return
+	}
+	cmd := exec.Command(self, backgroundSyncArg)
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		logger.Warn("can't start background sync", "error", err)
+		return
+	}
+	cmd.Process.Release()
+}
+
+// runBackgroundSync is the detached process: it checks for updates and
+// syncs, leaving the results in the cache for the next launch.
+func runBackgroundSync() {
+	ctx, cancel := context.WithTimeout(context.Background(), startupBudget)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if result := autoupdate.CheckWithContext(ctx); result.Error != nil {
+			logger.Warn("background update check failed", "error", result.Error)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		result := mcpconfig.Sync(ctx, mcpconfig.SyncOptions{Version: autoupdate.Version})
+		if result.Err != nil {
+			logger.Warn("background sync failed", "error", result.Err)
+		}
+	}()
+	wg.Wait()
+}
+
+// cancelOnSignal calls cancel on SIGINT/SIGTERM during startup.
+// interrupted reports whether that happened; stop restores default handling
+// so claude gets signals normally after exec.
+func cancelOnSignal(cancel context.CancelFunc) (interrupted func() bool, stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	var got atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			got.Store(true)
+			cancel()
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return got.Load, func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
+
+// cachedErrorReportingPolicy returns the dashboard's error-reporting policy
+// from the last synced config, since this run's sync hasn't finished yet.
+func cachedErrorReportingPolicy() bool {
+	cached, _ := mcpconfig.LoadCachedConfig()
+	return cached != nil && cached.Config.Policy.ErrorReporting
 }
 
 // isInteractive checks if we're running in an interactive terminal
-// Returns false if stdin is not a terminal or if -p/--print flag is used
-func isInteractive() bool {
+// Returns false in CI, if stdin or stderr is not a terminal or if
+// classifyArgs found -p/--print
+func isInteractive(kind invocation) bool {
+	if config.CI(env.OS{}) {
+		return false // CI runners may still hand claude a pseudo-terminal
+	}
+
 	// Check if stdin is a terminal (character device)
 	stat, err := os.Stdin.Stat()
 	if err != nil {
@@ -139,18 +557,14 @@ func isInteractive() bool {
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
 		return false // stdin is a pipe or file
 	}
-
-	// Check for -p or --print flags (non-interactive mode)
-	for _, arg := range os.Args[1:] {
-		if arg == "-p" || arg == "--print" || arg == "-h" || arg == "--help" || arg == "--version" {
-			return false
-		}
+	if !stderrIsTerminal() {
+		return false // nobody would see a prompt
 	}
 
-	return true
+	// -p/--print is non-interactive; --help and --version never get here
+	return kind != invocationPrint
 }
 
-
 // showStartupBanner displays a welcome message
 func showStartupBanner(syncResult mcpconfig.SyncResult) {
 	// Extract username from email (part before @)
@@ -163,10 +577,13 @@ func showStartupBanner(syncResult mcpconfig.SyncResult) {
 	}
 
 	// Version
-	version := autoupdate.GetVersion()
+	build := autoupdate.GetBuildInfo()
 	versionStr := ""
-	if version != "dev" {
-		versionStr = fmt.Sprintf(" %sv%s%s", colorGray, version, colorReset)
+	if build.Version != "dev" {
+		versionStr = fmt.Sprintf(" %sv%s%s", colorGray, build, colorReset)
+	}
+	if config.TelemetryDisabled(env.OS{}) {
+		versionStr += fmt.Sprintf(" %s(telemetry off)%s", colorGray, colorReset)
 	}
 
 	// Print welcome
@@ -174,65 +591,167 @@ func showStartupBanner(syncResult mcpconfig.SyncResult) {
 
 	// Show warning if agent key is not configured
 	if syncResult.NoAgentKey {
-		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ Run: echo 'agent_key=YOUR_KEY' > ~/.zeude/credentials%s\n",
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ No agent key: run 'zeude login'%s\n",
 			colorBlue, colorReset, colorYellow, colorReset)
 	}
+
+	showMOTD(syncResult)
 }
 
-// injectTelemetryEnv sets OTel environment variables for Claude's native telemetry.
-// Uses fail-open principle: only sets vars if not already configured.
-// Also injects Zeude user info as OTEL resource attributes for Bedrock users
-// who don't have email in their native telemetry.
-func injectTelemetryEnv(syncResult mcpconfig.SyncResult) {
-	// Enable Claude Code telemetry
-	setEnvIfEmpty("CLAUDE_CODE_ENABLE_TELEMETRY", "1")
+// showMOTD prints the dashboard's message of the day, colored by severity,
+// unless it expired or was already shown today. A failed sync falls back
+// to the message from the cached config.
+func showMOTD(syncResult mcpconfig.SyncResult) {
+	motd := syncResult.MOTD
+	if !syncResult.Success {
+		if cached, _ := mcpconfig.LoadCachedConfig(); cached != nil {
+			motd = cached.Config.MOTD
+		}
+	}
+	if motd = mcpconfig.TakeMOTD(motd); motd == nil {
+		return
+	}
+	color := colorGray
+	switch motd.Severity {
+	case mcpconfig.MOTDWarning:
+		color = colorYellow
+	case mcpconfig.MOTDCritical:
+		color = colorRed
+	}
+	// The text comes off the network: no escape sequences reach the terminal
+	text := strings.Map(func(r rune) rune {
+		if r != '\n' && unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimRight(motd.Text, "\n"))
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s%s%s\n", colorBlue, colorReset, color, line, colorReset)
+	}
+}
 
-	// Configure OTel exporter endpoint (using shared config package)
-	endpoint := config.GetCollectorEndpoint(config.DefaultCollectorEndpoint)
-	setEnvIfEmpty("OTEL_EXPORTER_OTLP_ENDPOINT", endpoint)
+// printCIErrors reports a failed or incomplete sync as one plain line for
+// the job log.
+func printCIErrors(syncResult mcpconfig.SyncResult) {
+	switch {
+	case !syncResult.Success && syncResult.Err != nil:
+		fmt.Fprintf(os.Stderr, "zeude: sync failed: %v\n", syncResult.Err)
+	case syncResult.HooksFailed+syncResult.SkillsFailed > 0:
+		fmt.Fprintf(os.Stderr, "zeude: %d hooks and %d skills could not be installed: %s\n",
+			syncResult.HooksFailed, syncResult.SkillsFailed, syncResult.FirstError)
+	}
+	if syncResult.HooksUnrunnable > 0 {
+		fmt.Fprintf(os.Stderr, "zeude: %d hooks can't run: %s not found on PATH\n",
+			syncResult.HooksUnrunnable, strings.Join(syncResult.MissingInterpreters, ", "))
+	}
+}
 
-	// Configure OTel protocol and exporters
-	// Use http/protobuf instead of grpc for better compatibility
-	setEnvIfEmpty("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
-	setEnvIfEmpty("OTEL_METRICS_EXPORTER", "otlp")
-	setEnvIfEmpty("OTEL_LOGS_EXPORTER", "otlp")
-	setEnvIfEmpty("OTEL_TRACES_EXPORTER", "otlp")
+// printQuietErrors prints what ZEUDE_QUIET still shows: problems that
+// stop Zeude from working until the user acts, rather than degrade it.
+func printQuietErrors(syncResult mcpconfig.SyncResult) {
+	var authErr *mcpconfig.AuthError
+	if errors.As(syncResult.Err, &authErr) {
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s✗ The dashboard rejected the agent key (HTTP %d): run 'zeude login'%s\n",
+			colorBlue, colorReset, colorRed, authErr.StatusCode, colorReset)
+	}
+	if syncResult.Overdue.Withheld() {
+		warnUpdateOverdue(syncResult.Overdue)
+	}
+}
 
-	// Inject Zeude user info as OTEL resource attributes
-	// This helps identify Bedrock users who don't have email in native telemetry
-	// and allows matching ClickHouse data with Supabase users
-	if syncResult.UserID != "" {
-		injectResourceAttribute("zeude.user.id", syncResult.UserID)
+// startSession exports the session ID for this launch so hooks, status
+// reports and telemetry from it can be matched up. A shim started inside
+// another claude keeps the outer session's ID; only a new session is
+// recorded in last_session. It reports whether the session is new.
+func startSession() bool {
+	id, inherited := telemetry.SessionID(env.OS{})
+	if inherited {
+		return false
 	}
-	if syncResult.UserEmail != "" {
-		injectResourceAttribute("zeude.user.email", syncResult.UserEmail)
+	os.Setenv(telemetry.SessionIDEnv, id)
+	path, err := paths.LastSession(env.OS{})
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = fsutil.WriteFileAtomic(path, []byte(id+"\n"), 0600)
+		}
 	}
-	if syncResult.Team != "" {
-		injectResourceAttribute("zeude.team", syncResult.Team)
+	if err != nil {
+		logger.Warn("failed to record session", "error", err)
 	}
+	return true
 }
 
-// injectResourceAttribute adds a key-value pair to OTEL_RESOURCE_ATTRIBUTES.
-// Appends to existing attributes if present, otherwise creates new.
-func injectResourceAttribute(key, value string) {
-	// Escape special characters in value (commas and equals signs)
-	escapedValue := strings.ReplaceAll(value, "=", "%3D")
-	escapedValue = strings.ReplaceAll(escapedValue, ",", "%2C")
+// sessionReportTimeout bounds each step of the session-start report: the
+// claude version probe, which overlaps the sync, and the request after it.
+// Launch waits for the report, so a slow dashboard just misses it.
+const sessionReportTimeout = time.Second
+
+// reportSessionStart tells the dashboard this session started, once the
+// sync has closed synced and only if it succeeded.
+func reportSessionStart(ctx context.Context, synced <-chan struct{}, syncResult *mcpconfig.SyncResult) {
+	start := mcpconfig.SessionStart{
+		SessionID: os.Getenv(telemetry.SessionIDEnv),
+		Version:   autoupdate.Version,
+		StartedAt: time.Now().UTC(),
+	}
+	if realClaude, err := resolver.FindRealBinary(); err == nil {
+		probeCtx, cancel := context.WithTimeout(ctx, sessionReportTimeout)
+		start.ClaudeVersion = resolver.ClaudeVersion(probeCtx, resolver.Options{}, realClaude)
+		cancel()
+	}
+	select {
+	case <-synced:
+	case <-ctx.Done():
+		return
+	}
+	if !syncResult.Success {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, sessionReportTimeout)
+	defer cancel()
+	if err := mcpconfig.ReportSessionStart(ctx, start); err != nil {
+		logger.Debug("session start report failed", "error", err)
+	}
+}
 
-	attr := key + "=" + escapedValue
+// collectorEndpoint returns the collector claude will export to: the
+// user's own OTEL_EXPORTER_OTLP_ENDPOINT, else the configured one (after
+// failover), as injectTelemetryEnv decides. fromEnv tells which.
+func collectorEndpoint(ctx context.Context) (endpoint string, fromEnv bool) {
+	if endpoint := os.Getenv(telemetry.EndpointEnv); endpoint != "" {
+		return endpoint, true
+	}
+	return telemetry.SelectEndpoint(ctx, env.OS{}, config.GetCollectorEndpoints()), false
+}
 
-	existing := os.Getenv("OTEL_RESOURCE_ATTRIBUTES")
-	if existing == "" {
-		os.Setenv("OTEL_RESOURCE_ATTRIBUTES", attr)
-	} else {
-		os.Setenv("OTEL_RESOURCE_ATTRIBUTES", existing+","+attr)
+// injectTelemetryEnv sets OTel environment variables for Claude's native
+// telemetry, leaving any the user already configured alone. `zeude env`
+// prints the same set. A collector the project file p chose never gets
+// the global agent key.
+func injectTelemetryEnv(syncResult mcpconfig.SyncResult, p *project.Config) {
+	agentKey := mcpconfig.AgentKey()
+	if projectCollector(p) {
+		agentKey = p.AgentKey(env.OS{})
 	}
+	telemetry.ApplyEnv(telemetry.ClaudeEnv(context.Background(), env.OS{}, telemetry.Identity{
+		UserID:    syncResult.UserID,
+		UserEmail: syncResult.UserEmail,
+		Team:      syncResult.Team,
+		SessionID: os.Getenv(telemetry.SessionIDEnv),
+		AgentKey:  agentKey,
+	}))
 }
 
-// setEnvIfEmpty sets an environment variable only if it's not already set.
-// This allows users to override Zeude defaults.
-func setEnvIfEmpty(key, value string) {
-	if os.Getenv(key) == "" {
-		os.Setenv(key, value)
+// injectProviderEnv points claude at the provider (Bedrock, Vertex) and
+// models the dashboard and ~/.zeude/config choose, leaving the user's own
+// exports alone. Without a fresh sync the cached settings are used, since
+// claude may not work at all without them.
+func injectProviderEnv(syncResult mcpconfig.SyncResult) {
+	settings := syncResult.Provider
+	if !syncResult.Success {
+		if cached, _ := mcpconfig.LoadCachedConfig(); cached != nil {
+			settings = cached.Config.Provider
+		}
 	}
+	telemetry.ApplyEnv(provider.ClaudeEnv(env.OS{}, settings))
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// onboard offers a fresh install the setup `zeude login` does: paste an
+// agent key, have the dashboard check it, save it and sync. It is offered
+// once; skipping leaves a marker so the shim doesn't ask again. It returns
+// the sync result to continue with.
+func onboard(syncResult mcpconfig.SyncResult) mcpconfig.SyncResult {
+	marker, err := paths.OnboardingDone(env.OS{})
+	if err != nil {
+		return syncResult
+	}
+	if _, err := os.Stat(marker); err == nil {
+		return syncResult
+	}
+
+	say := func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s", colorBlue, colorReset, fmt.Sprintf(format, a...))
+	}
+	say("Zeude isn't set up yet. Get your agent key from %s%s%s\n", colorBlue, mcpconfig.DashboardURL(), colorReset)
+	in := bufio.NewReader(os.Stdin)
+	for {
+		say("Paste your agent key (Enter to skip): ")
+		line, err := readSecret(in)
+		key := config.CleanPastedKey(line)
+		if err != nil || key == "" {
+			skipOnboarding(marker)
+			say("%sSkipped.%s Run 'zeude login' to set it up later.\n", colorGray, colorReset)
+			return syncResult
+		}
+		if problems := config.ValidateAgentKey(key); len(problems) > 0 {
+			say("%s✗ Invalid agent key:%s %s\n", colorRed, colorReset, strings.Join(problems, "; "))
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), startupBudget)
+		_, err = mcpconfig.CheckAgentKey(ctx, key)
+		cancel()
+		var authErr *mcpconfig.AuthError
+		switch {
+		case errors.As(err, &authErr):
+			say("%s✗ The dashboard rejected this key%s (HTTP %d)\n", colorRed, colorReset, authErr.StatusCode)
+			continue
+		case err != nil:
+			say("%s✗ Could not check the key:%s %v\n", colorRed, colorReset, err)
+			continue
+		}
+		if err := mcpconfig.SaveAgentKey(key); err != nil {
+			say("%s✗ Failed to save credentials:%s %v\n", colorRed, colorReset, err)
+			return syncResult
+		}
+		skipOnboarding(marker)
+		logger.Info("agent key set up at launch")
+		break
+	}
+
+	say("%s✓ Saved%s, syncing...", colorGreen, colorReset)
+	ctx, cancel := context.WithTimeout(context.Background(), startupBudget)
+	defer cancel()
+	result := mcpconfig.Sync(ctx, mcpconfig.SyncOptions{Version: autoupdate.Version})
+	if !result.Success {
+		fmt.Fprintf(os.Stderr, " %ssync failed%s\n", colorRed, colorReset)
+		return result
+	}
+	fmt.Fprintf(os.Stderr, " %s%d servers, %d hooks, %d skills%s\n", colorGray, result.ServerCount, result.HookCount, result.SkillCount, colorReset)
+	return result
+}
+
+// skipOnboarding writes the marker that stops onboard from asking again.
+func skipOnboarding(marker string) {
+	err := os.MkdirAll(filepath.Dir(marker), 0700)
+	if err == nil {
+		err = os.WriteFile(marker, nil, 0600)
+	}
+	if err != nil {
+		logger.Warn("failed to write onboarding marker", "error", err)
+	}
+}
+
+// readSecret reads a line with the terminal's echo off where possible, so
+// a pasted key doesn't stay on screen. Echo is restored on Ctrl-C too.
+func readSecret(in *bufio.Reader) (string, error) {
+	if setEcho(false) != nil {
+		return in.ReadString('\n')
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			setEcho(true)
+			fmt.Fprintln(os.Stderr)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	line, err := in.ReadString('\n')
+	signal.Stop(sigs)
+	close(done)
+	setEcho(true)
+	fmt.Fprintln(os.Stderr)
+	return line, err
+}
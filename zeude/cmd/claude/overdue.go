@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// overdueUrgent is how close to the deadline the warning turns red.
+const overdueUrgent = 24 * time.Hour
+
+// warnUpdateOverdue tells the user their update has been failing, more
+// insistently as the dashboard's deadline for withholding the agent key
+// from hooks approaches. Claude launches either way.
+func warnUpdateOverdue(o *mcpconfig.UpdateOverdue) {
+	if o == nil {
+		return
+	}
+	behind := "an update"
+	if o.Version != "" {
+		behind = "v" + o.Version
+	}
+	since := roughDuration(time.Since(o.Since))
+
+	switch {
+	case o.Expired:
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s✗ Update overdue: hooks aren't reporting until you run 'zeude update'%s\n",
+			colorBlue, colorReset, colorRed, colorReset)
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s  %s has failed to install for %s%s\n",
+			colorBlue, colorReset, colorGray, behind, since, colorReset)
+	case !o.Deadline.IsZero():
+		left := time.Until(o.Deadline)
+		color := colorYellow
+		if left < overdueUrgent {
+			color = colorRed
+		}
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ %s has failed to install for %s; hooks stop reporting in %s. Run 'zeude update'%s\n",
+			colorBlue, colorReset, color, behind, since, roughDuration(left), colorReset)
+	default:
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ %s has failed to install for %s; run 'zeude update'%s\n",
+			colorBlue, colorReset, colorYellow, behind, since, colorReset)
+	}
+}
+
+// roughDuration renders d in whole hours, or days past two of them.
+func roughDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return "under an hour"
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(d.Hours()/24))
+	}
+}
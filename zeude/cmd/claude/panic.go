@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/crashreport"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/resolver"
+)
+
+// startupPanic is a panic recovered in a startup goroutine, with the
+// goroutine's stack, kept for startup to re-raise where safeStartup can
+// catch it.
+type startupPanic struct {
+	value interface{}
+	stack []byte
+}
+
+var (
+	startupPanicMu sync.Mutex
+	firstPanic     *startupPanic
+)
+
+// recoverStartup is deferred at the top of each startup goroutine. A panic
+// there would kill the process before safeStartup could step in, so it is
+// recorded instead, and the goroutine just ends.
+func recoverStartup() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	crashreport.CapturePanic(r, stack)
+	startupPanicMu.Lock()
+	defer startupPanicMu.Unlock()
+	if firstPanic == nil {
+		firstPanic = &startupPanic{value: r, stack: stack}
+	}
+}
+
+// reraiseStartupPanic panics with the first recovered goroutine panic, if
+// any, so startup stops using results that may be half-written.
+func reraiseStartupPanic() {
+	startupPanicMu.Lock()
+	p := firstPanic
+	startupPanicMu.Unlock()
+	if p != nil {
+		panic(p)
+	}
+}
+
+// safeStartup runs startup, and if it panics, logs the panic and falls
+// back to the bare claude with no sync or telemetry: a bug in Zeude must
+// never cost the user claude itself.
+func safeStartup(kind invocation) (realClaude string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		stack := debug.Stack()
+		if p, ok := r.(*startupPanic); ok {
+			r, stack = p.value, p.stack
+		} else {
+			crashreport.CapturePanic(r, stack)
+		}
+		logger.Warn("startup panicked; launching claude without zeude", "panic", fmt.Sprint(r))
+		where := writePanicLog(r, stack)
+
+		if stderrIsTerminal() {
+			fmt.Fprint(os.Stderr, "\r\033[K") // over a half-printed status line
+		}
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s✗ Internal error (details in %s); starting claude without Zeude%s\n",
+			colorBlue, colorReset, colorRed, where, colorReset)
+
+		var err error
+		realClaude, err = resolver.FindRealBinary()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "zeude: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+	return startup(kind)
+}
+
+// writePanicLog appends the panic and its stack to logs/panic.log and
+// returns where it went, for the message.
+func writePanicLog(v interface{}, stack []byte) string {
+	path, err := paths.PanicLog(env.OS{})
+	if err != nil {
+		return "the zeude log"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "the zeude log"
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return "the zeude log"
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s zeude %s: panic: %v\n\n%s\n", time.Now().UTC().Format(time.RFC3339), autoupdate.GetBuildInfo(), v, stack)
+	return path
+}
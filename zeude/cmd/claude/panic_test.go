@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+func TestMain(m *testing.M) {
+	// Keep test runs out of the real ~/.zeude/logs
+	logger = logging.New(logging.Options{}).Component("shim")
+	os.Exit(m.Run())
+}
+
+// useTestHome points the home directory at a temp dir with a recorded
+// claude binary, and returns that binary's path.
+func useTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, key := range []string{"ZEUDE_HOME", "ZEUDE_USE_XDG", "XDG_DATA_HOME", "CLAUDE_CONFIG_DIR", telemetry.SessionIDEnv} {
+		t.Setenv(key, "")
+	}
+
+	claude := filepath.Join(home, "claude")
+	if err := os.WriteFile(claude, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pathFile, _ := paths.RealBinaryPath(env.OS{})
+	if err := os.MkdirAll(filepath.Dir(pathFile), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathFile, []byte(claude+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return claude
+}
+
+// TestStartupPanicStillLaunchesClaude injects a sync that panics and
+// checks safeStartup still hands back the real claude, with the panic
+// logged.
+func TestStartupPanicStillLaunchesClaude(t *testing.T) {
+	claude := useTestHome(t)
+	syncConfig = func(context.Context, mcpconfig.SyncOptions) mcpconfig.SyncResult {
+		var config *mcpconfig.ConfigResponse
+		_ = config.Skills // the nil dereference a malformed cache could cause
+		return mcpconfig.SyncResult{}
+	}
+	t.Cleanup(func() {
+		syncConfig = mcpconfig.Sync
+		firstPanic = nil
+	})
+
+	// claude mcp runs only the sync, nothing that needs the network
+	got := safeStartup(invocationManage)
+	if want, _ := filepath.EvalSymlinks(claude); got != want {
+		t.Errorf("safeStartup = %q, want %q", got, want)
+	}
+
+	logPath, _ := paths.PanicLog(env.OS{})
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("panic not logged: %v", err)
+	}
+	for _, want := range []string{"nil pointer dereference", "TestStartupPanicStillLaunchesClaude"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("panic log lacks %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestRecoverStartupKeepsFirstPanic(t *testing.T) {
+	t.Cleanup(func() { firstPanic = nil })
+	for _, v := range []string{"first", "second"} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer recoverStartup()
+			panic(v)
+		}()
+		<-done
+	}
+
+	defer func() {
+		p, ok := recover().(*startupPanic)
+		if !ok || p.value != "first" || len(p.stack) == 0 {
+			t.Errorf("re-raised %+v, want the first panic with its stack", p)
+		}
+	}()
+	reraiseStartupPanic()
+	t.Error("reraiseStartupPanic returned")
+}
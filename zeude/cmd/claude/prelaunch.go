@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// preLaunchBlock is the exit code with which a script stops claude from
+// starting. Any other failure only warns.
+const preLaunchBlock = 2
+
+// preLaunchTimeout bounds each pre-launch script; one that runs longer is
+// killed and counts as a failure. Tests shorten it.
+var preLaunchTimeout = 5 * time.Second
+
+// Environment variables set for pre-launch scripts, besides the shim's own
+// environment (which already has ZEUDE_SESSION_ID and the telemetry
+// variables claude gets).
+const (
+	preLaunchEmailEnv = "ZEUDE_USER_EMAIL"
+	preLaunchTeamEnv  = "ZEUDE_TEAM"
+)
+
+// preLaunchSummary is the JSON a pre-launch script gets on stdin.
+type preLaunchSummary struct {
+	Version         string `json:"version"`
+	SessionID       string `json:"sessionId"`
+	UserEmail       string `json:"userEmail,omitempty"`
+	Team            string `json:"team,omitempty"`
+	Success         bool   `json:"success"`
+	FromCache       bool   `json:"fromCache"`
+	Background      bool   `json:"background"` // the sync is still running detached
+	Paused          bool   `json:"paused"`
+	NoAgentKey      bool   `json:"noAgentKey"`
+	ServerCount     int    `json:"serverCount"`
+	DisabledServers int    `json:"disabledServerCount"`
+	HookCount       int    `json:"hookCount"`
+	SkillCount      int    `json:"skillCount"`
+	Error           string `json:"error,omitempty"`
+}
+
+// runPreLaunch runs the executables in ~/.zeude/pre-launch.d in name order,
+// each with the sync summary on stdin and its output on stderr. Failures
+// are reported and otherwise ignored, except a script exiting with
+// preLaunchBlock: the remaining scripts are skipped and it returns false,
+// so claude isn't started.
+func runPreLaunch(syncResult mcpconfig.SyncResult, background bool) bool {
+	scripts := preLaunchScripts(env.OS{})
+	if len(scripts) == 0 {
+		return true
+	}
+
+	summary := preLaunchSummary{
+		Version:         autoupdate.Version,
+		SessionID:       os.Getenv(telemetry.SessionIDEnv),
+		UserEmail:       syncResult.UserEmail,
+		Team:            syncResult.Team,
+		Success:         syncResult.Success,
+		FromCache:       syncResult.FromCache,
+		Background:      background,
+		Paused:          syncResult.Paused,
+		NoAgentKey:      syncResult.NoAgentKey,
+		ServerCount:     syncResult.ServerCount,
+		DisabledServers: syncResult.DisabledServerCount,
+		HookCount:       syncResult.HookCount,
+		SkillCount:      syncResult.SkillCount,
+	}
+	if syncResult.Err != nil {
+		summary.Error = syncResult.Err.Error()
+	}
+	input, _ := json.Marshal(summary)
+	environ := append(os.Environ(),
+		preLaunchEmailEnv+"="+syncResult.UserEmail,
+		preLaunchTeamEnv+"="+syncResult.Team,
+	)
+
+	for _, script := range scripts {
+		name := filepath.Base(script)
+		code, err := runPreLaunchScript(script, input, environ)
+		switch {
+		case code == preLaunchBlock:
+			logger.Warn("pre-launch script blocked launch", "script", name)
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s✗ %s stopped claude from starting%s\n", colorBlue, colorReset, colorRed, name, colorReset)
+			return false
+		case err != nil:
+			logger.Warn("pre-launch script failed", "script", name, "error", err)
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ pre-launch %s: %v%s\n", colorBlue, colorReset, colorYellow, name, err, colorReset)
+		}
+	}
+	return true
+}
+
+// runPreLaunchScript runs one script and returns its exit code, -1 when it
+// could not be run or was killed.
+func runPreLaunchScript(script string, input []byte, environ []string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), preLaunchTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stderr // stdout is claude's
+	cmd.Stderr = os.Stderr
+	cmd.Env = environ
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return -1, fmt.Errorf("timed out after %s", preLaunchTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), err
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// preLaunchScripts returns the executables in the pre-launch directory,
+// sorted by name. Hidden files and editor backups are skipped.
+func preLaunchScripts(e env.Env) []string {
+	dir, err := paths.PreLaunch(e)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("failed to read pre-launch directory", "error", err)
+		}
+		return nil
+	}
+	var scripts []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, name)) // follows symlinks
+		if err != nil || !info.Mode().IsRegular() || !isExecutable(name, info.Mode()) {
+			continue
+		}
+		scripts = append(scripts, filepath.Join(dir, name))
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// isExecutable reports whether a file can be run directly: by its mode
+// bits, or on Windows, which has none, by its extension.
+func isExecutable(name string, mode os.FileMode) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return mode&0111 != 0
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// preLaunchDir creates the pre-launch directory in a temp home.
+func preLaunchDir(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("pre-launch scripts here are shell scripts")
+	}
+	useTestHome(t)
+	dir, _ := paths.PreLaunch(env.OS{})
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// writeScript writes a shell script into dir.
+func writeScript(t *testing.T, dir, name, body string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPreLaunchScripts(t *testing.T) {
+	dir := preLaunchDir(t)
+	b := writeScript(t, dir, "20-b", "", 0755)
+	a := writeScript(t, dir, "10-a", "", 0700)
+	writeScript(t, dir, "15-not-executable", "", 0644)
+	writeScript(t, dir, ".hidden", "", 0755)
+	writeScript(t, dir, "30-c~", "", 0755)
+	os.Mkdir(filepath.Join(dir, "40-dir"), 0755)
+	target := writeScript(t, t.TempDir(), "elsewhere", "", 0755)
+	link := filepath.Join(dir, "50-link")
+	os.Symlink(target, link)
+	os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "60-broken"))
+
+	got := preLaunchScripts(env.OS{})
+	want := []string{a, b, link}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scripts:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	os.RemoveAll(dir)
+	if got := preLaunchScripts(env.OS{}); got != nil {
+		t.Errorf("scripts with no directory = %v", got)
+	}
+}
+
+func TestRunPreLaunch(t *testing.T) {
+	tests := []struct {
+		name   string
+		codes  []int // exit code of each script, in order
+		ran    int   // how many of them run
+		launch bool
+		output string
+	}{
+		{"no scripts", nil, 0, true, ""},
+		{"all pass", []int{0, 0}, 2, true, ""},
+		{"failure only warns", []int{1, 0}, 2, true, "⚠ pre-launch 1: exit status 1"},
+		{"exit 2 blocks", []int{0, 2, 0}, 2, false, "✗ 2 stopped claude from starting"},
+		{"other codes don't block", []int{3, 127, 0}, 3, true, "⚠ pre-launch 2: exit status 127"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := preLaunchDir(t)
+			log := filepath.Join(t.TempDir(), "ran")
+			for i, code := range tt.codes {
+				name := fmt.Sprint(i + 1)
+				writeScript(t, dir, name, fmt.Sprintf("echo %s >> %s\nexit %d", name, log, code), 0755)
+			}
+			var launch bool
+			out := captureOutput(t, func() { launch = runPreLaunch(mcpconfig.SyncResult{Success: true}, false) })
+			if launch != tt.launch {
+				t.Errorf("launch = %v, want %v", launch, tt.launch)
+			}
+			data, _ := os.ReadFile(log)
+			if ran := len(strings.Fields(string(data))); ran != tt.ran {
+				t.Errorf("%d scripts ran, want %d", ran, tt.ran)
+			}
+			if tt.output == "" && len(out) > 0 || !strings.Contains(string(out), tt.output) {
+				t.Errorf("output %q, want %q", out, tt.output)
+			}
+		})
+	}
+}
+
+func TestPreLaunchInput(t *testing.T) {
+	dir := preLaunchDir(t)
+	t.Setenv(telemetry.SessionIDEnv, "sess-1")
+	out := t.TempDir()
+	writeScript(t, dir, "capture", `cat > `+out+`/stdin
+echo "$ZEUDE_USER_EMAIL|$ZEUDE_TEAM|$ZEUDE_SESSION_ID" > `+out+`/env
+echo to-stdout`, 0755)
+
+	result := mcpconfig.SyncResult{
+		Success:     true,
+		FromCache:   true,
+		UserEmail:   "dev@example.com",
+		Team:        "platform",
+		ServerCount: 3,
+		HookCount:   2,
+		SkillCount:  1,
+		Err:         errors.New("partial"),
+	}
+	printed := captureOutput(t, func() { runPreLaunch(result, true) })
+
+	var got preLaunchSummary
+	data, _ := os.ReadFile(filepath.Join(out, "stdin"))
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("stdin %q: %v", data, err)
+	}
+	want := preLaunchSummary{
+		Version: got.Version, SessionID: "sess-1", UserEmail: "dev@example.com", Team: "platform",
+		Success: true, FromCache: true, Background: true, ServerCount: 3, HookCount: 2, SkillCount: 1, Error: "partial",
+	}
+	if got != want || got.Version == "" {
+		t.Errorf("stdin = %+v, want %+v", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "env")); string(data) != "dev@example.com|platform|sess-1\n" {
+		t.Errorf("environment = %q", data)
+	}
+	// Script stdout would land in claude's output, so it goes to stderr
+	if !strings.Contains(string(printed), "to-stdout") {
+		t.Errorf("script output %q not passed through", printed)
+	}
+}
+
+func TestPreLaunchTimeout(t *testing.T) {
+	dir := preLaunchDir(t)
+	old := preLaunchTimeout
+	preLaunchTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { preLaunchTimeout = old })
+	writeScript(t, dir, "slow", "exec sleep 10", 0755)
+
+	start := time.Now()
+	var launch bool
+	out := captureOutput(t, func() { launch = runPreLaunch(mcpconfig.SyncResult{}, false) })
+	if !launch {
+		t.Error("a timed-out script blocked the launch")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("runPreLaunch took %v", d)
+	}
+	if !strings.Contains(string(out), "timed out after 100ms") {
+		t.Errorf("output %q, want a timeout warning", out)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/project"
+)
+
+// applyProjectConfig picks up the .zeude file of the repository claude is
+// started in, if the user trusted it. mcpconfig reads its dashboard URL and
+// agent key itself; the collector endpoint is passed on as ZEUDE_ENDPOINT,
+// for this process and claude only, unless the user exported one. Nothing
+// is written to the global files. It returns the file when it overrides
+// anything, for the status line, or nil. A malformed or untrusted file is
+// ignored with a warning.
+func applyProjectConfig(interactive bool) *project.Config {
+	p, err := project.Find(env.OS{})
+	if err != nil {
+		logger.Warn("ignoring project file", "error", err)
+		if interactive {
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ Ignoring %v; using global settings%s\n",
+				colorBlue, colorReset, colorYellow, err, colorReset)
+		}
+		return nil
+	}
+	if p == nil || !p.Overrides() {
+		return nil
+	}
+	if !p.Trusted(env.OS{}) {
+		logger.Warn("ignoring untrusted project file", "path", p.Path)
+		if interactive {
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %s⚠ Ignoring %s until you run 'zeude trust'; using global settings%s\n",
+				colorBlue, colorReset, colorYellow, p.Path, colorReset)
+		}
+		return nil
+	}
+	if endpoint := p.Get(project.EndpointKey); endpoint != "" && os.Getenv("ZEUDE_ENDPOINT") == "" {
+		os.Setenv("ZEUDE_ENDPOINT", endpoint)
+	}
+	logger.Debug("using project config", "path", p.Path)
+	return p
+}
+
+// projectCollector reports whether p chose the collector claude exports
+// to, which then only gets the project's own agent key.
+func projectCollector(p *project.Config) bool {
+	return p != nil && p.Get(project.EndpointKey) != "" && os.Getenv("ZEUDE_ENDPOINT") == p.Get(project.EndpointKey)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/provider"
+)
+
+func TestInjectProviderEnvKeepsExports(t *testing.T) {
+	useTestHome(t)
+	for _, key := range []string{provider.UseBedrockEnv, provider.UseVertexEnv, "AWS_PROFILE", "ANTHROPIC_MODEL", "ANTHROPIC_SMALL_FAST_MODEL"} {
+		t.Setenv(key, "") // restored when the test ends
+	}
+	t.Setenv("AWS_REGION", "ap-south-1")
+
+	injectProviderEnv(mcpconfig.SyncResult{
+		Success:  true,
+		Provider: provider.Settings{Provider: provider.Bedrock, AWSRegion: "us-west-2", AWSProfile: "eng"},
+	})
+	for key, want := range map[string]string{
+		provider.UseBedrockEnv: "1",
+		"AWS_REGION":           "ap-south-1",
+		"AWS_PROFILE":          "eng",
+		"ANTHROPIC_MODEL":      "",
+	} {
+		if got := os.Getenv(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/zeude/zeude/internal/resolver"
+)
+
+// recoverRealBinary helps a user whose real claude isn't on PATH: it lists
+// the installs found in the usual places, lets them pick one, and offers
+// to remember it. With none found it prints the install command. It only
+// returns with a binary to run.
+func recoverRealBinary(cause error) string {
+	fmt.Fprintf(os.Stderr, "%s[zeude]%s %s%v%s\n", colorBlue, colorReset, colorYellow, cause, colorReset)
+	fmt.Fprintf(os.Stderr, "%s[zeude]%s Looking for Claude in the usual install locations...\n", colorBlue, colorReset)
+	candidates := resolver.Candidates(resolver.Options{})
+	if len(candidates) == 0 {
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s No Claude install found. Install it with:\n\n    %s\n\n", colorBlue, colorReset, resolver.InstallCommand)
+		os.Exit(1)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	ask := func(prompt string) string {
+		fmt.Fprintf(os.Stderr, "%s[zeude]%s %s", colorBlue, colorReset, prompt)
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(os.Stderr)
+			os.Exit(1)
+		}
+		return strings.ToLower(strings.TrimSpace(line))
+	}
+
+	var chosen string
+	if len(candidates) == 1 {
+		if answer := ask(fmt.Sprintf("Found %s. Run it? [Y/n]: ", candidates[0])); answer != "" && answer != "y" && answer != "yes" {
+			os.Exit(1)
+		}
+		chosen = candidates[0]
+	} else {
+		for i, c := range candidates {
+			fmt.Fprintf(os.Stderr, "  %d) %s\n", i+1, c)
+		}
+	}
+	for chosen == "" {
+		answer := ask(fmt.Sprintf("Run which one? [1-%d, Enter for 1, q to quit]: ", len(candidates)))
+		if answer == "q" {
+			os.Exit(1)
+		}
+		n := 1
+		if answer != "" {
+			var err error
+			if n, err = strconv.Atoi(answer); err != nil || n < 1 || n > len(candidates) {
+				continue
+			}
+		}
+		chosen = candidates[n-1]
+	}
+
+	if answer := ask("Remember it for next time? [Y/n]: "); answer == "" || answer == "y" || answer == "yes" {
+		if err := resolver.SaveRealBinary(resolver.Options{}, chosen); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[zeude]%s %sfailed to save: %v%s\n", colorBlue, colorReset, colorYellow, err, colorReset)
+		} else {
+			logger.Info("recorded real binary", "path", chosen)
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// reportSelfTelemetry sends sync and update outcomes for this launch to the
+// collector so fleet-wide failure rates and latency are visible.
+func reportSelfTelemetry(syncResult mcpconfig.SyncResult, syncDuration time.Duration, updateResult autoupdate.UpdateResult, updateDuration time.Duration) {
+	resource := map[string]string{
+		"service.name":    "zeude",
+		"service.version": autoupdate.GetVersion(),
+		"os.type":         runtime.GOOS,
+		"host.arch":       runtime.GOARCH,
+	}
+	if syncResult.UserID != "" {
+		resource["zeude.user.id"] = syncResult.UserID
+	}
+	if syncResult.UserEmail != "" {
+		resource["zeude.user.email"] = syncResult.UserEmail
+	}
+	if syncResult.Team != "" {
+		resource["zeude.team"] = syncResult.Team
+	}
+
+	now := time.Now()
+	records := []telemetry.Record{
+		{
+			Name: "zeude.sync",
+			Time: now,
+			Attributes: map[string]interface{}{
+				"outcome":      syncOutcome(syncResult),
+				"duration_ms":  syncDuration.Milliseconds(),
+				"cache_hit":    syncResult.FromCache,
+				"hook_count":   syncResult.HookCount,
+				"skill_count":  syncResult.SkillCount,
+				"server_count": syncResult.ServerCount,
+			},
+		},
+		{
+			Name: "zeude.update",
+			Time: now,
+			Attributes: map[string]interface{}{
+				"outcome":     updateOutcome(updateResult),
+				"duration_ms": updateDuration.Milliseconds(),
+				"new_version": updateResult.NewVersion,
+			},
+		},
+	}
+
+	telemetry.ReportSelf(resource, records)
+}
+
+func syncOutcome(r mcpconfig.SyncResult) string {
+	switch {
+	case r.Paused:
+		return "paused"
+	case r.NoAgentKey:
+		return "no_agent_key"
+	case r.Success && r.FromCache:
+		return "cached"
+	case r.Success:
+		return "ok"
+	default:
+		return "failed"
+	}
+}
+
+func updateOutcome(r autoupdate.UpdateResult) string {
+	switch {
+	case r.Error != nil:
+		return "error"
+	case r.Updated:
+		return "updated"
+	case r.Skipped:
+		return "skipped"
+	case r.Held:
+		return "held"
+	case r.NewVersionAvailable:
+		return "available"
+	default:
+		return "current"
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// sessionSchemaVersion is bumped when a field of the session file changes
+// meaning or is removed. Adding a field doesn't bump it.
+const sessionSchemaVersion = 1
+
+// sessionFile is the JSON in ~/.zeude/session.json: what the shim knew
+// when it launched the current session, for hooks to read.
+type sessionFile struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	SessionID     string    `json:"sessionId"`
+	UserID        string    `json:"userId,omitempty"`
+	UserEmail     string    `json:"userEmail,omitempty"`
+	Team          string    `json:"team,omitempty"`
+	ShimVersion   string    `json:"shimVersion"`
+	ClaudePath    string    `json:"claudePath"`
+	FromCache     bool      `json:"fromCache"` // the config came from the local cache
+	StartedAt     time.Time `json:"startedAt"`
+}
+
+// writeSessionFile replaces the session file with this launch's and
+// points ZEUDE_SESSION_FILE at it. Failing to write it only warns.
+func writeSessionFile(syncResult mcpconfig.SyncResult, realClaude string) {
+	path, err := paths.Session(env.OS{})
+	if err == nil {
+		var data []byte
+		data, err = json.MarshalIndent(sessionFile{
+			SchemaVersion: sessionSchemaVersion,
+			SessionID:     os.Getenv(telemetry.SessionIDEnv),
+			UserID:        syncResult.UserID,
+			UserEmail:     syncResult.UserEmail,
+			Team:          syncResult.Team,
+			ShimVersion:   autoupdate.Version,
+			ClaudePath:    realClaude,
+			FromCache:     syncResult.FromCache,
+			StartedAt:     time.Now().UTC(),
+		}, "", "  ")
+		if err == nil {
+			if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+				err = fsutil.WriteFileAtomic(path, append(data, '\n'), 0600)
+			}
+		}
+	}
+	if err != nil {
+		logger.Warn("failed to write session file", "error", err)
+		return
+	}
+	os.Setenv(telemetry.SessionFileEnv, path)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Startup phases the spinner names while they are outstanding.
+const (
+	phaseUpdate = "checking updates"
+	phaseSync   = "syncing config"
+)
+
+const spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinner animates the "[zeude] Initializing..." line on stderr while the
+// startup work runs. A nil *spinner is valid and does nothing, so callers
+// don't check whether one was started.
+type spinner struct {
+	prefix  string // printed text the spinner follows, with colors
+	visible int    // width of prefix on screen
+
+	mu      sync.Mutex
+	pending []string
+	stopped bool
+	quit    chan struct{}
+	exited  chan struct{}
+}
+
+// startSpinner starts animating after prefix, which the caller has
+// already printed, naming phases until each is finished. It returns nil
+// when stderr is not a terminal.
+func startSpinner(prefix string, visible int, phases ...string) *spinner {
+	if !stderrIsTerminal() {
+		return nil
+	}
+	s := &spinner{
+		prefix:  prefix,
+		visible: visible,
+		pending: phases,
+		quit:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// finish drops phase from the message.
+func (s *spinner) finish(phase string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, p := range s.pending {
+		if p == phase {
+			s.pending = append(s.pending[:i:i], s.pending[i+1:]...)
+			break
+		}
+	}
+}
+
+// stop erases the spinner and leaves the cursor after prefix, as it was
+// before the spinner started. It is safe to call more than once.
+func (s *spinner) stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	s.mu.Unlock()
+	close(s.quit)
+	<-s.exited
+}
+
+func (s *spinner) run() {
+	defer close(s.exited)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	drawn := false
+	for frame := 0; ; frame++ {
+		select {
+		case <-s.quit:
+			if drawn {
+				fmt.Fprintf(os.Stderr, "\r\033[K%s", s.prefix)
+			}
+			return
+		case <-ticker.C:
+			// The first tick is skipped so a fast start doesn't flicker
+			if frame == 0 {
+				continue
+			}
+			if line := s.line(frame); line != "" {
+				fmt.Fprintf(os.Stderr, "\r\033[K%s %s", s.prefix, line)
+				drawn = true
+			}
+		}
+	}
+}
+
+// line is what follows the prefix: a frame and the pending phases, cut to
+// fit the terminal so the line never wraps (\r only returns to the start of
+// the last row). It is "" when not even the frame fits.
+func (s *spinner) line(frame int) string {
+	s.mu.Lock()
+	msg := strings.Join(s.pending, ", ")
+	s.mu.Unlock()
+
+	width := terminalWidth(os.Stderr)
+	if width <= 0 {
+		width = 80
+	}
+	room := width - 1 - s.visible - 2 // keep the last column free; " ⠋"
+	if room < 0 {
+		return ""
+	}
+	line := spinnerFrames[frame%len(spinnerFrames)]
+	if msg == "" || room < 4 {
+		return line
+	}
+	if utf8.RuneCountInString(msg)+1 > room {
+		msg = string([]rune(msg)[:room-2]) + "…"
+	}
+	return fmt.Sprintf("%s %s%s%s", line, colorGray, msg, colorReset)
+}
+
+// stderrIsTerminal reports whether stderr is a character device.
+func stderrIsTerminal() bool {
+	stat, err := os.Stderr.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/project"
+	"github.com/zeude/zeude/internal/resolver"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// statusArg makes the shim describe itself instead of starting claude: the
+// quickest way to tell whether `claude` on PATH is Zeude's. It is never
+// passed on.
+const statusArg = "--zeude-status"
+
+// wantsStatus reports whether args ask for statusArg. It is meant to come
+// first, but anywhere before a "--" counts, since claude would reject it.
+func wantsStatus(args []string) bool {
+	for _, arg := range args[1:] {
+		if arg == "--" {
+			return false
+		}
+		if arg == statusArg {
+			return true
+		}
+	}
+	return false
+}
+
+// printShimStatus prints what the shim would run with, from local state
+// only; nothing is synced or updated.
+func printShimStatus() {
+	applyProjectConfig(false)
+	self, _ := os.Executable()
+	fmt.Printf("Zeude shim:     %s (%s)\n", autoupdate.Version, self)
+
+	if real, err := resolver.FindRealBinary(); err != nil {
+		fmt.Printf("Real claude:    %s%v%s\n", colorRed, err, colorReset)
+	} else {
+		fmt.Printf("Real claude:    %s\n", real)
+	}
+
+	if key := mcpconfig.AgentKey(); key != "" {
+		fmt.Printf("Agent key:      %s\n", config.MaskAgentKey(key))
+	} else {
+		fmt.Printf("Agent key:      %snot configured%s (run 'zeude login')\n", colorYellow, colorReset)
+	}
+	fmt.Printf("Dashboard:      %s\n", mcpconfig.DashboardURL())
+	if p, err := project.Find(env.OS{}); err != nil {
+		fmt.Printf("Project config: %signored: %v%s\n", colorYellow, err, colorReset)
+	} else if p != nil && p.Overrides() && !p.Trusted(env.OS{}) {
+		fmt.Printf("Project config: %s%s ignored: not trusted (run 'zeude trust')%s\n", colorYellow, p.Path, colorReset)
+	} else if p != nil && p.Overrides() {
+		fmt.Printf("Project config: %s\n", p.Path)
+	}
+
+	if pause := mcpconfig.CurrentPause(); pause.Paused {
+		fmt.Printf("Sync:           %spaused%s\n", colorYellow, colorReset)
+	}
+	if cached, expired := mcpconfig.LoadCachedConfig(); cached == nil {
+		fmt.Printf("Last sync:      %snone yet%s\n", colorYellow, colorReset)
+	} else {
+		c := cached.Config
+		freshness := "fresh"
+		if expired {
+			freshness = colorYellow + "stale" + colorReset
+		}
+		fmt.Printf("Last sync:      %s ago, %s: %d servers, %d hooks, %d skills\n",
+			time.Since(cached.CachedAt).Round(time.Second), freshness, len(c.MCPServers), len(c.Hooks), len(c.Skills))
+		if c.UserEmail != "" {
+			fmt.Printf("User:           %s\n", c.UserEmail)
+		}
+	}
+
+	endpoint, fromEnv := collectorEndpoint(context.Background())
+	source := "from config"
+	if fromEnv {
+		source = "from " + telemetry.EndpointEnv
+	}
+	fmt.Printf("Collector:      %s (%s)\n", endpoint, source)
+}
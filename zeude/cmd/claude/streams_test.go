@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zeude/zeude/internal/term"
+)
+
+// openStream returns a file of the given kind: "tty" (a character device),
+// "pipe" or "file".
+func openStream(t *testing.T, kind string) *os.File {
+	t.Helper()
+	var f *os.File
+	var err error
+	switch kind {
+	case "tty":
+		f, err = os.Open(os.DevNull)
+	case "pipe":
+		var w *os.File
+		f, w, err = os.Pipe()
+		if err == nil {
+			t.Cleanup(func() { w.Close() })
+		}
+	case "file":
+		f, err = os.Create(filepath.Join(t.TempDir(), "stream"))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// TestStreamCombinations checks that prompting needs both stdin and stderr
+// to be terminals, while color only depends on stderr.
+func TestStreamCombinations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("NUL is not a character device")
+	}
+	for _, key := range []string{"NO_COLOR", "ZEUDE_NO_COLOR", "CI", "ZEUDE_CI"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("TERM", "xterm")
+
+	oldStdin, oldStderr := os.Stdin, os.Stderr
+	t.Cleanup(func() { os.Stdin, os.Stderr = oldStdin, oldStderr })
+
+	kinds := []string{"tty", "pipe", "file"}
+	for _, in := range kinds {
+		for _, errKind := range kinds {
+			t.Run(in+" to "+errKind, func(t *testing.T) {
+				os.Stdin, os.Stderr = openStream(t, in), openStream(t, errKind)
+
+				tty := errKind == "tty"
+				gotTTY := stderrIsTerminal()
+				gotColors := shimColors()
+				gotInteractive := isInteractive(invocationSession)
+				gotPrint := isInteractive(invocationPrint)
+				os.Stdin, os.Stderr = oldStdin, oldStderr
+
+				if gotTTY != tty {
+					t.Errorf("stderrIsTerminal() = %v, want %v", gotTTY, tty)
+				}
+				if want := term.Colors(tty); gotColors != want {
+					t.Errorf("shimColors() = %q, want %q", gotColors, want)
+				}
+				if want := in == "tty" && tty; gotInteractive != want {
+					t.Errorf("isInteractive(session) = %v, want %v", gotInteractive, want)
+				}
+				if gotPrint {
+					t.Error("isInteractive(print) = true")
+				}
+			})
+		}
+	}
+}
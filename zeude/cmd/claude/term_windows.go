@@ -0,0 +1,19 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// terminalWidth returns 0: the console size isn't queried on Windows, and
+// callers assume 80 columns.
+func terminalWidth(f *os.File) int {
+	return 0
+}
+
+// setEcho is unsupported on Windows; input stays visible.
+func setEcho(on bool) error {
+	return errors.New("not supported on Windows")
+}
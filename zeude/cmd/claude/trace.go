@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// traceEnv prints how long each startup phase took before claude starts.
+const traceEnv = "ZEUDE_TRACE"
+
+// launchStart is when the shim was started, near enough.
+var launchStart = time.Now()
+
+// startupTrace collects phase durations from the startup goroutines.
+type startupTrace struct {
+	mu     sync.Mutex
+	phases []tracePhase
+}
+
+type tracePhase struct {
+	name string
+	d    time.Duration
+}
+
+var trace startupTrace
+
+// add records a phase; zero durations belong to phases that didn't run.
+func (t *startupTrace) add(name string, d time.Duration) {
+	if d == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases = append(t.phases, tracePhase{name, d})
+}
+
+// addSync records the phases inside a sync.
+func (t *startupTrace) addSync(timings mcpconfig.SyncTimings) {
+	t.add("config fetch", timings.Fetch)
+	t.add("claude.json merge", timings.Merge)
+	t.add("hook install", timings.Hooks)
+	t.add("skill install", timings.Skills)
+	t.add("status reporting", timings.Status)
+}
+
+// report writes the phases and the total so far to the debug log and,
+// with ZEUDE_TRACE, to stderr. The update check and the sync run in
+// parallel, so the phases add up to more than the total.
+func (t *startupTrace) report() {
+	t.mu.Lock()
+	phases := append(t.phases, tracePhase{"total before exec", time.Since(launchStart)})
+	t.mu.Unlock()
+
+	kv := make([]interface{}, 0, 2*len(phases))
+	for _, p := range phases {
+		kv = append(kv, strings.ReplaceAll(p.name, " ", "_"), millis(p.d))
+	}
+	logger.Debug("startup timings", kv...)
+
+	if !config.IsTruthy(os.Getenv(traceEnv)) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[zeude] startup timings:\n")
+	for _, p := range phases {
+		fmt.Fprintf(os.Stderr, "  %-18s %9s\n", p.name, millis(p.d))
+	}
+}
+
+// millis renders d in milliseconds: most phases take well under one.
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const credentialsCheckName = "Credentials"
+
+// checkCredentials validates where the agent key comes from, the credentials
+// file permissions, and the shape of the key. The key itself is never printed.
+func checkCredentials() checkResult {
+	// Environment variable takes precedence over the file (same as the shim)
+	if key := strings.TrimSpace(os.Getenv(config.AgentKeyEnv)); key != "" {
+		if problems := config.ValidateAgentKey(key); len(problems) > 0 {
+			return checkResult{credentialsCheckName, "fail", fmt.Sprintf("%s: %s", config.AgentKeyEnv, strings.Join(problems, "; ")), nil}
+		}
+		return checkResult{credentialsCheckName, "pass", fmt.Sprintf("Agent key from %s (file checks skipped)", config.AgentKeyEnv), nil}
+	}
+
+	credPath, err := paths.Credentials(env.OS{})
+	if err != nil {
+		return checkResult{credentialsCheckName, "fail", "Cannot get home directory", nil}
+	}
+
+	info, err := os.Stat(credPath)
+	if os.IsNotExist(err) {
+		return checkResult{credentialsCheckName, "warn", "No credentials file at ~/.zeude/credentials", nil}
+	}
+	if err != nil {
+		return checkResult{credentialsCheckName, "fail", fmt.Sprintf("Cannot stat credentials: %v", err), nil}
+	}
+
+	data, err := os.ReadFile(credPath)
+	if err != nil {
+		return checkResult{credentialsCheckName, "fail", fmt.Sprintf("Cannot read credentials: %v", err), nil}
+	}
+
+	problems, notes := credentialsContentProblems(data)
+
+	// Group/world-readable keys can be read by other local users
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		msg := fmt.Sprintf("Credentials file is group/world-accessible (mode %04o, want 0600)", perm)
+		if len(problems) > 0 {
+			msg += "; " + strings.Join(problems, "; ")
+		}
+		return checkResult{credentialsCheckName, "fail", msg, func() (string, error) {
+			if err := os.Chmod(credPath, 0600); err != nil {
+				return "", err
+			}
+			if len(problems) > 0 {
+				return "", fmt.Errorf("permissions fixed, but %s", strings.Join(problems, "; "))
+			}
+			return "chmod 0600 ~/.zeude/credentials", nil
+		}}
+	}
+
+	if len(problems) > 0 {
+		return checkResult{credentialsCheckName, "fail", strings.Join(problems, "; "), nil}
+	}
+
+	// The parser tolerates these, but they usually mean the file was hand-edited
+	if len(notes) > 0 {
+		return checkResult{credentialsCheckName, "warn", "Agent key usable, but: " + strings.Join(notes, "; "), nil}
+	}
+
+	return checkResult{credentialsCheckName, "pass", "Agent key configured (~/.zeude/credentials, mode 0600)", nil}
+}
+
+// credentialsContentProblems parses credentials content with the same parser
+// the shim uses. Problems make the key unusable; notes are lines the parser
+// repaired or skipped. Both are printable.
+func credentialsContentProblems(data []byte) (problems, notes []string) {
+	key, notes := config.ParseAgentKeyWithNotes(data)
+	if key == "" {
+		return []string{"no agent_key entry found"}, notes
+	}
+	return config.ValidateAgentKey(key), notes
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const testAgentKey = "zd_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestCheckCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		envKey  string
+		file    string // "" for no credentials file
+		mode    os.FileMode
+		status  string
+		message string // substring of the message
+	}{
+		{name: "valid", file: "agent_key=" + testAgentKey + "\n", mode: 0600, status: "pass", message: "Agent key configured"},
+		{name: "no file", status: "warn", message: "No credentials file"},
+		{name: "from the environment", envKey: testAgentKey, status: "pass", message: config.AgentKeyEnv},
+		{name: "bad key in the environment", envKey: "zd_short", status: "fail", message: "key length"},
+		{name: "environment wins over a broken file", envKey: testAgentKey, file: "nonsense\n", mode: 0644, status: "pass"},
+		{name: "no agent_key entry", file: "other=1\n", mode: 0600, status: "fail", message: "no agent_key entry found"},
+		{name: "empty file", file: "\n", mode: 0600, status: "fail", message: "no agent_key entry found"},
+		{name: "wrong prefix", file: "agent_key=ak_0123456789abcdef0123456789abcdef0123\n", mode: 0600, status: "fail", message: `does not start with "zd_"`},
+		{name: "too short", file: "agent_key=zd_123\n", mode: 0600, status: "fail", message: "key length 6 outside"},
+		{name: "whitespace inside", file: "agent_key=zd_0123456789abcdef 0123456789abcdef0123\n", mode: 0600, status: "fail", message: "whitespace"},
+		{name: "only in another section", file: "[staging]\nagent_key=" + testAgentKey + "\n", mode: 0600, status: "fail", message: "no agent_key entry found"},
+		{name: "quoted", file: `agent_key="` + testAgentKey + `"` + "\n", mode: 0600, status: "warn", message: "quotes removed"},
+		{name: "typographic quotes", file: "agent_key=“" + testAgentKey + "”\n", mode: 0600, status: "warn", message: "quotes removed"},
+		{name: "unterminated quote", file: `agent_key="` + testAgentKey + "\n", mode: 0600, status: "warn", message: "unterminated quote"},
+		{name: "BOM and CRLF", file: "\uFEFFagent_key=" + testAgentKey + "\r\n", mode: 0600, status: "warn", message: "BOM"},
+		{name: "duplicate entries", file: "agent_key=zd_old\nagent_key=" + testAgentKey + "\n", mode: 0600, status: "warn", message: "using the last one (line 2)"},
+		{name: "stray line", file: "agent_key " + testAgentKey + "\nagent_key=" + testAgentKey + "\n", mode: 0600, status: "warn", message: "line 1: not a key=value line"},
+		{name: "comments and export", file: "# zeude\nexport agent_key=" + testAgentKey + " # work\n", mode: 0600, status: "pass"},
+		{name: "world-readable", file: "agent_key=" + testAgentKey + "\n", mode: 0644, status: "fail", message: "mode 0644, want 0600"},
+		{name: "world-readable and broken", file: "agent_key=zd_123\n", mode: 0644, status: "fail", message: "want 0600); key length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.file != "" && runtime.GOOS == "windows" {
+				t.Skip("file modes are not enforced on Windows")
+			}
+			useTestHome(t)
+			t.Setenv(config.AgentKeyEnv, tt.envKey)
+			if tt.file != "" {
+				writeCredentials(t, tt.file, tt.mode)
+			}
+
+			r := checkCredentials()
+			if r.status != tt.status || !strings.Contains(r.message, tt.message) {
+				t.Errorf("checkCredentials() = %s %q, want %s containing %q", r.status, r.message, tt.status, tt.message)
+			}
+			if strings.Contains(r.message, testAgentKey) || strings.Contains(r.message, "zd_0123") {
+				t.Errorf("message shows the key: %q", r.message)
+			}
+		})
+	}
+}
+
+func TestCheckCredentialsFixesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	useTestHome(t)
+	t.Setenv(config.AgentKeyEnv, "")
+
+	path := writeCredentials(t, "agent_key=zd_123\n", 0644)
+	r := checkCredentials()
+	if r.fix == nil {
+		t.Fatalf("no fix offered: %s %q", r.status, r.message)
+	}
+	if _, err := r.fix(); err == nil || !strings.Contains(err.Error(), "permissions fixed, but") {
+		t.Errorf("fix with a broken key: err = %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode after fix = %04o, want 0600", info.Mode().Perm())
+	}
+
+	writeCredentials(t, "agent_key="+testAgentKey+"\n", 0640)
+	if _, err := checkCredentials().fix(); err != nil {
+		t.Errorf("fix: %v", err)
+	}
+	if r := checkCredentials(); r.status != "pass" {
+		t.Errorf("after fix: %s %q", r.status, r.message)
+	}
+}
+
+func writeCredentials(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path, err := paths.Credentials(env.OS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile leaves an existing file's mode alone, and the umask applies
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const interpreterCheckName = "Hook interpreters"
+
+// checkHookInterpreters finds installed hooks whose interpreter isn't on
+// PATH. Claude runs them anyway, and each run fails.
+func checkHookInterpreters() checkResult {
+	missing, err := mcpconfig.MissingHookInterpreters()
+	if err != nil {
+		return checkResult{interpreterCheckName, "warn", fmt.Sprintf("Cannot list hooks: %v", err), nil}
+	}
+	if len(missing) == 0 {
+		return checkResult{interpreterCheckName, "pass", "Every installed hook's interpreter is on PATH", nil}
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	details := make([]string, 0, len(names))
+	for _, name := range names {
+		details = append(details, fmt.Sprintf("%s (needed by %s)", name, strings.Join(missing[name], ", ")))
+	}
+	return checkResult{interpreterCheckName, "fail", "Not found on PATH: " + strings.Join(details, "; "), nil}
+}
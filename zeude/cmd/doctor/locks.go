@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const lockCheckName = "Lock files"
+
+// checkLockFiles lists the locks held and looks for ones left behind by a
+// crashed sync, which make every later sync wait for the lock timeout.
+// Only Windows lock files can be left behind; see LockFileInfo.Stale.
+func checkLockFiles() checkResult {
+	locks, err := mcpconfig.InspectLocks()
+	if err != nil {
+		return checkResult{lockCheckName, "fail", fmt.Sprintf("Cannot inspect locks: %v", err), nil}
+	}
+	if len(locks) == 0 {
+		return checkResult{lockCheckName, "pass", "No locks held", nil}
+	}
+
+	var stale []string
+	var details []string
+	for _, l := range locks {
+		detail := fmt.Sprintf("%s (age %s", l.Path, l.Age.Round(time.Second))
+		if l.PID > 0 {
+			state := "dead"
+			if l.HolderAlive {
+				state = "alive"
+			}
+			detail += fmt.Sprintf(", holder PID %d %s", l.PID, state)
+		}
+		detail += ")"
+		details = append(details, detail)
+
+		if l.Stale() {
+			stale = append(stale, l.Path)
+		}
+	}
+
+	if len(stale) == 0 {
+		return checkResult{lockCheckName, "pass", "Held by a running sync: " + strings.Join(details, ", "), nil}
+	}
+
+	return checkResult{lockCheckName, "warn", "Stale lock may block sync: " + strings.Join(details, ", "), func() (string, error) {
+		for _, path := range stale {
+			if err := mcpconfig.RemoveStaleLock(path); err != nil {
+				return "", err
+			}
+		}
+		return "removed " + strings.Join(stale, ", "), nil
+	}}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// TestCheckLockFilesLeftover fabricates the sync lock a crashed sync
+// leaves behind, holder PID dead. On Windows the file itself is the lock,
+// so it is reported and --fix removes it; elsewhere the flock died with
+// the process and there is nothing to report.
+func TestCheckLockFilesLeftover(t *testing.T) {
+	useTestHome(t)
+
+	if r := checkLockFiles(); r.status != "pass" || r.message != "No locks held" {
+		t.Fatalf("with no lock files: %s %q", r.status, r.message)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	lockPath, err := paths.SyncLock(env.OS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(lockPath), 0700)
+	if err := os.WriteFile(lockPath, []byte(strconv.Itoa(cmd.Process.Pid)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := checkLockFiles()
+	if runtime.GOOS != "windows" {
+		if r.status != "pass" || r.fix != nil {
+			t.Errorf("leftover flock file: %s %q, want pass with no fix", r.status, r.message)
+		}
+		return
+	}
+	if r.status != "warn" || r.fix == nil {
+		t.Fatalf("stale lock: %s %q, want warn with a fix", r.status, r.message)
+	}
+	if _, err := r.fix(); err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file still present after fix: %v", err)
+	}
+}
@@ -4,16 +4,23 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/zeude/zeude/internal/autoupdate"
 	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+	"github.com/zeude/zeude/internal/term"
 )
 
 const (
@@ -26,40 +33,106 @@ type checkResult struct {
 	name    string
 	status  string // "pass", "fail", "warn"
 	message string
+	// fix repairs the problem when --fix is given and returns what it did.
+	fix func() (string, error)
 }
 
 func main() {
-	fmt.Println("Zeude Doctor")
-	fmt.Println("============")
-	fmt.Println()
+	httpclient.SetVersion(autoupdate.Version)
+	// Accept both "zeude-doctor --fix" and "zeude doctor --fix"
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "doctor" {
+		args = args[1:]
+	}
 
-	results := []checkResult{
-		checkShimInstalled(),
-		checkRealClaudePath(),
-		checkPATHOrder(),
-		checkCollectorEndpoint(),
-		checkCollectorConnectivity(),
-		checkClaudeVersion(),
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "attempt to repair problems that have a known fix")
+	only := fs.String("only", "", "comma-separated check IDs to run (see --list)")
+	skip := fs.String("skip", "", "comma-separated check IDs to skip (see --list)")
+	list := fs.Bool("list", false, "list available check IDs and exit")
+	jsonOut := fs.Bool("json", false, "print results as JSON")
+	fs.Parse(args)
+
+	if *list {
+		for _, c := range checks {
+			fmt.Printf("%-24s %s\n", c.id, c.description)
+		}
+		return
 	}
 
-	// Print results
+	selected, err := selectChecks(splitIDs(*only), splitIDs(*skip))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if !*jsonOut {
+		build := autoupdate.GetBuildInfo()
+		fmt.Println("Zeude Doctor")
+		fmt.Println("============")
+		fmt.Printf("zeude %s, %s/%s\n", build, build.OS, build.Arch)
+		fmt.Println()
+	}
+
+	results := make([]checkResult, len(selected))
+	for i, c := range selected {
+		results[i] = c.run()
+	}
+
+	// Apply fixes before reporting so the summary reflects the repaired state
+	fixable := 0
+	for i, r := range results {
+		if r.status == "pass" || r.fix == nil {
+			continue
+		}
+		if !*fix {
+			fixable++
+			continue
+		}
+		done, err := r.fix()
+		if err != nil {
+			results[i].message = fmt.Sprintf("%s (fix failed: %v)", r.message, err)
+			continue
+		}
+		results[i].status = "pass"
+		results[i].message = "Fixed: " + done
+	}
+
+	// Count results
 	passCount := 0
 	failCount := 0
 	warnCount := 0
-
 	for _, r := range results {
-		var mark string
 		switch r.status {
 		case "pass":
-			mark = fmt.Sprintf("\033[32m[%s]\033[0m", checkMark)
 			passCount++
 		case "fail":
-			mark = fmt.Sprintf("\033[31m[%s]\033[0m", crossMark)
 			failCount++
 		case "warn":
-			mark = fmt.Sprintf("\033[33m[%s]\033[0m", warnMark)
 			warnCount++
 		}
+	}
+
+	if *jsonOut {
+		printJSONReport(selected, splitIDs(*only), splitIDs(*skip), results, passCount, warnCount, failCount)
+		if failCount > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Print results
+	colors := term.Colors(term.ColorEnabled(env.OS{}))
+	for _, r := range results {
+		var mark string
+		switch r.status {
+		case "pass":
+			mark = fmt.Sprintf("%s[%s]%s", colors.Green, checkMark, colors.Reset)
+		case "fail":
+			mark = fmt.Sprintf("%s[%s]%s", colors.Red, crossMark, colors.Reset)
+		case "warn":
+			mark = fmt.Sprintf("%s[%s]%s", colors.Yellow, warnMark, colors.Reset)
+		}
 		fmt.Printf("%s %s: %s\n", mark, r.name, r.message)
 	}
 
@@ -67,6 +140,13 @@ func main() {
 	fmt.Println()
 	fmt.Println("------------------")
 	fmt.Printf("Results: %d passed, %d warnings, %d failed\n", passCount, warnCount, failCount)
+	if len(selected) < len(checks) {
+		fmt.Printf("Ran %d of %d checks\n", len(selected), len(checks))
+	}
+
+	if fixable > 0 {
+		fmt.Printf("%d issue(s) can be repaired automatically: run 'zeude doctor --fix'\n", fixable)
+	}
 
 	if failCount > 0 {
 		fmt.Println()
@@ -76,51 +156,49 @@ func main() {
 }
 
 func checkShimInstalled() checkResult {
-	home, err := os.UserHomeDir()
+	binDir, err := paths.Bin(env.OS{})
 	if err != nil {
-		return checkResult{"Shim installed", "fail", "Cannot get home directory"}
+		return checkResult{"Shim installed", "fail", "Cannot get home directory", nil}
 	}
 
-	shimPath := filepath.Join(home, ".zeude", "bin", "claude")
+	shimPath := filepath.Join(binDir, "claude")
 	if _, err := os.Stat(shimPath); os.IsNotExist(err) {
-		return checkResult{"Shim installed", "fail", "Shim not found at ~/.zeude/bin/claude"}
+		return checkResult{"Shim installed", "fail", "Shim not found at ~/.zeude/bin/claude", nil}
 	}
 
-	return checkResult{"Shim installed", "pass", shimPath}
+	return checkResult{"Shim installed", "pass", shimPath, nil}
 }
 
 func checkRealClaudePath() checkResult {
-	home, err := os.UserHomeDir()
+	pathFile, err := paths.RealBinaryPath(env.OS{})
 	if err != nil {
-		return checkResult{"Real claude path", "fail", "Cannot get home directory"}
+		return checkResult{"Real claude path", "fail", "Cannot get home directory", nil}
 	}
 
-	pathFile := filepath.Join(home, ".zeude", "real_binary_path")
 	data, err := os.ReadFile(pathFile)
 	if err != nil {
-		return checkResult{"Real claude path", "fail", "Path file not found at ~/.zeude/real_binary_path"}
+		return checkResult{"Real claude path", "fail", "Path file not found at ~/.zeude/real_binary_path", nil}
 	}
 
 	path := strings.TrimSpace(string(data))
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return checkResult{"Real claude path", "fail", fmt.Sprintf("Binary not found: %s", path)}
+		return checkResult{"Real claude path", "fail", fmt.Sprintf("Binary not found: %s", path), nil}
 	}
 
-	return checkResult{"Real claude path", "pass", path}
+	return checkResult{"Real claude path", "pass", path, nil}
 }
 
 func checkPATHOrder() checkResult {
-	home, err := os.UserHomeDir()
+	shimDir, err := paths.Bin(env.OS{})
 	if err != nil {
-		return checkResult{"PATH order", "fail", "Cannot get home directory"}
+		return checkResult{"PATH order", "fail", "Cannot get home directory", nil}
 	}
 
-	shimDir := filepath.Join(home, ".zeude", "bin")
 	pathEnv := os.Getenv("PATH")
-	paths := strings.Split(pathEnv, string(os.PathListSeparator))
+	dirs := strings.Split(pathEnv, string(os.PathListSeparator))
 
 	shimIndex := -1
-	for i, p := range paths {
+	for i, p := range dirs {
 		absPath, _ := filepath.Abs(p)
 		if absPath == shimDir {
 			shimIndex = i
@@ -129,31 +207,41 @@ func checkPATHOrder() checkResult {
 	}
 
 	if shimIndex == -1 {
-		return checkResult{"PATH order", "fail", "~/.zeude/bin not in PATH"}
+		return checkResult{"PATH order", "fail", "~/.zeude/bin not in PATH", nil}
 	}
 
 	if shimIndex == 0 {
-		return checkResult{"PATH order", "pass", "Shim directory is first in PATH"}
+		return checkResult{"PATH order", "pass", "Shim directory is first in PATH", nil}
 	}
 
-	return checkResult{"PATH order", "warn", fmt.Sprintf("Shim at position %d in PATH (should be first)", shimIndex+1)}
+	return checkResult{"PATH order", "warn", fmt.Sprintf("Shim at position %d in PATH (should be first)", shimIndex+1), nil}
 }
 
 func checkCollectorEndpoint() checkResult {
-	endpoint := config.GetCollectorEndpoint("")
-	if endpoint == "" {
-		return checkResult{"Collector endpoint", "warn", "Using default: " + config.DefaultCollectorEndpoint}
+	endpoints := config.GetCollectorEndpoints()
+	if len(endpoints) == 0 {
+		return checkResult{"Collector endpoint", "warn", "Using default: " + config.DefaultCollectorEndpoint, nil}
 	}
-	return checkResult{"Collector endpoint", "pass", endpoint}
+	if len(endpoints) > 1 {
+		return checkResult{"Collector endpoint", "pass", fmt.Sprintf("%s (failover: %s)", endpoints[0], strings.Join(endpoints[1:], ", ")), nil}
+	}
+	return checkResult{"Collector endpoint", "pass", endpoints[0], nil}
 }
 
 func checkCollectorConnectivity() checkResult {
+	if config.TelemetryDisabled(env.OS{}) {
+		return checkResult{"Collector connectivity", "pass", "Disabled by user (" + config.DisableTelemetryEnv + " or telemetry=off)", nil}
+	}
+	if endpoints := config.GetCollectorEndpoints(); len(endpoints) > 1 {
+		return checkCollectorFailover(endpoints)
+	}
+
 	endpoint := config.GetCollectorEndpoint(config.DefaultCollectorEndpoint)
 
 	// Parse endpoint properly using shared config package
 	host, port, _, err := config.ParseEndpoint(endpoint)
 	if err != nil {
-		return checkResult{"Collector connectivity", "fail", fmt.Sprintf("Invalid endpoint URL: %s", endpoint)}
+		return checkResult{"Collector connectivity", "fail", fmt.Sprintf("Invalid endpoint URL: %s", endpoint), nil}
 	}
 
 	grpcAddr := host + ":" + port
@@ -166,39 +254,93 @@ func checkCollectorConnectivity() checkResult {
 	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", grpcAddr)
 	if err != nil {
 		// Try HTTP health endpoint using proper URL conversion
-		client := &http.Client{Timeout: 2 * time.Second}
+		client := httpclient.New(2 * time.Second)
 		httpEndpoint := config.GetHTTPEndpoint(endpoint)
 		resp, err := client.Get(httpEndpoint + "/health")
 		if err != nil {
-			return checkResult{"Collector connectivity", "warn", fmt.Sprintf("Cannot connect to %s (telemetry will be skipped)", grpcAddr)}
+			return checkResult{"Collector connectivity", "warn", fmt.Sprintf("Cannot connect to %s (telemetry will be skipped)", grpcAddr), nil}
 		}
 		resp.Body.Close()
-		return checkResult{"Collector connectivity", "pass", "HTTP endpoint responding"}
+		return checkResult{"Collector connectivity", "pass", "HTTP endpoint responding", nil}
 	}
 	conn.Close()
-	return checkResult{"Collector connectivity", "pass", "gRPC endpoint responding"}
+	return checkResult{"Collector connectivity", "pass", "gRPC endpoint responding", nil}
+}
+
+// checkCollectorFailover reports every endpoint in a failover list.
+func checkCollectorFailover(endpoints []string) checkResult {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	statuses := telemetry.ProbeEndpoints(ctx, endpoints)
+	parts := make([]string, 0, len(statuses))
+	firstUp := -1
+	for i, st := range statuses {
+		mark := "down"
+		if st.Reachable {
+			mark = "up"
+			if firstUp < 0 {
+				firstUp = i
+			}
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", st.Endpoint, mark))
+	}
+	list := strings.Join(parts, ", ")
+
+	switch {
+	case firstUp == 0:
+		return checkResult{"Collector connectivity", "pass", list, nil}
+	case firstUp > 0:
+		return checkResult{"Collector connectivity", "warn", "Primary down, using failover: " + list, nil}
+	default:
+		return checkResult{"Collector connectivity", "warn", "No collector reachable (telemetry will be skipped): " + list, nil}
+	}
+}
+
+// checkQuietMode reports quiet mode, which always passes: it only explains
+// why a user sees no banner or status line.
+func checkQuietMode() checkResult {
+	if config.Quiet(env.OS{}) {
+		return checkResult{"Quiet mode", "pass", "On (" + config.QuietEnv + " or quiet=true): banner and status line hidden, errors still shown", nil}
+	}
+	return checkResult{"Quiet mode", "pass", "Off", nil}
+}
+
+// checkSyncSettings shows the sync timeout and cache TTL in effect, and
+// warns about a value that was ignored.
+func checkSyncSettings() checkResult {
+	timeout, ttl := mcpconfig.EffectiveSyncTimeout(), mcpconfig.EffectiveCacheTTL()
+	msg := fmt.Sprintf("Timeout %s (%s), cache TTL %s (%s)", timeout.Value, timeout.Source, ttl.Value, ttl.Source)
+	var invalid []string
+	for _, s := range []mcpconfig.DurationSetting{timeout, ttl} {
+		if s.Invalid != "" {
+			invalid = append(invalid, s.Invalid)
+		}
+	}
+	if len(invalid) > 0 {
+		return checkResult{"Sync settings", "warn", msg + "; ignored invalid " + strings.Join(invalid, ", "), nil}
+	}
+	return checkResult{"Sync settings", "pass", msg, nil}
 }
 
 func checkClaudeVersion() checkResult {
-	home, err := os.UserHomeDir()
+	pathFile, err := paths.RealBinaryPath(env.OS{})
 	if err != nil {
-		return checkResult{"Claude version", "fail", "Cannot get home directory"}
+		return checkResult{"Claude version", "fail", "Cannot get home directory", nil}
 	}
 
-	pathFile := filepath.Join(home, ".zeude", "real_binary_path")
 	data, err := os.ReadFile(pathFile)
 	if err != nil {
-		return checkResult{"Claude version", "warn", "Cannot determine (path file missing)"}
+		return checkResult{"Claude version", "warn", "Cannot determine (path file missing)", nil}
 	}
 
 	realClaude := strings.TrimSpace(string(data))
 	cmd := exec.Command(realClaude, "--version")
 	output, err := cmd.Output()
 	if err != nil {
-		return checkResult{"Claude version", "warn", "Cannot determine version"}
+		return checkResult{"Claude version", "warn", "Cannot determine version", nil}
 	}
 
 	version := strings.TrimSpace(string(output))
-	return checkResult{"Claude version", "pass", version}
+	return checkResult{"Claude version", "pass", version, nil}
 }
-
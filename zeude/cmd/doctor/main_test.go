@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+// useTestHome points the process environment at a temp home, since the
+// checks read the real environment, and returns it.
+func useTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, key := range []string{"ZEUDE_HOME", "ZEUDE_USE_XDG", "XDG_DATA_HOME", "CLAUDE_CONFIG_DIR"} {
+		t.Setenv(key, "")
+	}
+	return home
+}
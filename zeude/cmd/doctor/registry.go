@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+)
+
+// doctorCheck is a registered check addressable by --only and --skip.
+type doctorCheck struct {
+	id          string
+	description string
+	run         func() checkResult
+}
+
+// checks is the ordered registry of doctor checks.
+// IDs are part of the CLI and JSON compatibility surface: never rename or
+// reuse one, only add new IDs.
+var checks = []doctorCheck{
+	{"shim", "Shim binary installed in ~/.zeude/bin", checkShimInstalled},
+	{"real-claude", "Stored real claude binary path is valid", checkRealClaudePath},
+	{"path-order", "Shim directory is first in PATH", checkPATHOrder},
+	{"collector-endpoint", "Collector endpoint configuration", checkCollectorEndpoint},
+	{"collector-connectivity", "Collector endpoint is reachable", checkCollectorConnectivity},
+	{"claude-version", "Real claude binary reports a version", checkClaudeVersion},
+	{"credentials", "Agent key source, file permissions, and format", checkCredentials},
+	{"locks", "No stale lock files blocking sync", checkLockFiles},
+	{"resource-attributes", "OTEL_RESOURCE_ATTRIBUTES is well-formed before and after injection", checkResourceAttributes},
+	{"quiet", "Whether the shim's banner and status line are hidden", checkQuietMode},
+	{"sync-settings", "Effective sync timeout and cache TTL", checkSyncSettings},
+	{"hook-interpreters", "Installed hooks have their interpreter on PATH", checkHookInterpreters},
+}
+
+// splitIDs parses a comma-separated flag value into trimmed, non-empty IDs.
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// selectChecks filters the registry by --only and --skip, preserving order.
+// Unknown IDs are rejected so typos don't silently run nothing.
+func selectChecks(only, skip []string) ([]doctorCheck, error) {
+	known := make(map[string]bool, len(checks))
+	for _, c := range checks {
+		known[c.id] = true
+	}
+	for _, id := range append(append([]string{}, only...), skip...) {
+		if !known[id] {
+			return nil, fmt.Errorf("unknown check ID %q (run 'zeude doctor --list' to see available checks)", id)
+		}
+	}
+
+	onlySet := toSet(only)
+	skipSet := toSet(skip)
+
+	var selected []doctorCheck
+	for _, c := range checks {
+		if len(onlySet) > 0 && !onlySet[c.id] {
+			continue
+		}
+		if skipSet[c.id] {
+			continue
+		}
+		selected = append(selected, c)
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no checks selected")
+	}
+	return selected, nil
+}
+
+// toSet converts a slice of IDs into a lookup set.
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// jsonReport is the machine-readable doctor output.
+type jsonReport struct {
+	Build     autoupdate.BuildInfo `json:"build"`
+	Selection jsonSelection        `json:"selection"`
+	Results   []jsonResult         `json:"results"`
+	Summary   jsonSummary          `json:"summary"`
+}
+
+type jsonSelection struct {
+	Only   []string `json:"only,omitempty"`
+	Skip   []string `json:"skip,omitempty"`
+	Checks []string `json:"checks"` // IDs actually evaluated
+}
+
+type jsonResult struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+type jsonSummary struct {
+	Passed   int `json:"passed"`
+	Warnings int `json:"warnings"`
+	Failed   int `json:"failed"`
+}
+
+// printJSONReport writes the results and the check selection as JSON to stdout.
+func printJSONReport(selected []doctorCheck, only, skip []string, results []checkResult, passed, warnings, failed int) {
+	report := jsonReport{
+		Build:     autoupdate.GetBuildInfo(),
+		Selection: jsonSelection{Only: only, Skip: skip},
+		Summary:   jsonSummary{Passed: passed, Warnings: warnings, Failed: failed},
+	}
+	for i, c := range selected {
+		report.Selection.Checks = append(report.Selection.Checks, c.id)
+		report.Results = append(report.Results, jsonResult{
+			ID:      c.id,
+			Name:    results[i].name,
+			Status:  results[i].status,
+			Message: results[i].message,
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckIDsPinned pins the check IDs: scripts pass them to --only and
+// --skip and dashboards read them from --json, so an ID may be added but
+// never renamed, reordered or removed.
+func TestCheckIDsPinned(t *testing.T) {
+	pinned := []string{
+		"shim",
+		"real-claude",
+		"path-order",
+		"collector-endpoint",
+		"collector-connectivity",
+		"claude-version",
+		"credentials",
+		"locks",
+		"resource-attributes",
+		"quiet",
+		"sync-settings",
+		"hook-interpreters",
+	}
+	if len(checks) < len(pinned) {
+		t.Fatalf("%d checks registered, %d pinned", len(checks), len(pinned))
+	}
+	seen := map[string]bool{}
+	for i, c := range checks {
+		if i < len(pinned) && c.id != pinned[i] {
+			t.Errorf("check %d is %q, pinned as %q", i, c.id, pinned[i])
+		}
+		if seen[c.id] {
+			t.Errorf("duplicate check ID %q", c.id)
+		}
+		seen[c.id] = true
+		if c.description == "" || c.run == nil {
+			t.Errorf("check %q lacks a description or run func", c.id)
+		}
+	}
+}
+
+func TestSelectChecks(t *testing.T) {
+	tests := []struct {
+		name       string
+		only, skip string
+		want       string // selected IDs, space-separated, or the error
+	}{
+		{"everything", "", "", "shim real-claude path-order collector-endpoint collector-connectivity claude-version credentials locks resource-attributes quiet sync-settings hook-interpreters"},
+		{"only keeps registry order", "claude-version,collector-connectivity", "", "collector-connectivity claude-version"},
+		{"only with spaces", " locks , credentials ,", "", "credentials locks"},
+		{"skip", "", "hook-interpreters,collector-connectivity,claude-version", "shim real-claude path-order collector-endpoint credentials locks resource-attributes quiet sync-settings"},
+		{"only and skip", "locks,credentials", "locks", "credentials"},
+		{"unknown only", "lock", "", `unknown check ID "lock"`},
+		{"unknown skip", "", "shim,nope", `unknown check ID "nope"`},
+		{"nothing left", "quiet", "quiet", "no checks selected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectChecks(splitIDs(tt.only), splitIDs(tt.skip))
+			var got string
+			if err != nil {
+				got = err.Error()
+			} else {
+				ids := make([]string, len(selected))
+				for i, c := range selected {
+					ids[i] = c.id
+				}
+				got = strings.Join(ids, " ")
+			}
+			if !strings.Contains(got, tt.want) || (err == nil && got != tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+const resourceAttrsCheckName = "Resource attributes"
+
+// checkResourceAttributes validates OTEL_RESOURCE_ATTRIBUTES as it is now and
+// as it will look after the shim appends the zeude.* identity attributes.
+// Some SDKs drop the whole attribute set when any member is malformed.
+func checkResourceAttributes() checkResult {
+	current := os.Getenv(telemetry.ResourceAttributesEnv)
+
+	attrs, before := telemetry.ParseResourceAttributes(current)
+
+	var problems []string
+	for _, p := range before {
+		problems = append(problems, "current "+p.String())
+	}
+
+	// Simulate the shim's injection using the cached identity when available
+	userID, email, team := "00000000-0000-0000-0000-000000000000", "user@example.com", "team"
+	if cached, _ := mcpconfig.LoadCachedConfig(); cached != nil {
+		userID, email, team = cached.Config.UserID, cached.Config.UserEmail, cached.Config.Team
+	}
+	simulated := current
+	if userID != "" {
+		simulated = telemetry.SetResourceAttribute(simulated, "zeude.user.id", userID)
+	}
+	if email != "" {
+		simulated = telemetry.SetResourceAttribute(simulated, "zeude.user.email", email)
+	}
+	if team != "" {
+		simulated = telemetry.SetResourceAttribute(simulated, "zeude.team", team)
+	}
+	simulated = telemetry.SetResourceAttribute(simulated, telemetry.SessionAttribute, telemetry.NewSessionID())
+
+	// Only report problems introduced by the injection itself
+	_, after := telemetry.ParseResourceAttributes(simulated)
+	if len(after) > len(before) {
+		for _, p := range after[len(before):] {
+			problems = append(problems, "after injection "+p.String())
+		}
+	}
+
+	if len(problems) > 0 {
+		return checkResult{resourceAttrsCheckName, "warn", strings.Join(problems, "; "), nil}
+	}
+
+	if current == "" {
+		return checkResult{resourceAttrsCheckName, "pass", "Not set (shim will create it)", nil}
+	}
+	return checkResult{resourceAttrsCheckName, "pass", fmt.Sprintf("%d well-formed attribute(s)", len(attrs)), nil}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runBackup snapshots claude.json, settings.json and the managed lists.
+func runBackup(args []string) {
+	fs := newFlagSet("backup")
+	fs.Parse(args)
+
+	backup, err := mcpconfig.CreateBackup(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(backup.Files) == 0 {
+		fmt.Printf("%s[WARN]%s Nothing to back up yet; created empty backup %s\n", colorYellow, colorReset, backup.ID)
+		return
+	}
+	fmt.Printf("%s✓ Backed up%s %s to %s\n", colorGreen, colorReset, strings.Join(backup.Files, ", "), backup.Dir)
+	fmt.Printf("Restore it with 'zeude restore %s'.\n", backup.ID)
+}
+
+// runRestore puts a snapshot back, the newest unless an ID is given.
+//
+//	zeude restore [id] [--yes]
+//	zeude restore --list [--json]
+func runRestore(args []string) {
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	fs := newFlagSet("restore")
+	list := fs.Bool("list", false, "list backups instead of restoring")
+	asJSON := fs.Bool("json", globals.json, "print the list as JSON")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Parse(args)
+	if id == "" && fs.NArg() > 0 {
+		id = fs.Arg(0)
+	}
+
+	backups, err := mcpconfig.ListBackups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *list {
+		printBackups(backups, *asJSON)
+		return
+	}
+	if len(backups) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no backups yet; create one with 'zeude backup'")
+		os.Exit(1)
+	}
+
+	target := backups[0]
+	if id != "" {
+		found := false
+		for _, b := range backups {
+			if b.ID == id {
+				target, found = b, true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "Error: no backup %q (see 'zeude restore --list')\n", id)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Backup %s (%s ago) holds: %s\n", target.ID, formatAge(time.Since(target.Time)), strings.Join(target.Files, ", "))
+	if !*yes && !confirm("Overwrite the current files with it?") {
+		fmt.Println("Nothing restored.")
+		return
+	}
+	// A sync during the prompt takes a newer snapshot, so restore the one
+	// the user confirmed rather than whatever is newest by then
+	var restored *mcpconfig.Backup
+	if id == "" && *yes {
+		restored, err = mcpconfig.RestoreLatestBackup(context.Background())
+	} else {
+		restored, err = mcpconfig.RestoreBackup(context.Background(), target.ID)
+	}
+	if err != nil {
+		if errors.Is(err, mcpconfig.ErrNoBackups) {
+			err = errors.New("the backup disappeared before it could be restored")
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Restored%s backup %s. The files it replaced were backed up first.\n", colorGreen, colorReset, restored.ID)
+	if pause := mcpconfig.CurrentPause(); !pause.Paused {
+		fmt.Println("The next sync will reapply Zeude's servers and hooks; run 'zeude pause' first to keep the restored files as they are.")
+	}
+}
+
+func printBackups(backups []mcpconfig.Backup, asJSON bool) {
+	if asJSON {
+		if backups == nil {
+			backups = []mcpconfig.Backup{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(backups)
+		return
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups yet.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAGE\tFILES")
+	for _, b := range backups {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", b.ID, formatAge(time.Since(b.Time)), orDash(strings.Join(b.Files, ", ")))
+	}
+	w.Flush()
+}
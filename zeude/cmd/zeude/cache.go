@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/housekeeping"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const cacheUsage = "Usage: zeude cache show | clear [--all] | clean"
+
+// runCache handles `zeude cache <subcommand>`.
+func runCache(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, cacheUsage)
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "show":
+		runCacheShow()
+	case "clear":
+		runCacheClear(args[1:])
+	case "clean":
+		runCacheClean()
+	default:
+		fmt.Fprintln(os.Stderr, cacheUsage)
+		os.Exit(1)
+	}
+}
+
+// runCacheShow prints the sync cache metadata and warns when it is stale.
+func runCacheShow() {
+	path, err := mcpconfig.CachePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cached, expired := mcpconfig.LoadCachedConfig()
+	if cached == nil {
+		fmt.Printf("No config cache at %s\n", path)
+		return
+	}
+
+	fmt.Printf("Path:           %s\n", path)
+	fmt.Printf("Cached at:      %s (%s ago)\n", cached.CachedAt.Format(time.RFC3339), formatAge(time.Since(cached.CachedAt)))
+	fmt.Printf("Expires at:     %s\n", cached.ExpiresAt.Format(time.RFC3339))
+	fmt.Printf("Version:        %s\n", orDash(cached.Version))
+	fmt.Printf("Server version: %s\n", orDash(cached.ServerVersion))
+	fmt.Printf("Servers:        %d\n", len(cached.Config.MCPServers))
+	fmt.Printf("Hooks:          %d\n", len(cached.Config.Hooks))
+	fmt.Printf("Skills:         %d\n", len(cached.Config.Skills))
+
+	if expired {
+		fmt.Printf("%s[WARN]%s Cache expired %s ago (TTL %s); the next sync will revalidate it\n",
+			colorYellow, colorReset, formatAge(time.Since(cached.ExpiresAt)), mcpconfig.EffectiveCacheTTL().Value)
+	}
+	if cached.ServerVersion != "" && cached.ServerVersion != cached.Version {
+		fmt.Printf("%s[WARN]%s Cached version differs from the server's (%s); every sync will refetch the full config\n",
+			colorYellow, colorReset, cached.ServerVersion)
+	}
+}
+
+// runCacheClear removes the sync cache, and with --all the managed lists.
+func runCacheClear(args []string) {
+	fs := newFlagSet("cache clear")
+	all := fs.Bool("all", false, "also forget which servers, hooks and skills Zeude manages")
+	fs.Parse(args)
+
+	removed, err := mcpconfig.ClearCache(context.Background(), mcpconfig.ClearCacheOptions{All: *all})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if removed {
+		fmt.Printf("%s✓ Cleared config cache%s; the next sync refetches it\n", colorGreen, colorReset)
+	} else {
+		fmt.Printf("%s✓ No config cache to clear%s\n", colorGreen, colorReset)
+	}
+	if *all {
+		fmt.Printf("%sManaged lists removed; entries Zeude added before are now left alone by sync.%s\n", colorGray, colorReset)
+	}
+}
+
+// runCacheClean removes stale files from the data directory now.
+func runCacheClean() {
+	report := housekeeping.Clean(env.OS{})
+	for _, path := range report.Removed {
+		fmt.Printf("%sremoved%s %s\n", colorGray, colorReset, path)
+	}
+	for _, path := range report.Failed {
+		fmt.Printf("%s[FAIL]%s could not remove %s\n", colorRed, colorReset, path)
+	}
+	if len(report.Removed) == 0 && len(report.Failed) == 0 {
+		fmt.Printf("%s✓ Nothing to clean%s\n", colorGreen, colorReset)
+		return
+	}
+	fmt.Printf("%s✓ Removed %d file(s)%s\n", colorGreen, len(report.Removed), colorReset)
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/term"
+)
+
+// command is one `zeude <name>` subcommand. printUsage and per-command
+// usage are generated from the registry, so help can't drift from dispatch.
+type command struct {
+	name    string
+	aliases []string
+	summary string // one line for `zeude help`
+	usage   string // full usage, shown on bad flags and by `zeude help <name>`
+	json    bool   // honours the global --json
+	run     func(args []string)
+}
+
+// globals holds the flags accepted before the command name.
+var globals struct {
+	quiet   bool // suppress progress banners and other decoration
+	noColor bool
+	json    bool // machine output, for commands that support it
+}
+
+// commands is the registry, in the order `zeude help` lists them.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "install", summary: "Install the claude shim and put it on PATH",
+			usage: "Usage: zeude install [--shim path] [--real path] [--yes] [--no-modify-path]", run: runInstall},
+		{name: "uninstall", summary: "Remove Zeude and everything it synced",
+			usage: "Usage: zeude uninstall [--yes] [--dry-run] [--keep-credentials]", run: runUninstall},
+		{name: "login", summary: "Save your agent key and run the first sync",
+			usage: "Usage: zeude login [key]", run: runLogin},
+		{name: "logout", summary: "Remove your agent key and what it synced",
+			usage: "Usage: zeude logout [--keep-local]", run: runLogout},
+		{name: "key", summary: "Rotate the agent key (key rotate)",
+			usage: keyUsage, run: runKey},
+		{name: "whoami", summary: "Show which account the agent key belongs to",
+			usage: "Usage: zeude whoami", run: noArgs("whoami", runWhoami)},
+		{name: "init", summary: "Register this project and write its .zeude file",
+			usage: "Usage: zeude init [--dry-run] [--name name] [--agent-key-env VAR] [--force]", run: runInit},
+		{name: "trust", summary: "Let this project's .zeude file override the global settings",
+			usage: "Usage: zeude trust [--yes]", run: runTrust},
+		{name: "sync", summary: "Sync MCP servers, hooks, and skills now",
+			usage: "Usage: zeude sync [--force] [--offline] [--verbose] [--dry-run [--json]]", run: runSync},
+		{name: "pause", summary: "Stop syncing so local edits survive (--for 2h)",
+			usage: "Usage: zeude pause [--for 2h]", run: runPause},
+		{name: "resume", summary: "Undo 'zeude pause'",
+			usage: "Usage: zeude resume", run: noArgs("resume", runResume)},
+		{name: "status", summary: "Show what Zeude last synced (offline)",
+			usage: "Usage: zeude status", run: noArgs("status", runStatus)},
+		{name: "drift", summary: "Show managed files edited or deleted since the last sync",
+			usage: "Usage: zeude drift [--json]", json: true, run: runDrift},
+		{name: "backup", summary: "Snapshot claude.json, settings.json and the managed lists",
+			usage: "Usage: zeude backup", run: runBackup},
+		{name: "restore", summary: "Restore a backup (restore [id], restore --list)",
+			usage: "Usage: zeude restore [id] [--yes] | --list [--json]", json: true, run: runRestore},
+		{name: "prune", summary: "Remove Zeude hooks and skills that no manifest tracks",
+			usage: "Usage: zeude prune [--yes] [--dry-run] [--json]", json: true, run: runPrune},
+		{name: "migrate", summary: "Convert managed lists from older versions into managed.json",
+			usage: "Usage: zeude migrate [--dry-run] [--json]", json: true, run: runMigrate},
+		{name: "update", summary: "Check for updates and install if available (--check-only, --force, --to)",
+			usage: "Usage: zeude update [--channel stable|beta] [--check-only | --force] | --to <version> [--pin] [--force]", run: runUpdate},
+		{name: "releases", summary: "List shim versions on the update server",
+			usage: "Usage: zeude releases", run: noArgs("releases", runReleases)},
+		{name: "doctor", summary: "Run diagnostic checks",
+			usage: "Usage: zeude doctor [--fix] [--only ids] [--skip ids] [--list] [--json]", json: true, run: runDoctor},
+		{name: "env", summary: "Print the telemetry and provider variables the shim sets for claude",
+			usage: "Usage: zeude env [--shell sh|bash|zsh|fish] [--json]", json: true, run: runEnv},
+		{name: "config", summary: "Get or set values in ~/.zeude/config",
+			usage: configUsage, run: runConfig},
+		{name: "servers", summary: "List MCP servers, or check the managed ones are installed",
+			usage: serversUsage, json: true, run: runServers},
+		{name: "hooks", summary: "List, test, or show the run log of Zeude hooks (hooks logs)",
+			usage: hooksUsage, json: true, run: runHooks},
+		{name: "skills", summary: "List or show Zeude-managed slash commands",
+			usage: skillsUsage, json: true, run: runSkills},
+		{name: "push", summary: "Upload a local hook or skill to the dashboard, then sync",
+			usage: pushUsage, run: runPush},
+		{name: "logs", summary: "Show Zeude's log file (--follow, --since, --component, --audit)",
+			usage: "Usage: zeude logs [-f] [-n 100] [--since 1h] [--component name] [--audit]", run: runLogs},
+		{name: "cache", summary: "Show or clear the sync cache (cache show|clear|clean)",
+			usage: cacheUsage, run: runCache},
+		{name: "trust-key", summary: "Pin the team's content signing key",
+			usage: "Usage: zeude trust-key [<base64-key> | --list]", run: runTrustKey},
+		{name: "version", aliases: []string{"-v", "--version"}, summary: "Show version and build information (--json)",
+			usage: "Usage: zeude version [--json]", json: true, run: runVersion},
+		{name: "help", aliases: []string{"-h", "--help"}, summary: "Show this help message, or a command's usage",
+			usage: "Usage: zeude help [command]", run: runHelp},
+	}
+}
+
+// lookupCommand finds a command by name or alias.
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+		for _, alias := range c.aliases {
+			if alias == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// newFlagSet is flag.NewFlagSet with ExitOnError whose usage message is the
+// command's own, so a mistyped flag shows what the command accepts. name
+// may include a subcommand ("servers check").
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		if c := lookupCommand(strings.Fields(name)[0]); c != nil {
+			fmt.Fprintln(fs.Output(), c.usage)
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// noArgs adapts a command that takes no arguments, rejecting any given.
+func noArgs(name string, run func()) func([]string) {
+	return func(args []string) {
+		fs := newFlagSet(name)
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		run()
+	}
+}
+
+// disableColor blanks the color codes, for --no-color and term.ColorEnabled.
+func disableColor() {
+	p := term.Colors(false)
+	colorReset, colorBlue, colorGreen, colorYellow, colorRed, colorGray = p.Reset, p.Blue, p.Green, p.Yellow, p.Red, p.Gray
+}
+
+// progress prints a "[zeude] ..." banner unless --quiet.
+func progress(format string, args ...interface{}) {
+	if globals.quiet {
+		return
+	}
+	fmt.Printf("%s[zeude]%s %s\n", colorBlue, colorReset, fmt.Sprintf(format, args...))
+}
+
+func printUsage() {
+	fmt.Println("Zeude CLI - Claude Code telemetry & management")
+	fmt.Println()
+	fmt.Println("Usage: zeude [--quiet] [--no-color] [--json] <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	width := 0
+	for _, c := range commands {
+		if len(c.name) > width {
+			width = len(c.name)
+		}
+	}
+	for _, c := range commands {
+		fmt.Printf("  %-*s %s\n", width, c.name, c.summary)
+	}
+	fmt.Println()
+	fmt.Println("Global flags:")
+	fmt.Println("  --quiet     Suppress progress banners and hints")
+	fmt.Println("  --no-color  Disable colored output (also NO_COLOR=1, ZEUDE_NO_COLOR=1, TERM=dumb)")
+	fmt.Println("  --json      Print JSON, for commands that support it")
+}
+
+// runHelp prints the command list, or one command's usage.
+func runHelp(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		return
+	}
+	c := lookupCommand(args[0])
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
+		printUsage()
+		os.Exit(1)
+	}
+	fmt.Println(c.usage)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+)
+
+const configUsage = `Usage:
+  zeude config get <key>
+  zeude config set <key> <value>
+  zeude config unset <key>
+  zeude config list`
+
+// runConfig reads and edits ~/.zeude/config.
+func runConfig(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		os.Exit(1)
+	}
+
+	f, err := config.LoadFile(env.OS{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case args[0] == "get" && len(args) == 2:
+		value, ok := f.Get(args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s is not set\n", args[1])
+			os.Exit(1)
+		}
+		fmt.Println(value)
+
+	case args[0] == "set" && len(args) == 3:
+		key, value := args[1], args[2]
+		if err := config.ValidateValue(key, value); err != nil {
+			fmt.Fprintf(os.Stderr, "%s✗ Invalid %s:%s %v\n", colorRed, key, colorReset, err)
+			os.Exit(1)
+		}
+		if !config.KnownKey(key) {
+			fmt.Printf("%s[WARN]%s %s is not a key Zeude reads; setting it anyway\n", colorYellow, colorReset, key)
+		}
+		f.Set(key, value)
+		saveConfigFile(f)
+		fmt.Printf("%s✓%s %s=%s\n", colorGreen, colorReset, key, value)
+
+	case args[0] == "unset" && len(args) == 2:
+		if !f.Unset(args[1]) {
+			fmt.Printf("%s%s was not set%s\n", colorGray, args[1], colorReset)
+			return
+		}
+		saveConfigFile(f)
+		fmt.Printf("%s✓%s unset %s\n", colorGreen, colorReset, args[1])
+
+	case args[0] == "list" && len(args) == 1:
+		for _, entry := range f.List() {
+			note := ""
+			if !config.KnownKey(entry.Key) {
+				note = colorGray + "  (not used by Zeude)" + colorReset
+			}
+			fmt.Printf("%s=%s%s\n", entry.Key, entry.Value, note)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, configUsage)
+		os.Exit(1)
+	}
+}
+
+func saveConfigFile(f *config.File) {
+	if err := f.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", f.Path(), err)
+		os.Exit(1)
+	}
+}
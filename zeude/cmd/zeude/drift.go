@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runDrift compares Zeude-managed servers, hooks and skills with what the
+// cached config would write. Exits 1 when anything drifted and 2 on errors,
+// so scripts can tell the two apart.
+func runDrift(args []string) {
+	fs := newFlagSet("drift")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	report, err := mcpconfig.CheckDrift()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printDrift(report)
+	}
+	if len(report.Entries) > 0 {
+		os.Exit(1)
+	}
+}
+
+func printDrift(report *mcpconfig.DriftReport) {
+	if len(report.Entries) == 0 {
+		fmt.Printf("%s✓ No drift%s: %d managed entries match config %s\n",
+			colorGreen, colorReset, report.Checked, orDash(report.ConfigVersion))
+		return
+	}
+
+	for _, d := range report.Entries {
+		color := colorYellow
+		if d.State == mcpconfig.DriftMissing {
+			color = colorRed
+		}
+		fmt.Printf("%s%-8s%s %-6s %s %s(%s)%s\n", color, d.State, colorReset, d.Kind, d.Name, colorGray, d.Path, colorReset)
+		if d.Diff == "" {
+			continue
+		}
+		printColoredDiff(d.Diff)
+	}
+	fmt.Println()
+	fmt.Printf("%d of %d managed entries drifted from config %s. Run 'zeude sync' to restore them.\n",
+		len(report.Entries), report.Checked, orDash(report.ConfigVersion))
+}
+
+// printColoredDiff prints a unified diff indented, with added and removed
+// lines colored.
+func printColoredDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			fmt.Printf("    %s\n", line)
+		case strings.HasPrefix(line, "@@"):
+			fmt.Printf("    %s%s%s\n", colorBlue, line, colorReset)
+		case strings.HasPrefix(line, "-"):
+			fmt.Printf("    %s%s%s\n", colorRed, line, colorReset)
+		case strings.HasPrefix(line, "+"):
+			fmt.Printf("    %s%s%s\n", colorGreen, line, colorReset)
+		default:
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
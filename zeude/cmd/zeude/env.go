@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/provider"
+	"github.com/zeude/zeude/internal/shellrc"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// runEnv prints the telemetry and provider variables the shim would give claude right
+// now, as shell lines that can be eval'd. Variables the user already set
+// are shown commented out, since the shim leaves them alone.
+func runEnv(args []string) {
+	fs := newFlagSet("env")
+	shell := fs.String("shell", "sh", "output syntax: sh, bash, zsh or fish")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	switch shellrc.Shell(*shell) {
+	case shellrc.Fish, shellrc.Bash, shellrc.Zsh, "sh":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported shell %q (want sh, bash, zsh or fish)\n", *shell)
+		os.Exit(1)
+	}
+
+	// The shim uses the user info from its sync; the cache holds the last one
+	id := telemetry.Identity{AgentKey: mcpconfig.AgentKey()}
+	var settings provider.Settings
+	if cached, _ := mcpconfig.LoadCachedConfig(); cached != nil {
+		id.UserID, id.UserEmail, id.Team = cached.Config.UserID, cached.Config.UserEmail, cached.Config.Team
+		settings = cached.Config.Provider
+	}
+	vars := telemetry.ClaudeEnv(context.Background(), env.OS{}, id)
+	vars = append(vars, provider.ClaudeEnv(env.OS{}, settings)...)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(vars)
+		return
+	}
+	if config.TelemetryDisabled(env.OS{}) {
+		fmt.Printf("# telemetry is off (%s or telemetry=off): claude gets no OTel settings\n", config.DisableTelemetryEnv)
+	}
+	for _, v := range vars {
+		line := shellrc.Export(shellrc.Shell(*shell), v.Key, v.Value)
+		switch {
+		case v.Skipped:
+			fmt.Printf("# already set, left alone: %s\n", line)
+		case v.UserSet:
+			fmt.Printf("%s # appended to your value\n", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
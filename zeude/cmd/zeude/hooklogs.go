@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// runHooksLogs prints the latest hook runs from ~/.zeude/logs/hooks.log,
+// which the generated hook scripts append to themselves.
+func runHooksLogs(args []string) {
+	fs := newFlagSet("hooks logs")
+	hook := fs.String("hook", "", "only show runs of this hook")
+	event := fs.String("event", "", "only show runs for this event (e.g. PreToolUse)")
+	tail := fs.Int("tail", 20, "number of runs to show, 0 for all")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	dir, err := paths.Logs(env.OS{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	path := filepath.Join(dir, logging.HookLogFileName)
+
+	var records []logging.HookRecord
+	for _, f := range logging.Files(path) {
+		readLogLines(f, func(line string) {
+			rec, ok := logging.ParseHookRecord(line)
+			if !ok {
+				return
+			}
+			if *hook != "" && rec.Hook != *hook {
+				return
+			}
+			if *event != "" && !strings.EqualFold(rec.Event, *event) {
+				return
+			}
+			records = append(records, rec)
+			if *tail > 0 && len(records) > *tail {
+				records = records[1:]
+			}
+		})
+	}
+
+	if *asJSON {
+		if records == nil {
+			records = []logging.HookRecord{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(records)
+		return
+	}
+	if len(records) == 0 {
+		if *hook != "" || *event != "" {
+			fmt.Println("No matching hook runs logged.")
+		} else {
+			fmt.Printf("No hook runs logged yet at %s (hooks log from their next sync on).\n", path)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEVENT\tHOOK\tEXIT\tDURATION\tSTDERR")
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Time.Local().Format("2006-01-02 15:04:05"), r.Event, r.Hook,
+			formatHookExit(r.ExitCode), time.Duration(r.DurationMs)*time.Millisecond, orDash(r.Stderr))
+	}
+	w.Flush()
+}
+
+// formatHookExit labels exit codes the way Claude Code treats them. No
+// color: escape codes would throw off the tabwriter columns.
+func formatHookExit(code int) string {
+	switch code {
+	case 0:
+		return "0"
+	case 2:
+		return "2 blocked"
+	default:
+		return fmt.Sprintf("%d error", code)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const hooksUsage = "Usage: zeude hooks list [--json] | test <name> [--stdin payload.json] | logs [--hook name] [--event event] [--tail N] | disable <name> | enable <name>"
+
+// runHooks handles `zeude hooks <subcommand>`.
+func runHooks(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, hooksUsage)
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "list":
+		runHooksList(args[1:])
+	case "test":
+		runHooksTest(args[1:])
+	case "logs":
+		runHooksLogs(args[1:])
+	case "disable":
+		runHooksToggle(args[1:], true)
+	case "enable":
+		runHooksToggle(args[1:], false)
+	default:
+		fmt.Fprintln(os.Stderr, hooksUsage)
+		os.Exit(1)
+	}
+}
+
+// runHooksList prints each Zeude hook with its file and registration state,
+// flagging the ones that won't fire.
+func runHooksList(args []string) {
+	fs := newFlagSet("hooks list")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	hooks, err := mcpconfig.ListHooks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(hooks)
+		return
+	}
+
+	if len(hooks) == 0 {
+		fmt.Println("No Zeude hooks installed.")
+		return
+	}
+	problems := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EVENT\tNAME\tTYPE\tFILE\tEXEC\tREGISTERED\tPATH")
+	for _, h := range hooks {
+		registered := yesNo(h.Registered)
+		if h.Disabled {
+			registered += " (disabled)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", h.Event, orDash(h.Name), h.ScriptType,
+			yesNo(h.Exists), yesNo(h.Executable), registered, h.Path)
+	}
+	w.Flush()
+
+	for _, h := range hooks {
+		if h.Problem != "" {
+			if problems == 0 {
+				fmt.Println()
+			}
+			problems++
+			fmt.Printf("%s[WARN]%s %s: %s\n", colorYellow, colorReset, h.Path, h.Problem)
+		}
+	}
+}
+
+// runHooksToggle handles `zeude hooks disable|enable <name>`: a local opt-out
+// that outlasts syncs, without touching the team's config.
+func runHooksToggle(args []string, disable bool) {
+	verb := "enable"
+	if disable {
+		verb = "disable"
+	}
+	fs := newFlagSet("hooks " + verb)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: zeude hooks %s <name>\n", verb)
+		os.Exit(1)
+	}
+
+	hook, err := findManagedHook(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if hook.Disabled == disable {
+		fmt.Printf("%s is already %sd.\n", fs.Arg(0), verb)
+		return
+	}
+	if err := mcpconfig.SetHookDisabled(context.Background(), hook, disable); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if disable {
+		fmt.Printf("%s✓ Disabled%s %s/%s on this machine. The script stays in %s; 'zeude hooks enable %s' turns it back on.\n",
+			colorGreen, colorReset, hook.Event, hook.Name, hook.Path, fs.Arg(0))
+		return
+	}
+	fmt.Printf("%s✓ Enabled%s %s/%s\n", colorGreen, colorReset, hook.Event, hook.Name)
+	if !hook.Exists {
+		fmt.Println("Its script is missing; run 'zeude sync' to reinstall and register it.")
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// defaultHookTimeout matches Claude Code's default per-hook timeout.
+const defaultHookTimeout = 60 * time.Second
+
+// runHooksTest runs one managed hook the way Claude Code would: the event
+// payload as JSON on stdin, in the current directory. The script carries the
+// ZEUDE_* variables installHooks wrote into it, so nothing extra is set.
+//
+//	zeude hooks test <name> [--stdin payload.json] [--timeout 60s]
+func runHooksTest(args []string) {
+	// Allow the name before or after the flags
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	fs := newFlagSet("hooks test")
+	stdinFile := fs.String("stdin", "", "JSON file to send as the event payload instead of the sample")
+	timeout := fs.Duration("timeout", defaultHookTimeout, "kill the hook after this long")
+	fs.Parse(args)
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "Usage: zeude hooks test <name> [--stdin payload.json] [--timeout 60s]")
+		os.Exit(1)
+	}
+
+	hook, err := findManagedHook(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !hook.Exists {
+		fmt.Fprintf(os.Stderr, "Error: %s is missing; run 'zeude sync' to reinstall it\n", hook.Path)
+		os.Exit(1)
+	}
+
+	var payload []byte
+	if *stdinFile != "" {
+		payload, err = os.ReadFile(*stdinFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !json.Valid(payload) {
+			fmt.Fprintf(os.Stderr, "%s[WARN]%s %s is not valid JSON; sending it anyway\n", colorYellow, colorReset, *stdinFile)
+		}
+	} else {
+		payload = samplePayload(hook.Event)
+	}
+
+	progress("Running %s hook %s", hook.Event, hook.Path)
+	fmt.Printf("%sstdin:%s %s\n", colorGray, colorReset, strings.TrimSpace(string(payload)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, hook.Path)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	printHookOutput("stdout", stdout.String())
+	printHookOutput("stderr", stderr.String())
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		fmt.Printf("%s✗ Timed out%s after %s; Claude Code would cancel the hook and continue\n", colorRed, colorReset, *timeout)
+		os.Exit(1)
+	case runErr == nil:
+		fmt.Printf("%s✓ Exit 0%s in %s: success\n", colorGreen, colorReset, elapsed)
+	case errors.As(runErr, &exitErr):
+		code := exitErr.ExitCode()
+		if code == 2 {
+			fmt.Printf("%s⚠ Exit 2%s in %s: blocking. Claude Code feeds stderr back to Claude and blocks the action (for %s: %s)\n",
+				colorYellow, colorReset, elapsed, hook.Event, blockingEffect(hook.Event))
+		} else {
+			fmt.Printf("%s✗ Exit %d%s in %s: non-blocking error. Claude Code shows stderr to the user and continues\n",
+				colorRed, code, colorReset, elapsed)
+		}
+		os.Exit(1)
+	default:
+		fmt.Printf("%s✗ Could not run the hook:%s %v\n", colorRed, colorReset, runErr)
+		os.Exit(1)
+	}
+}
+
+// findManagedHook resolves a hook by its name, its file name without the
+// extension, or "Event/name" when the name is used for several events.
+func findManagedHook(name string) (mcpconfig.HookStatus, error) {
+	hooks, err := mcpconfig.ListHooks()
+	if err != nil {
+		return mcpconfig.HookStatus{}, err
+	}
+	var matches []mcpconfig.HookStatus
+	for _, h := range hooks {
+		if !h.Managed {
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(h.Path), filepath.Ext(h.Path))
+		for _, candidate := range []string{h.Name, base, h.Event + "/" + h.Name, h.Event + "/" + base} {
+			if candidate != "" && candidate != "/" && candidate == name {
+				matches = append(matches, h)
+				break
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return mcpconfig.HookStatus{}, fmt.Errorf("no managed hook named %q (see 'zeude hooks list')", name)
+	case 1:
+		return matches[0], nil
+	}
+	var options []string
+	for _, h := range matches {
+		options = append(options, h.Event+"/"+name)
+	}
+	return mcpconfig.HookStatus{}, fmt.Errorf("%q matches hooks for several events; use one of: %s", name, strings.Join(options, ", "))
+}
+
+// samplePayload builds a representative stdin payload for event, following
+// the Claude Code hook input schema.
+func samplePayload(event string) []byte {
+	cwd, _ := os.Getwd()
+	payload := map[string]interface{}{
+		"session_id":      "zeude-hooks-test",
+		"transcript_path": filepath.Join(os.TempDir(), "zeude-hooks-test.jsonl"),
+		"cwd":             cwd,
+		"hook_event_name": event,
+	}
+	switch event {
+	case "PreToolUse":
+		payload["tool_name"] = "Bash"
+		payload["tool_input"] = map[string]interface{}{"command": "echo hello", "description": "Print hello"}
+	case "PostToolUse":
+		payload["tool_name"] = "Bash"
+		payload["tool_input"] = map[string]interface{}{"command": "echo hello", "description": "Print hello"}
+		payload["tool_response"] = map[string]interface{}{"stdout": "hello\n", "stderr": "", "interrupted": false}
+	case "UserPromptSubmit":
+		payload["prompt"] = "Write a function that adds two numbers"
+	case "Notification":
+		payload["message"] = "Claude needs your permission to use Bash"
+	case "Stop", "SubagentStop":
+		payload["stop_hook_active"] = false
+	case "PreCompact":
+		payload["trigger"] = "manual"
+		payload["custom_instructions"] = ""
+	case "SessionStart":
+		payload["source"] = "startup"
+	case "SessionEnd":
+		payload["reason"] = "exit"
+	}
+	data, _ := json.MarshalIndent(payload, "", "  ")
+	return append(data, '\n')
+}
+
+// blockingEffect says what exit code 2 blocks for each event.
+func blockingEffect(event string) string {
+	switch event {
+	case "PreToolUse":
+		return "the tool call is not run"
+	case "PostToolUse":
+		return "the tool already ran; Claude sees the error"
+	case "UserPromptSubmit":
+		return "the prompt is erased and not sent"
+	case "Stop", "SubagentStop":
+		return "Claude is told to keep working"
+	default:
+		return "stderr is shown to the user only"
+	}
+}
+
+func printHookOutput(label, out string) {
+	if out == "" {
+		fmt.Printf("%s%s:%s (empty)\n", colorGray, label, colorReset)
+		return
+	}
+	fmt.Printf("%s%s:%s\n", colorGray, label, colorReset)
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/project"
+)
+
+// starterMCPConfig is the project .mcp.json init writes if there is none.
+const starterMCPConfig = "{\n  \"mcpServers\": {}\n}\n"
+
+// runInit registers the current project with the dashboard and writes a
+// .zeude file at its root, so syncs run there fetch the project's servers.
+func runInit(args []string) {
+	fs := newFlagSet("init")
+	dryRun := fs.Bool("dry-run", false, "print what would be created without registering")
+	name := fs.String("name", "", "project name (default: the directory name)")
+	keyEnv := fs.String("agent-key-env", "", "environment variable holding a project-scoped agent key")
+	force := fs.Bool("force", false, "overwrite an existing .zeude file")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	root := project.GitRoot(wd)
+	if root == "" {
+		root = wd
+	}
+	if *name == "" {
+		*name = filepath.Base(root)
+	}
+	if *keyEnv != "" && strings.ContainsAny(*keyEnv, "= \t") {
+		fmt.Fprintf(os.Stderr, "Error: --agent-key-env takes a variable name, not a key\n")
+		os.Exit(2)
+	}
+
+	projectPath := filepath.Join(root, project.FileName)
+	if info, err := os.Stat(projectPath); err == nil {
+		if info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: %s is a directory (Zeude's own data directory?); run init inside a project\n", projectPath)
+			os.Exit(1)
+		}
+		if !*force {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists (--force to replace it)\n", projectPath)
+			os.Exit(1)
+		}
+	}
+	mcpPath := filepath.Join(root, ".mcp.json")
+	_, err = os.Stat(mcpPath)
+	writeMCP := os.IsNotExist(err)
+	remote := project.GitRemote(root)
+
+	if *dryRun {
+		fmt.Printf("Would register project %q", *name)
+		if remote != "" {
+			fmt.Printf(" (%s)", remote)
+		}
+		fmt.Println(" with the dashboard and write:")
+		fmt.Printf("\n%s:\n%s", projectPath, projectFileContent("<assigned at init>", *keyEnv))
+		if writeMCP {
+			fmt.Printf("\n%s:\n%s", mcpPath, starterMCPConfig)
+		} else {
+			fmt.Printf("\n%s already exists and would be left alone.\n", mcpPath)
+		}
+		return
+	}
+
+	progress("Registering project %s...", *name)
+	id, err := mcpconfig.RegisterProject(context.Background(), mcpconfig.ProjectRegistration{Name: *name, GitRemote: remote})
+	if err != nil {
+		var authErr *mcpconfig.AuthError
+		if errors.As(err, &authErr) {
+			err = fmt.Errorf("the dashboard rejected the agent key (HTTP %d); run 'zeude login' with a current key", authErr.StatusCode)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(projectPath, []byte(projectFileContent(id, *keyEnv)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: project %s was registered but %s could not be written: %v\n", id, projectPath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Registered%s project %s; wrote %s\n", colorGreen, colorReset, id, projectPath)
+	// Written here, so trusted here; teammates run 'zeude trust' once
+	p, err := project.Load(projectPath)
+	if err == nil {
+		err = project.Trust(env.OS{}, p)
+	}
+	if err != nil {
+		fmt.Printf("%s[WARN]%s Could not trust %s: %v\n", colorYellow, colorReset, projectPath, err)
+	}
+	if writeMCP {
+		if err := os.WriteFile(mcpPath, []byte(starterMCPConfig), 0644); err != nil {
+			fmt.Printf("%s[WARN]%s Could not write %s: %v\n", colorYellow, colorReset, mcpPath, err)
+		} else {
+			fmt.Printf("%s✓ Wrote%s starter %s\n", colorGreen, colorReset, mcpPath)
+		}
+	}
+	if !globals.quiet {
+		fmt.Println("Commit .zeude so teammates' syncs pick up the project's servers.")
+	}
+}
+
+// projectFileContent renders the .zeude file. It never holds a key, since
+// it is meant to be committed.
+func projectFileContent(id, keyEnv string) string {
+	var b strings.Builder
+	b.WriteString("# Zeude project configuration, written by 'zeude init'.\n")
+	b.WriteString("# Safe to commit: it names the project, never an agent key.\n")
+	fmt.Fprintf(&b, "%s=%s\n", project.IDKey, id)
+	if keyEnv != "" {
+		fmt.Fprintf(&b, "%s=%s\n", project.AgentKeyEnvKey, keyEnv)
+	} else {
+		b.WriteString("# Read a project-scoped agent key from this variable:\n")
+		fmt.Fprintf(&b, "# %s=ZEUDE_PROJECT_AGENT_KEY\n", project.AgentKeyEnvKey)
+	}
+	return b.String()
+}
+
+// runTrust lets the project's .zeude file override the global settings,
+// after showing what it overrides. The trust covers the file as it is:
+// once it changes, say after a pull, it is ignored until trusted again.
+//
+//	zeude trust [--yes]
+func runTrust(args []string) {
+	fs := newFlagSet("trust")
+	yes := fs.Bool("yes", false, "trust without asking")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	e := env.OS{}
+	p, err := project.Find(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if p == nil {
+		fmt.Fprintf(os.Stderr, "Error: no %s file here or at the repository root\n", project.FileName)
+		os.Exit(1)
+	}
+	if p.Trusted(e) {
+		fmt.Printf("%s✓ Already trusted: %s%s\n", colorGreen, p.Path, colorReset)
+		return
+	}
+	if p.Overrides() && !*yes {
+		fmt.Printf("%s overrides your global settings:\n\n", p.Path)
+		for _, key := range []string{project.DashboardURLKey, project.EndpointKey, project.AgentKeyEnvKey} {
+			if value := p.Get(key); value != "" {
+				fmt.Printf("  %s=%s\n", key, value)
+			}
+		}
+		fmt.Println("\nThat dashboard can add MCP servers to this project, and the collector receives Claude's telemetry from it.")
+		if !confirm("Trust this file?") {
+			fmt.Println("Not trusted.")
+			os.Exit(1)
+		}
+	}
+	if err := project.Trust(e, p); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to trust %s: %v\n", p.Path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Trusted%s %s\n", colorGreen, colorReset, p.Path)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/resolver"
+	"github.com/zeude/zeude/internal/shellrc"
+)
+
+// stdin is shared by every prompt so buffered input isn't lost between them.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question; anything but y/yes (including EOF) is no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := stdin.ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(answer))
+	return a == "y" || a == "yes"
+}
+
+// runInstall sets up the shim in ~/.zeude/bin, records the real claude
+// binary, and offers to put the bin directory on PATH. Re-running it only
+// changes what is missing or out of date.
+func runInstall(args []string) {
+	fs := newFlagSet("install")
+	shimSrc := fs.String("shim", "", "claude shim binary to install (default: the one next to zeude)")
+	realFlag := fs.String("real", "", "path of the real claude binary (default: search PATH)")
+	yes := fs.Bool("yes", false, "update shell startup files without asking")
+	noPath := fs.Bool("no-modify-path", false, "don't touch shell startup files")
+	fs.Parse(args)
+
+	e := env.OS{}
+	binDir, err := paths.Bin(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	shimPath := filepath.Join(binDir, "claude")
+	realPathFile, err := paths.RealBinaryPath(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(shimPath); err == nil {
+		progress("Existing installation found in %s", binDir)
+	} else {
+		progress("Installing into %s", binDir)
+	}
+
+	var changes []string
+	fail := func(format string, a ...interface{}) {
+		printInstallChanges(changes)
+		fmt.Fprintf(os.Stderr, "%s✗ %s%s\n", colorRed, fmt.Sprintf(format, a...), colorReset)
+		os.Exit(1)
+	}
+
+	// 1. Bin directory
+	if _, err := os.Stat(binDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(binDir, 0755); err != nil {
+			fail("failed to create %s: %v", binDir, err)
+		}
+		changes = append(changes, "created "+binDir)
+	}
+
+	// 2. The shim, and zeude itself so it stays on PATH next to it
+	self, err := os.Executable()
+	if err == nil {
+		self, err = filepath.EvalSymlinks(self)
+	}
+	if err != nil {
+		fail("cannot locate the zeude executable: %v", err)
+	}
+	src := *shimSrc
+	if src == "" {
+		src = findShim(filepath.Dir(self))
+		if src == "" {
+			fail("no claude shim found next to %s; pass --shim <path>", self)
+		}
+	}
+	if changed, err := installBinary(src, shimPath); err != nil {
+		fail("failed to install shim: %v", err)
+	} else if changed {
+		changes = append(changes, fmt.Sprintf("installed shim %s (from %s)", shimPath, src))
+	}
+	selfDest := filepath.Join(binDir, "zeude")
+	if changed, err := installBinary(self, selfDest); err != nil {
+		fail("failed to install zeude: %v", err)
+	} else if changed {
+		changes = append(changes, "installed "+selfDest)
+	}
+
+	// 3. The real claude binary the shim execs
+	realClaude, err := resolveRealClaude(e, *realFlag, realPathFile, binDir)
+	if err != nil {
+		fail("%v", err)
+	}
+	if changed, err := writeInstallFile(realPathFile, []byte(realClaude+"\n"), 0644); err != nil {
+		fail("failed to write %s: %v", realPathFile, err)
+	} else if changed {
+		changes = append(changes, fmt.Sprintf("recorded real claude %s in %s", realClaude, realPathFile))
+	}
+
+	// 4. PATH
+	if !*noPath {
+		files, err := pathCandidates(e)
+		if err != nil {
+			fail("%v", err)
+		}
+		for _, f := range files {
+			if ok, _ := shellrc.Installed(f.Path); ok {
+				fmt.Printf("%sPATH already configured in %s%s\n", colorGray, f.Path, colorReset)
+				continue
+			}
+			if !*yes && !confirm(fmt.Sprintf("Add %s to PATH in %s?", binDir, f.Path)) {
+				continue
+			}
+			added, err := shellrc.Append(f, binDir)
+			if err != nil {
+				fail("failed to update %s: %v", f.Path, err)
+			}
+			if added {
+				changes = append(changes, "added PATH block to "+f.Path)
+			}
+		}
+	}
+
+	printInstallChanges(changes)
+	fmt.Printf("%s✓ Installed%s claude → %s\n", colorGreen, colorReset, realClaude)
+	if !pathHasDir(e.Getenv("PATH"), binDir) {
+		fmt.Println("Restart your shell so the shim is found before the real claude.")
+	}
+}
+
+func printInstallChanges(changes []string) {
+	if len(changes) == 0 {
+		fmt.Printf("%sNo changes needed%s\n", colorGray, colorReset)
+		return
+	}
+	for _, c := range changes {
+		fmt.Printf("  %s+%s %s\n", colorGreen, colorReset, c)
+	}
+}
+
+// findShim returns the claude shim shipped next to zeude: either the
+// installed name or the release asset name.
+func findShim(dir string) string {
+	for _, name := range []string{"claude", fmt.Sprintf("claude-%s-%s", runtime.GOOS, runtime.GOARCH)} {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// installBinary copies src to dest as an executable, reporting whether dest
+// changed. It is a no-op when dest already has the same content.
+func installBinary(src, dest string) (bool, error) {
+	if a, err := filepath.EvalSymlinks(src); err == nil {
+		if b, err := filepath.EvalSymlinks(dest); err == nil && a == b {
+			return false, nil
+		}
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+	return writeInstallFile(dest, data, 0755)
+}
+
+// writeInstallFile atomically writes data unless path already holds it.
+func writeInstallFile(path string, data []byte, perm os.FileMode) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		if info, err := os.Stat(path); err == nil && info.Mode().Perm() == perm {
+			return false, nil
+		}
+	}
+	err := fsutil.WriteFileAtomic(path, data, perm)
+	logging.Audit().RecordResult(logging.AuditEvent{
+		Action:      logging.AuditWrite,
+		Path:        path,
+		ContentHash: logging.ContentHash(data),
+	}, err)
+	return err == nil, err
+}
+
+// resolveRealClaude picks the binary to record: an explicit path, else the
+// still-valid stored one, else the first claude on PATH outside binDir.
+func resolveRealClaude(e env.Env, explicit, storedFile, binDir string) (string, error) {
+	if explicit != "" {
+		path, err := filepath.Abs(explicit)
+		if err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("--real: %v", err)
+		}
+		if filepath.Dir(path) == binDir {
+			return "", fmt.Errorf("--real points at the Zeude shim directory")
+		}
+		return path, nil
+	}
+
+	if data, err := os.ReadFile(storedFile); err == nil {
+		stored := strings.TrimSpace(string(data))
+		if info, err := os.Stat(stored); err == nil && !info.IsDir() && info.Mode()&0111 != 0 && filepath.Dir(stored) != binDir {
+			return stored, nil
+		}
+	}
+
+	path, err := resolver.SearchPATH(resolver.Options{Env: e})
+	if err != nil {
+		return "", fmt.Errorf("claude not found on PATH; install Claude Code first or pass --real <path>")
+	}
+	return path, nil
+}
+
+// pathCandidates returns the startup files to offer: those that exist, plus
+// the login shell's own even if it doesn't yet.
+func pathCandidates(e env.Env) ([]shellrc.File, error) {
+	all, err := shellrc.Candidates(e)
+	if err != nil {
+		return nil, err
+	}
+	login := shellrc.Shell(filepath.Base(e.Getenv("SHELL")))
+	var files []shellrc.File
+	for _, f := range all {
+		if _, err := os.Stat(f.Path); err == nil || f.Shell == login {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		files = all[:1]
+	}
+	return files, nil
+}
+
+// pathHasDir reports whether dir is an entry of the PATH value.
+func pathHasDir(pathEnv, dir string) bool {
+	for _, p := range filepath.SplitList(pathEnv) {
+		if filepath.Clean(p) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/project"
+)
+
+const keyUsage = "Usage: zeude key rotate"
+
+// runKey handles `zeude key <subcommand>`.
+func runKey(args []string) {
+	if len(args) == 0 || args[0] != "rotate" {
+		fmt.Fprintln(os.Stderr, keyUsage)
+		os.Exit(1)
+	}
+	fs := newFlagSet("key rotate")
+	fs.Parse(args[1:])
+	runKeyRotate()
+}
+
+// runKeyRotate swaps the agent key for a new one from the dashboard and
+// syncs straight away, so hook scripts stop embedding the old key.
+func runKeyRotate() {
+	// Only the global key is saved by Zeude; a project's comes from a variable
+	os.Setenv(project.IgnoreEnv, "1")
+	ctx := context.Background()
+	progress("Rotating agent key with %s...", mcpconfig.DashboardURL())
+	newKey, err := mcpconfig.RotateAgentKey(ctx)
+	if err != nil {
+		var saveErr *mcpconfig.RotationSaveError
+		var authErr *mcpconfig.AuthError
+		switch {
+		case errors.As(err, &saveErr):
+			// The dashboard may already have revoked the old key: don't lose the new one
+			fmt.Fprintf(os.Stderr, "%s✗ The dashboard issued a new key but it could not be saved:%s %v\n", colorRed, colorReset, saveErr.Err)
+			fmt.Fprintf(os.Stderr, "Save it now with:\n\n  zeude login %s\n\n", saveErr.NewKey)
+		case errors.As(err, &authErr):
+			fmt.Fprintf(os.Stderr, "%s✗ The dashboard rejected the current key%s (HTTP %d: %s). Run 'zeude login' with a new key.\n",
+				colorRed, colorReset, authErr.StatusCode, authErr.Message)
+		default:
+			fmt.Fprintf(os.Stderr, "%s✗ Key rotation failed:%s %v\nThe current key is unchanged.\n", colorRed, colorReset, err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Rotated%s agent key, now %s\n", colorGreen, colorReset, config.MaskAgentKey(newKey))
+
+	// Hooks embed the key, so they must be regenerated before the old key is useless
+	progress("Syncing to update hooks...")
+	result := mcpconfig.Sync(ctx, mcpconfig.SyncOptions{Version: autoupdate.Version})
+	if !result.Success || result.Paused {
+		reason := "sync failed"
+		if result.Paused {
+			reason = "sync is paused"
+		} else if result.Err != nil {
+			reason = fmt.Sprintf("sync failed: %v", result.Err)
+		}
+		fmt.Fprintf(os.Stderr, "\n%s[WARN] Hooks may still use the old, revoked key (%s).%s\n", colorRed, reason, colorReset)
+		fmt.Fprintln(os.Stderr, "Telemetry from hooks will be rejected until you run 'zeude sync' (after 'zeude resume' if paused).")
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Synced%s %d servers, %d hooks, %d skills with the new key\n", colorGreen, colorReset, result.ServerCount, result.HookCount, result.SkillCount)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/project"
+)
+
+// runLogin checks an agent key with the dashboard, stores it, and runs the
+// first sync.
+//
+//	zeude login [key]
+func runLogin(args []string) {
+	// The key is saved as the global one, so it is checked with, and
+	// first synced from, the global dashboard whatever .zeude is here
+	os.Setenv(project.IgnoreEnv, "1")
+	var key string
+	if len(args) > 0 {
+		key = args[0]
+	} else {
+		fmt.Printf("Get your agent key from: %s%s%s\n", colorBlue, mcpconfig.DashboardURL(), colorReset)
+		fmt.Print("Agent key (zd_...): ")
+		key, _ = stdin.ReadString('\n')
+	}
+	key = config.CleanPastedKey(key)
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: no agent key given")
+		os.Exit(1)
+	}
+	if problems := config.ValidateAgentKey(key); len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%s✗ Invalid agent key:%s %s\n", colorRed, colorReset, strings.Join(problems, "; "))
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	// Own line: sync errors are logged to stderr while the check runs
+	progress("Checking key with %s...", mcpconfig.DashboardURL())
+	if _, err := mcpconfig.CheckAgentKey(ctx, key); err != nil {
+		var authErr *mcpconfig.AuthError
+		if errors.As(err, &authErr) {
+			fmt.Fprintf(os.Stderr, "%s✗ The dashboard rejected this key%s (HTTP %d: %s). It was not saved.\n", colorRed, colorReset, authErr.StatusCode, authErr.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s✗ Could not check the key:%s %v\nIt was not saved.\n", colorRed, colorReset, err)
+		}
+		os.Exit(1)
+	}
+
+	if err := mcpconfig.SaveAgentKey(key); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to save credentials: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Saved%s agent key %s\n", colorGreen, colorReset, config.MaskAgentKey(key))
+	if override := strings.TrimSpace(os.Getenv(config.AgentKeyEnv)); override != "" && override != key {
+		fmt.Printf("%s[WARN]%s %s is set and overrides the saved key\n", colorYellow, colorReset, config.AgentKeyEnv)
+	}
+
+	progress("Running first sync...")
+	result := mcpconfig.Sync(ctx, mcpconfig.SyncOptions{Version: autoupdate.Version})
+	if result.Paused {
+		state := mcpconfig.PauseState{Paused: true, Until: result.PausedUntil}
+		fmt.Printf("%s⏸ Sync %s%s; run 'zeude resume' to sync with the new key.\n", colorYellow, describePause(state), colorReset)
+		return
+	}
+	if !result.Success {
+		fmt.Fprintf(os.Stderr, "%s✗ Sync failed%s", colorRed, colorReset)
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, ": %v", result.Err)
+		}
+		fmt.Fprintln(os.Stderr, "\nThe key is saved; run 'zeude sync' to retry.")
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Synced%s %d servers, %d hooks, %d skills\n", colorGreen, colorReset, result.ServerCount, result.HookCount, result.SkillCount)
+}
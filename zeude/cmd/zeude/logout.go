@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runLogout removes the agent key and, unless --keep-local, everything the
+// key synced.
+func runLogout(args []string) {
+	fs := newFlagSet("logout")
+	keepLocal := fs.Bool("keep-local", false, "remove only the credentials and cache; keep installed servers, hooks and skills")
+	fs.Parse(args)
+
+	result, err := mcpconfig.Logout(context.Background(), mcpconfig.LogoutOptions{KeepLocal: *keepLocal})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s✗ Logout failed:%s %v\n", colorRed, colorReset, err)
+		os.Exit(1)
+	}
+
+	if result.CredentialsRemoved {
+		fmt.Printf("%s✓%s Removed credentials\n", colorGreen, colorReset)
+	} else {
+		fmt.Printf("%sNo credentials file to remove%s\n", colorGray, colorReset)
+	}
+	fmt.Printf("%s✓%s Cleared config cache\n", colorGreen, colorReset)
+
+	m := result.Managed
+	printChanges("Servers removed", m.Servers)
+	printChanges("Project servers removed from", m.ProjectFiles)
+	printChanges("Hooks removed", m.HookFiles)
+	printChanges("Skills removed", m.SkillFiles)
+	if *keepLocal {
+		fmt.Printf("%sInstalled servers, hooks and skills were kept.%s\n", colorGray, colorReset)
+	}
+
+	if strings.TrimSpace(os.Getenv(config.AgentKeyEnv)) != "" {
+		fmt.Printf("%s[WARN]%s %s is still set in your environment\n", colorYellow, colorReset, config.AgentKeyEnv)
+	}
+}
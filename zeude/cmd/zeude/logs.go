@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// followInterval is how often --follow polls the log file for new lines.
+const followInterval = 500 * time.Millisecond
+
+// componentAliases maps the names users type to the component= values
+// written by each package's logger.
+var componentAliases = map[string]string{
+	"update": "autoupdate",
+}
+
+// runLogs prints the tail of ~/.zeude/logs/zeude.log (or the audit log with
+// --audit), including rotated files, and optionally follows it.
+func runLogs(args []string) {
+	fs := newFlagSet("logs")
+	follow := fs.Bool("follow", false, "keep printing lines as they are written")
+	fs.BoolVar(follow, "f", false, "shorthand for --follow")
+	since := fs.Duration("since", 0, "only show lines newer than this (e.g. 30m, 1h)")
+	component := fs.String("component", "", "only show lines from one component (sync, update, shim, ...)")
+	audit := fs.Bool("audit", false, "show the audit log of files Zeude changed")
+	lines := fs.Int("n", 100, "number of lines to show, 0 for all")
+	fs.Parse(args)
+
+	if *audit && *component != "" {
+		fmt.Fprintln(os.Stderr, "Error: --component does not apply to --audit")
+		os.Exit(1)
+	}
+
+	e := env.OS{}
+	var path string
+	if *audit {
+		p, err := paths.Events(e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path = p
+	} else {
+		dir, err := paths.Logs(e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path = filepath.Join(dir, logging.LogFileName)
+	}
+
+	var cutoff time.Time
+	if *since > 0 {
+		cutoff = time.Now().Add(-*since)
+	}
+	want := *component
+	if alias, ok := componentAliases[want]; ok {
+		want = alias
+	}
+
+	// format returns the line to print, or "" to skip it
+	format := func(line string) string {
+		if *audit {
+			return formatAuditLine(line, cutoff)
+		}
+		rec := logging.ParseRecord(line)
+		if !cutoff.IsZero() && !rec.Time.IsZero() && rec.Time.Before(cutoff) {
+			return ""
+		}
+		if want != "" && rec.Component != want {
+			return ""
+		}
+		return line
+	}
+
+	files := logging.Files(path)
+	if len(files) == 0 && !*follow {
+		fmt.Fprintf(os.Stderr, "No log file yet at %s\n", path)
+		return
+	}
+
+	var tail []string
+	for _, f := range files {
+		readLogLines(f, func(line string) {
+			if out := format(line); out != "" {
+				tail = append(tail, out)
+				if *lines > 0 && len(tail) > *lines {
+					tail = tail[1:]
+				}
+			}
+		})
+	}
+	for _, line := range tail {
+		fmt.Println(line)
+	}
+
+	if *follow {
+		followLog(path, format)
+	}
+}
+
+// readLogLines calls fn for each line of path. A missing file is skipped.
+func readLogLines(path string, fn func(line string)) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), logging.DefaultMaxSize)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
+
+// followLog prints lines appended to path until interrupted. When the file
+// is rotated or recreated it starts again from the top of the new file.
+func followLog(path string, format func(string) string) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	var offset int64
+	var last os.FileInfo
+	if info, err := os.Stat(path); err == nil {
+		offset, last = info.Size(), info
+	}
+
+	var partial string
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// Not created yet, or mid-rotation
+			continue
+		}
+		if last != nil && (!os.SameFile(last, info) || info.Size() < offset) {
+			offset, partial = 0, ""
+		}
+		last = info
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			continue
+		}
+		data, _ := io.ReadAll(f)
+		f.Close()
+		offset += int64(len(data))
+
+		chunk := partial + string(data)
+		parts := strings.Split(chunk, "\n")
+		// The last element is an incomplete line (or "" after a newline)
+		partial = parts[len(parts)-1]
+		for _, line := range parts[:len(parts)-1] {
+			if out := format(line); out != "" {
+				fmt.Println(out)
+			}
+		}
+	}
+}
+
+// formatAuditLine renders one events.jsonl record, or "" if it is older
+// than cutoff or unreadable.
+func formatAuditLine(line string, cutoff time.Time) string {
+	var ev logging.AuditEvent
+	if err := json.Unmarshal([]byte(line), &ev); err != nil {
+		return ""
+	}
+	if !cutoff.IsZero() && ev.Time.Before(cutoff) {
+		return ""
+	}
+	target := ev.Path
+	if ev.Key != "" {
+		if target != "" {
+			target += " "
+		}
+		target += "[" + ev.Key + "]"
+	}
+	out := fmt.Sprintf("%s  %-20s %-5s %s", ev.Time.Local().Format(time.RFC3339), ev.Action, ev.Outcome, target)
+	if ev.ConfigVersion != "" {
+		out += " config=" + ev.ConfigVersion
+	}
+	if ev.Error != "" {
+		out += " error=" + ev.Error
+	}
+	return strings.TrimRight(out, " ")
+}
@@ -1,61 +1,129 @@
 // Package main provides the Zeude CLI tool.
-// Subcommands: update, doctor, version
+// Subcommands: install, uninstall, login, logout, key, whoami, init, trust, sync, pause, resume, status, drift, backup, restore, prune, migrate, env, config, servers, hooks, skills, push, logs, update, releases, doctor, cache, trust-key, version
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"syscall"
 
 	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/term"
 )
 
-const (
-	colorReset  = "\033[0m"
-	colorBlue   = "\033[1;34m"
-	colorGreen  = "\033[1;32m"
-	colorYellow = "\033[1;33m"
-	colorRed    = "\033[1;31m"
-	colorGray   = "\033[0;90m"
+// Colors are blanked by --no-color, NO_COLOR, ZEUDE_NO_COLOR and TERM=dumb.
+var (
+	colorReset  = term.ANSI.Reset
+	colorBlue   = term.ANSI.Blue
+	colorGreen  = term.ANSI.Green
+	colorYellow = term.ANSI.Yellow
+	colorRed    = term.ANSI.Red
+	colorGray   = term.ANSI.Gray
 )
 
 func main() {
-	if len(os.Args) < 2 {
+	httpclient.SetVersion(autoupdate.Version)
+	fs := flag.NewFlagSet("zeude", flag.ExitOnError)
+	fs.Usage = printUsage
+	fs.BoolVar(&globals.quiet, "quiet", false, "suppress progress banners and hints")
+	fs.BoolVar(&globals.noColor, "no-color", false, "disable colored output")
+	fs.BoolVar(&globals.json, "json", false, "print JSON, for commands that support it")
+	showVersion := fs.Bool("version", false, "show version")
+	fs.BoolVar(showVersion, "v", false, "show version")
+	fs.Parse(os.Args[1:])
+
+	if globals.noColor || !term.ColorEnabled(env.OS{}) {
+		disableColor()
+	}
+
+	args := fs.Args()
+	if *showVersion {
+		args = append([]string{"version"}, args...)
+	}
+	if len(args) == 0 {
 		printUsage()
 		os.Exit(0)
 	}
 
-	switch os.Args[1] {
-	case "update":
-		runUpdate()
-	case "doctor":
-		runDoctor()
-	case "version", "-v", "--version":
-		fmt.Printf("zeude %s\n", autoupdate.GetVersion())
-	case "help", "-h", "--help":
-		printUsage()
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", os.Args[1])
+	c := lookupCommand(args[0])
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
 		printUsage()
 		os.Exit(1)
 	}
+	if globals.json && !c.json {
+		fmt.Fprintf(os.Stderr, "Error: zeude %s has no JSON output\n", c.name)
+		os.Exit(1)
+	}
+	c.run(args[1:])
 }
 
-func printUsage() {
-	fmt.Println("Zeude CLI - Claude Code telemetry & management")
-	fmt.Println()
-	fmt.Println("Usage: zeude <command>")
-	fmt.Println()
-	fmt.Println("Commands:")
-	fmt.Println("  update    Check for updates and install if available")
-	fmt.Println("  doctor    Run diagnostic checks")
-	fmt.Println("  version   Show version information")
-	fmt.Println("  help      Show this help message")
+func runVersion(args []string) {
+	fs := newFlagSet("version")
+	jsonOut := fs.Bool("json", globals.json, "print build metadata as JSON")
+	fs.Parse(args)
+
+	build := autoupdate.GetBuildInfo()
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(build)
+		return
+	}
+	fmt.Printf("zeude %s\n", build.Version)
+	fmt.Printf("commit:     %s\n", build.Commit)
+	fmt.Printf("built:      %s\n", build.BuildDate)
+	fmt.Printf("platform:   %s/%s\n", build.OS, build.Arch)
+	fmt.Printf("executable: %s\n", orDash(build.Executable))
 }
 
-func runUpdate() {
-	fmt.Printf("%s[zeude]%s Checking for updates...", colorBlue, colorReset)
+// exitUpdateAvailable is the exit status of `zeude update --check-only`
+// when a newer version exists, so scripts can tell it from errors.
+const exitUpdateAvailable = 10
+
+func runUpdate(args []string) {
+	fs := newFlagSet("update")
+	channel := fs.String("channel", "", "switch to a release channel (stable or beta) and update from it")
+	checkOnly := fs.Bool("check-only", false, "report whether an update exists without installing it (exit 10 if so)")
+	force := fs.Bool("force", false, "reinstall the latest release even if already up to date")
+	to := fs.String("to", "", "install this exact version (e.g. v1.4.2); automatic updates skip it until a newer release than today's ships")
+	pin := fs.Bool("pin", false, "with --to, keep that version until a plain 'zeude update'")
+	fs.Parse(args)
+
+	if *checkOnly && *force {
+		fmt.Fprintln(os.Stderr, "Error: --check-only and --force can't be used together")
+		os.Exit(1)
+	}
+	if *to != "" && (*checkOnly || *channel != "") {
+		fmt.Fprintln(os.Stderr, "Error: --to can't be combined with --check-only or --channel")
+		os.Exit(1)
+	}
+	if *pin && *to == "" {
+		fmt.Fprintln(os.Stderr, "Error: --pin needs --to <version>")
+		os.Exit(1)
+	}
+	if *channel != "" {
+		if err := setUpdateChannel(*channel); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if !globals.quiet {
+		if *to != "" {
+			fmt.Printf("%s[zeude]%s Installing %s...", colorBlue, colorReset, *to)
+		} else {
+			fmt.Printf("%s[zeude]%s Checking for updates (%s)...", colorBlue, colorReset, autoupdate.ConfiguredChannel())
+		}
+	}
 
 	version := autoupdate.GetVersion()
 	if version == "dev" {
@@ -63,7 +131,14 @@ func runUpdate() {
 		return
 	}
 
-	result := autoupdate.CheckWithResult()
+	result := autoupdate.CheckWithOptions(context.Background(), autoupdate.CheckOptions{
+		CheckOnly: *checkOnly,
+		Force:     *force,
+		NoReexec:  true,
+		Manual:    true,
+		To:        *to,
+		Pin:       *pin,
+	})
 
 	if result.Error != nil {
 		fmt.Printf(" %sfailed%s\n", colorRed, colorReset)
@@ -71,29 +146,61 @@ func runUpdate() {
 		os.Exit(1)
 	}
 
-	if result.Updated {
+	if *to != "" {
+		if result.Updated {
+			fmt.Printf(" %s✓ Installed %s%s\n", colorGreen, result.NewVersion, colorReset)
+		} else {
+			fmt.Printf(" %s✓ Already on %s%s\n", colorGreen, result.NewVersion, colorReset)
+		}
+		if *pin {
+			fmt.Println("Pinned: automatic updates are off until you run 'zeude update'.")
+		} else {
+			fmt.Println("Automatic updates skip it until a newer release ships; 'zeude update' returns to the latest now.")
+		}
+		return
+	}
+
+	if result.Updated && result.Reinstalled {
+		fmt.Printf(" %s✓ Reinstalled %s%s\n", colorGreen, result.NewVersion, colorReset)
+	} else if result.Updated && result.Downgrade {
+		fmt.Printf(" %s✓ Switched to %s %s%s\n", colorGreen, result.Channel, result.NewVersion, colorReset)
+		fmt.Println()
+		fmt.Println("Run 'claude' to use the new version.")
+	} else if result.Updated {
 		fmt.Printf(" %s✓ Updated to %s%s\n", colorGreen, result.NewVersion, colorReset)
 		fmt.Println()
 		fmt.Println("Run 'claude' to use the new version.")
 	} else if result.NewVersionAvailable {
 		fmt.Printf(" %s(update available: %s)%s\n", colorYellow, result.NewVersion, colorReset)
+		if *checkOnly {
+			os.Exit(exitUpdateAvailable)
+		}
 	} else {
 		fmt.Printf(" %s✓ Already up to date (%s)%s\n", colorGreen, version, colorReset)
 	}
 }
 
-func runDoctor() {
-	home, err := os.UserHomeDir()
+func runDoctor(args []string) {
+	e := env.OS{}
+	binDir, err := paths.Bin(e)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: cannot get home directory\n")
 		os.Exit(1)
 	}
 
 	// Try to find and exec the doctor binary
-	doctorPath := filepath.Join(home, ".zeude", "bin", "zeude-doctor")
+	doctorPath := filepath.Join(binDir, "zeude-doctor")
 	if _, err := os.Stat(doctorPath); err == nil {
-		// Found zeude-doctor binary - exec it
-		err = syscall.Exec(doctorPath, []string{"zeude-doctor"}, os.Environ())
+		// Found zeude-doctor binary - exec it, forwarding flags like --fix
+		argv := append([]string{"zeude-doctor"}, args...)
+		if globals.json {
+			argv = append(argv, "--json")
+		}
+		environ := os.Environ()
+		if globals.noColor {
+			environ = append(environ, "NO_COLOR=1")
+		}
+		err = syscall.Exec(doctorPath, argv, environ)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to exec zeude-doctor: %v\n", err)
 			os.Exit(1)
@@ -111,7 +218,7 @@ func runDoctor() {
 	fmt.Printf("%s[OK]%s Zeude version: %s\n", colorGreen, colorReset, version)
 
 	// Check shim
-	shimPath := filepath.Join(home, ".zeude", "bin", "claude")
+	shimPath := filepath.Join(binDir, "claude")
 	if _, err := os.Stat(shimPath); err == nil {
 		fmt.Printf("%s[OK]%s Shim installed: %s\n", colorGreen, colorReset, shimPath)
 	} else {
@@ -119,7 +226,7 @@ func runDoctor() {
 	}
 
 	// Check credentials
-	credsPath := filepath.Join(home, ".zeude", "credentials")
+	credsPath, _ := paths.Credentials(e)
 	if _, err := os.Stat(credsPath); err == nil {
 		fmt.Printf("%s[OK]%s Credentials configured\n", colorGreen, colorReset)
 	} else {
@@ -127,7 +234,7 @@ func runDoctor() {
 	}
 
 	// Check real claude
-	realPath := filepath.Join(home, ".zeude", "real_binary_path")
+	realPath, _ := paths.RealBinaryPath(e)
 	if data, err := os.ReadFile(realPath); err == nil {
 		path := string(data)
 		if _, err := os.Stat(path); err == nil {
@@ -142,7 +249,7 @@ func runDoctor() {
 	// Check hooks
 	fmt.Println()
 	fmt.Println("Hooks:")
-	hooksDir := filepath.Join(home, ".claude", "hooks")
+	hooksDir, _ := paths.ClaudeHooks(e)
 	if _, err := os.Stat(hooksDir); os.IsNotExist(err) {
 		fmt.Printf("%s[INFO]%s No hooks directory\n", colorGray, colorReset)
 	} else {
@@ -196,6 +303,27 @@ func runDoctor() {
 	fmt.Printf("%s[INFO]%s Install zeude-doctor for full diagnostics\n", colorGray, colorReset)
 }
 
+// setUpdateChannel persists channel= so the shim's own update checks
+// follow the same channel; a one-off switch would be undone on next launch.
+func setUpdateChannel(channel string) error {
+	if err := config.ValidateValue(autoupdate.ChannelKey, channel); err != nil {
+		return fmt.Errorf("--channel: %v", err)
+	}
+	f, err := config.LoadFile(env.OS{})
+	if err != nil {
+		return err
+	}
+	if current, _ := f.Get(autoupdate.ChannelKey); current == channel {
+		return nil
+	}
+	f.Set(autoupdate.ChannelKey, channel)
+	if err := f.Save(); err != nil {
+		return fmt.Errorf("failed to save channel: %w", err)
+	}
+	fmt.Printf("%s✓%s Release channel set to %s\n", colorGreen, colorReset, channel)
+	return nil
+}
+
 // ForceUpdate forces an update check and install, ignoring any skip logic
 func ForceUpdate() error {
 	result := autoupdate.CheckWithResult()
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runMigrate converts the managed lists older versions kept in separate
+// files into managed.json. Sync does the same on its own; this shows it.
+func runMigrate(args []string) {
+	fs := newFlagSet("migrate")
+	dryRun := fs.Bool("dry-run", false, "only report what would be converted")
+	asJSON := fs.Bool("json", globals.json, "print the result as JSON")
+	fs.Parse(args)
+
+	result, err := mcpconfig.MigrateManifest(context.Background(), mcpconfig.MigrateOptions{DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		if result.Files == nil {
+			result.Files = []string{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(result)
+		return
+	}
+	if !result.Migrated() {
+		fmt.Printf("%s✓ Nothing to migrate%s; %s is current\n", colorGreen, colorReset, result.Path)
+		return
+	}
+
+	verb := "Converted"
+	if *dryRun {
+		verb = "Would convert"
+	}
+	fmt.Printf("%s %s into %s\n", verb, strings.Join(result.Files, ", "), result.Path)
+	fmt.Printf("  %d servers, %d hooks, %d skills managed\n", result.Servers, result.Hooks, result.Skills)
+	if *dryRun {
+		return
+	}
+	fmt.Printf("%s✓ Migrated%s; the old files were removed\n", colorGreen, colorReset)
+}
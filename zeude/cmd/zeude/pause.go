@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runPause stops sync from overwriting local edits, optionally for a while.
+func runPause(args []string) {
+	fs := newFlagSet("pause")
+	duration := fs.Duration("for", 0, "resume automatically after this long (e.g. 30m, 2h)")
+	fs.Parse(args)
+
+	if *duration < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --for must be positive")
+		os.Exit(1)
+	}
+	state, err := mcpconfig.Pause(*duration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s⏸ Sync %s.%s Run 'zeude resume' to sync again.\n", colorYellow, describePause(state), colorReset)
+}
+
+// runResume removes the pause so the next launch syncs again.
+func runResume() {
+	wasPaused, err := mcpconfig.Resume()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !wasPaused {
+		fmt.Println("Sync is not paused.")
+		return
+	}
+	fmt.Printf("%s✓ Sync resumed%s; the next claude launch or 'zeude sync' will sync.\n", colorGreen, colorReset)
+}
+
+// describePause renders a pause for humans, e.g. "paused until Jan 2 15:04 (1h30m left)".
+func describePause(state mcpconfig.PauseState) string {
+	if state.Until.IsZero() {
+		return "paused until resumed"
+	}
+	left := strings.TrimSuffix(time.Until(state.Until).Round(time.Minute).String(), "0s")
+	return fmt.Sprintf("paused until %s (%s left)", state.Until.Local().Format("Jan 2 15:04"), left)
+}
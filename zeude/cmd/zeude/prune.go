@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runPrune removes Zeude hook scripts and skills that no manifest tracks,
+// and settings.json entries for hook scripts that are gone.
+func runPrune(args []string) {
+	fs := newFlagSet("prune")
+	yes := fs.Bool("yes", false, "delete without asking")
+	dryRun := fs.Bool("dry-run", false, "only list what would be removed")
+	asJSON := fs.Bool("json", globals.json, "print the orphans as JSON (implies --dry-run)")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	found, err := mcpconfig.Prune(ctx, mcpconfig.PruneOptions{DryRun: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		orphans := found.Orphans
+		if orphans == nil {
+			orphans = []mcpconfig.Orphan{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(orphans)
+		return
+	}
+	if len(found.Orphans) == 0 {
+		fmt.Printf("%s✓ Nothing to prune%s\n", colorGreen, colorReset)
+		return
+	}
+
+	for _, o := range found.Orphans {
+		switch o.Kind {
+		case mcpconfig.OrphanSettings:
+			fmt.Printf("  %-8s settings.json entry for %s\n", o.Kind, o.Path)
+		default:
+			fmt.Printf("  %-8s %s\n", o.Kind, o.Path)
+		}
+	}
+	if *dryRun {
+		return
+	}
+	if !*yes && !confirm(fmt.Sprintf("Remove these %d Zeude leftovers?", len(found.Orphans))) {
+		fmt.Println("Nothing removed.")
+		return
+	}
+
+	result, err := mcpconfig.Prune(ctx, mcpconfig.PruneOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Removed%s %d leftovers\n", colorGreen, colorReset, len(result.Orphans))
+	if result.Backup != nil {
+		fmt.Printf("settings.json was backed up first; 'zeude restore %s' undoes the change.\n", result.Backup.ID)
+	}
+}
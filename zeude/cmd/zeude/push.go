@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const pushUsage = `Usage: zeude push hook <file> --event <event> [--type bash|python|node|deno|ruby|powershell] [--matcher tools] [--timeout seconds] [--name name] [--description text]
+       zeude push skill <file.md> [--slug slug] [--namespace ns]`
+
+// runPush handles `zeude push hook|skill`.
+func runPush(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, pushUsage)
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "hook":
+		runPushHook(args[1:])
+	case "skill":
+		runPushSkill(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, pushUsage)
+		os.Exit(1)
+	}
+}
+
+// runPushHook uploads a local hook script, then syncs so the generated
+// version replaces it. The script type defaults from the file extension.
+func runPushHook(args []string) {
+	file, args := splitFileArg(args)
+	fs := newFlagSet("push hook")
+	event := fs.String("event", "", "Claude Code event the hook runs on (Stop, PreToolUse, ...)")
+	scriptType := fs.String("type", "", "script type: "+strings.Join(mcpconfig.HookScriptTypes(), ", ")+" (default from the extension)")
+	matcher := fs.String("matcher", "", "tools a PreToolUse/PostToolUse hook fires for, e.g. Bash or 'Edit|Write' (default: all)")
+	timeout := fs.Int("timeout", 0, "seconds Claude lets the hook run (default: Claude's own)")
+	name := fs.String("name", "", "hook name (default: the file name)")
+	description := fs.String("description", "", "description shown in the dashboard")
+	fs.Parse(args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" || *event == "" {
+		fmt.Fprintln(os.Stderr, pushUsage)
+		os.Exit(1)
+	}
+
+	ext := filepath.Ext(file)
+	if *scriptType == "" {
+		*scriptType = mcpconfig.HookScriptTypeForExt(ext)
+		if *scriptType == "" {
+			fmt.Fprintf(os.Stderr, "Error: can't tell the script type of %s; pass --type (%s)\n", file, strings.Join(mcpconfig.HookScriptTypes(), ", "))
+			os.Exit(1)
+		}
+	}
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(file), ext)
+	}
+	data := readPushFile(file)
+
+	hook := mcpconfig.Hook{
+		Name:           *name,
+		Event:          *event,
+		Description:    *description,
+		Script:         stripShebang(string(data)),
+		ScriptType:     *scriptType,
+		Matcher:        *matcher,
+		TimeoutSeconds: *timeout,
+	}
+	id, err := mcpconfig.PushHook(context.Background(), hook)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Pushed%s hook %s (%s, %s)%s\n", colorGreen, colorReset, hook.Name, hook.Event, hook.ScriptType, pushedID(id))
+	syncAfterPush()
+}
+
+// runPushSkill uploads a local command file, reading its name and
+// description from the frontmatter, then syncs.
+func runPushSkill(args []string) {
+	file, args := splitFileArg(args)
+	fs := newFlagSet("push skill")
+	slug := fs.String("slug", "", "command slug, the /name it is invoked by (default: the file name)")
+	namespace := fs.String("namespace", "", "install under ~/.claude/commands/<namespace>/, invoked as /namespace:slug")
+	fs.Parse(args)
+	if file == "" && fs.NArg() > 0 {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(os.Stderr, pushUsage)
+		os.Exit(1)
+	}
+
+	skill := mcpconfig.ParseSkillFile(readPushFile(file))
+	skill.Slug, skill.Namespace = *slug, *namespace
+	if skill.Slug == "" {
+		skill.Slug = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if skill.Name == "" {
+		skill.Name = skill.Slug
+	}
+	id, err := mcpconfig.PushSkill(context.Background(), skill)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✓ Pushed%s skill /%s (%s)%s\n", colorGreen, colorReset, skill.Command(), skill.Name, pushedID(id))
+	syncAfterPush()
+}
+
+// splitFileArg takes the file argument off the front, allowing it before
+// the flags.
+func splitFileArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// readPushFile reads file, refusing anything the dashboard would be unable
+// to send back in a config response.
+func readPushFile(file string) []byte {
+	info, err := os.Stat(file)
+	if err == nil && info.Size() > mcpconfig.MaxResponseSize {
+		err = fmt.Errorf("%s is %d bytes; the limit is %d", file, info.Size(), mcpconfig.MaxResponseSize)
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return data
+}
+
+// stripShebang drops a leading #! line: the installed script gets its own,
+// and a second one mid-file is a syntax error for node.
+func stripShebang(script string) string {
+	if !strings.HasPrefix(script, "#!") {
+		return script
+	}
+	if i := strings.IndexByte(script, '\n'); i >= 0 {
+		return script[i+1:]
+	}
+	return ""
+}
+
+func pushedID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return " as " + id
+}
+
+// syncAfterPush refetches the config so the dashboard's version of what
+// was pushed replaces the local draft.
+func syncAfterPush() {
+	progress("Syncing...")
+	result := mcpconfig.Sync(context.Background(), mcpconfig.SyncOptions{
+		Version:      autoupdate.Version,
+		ForceRefresh: true,
+	})
+	switch {
+	case result.Paused:
+		fmt.Printf("%s⏸ Sync is paused;%s it arrives here after 'zeude resume'.\n", colorYellow, colorReset)
+	case !result.Success:
+		fmt.Fprintf(os.Stderr, "%s[WARN]%s Pushed, but the sync that installs it failed", colorYellow, colorReset)
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, ": %v", result.Err)
+		}
+		fmt.Fprintln(os.Stderr, "; run 'zeude sync' to retry")
+	default:
+		c := result.Changes
+		printChanges("Hooks installed", c.HooksInstalled)
+		printChanges("Skills written", c.SkillsWritten)
+		fmt.Printf("%s✓ Synced%s %d servers, %d hooks, %d skills\n", colorGreen, colorReset, result.ServerCount, result.HookCount, result.SkillCount)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+)
+
+// releasePlatforms are the artifacts build-release.sh publishes.
+var releasePlatforms = []string{"darwin-amd64", "darwin-arm64", "linux-amd64", "linux-arm64"}
+
+// runReleases lists the shim versions on the update server, newest first.
+func runReleases() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	index, err := autoupdate.ListReleases(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to list releases: %v\n", err)
+		os.Exit(1)
+	}
+
+	current := autoupdate.GetVersion()
+	if index.VersionOnly {
+		fmt.Printf("Latest:  %s\n", index.Latest)
+		fmt.Printf("Current: %s\n", current)
+		fmt.Printf("%s(the update server publishes no release index)%s\n", colorGray, colorReset)
+		return
+	}
+
+	own := autoupdate.PlatformArtifact()
+	for _, r := range index.Releases {
+		marker := "  "
+		if autoupdate.SameVersion(r.Version, current) {
+			marker = colorGreen + "* " + colorReset
+		}
+		line := marker + r.Version
+		if !r.Date.IsZero() {
+			line += "  " + r.Date.Format("2006-01-02")
+		}
+		if r.Version == index.Latest {
+			line += "  (latest)"
+		}
+		fmt.Println(line)
+
+		if len(r.Artifacts) == 0 {
+			continue
+		}
+		var platforms []string
+		for _, p := range releasePlatforms {
+			name := "claude-" + p
+			switch {
+			case !r.HasArtifact(name):
+				platforms = append(platforms, colorGray+p+" ✗"+colorReset)
+			case name == own:
+				platforms = append(platforms, p+" ✓ (this machine)")
+			default:
+				platforms = append(platforms, p+" ✓")
+			}
+		}
+		fmt.Printf("      %s\n", strings.Join(platforms, ", "))
+	}
+	if current == "dev" {
+		fmt.Printf("\n%sInstalled: dev build%s\n", colorGray, colorReset)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const serversUsage = "Usage: zeude servers list [--json] | check [--report] [--timeout 2m] | disable <name> | enable <name>"
+
+// runServers handles `zeude servers <subcommand>`.
+func runServers(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, serversUsage)
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "list":
+		runServersList(args[1:])
+	case "check":
+		runServersCheck(args[1:])
+	case "disable":
+		runServersToggle(args[1:], true)
+	case "enable":
+		runServersToggle(args[1:], false)
+	default:
+		fmt.Fprintln(os.Stderr, serversUsage)
+		os.Exit(1)
+	}
+}
+
+// runServersList prints the MCP servers in claude.json, marking the ones
+// Zeude manages. Env values are redacted.
+func runServersList(args []string) {
+	fs := newFlagSet("servers list")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	servers, err := mcpconfig.ListServers()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(servers)
+		return
+	}
+
+	if len(servers) == 0 {
+		fmt.Println("No MCP servers in claude.json.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tZEUDE\tINSTALLED\tENV\tCOMMAND")
+	for _, s := range servers {
+		managed := "-"
+		if s.Disabled {
+			managed = "disabled"
+		} else if s.Managed {
+			managed = "managed"
+		}
+		installed := "-"
+		if s.Installed != nil {
+			installed = yesNo(*s.Installed)
+			if s.Version != "" {
+				installed += " (" + s.Version + ")"
+			}
+		}
+		command := strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+		if command == "" {
+			command = s.URL
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, managed, installed, orDash(strings.Join(s.EnvNames, ",")), orDash(command))
+	}
+	w.Flush()
+
+	if status := mcpconfig.LoadInstallStatus(); status != nil {
+		fmt.Printf("\n%sInstall state as of %s (%s ago)%s\n", colorGray, status.CheckedAt.Local().Format(time.RFC3339), formatAge(time.Since(status.CheckedAt)), colorReset)
+	}
+}
+
+// runServersCheck runs the MCP install checks that sync does in the
+// background, and waits for them. Cold npm/uv caches can make this slow.
+func runServersCheck(args []string) {
+	fs := newFlagSet("servers check")
+	report := fs.Bool("report", false, "also send the result to the dashboard")
+	timeout := fs.Duration("timeout", 2*time.Minute, "give up on the checks after this long")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if !*asJSON {
+		progress("Checking managed MCP servers...")
+	}
+	servers, status, err := mcpconfig.CheckCachedServers(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].ServerName < status[j].ServerName })
+	timedOut := ctx.Err() != nil
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(status)
+	} else if len(status) == 0 {
+		fmt.Println("No Zeude-managed MCP servers in the cached config.")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCOMMAND\tINSTALLED\tVERSION")
+		for _, st := range status {
+			server := servers[st.ServerName]
+			command := strings.TrimSpace(server.Command + " " + strings.Join(server.Args, " "))
+			if server.Remote() {
+				command = server.URL
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", st.ServerName, orDash(command), yesNo(st.Installed), orDash(st.Version))
+		}
+		w.Flush()
+	}
+
+	if timedOut {
+		fmt.Fprintf(os.Stderr, "%s[WARN]%s Checks timed out after %s; unfinished servers show as not installed. Try a longer --timeout.\n",
+			colorYellow, colorReset, *timeout)
+	}
+	if *report {
+		if timedOut {
+			fmt.Fprintln(os.Stderr, "Not reporting a partial result to the dashboard.")
+			os.Exit(1)
+		}
+		if err := mcpconfig.ReportInstallStatus(mcpconfig.AgentKey(), status); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to report install status: %v\n", err)
+			os.Exit(1)
+		}
+		if !*asJSON {
+			fmt.Printf("%s✓ Reported to the dashboard%s\n", colorGreen, colorReset)
+		}
+	}
+	if timedOut {
+		os.Exit(1)
+	}
+}
+
+// runServersToggle handles `zeude servers disable|enable <name>`: a local
+// opt-out from a team MCP server that outlasts syncs.
+func runServersToggle(args []string, disable bool) {
+	verb := "enable"
+	if disable {
+		verb = "disable"
+	}
+	fs := newFlagSet("servers " + verb)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: zeude servers %s <name>\n", verb)
+		os.Exit(1)
+	}
+	name := fs.Arg(0)
+
+	if err := mcpconfig.SetServerDisabled(context.Background(), name, disable); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if disable {
+		fmt.Printf("%s✓ Disabled%s %s on this machine; it's removed from claude.json and syncs won't add it back.\n", colorGreen, colorReset, name)
+		fmt.Printf("'zeude servers enable %s' turns it back on.\n", name)
+		return
+	}
+	fmt.Printf("%s✓ Enabled%s %s\n", colorGreen, colorReset, name)
+}
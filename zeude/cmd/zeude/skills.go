@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+const skillsUsage = "Usage: zeude skills list [--json] | show <slug> [--raw]"
+
+// runSkills handles `zeude skills <subcommand>`.
+func runSkills(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, skillsUsage)
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "list":
+		runSkillsList(args[1:])
+	case "show":
+		runSkillsShow(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, skillsUsage)
+		os.Exit(1)
+	}
+}
+
+// runSkillsList prints the Zeude-managed slash commands and whether each
+// file still matches what was synced.
+func runSkillsList(args []string) {
+	fs := newFlagSet("skills list")
+	asJSON := fs.Bool("json", globals.json, "print JSON")
+	fs.Parse(args)
+
+	skills, err := mcpconfig.ListSkills()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		if skills == nil {
+			skills = []mcpconfig.SkillStatus{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(skills)
+		return
+	}
+
+	if len(skills) == 0 {
+		fmt.Println("No Zeude-managed skills installed.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSLUG\tSIZE\tSTATE\tPATH")
+	for _, s := range skills {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", orDash(s.Name), orDash(s.Slug), s.Size, s.State, s.Path)
+	}
+	w.Flush()
+}
+
+// runSkillsShow prints one skill file and whether it still matches the
+// last sync. --raw prints only the file, for piping. --complete prints the
+// managed slugs one per line, for shell completion scripts.
+func runSkillsShow(args []string) {
+	// Allow the slug before or after the flags
+	var slug string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		slug, args = args[0], args[1:]
+	}
+	fs := newFlagSet("skills show")
+	raw := fs.Bool("raw", false, "print only the file contents")
+	complete := fs.Bool("complete", false, "print managed skill slugs for shell completion")
+	fs.Parse(args)
+	if slug == "" && fs.NArg() > 0 {
+		slug = fs.Arg(0)
+	}
+
+	if *complete {
+		for _, s := range mcpconfig.ManagedSkillSlugs() {
+			if strings.HasPrefix(s, strings.TrimPrefix(slug, "/")) {
+				fmt.Println(s)
+			}
+		}
+		return
+	}
+	if slug == "" {
+		fmt.Fprintln(os.Stderr, "Usage: zeude skills show <slug> [--raw]")
+		os.Exit(1)
+	}
+
+	skill, err := mcpconfig.ShowSkill(slug)
+	if errors.Is(err, mcpconfig.ErrSkillNotFound) {
+		fmt.Fprintf(os.Stderr, "Error: no skill /%s (see 'zeude skills list')\n", strings.TrimPrefix(slug, "/"))
+		os.Exit(1)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *raw {
+		if skill.State == mcpconfig.SkillMissing {
+			fmt.Fprintf(os.Stderr, "Error: %s is missing; run 'zeude sync' to reinstall it\n", skill.Path)
+			os.Exit(1)
+		}
+		fmt.Print(skill.Content)
+		return
+	}
+
+	fmt.Printf("Skill: /%s", skill.Slug)
+	if skill.Name != "" {
+		fmt.Printf(" (%s)", skill.Name)
+	}
+	fmt.Println()
+	fmt.Printf("Path:  %s\n", skill.Path)
+	switch {
+	case !skill.Managed:
+		fmt.Printf("State: %snot managed by Zeude%s; this is your own command file and sync leaves it alone\n", colorYellow, colorReset)
+	case skill.State == mcpconfig.SkillSynced:
+		fmt.Printf("State: %s✓ matches the last sync%s\n", colorGreen, colorReset)
+	case skill.State == mcpconfig.SkillModified:
+		fmt.Printf("State: %s✗ edited since the last sync%s; the next sync will overwrite it\n", colorYellow, colorReset)
+	case skill.State == mcpconfig.SkillMissing:
+		fmt.Printf("State: %s✗ missing%s; run 'zeude sync' to reinstall it\n", colorRed, colorReset)
+		return
+	default:
+		fmt.Printf("State: %sunknown%s; not in the cached config, it will be removed on the next sync\n", colorGray, colorReset)
+	}
+	fmt.Println()
+	fmt.Print(skill.Content)
+	if !strings.HasSuffix(skill.Content, "\n") {
+		fmt.Println()
+	}
+	if skill.Diff != "" {
+		fmt.Printf("\n%sChanges since the last sync:%s\n", colorGray, colorReset)
+		printColoredDiff(skill.Diff)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// runStatus prints what Zeude last synced, from local files only.
+func runStatus() {
+	e := env.OS{}
+
+	if key := mcpconfig.AgentKey(); key != "" {
+		fmt.Printf("Agent key:      %s\n", config.MaskAgentKey(key))
+	} else {
+		fmt.Printf("Agent key:      %snot configured%s (run 'zeude login')\n", colorYellow, colorReset)
+	}
+	fmt.Printf("Dashboard:      %s\n", mcpconfig.DashboardURL())
+
+	if t, ok := autoupdate.LastUpdateTime(); ok {
+		fmt.Printf("Last update:    %s (%s ago), version %s\n", t.Format(time.RFC3339), formatAge(time.Since(t)), autoupdate.GetVersion())
+	} else {
+		fmt.Printf("Last update:    never, version %s\n", autoupdate.GetVersion())
+	}
+	if held, pinned := autoupdate.HeldVersion(); held != "" {
+		how := "until a newer release ships"
+		if pinned {
+			how = "pinned"
+		}
+		fmt.Printf("Update hold:    %s%s, %s%s ('zeude update' lifts it)\n", colorYellow, held, how, colorReset)
+	}
+
+	if pause := mcpconfig.CurrentPause(); pause.Paused {
+		fmt.Printf("Sync:           %s%s%s\n", colorYellow, describePause(pause), colorReset)
+	}
+
+	cached, expired := mcpconfig.LoadCachedConfig()
+	if cached == nil {
+		fmt.Println()
+		fmt.Printf("%sNo synced config yet.%s Run 'zeude sync' or start claude once.\n", colorYellow, colorReset)
+		return
+	}
+
+	version := cached.Version
+	if version == "" {
+		version = "(none)"
+	}
+	fmt.Printf("Config version: %s\n", version)
+	age := time.Since(cached.CachedAt)
+	freshness := colorGreen + "fresh" + colorReset
+	if expired {
+		freshness = colorYellow + "stale" + colorReset
+	}
+	fmt.Printf("Cache:          synced %s ago, %s (TTL %s)\n", formatAge(age), freshness, mcpconfig.EffectiveCacheTTL().Value)
+
+	state := mcpconfig.LoadManagedState()
+	hooksDir, _ := paths.ClaudeHooks(e)
+	fmt.Println()
+	printManaged("Servers", state.Servers, func(s string) string { return s })
+	printManaged("Hooks", state.Hooks, func(p string) string {
+		if rel, err := filepath.Rel(hooksDir, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+		return p
+	})
+	printManaged("Skills", state.Skills, func(p string) string {
+		return "/" + strings.TrimSuffix(filepath.Base(p), ".md")
+	})
+}
+
+func printManaged(label string, items []string, name func(string) string) {
+	fmt.Printf("%-8s %d\n", label+":", len(items))
+	for _, item := range items {
+		fmt.Printf("  %s\n", name(item))
+	}
+}
+
+// formatAge renders a duration at a human granularity (45s, 12m, 3h, 2d).
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runSync runs an MCP/hook/skill sync without launching claude.
+func runSync(args []string) {
+	fs := newFlagSet("sync")
+	verbose := fs.Bool("verbose", false, "print debug output (same as ZEUDE_DEBUG=1)")
+	force := fs.Bool("force", false, "refetch the full config and reinstall every section, changed or not")
+	offline := fs.Bool("offline", false, "apply the cached config without contacting the dashboard")
+	dryRun := fs.Bool("dry-run", false, "print what the sync would change, and why, without changing anything")
+	asJSON := fs.Bool("json", globals.json, "print the --dry-run plan as JSON")
+	fs.Parse(args)
+
+	if *verbose {
+		logging.Default().SetStderrLevel(logging.LevelDebug)
+	}
+	if *dryRun {
+		runSyncPlan(*force, *offline, *asJSON)
+		return
+	}
+
+	progress("Syncing...")
+	result := mcpconfig.Sync(context.Background(), mcpconfig.SyncOptions{
+		Version:      autoupdate.Version,
+		ForceRefresh: *force,
+		OfflineOnly:  *offline,
+		Progress:     progressWriter{},
+	})
+
+	if result.Paused {
+		state := mcpconfig.PauseState{Paused: true, Until: result.PausedUntil}
+		fmt.Printf("%s⏸ Sync %s%s; nothing was synced. Run 'zeude resume' first.\n", colorYellow, describePause(state), colorReset)
+		return
+	}
+	if result.NoAgentKey {
+		fmt.Fprintf(os.Stderr, "%s✗ No agent key configured.%s Run 'zeude login' first.\n", colorRed, colorReset)
+		os.Exit(1)
+	}
+	if result.TornDown {
+		c := result.Changes
+		printChanges("Servers removed", c.ServersRemoved)
+		printChanges("Hooks removed", c.HooksRemoved)
+		printChanges("Skills removed", c.SkillsRemoved)
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "%s✗ Your organization asked to remove Zeude's config from this machine, but %v%s\n", colorRed, result.Err, colorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%s⚠ Your organization removed Zeude's servers, hooks and skills from this machine.%s\n", colorYellow, colorReset)
+		return
+	}
+	if !result.Success {
+		fmt.Fprintf(os.Stderr, "%s✗ Sync failed%s", colorRed, colorReset)
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, ": %v", result.Err)
+		}
+		fmt.Fprintln(os.Stderr)
+		os.Exit(1)
+	}
+
+	switch {
+	case result.Offline:
+		fmt.Println("Source: cache (offline)")
+	case result.NotModified:
+		fmt.Println("Source: cache (dashboard config unchanged)")
+	case !result.RateLimitedUntil.IsZero():
+		fmt.Printf("Source: %scache (dashboard rate limited until %s)%s\n", colorYellow, result.RateLimitedUntil.Local().Format("15:04:05"), colorReset)
+	case result.FromCache:
+		fmt.Printf("Source: %scache (dashboard unreachable)%s\n", colorYellow, colorReset)
+	default:
+		fmt.Println("Source: dashboard (fresh fetch)")
+	}
+
+	c := result.Changes
+	printChanges("Servers added", c.ServersAdded)
+	printChanges("Servers updated", c.ServersUpdated)
+	printChanges("Servers removed", c.ServersRemoved)
+	printChanges("Hooks installed", c.HooksInstalled)
+	printChanges("Hooks removed", c.HooksRemoved)
+	printChanges("Skills written", c.SkillsWritten)
+	printChanges("Skills removed", c.SkillsRemoved)
+	if c.Empty() {
+		fmt.Printf("%sNo local changes%s\n", colorGray, colorReset)
+	}
+	if result.UnverifiedCount > 0 {
+		fmt.Printf("%s[WARN]%s %d hook(s)/skill(s) skipped: signature not verified\n", colorYellow, colorReset, result.UnverifiedCount)
+	}
+	if result.HooksFailed+result.SkillsFailed > 0 {
+		fmt.Printf("%s[WARN]%s %d hook(s), %d skill(s) failed to install: %s\n", colorYellow, colorReset, result.HooksFailed, result.SkillsFailed, result.FirstError)
+	}
+	if result.HooksUnrunnable > 0 {
+		fmt.Printf("%s[WARN]%s %d hook(s) can't run: %s not found on PATH\n", colorYellow, colorReset, result.HooksUnrunnable, strings.Join(result.MissingInterpreters, ", "))
+	}
+
+	fmt.Printf("%s✓ Synced%s %d servers, %d hooks, %d skills\n", colorGreen, colorReset, result.ServerCount, result.HookCount, result.SkillCount)
+
+	if result.Err != nil {
+		fmt.Fprintf(os.Stderr, "%s✗ %v%s\n", colorRed, result.Err, colorReset)
+		os.Exit(1)
+	}
+}
+
+// progressWriter prints each line written to it as a progress message.
+type progressWriter struct{}
+
+func (progressWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		progress("%s", line)
+	}
+	return len(p), nil
+}
+
+func printChanges(label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d): %s\n", label, len(items), strings.Join(items, ", "))
+}
+
+// runSyncPlan prints what a sync would change without changing anything.
+func runSyncPlan(force, offline, asJSON bool) {
+	plan, err := mcpconfig.Plan(context.Background(), mcpconfig.SyncOptions{
+		Version:      autoupdate.Version,
+		ForceRefresh: force,
+		OfflineOnly:  offline,
+	})
+	if err != nil {
+		if errors.Is(err, mcpconfig.ErrSyncPaused) {
+			fmt.Printf("%s⏸ Sync %s%s; a sync would change nothing.\n", colorYellow, describePause(mcpconfig.CurrentPause()), colorReset)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "%s✗ Plan failed%s: %v\n", colorRed, colorReset, err)
+		os.Exit(1)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(plan)
+		return
+	}
+	source := "dashboard"
+	if plan.FromCache {
+		source = "cache"
+	}
+	fmt.Printf("Config %s (from %s)\n", orDash(plan.ConfigVersion), source)
+	if plan.UninstallAll {
+		fmt.Printf("%s⚠ Your organization asked to remove Zeude's servers, hooks and skills from this machine.%s\n", colorYellow, colorReset)
+	}
+	if plan.Empty() {
+		fmt.Printf("%sNo local changes%s\n", colorGray, colorReset)
+		return
+	}
+	printPlanItems("server", plan.Servers)
+	printPlanItems("hook", plan.Hooks)
+	printPlanItems("skill", plan.Skills)
+	fmt.Println()
+	fmt.Printf("%d change(s). Run 'zeude sync' to apply them.\n", len(plan.Servers)+len(plan.Hooks)+len(plan.Skills))
+}
+
+func printPlanItems(kind string, items []mcpconfig.PlanItem) {
+	for _, item := range items {
+		color := colorGreen
+		switch item.Action {
+		case mcpconfig.PlanRemove, mcpconfig.PlanDelete, mcpconfig.PlanUnregister:
+			color = colorRed
+		case mcpconfig.PlanUpdate:
+			color = colorYellow
+		}
+		fmt.Printf("%s%-10s%s %-6s %s: %s %s(%s)%s\n", color, item.Action, colorReset, kind, item.Name, item.Reason, colorGray, item.Path, colorReset)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/signing"
+)
+
+// runTrustKey pins a content signing key.
+//
+//	zeude trust-key <base64-key>   pin a key received out of band
+//	zeude trust-key                trust the key the dashboard offered, after confirmation
+//	zeude trust-key --list         show pinned keys
+func runTrustKey(args []string) {
+	e := env.OS{}
+
+	if len(args) > 0 && args[0] == "--list" {
+		keys, err := signing.LoadTrusted(e)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(keys) == 0 {
+			fmt.Println("No signing keys pinned.")
+			return
+		}
+		for _, key := range keys {
+			fmt.Println(signing.Fingerprint(key))
+		}
+		return
+	}
+
+	var key ed25519.PublicKey
+	comment := "pinned manually"
+	if len(args) > 0 {
+		k, err := signing.ParsePublicKey(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		key = k
+	} else {
+		// Trust on first use: the key comes from the last synced config
+		cached, _ := mcpconfig.LoadCachedConfig()
+		if cached == nil || cached.Config.SigningKey == "" {
+			fmt.Fprintln(os.Stderr, "Error: the dashboard has not offered a signing key. Run 'claude' once to sync, or pass the key explicitly.")
+			os.Exit(1)
+		}
+		k, err := signing.ParsePublicKey(cached.Config.SigningKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: dashboard key: %v\n", err)
+			os.Exit(1)
+		}
+		key = k
+		comment = "trusted on first use"
+
+		fmt.Printf("The dashboard offers this content signing key:\n\n  %s\n\n", signing.Fingerprint(key))
+		fmt.Println("Verify the fingerprint with your Zeude administrator before trusting it.")
+		if !confirm("Trust this key?") {
+			fmt.Println("Not trusted.")
+			os.Exit(1)
+		}
+	}
+
+	added, err := signing.Pin(e, key, comment)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to pin key: %v\n", err)
+		os.Exit(1)
+	}
+	if !added {
+		fmt.Printf("%s✓ Already trusted: %s%s\n", colorGreen, signing.Fingerprint(key), colorReset)
+		return
+	}
+	fmt.Printf("%s✓ Trusted %s%s\n", colorGreen, signing.Fingerprint(key), colorReset)
+	fmt.Printf("Set %s=true in ~/.zeude/config to reject unsigned hooks and skills.\n", signing.RequireSignedKey)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/shellrc"
+)
+
+// runUninstall removes what Zeude added to Claude's files, the PATH blocks
+// added by install, and finally the Zeude data directory.
+func runUninstall(args []string) {
+	fs := newFlagSet("uninstall")
+	keepCreds := fs.Bool("keep-credentials", false, "keep the credentials file so a reinstall needs no login")
+	dryRun := fs.Bool("dry-run", false, "print what would be removed without changing anything")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Parse(args)
+
+	e := env.OS{}
+	ctx := context.Background()
+
+	dataDir, err := paths.Dir(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	plan, err := mcpconfig.Uninstall(ctx, mcpconfig.UninstallOptions{DryRun: true})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rcFiles := installedRCFiles(e)
+
+	fmt.Println("This will remove:")
+	printPlan("MCP servers from claude.json", plan.Servers)
+	printPlan("MCP servers from", plan.ProjectFiles)
+	if plan.HookEntries > 0 {
+		fmt.Printf("  %d hook registration(s) from settings.json\n", plan.HookEntries)
+	}
+	printPlan("Hook scripts", plan.HookFiles)
+	printPlan("Skill files", plan.SkillFiles)
+	printPlan("PATH block from", rcFiles)
+	if *keepCreds {
+		fmt.Printf("  %s (keeping %s)\n", dataDir, paths.CredentialsFile)
+	} else {
+		fmt.Printf("  %s\n", dataDir)
+	}
+	fmt.Println()
+
+	if *dryRun {
+		fmt.Printf("%sDry run: nothing was changed.%s\n", colorGray, colorReset)
+		return
+	}
+	if !*yes && !confirm("Uninstall Zeude?") {
+		fmt.Println("Uninstall cancelled.")
+		return
+	}
+
+	failed := false
+	result, err := mcpconfig.Uninstall(ctx, mcpconfig.UninstallOptions{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s[FAIL]%s %v\n", colorRed, colorReset, err)
+		failed = true
+	} else {
+		fmt.Printf("%s✓%s Removed %d server(s), %d hook(s), %d skill(s)\n", colorGreen, colorReset,
+			len(result.Servers), len(result.HookFiles), len(result.SkillFiles))
+	}
+
+	for _, path := range rcFiles {
+		if _, err := shellrc.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s[FAIL]%s %s: %v\n", colorRed, colorReset, path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s✓%s Removed PATH block from %s\n", colorGreen, colorReset, path)
+	}
+
+	// Last: the managed lists above lived here, and nothing may log or
+	// audit into it afterwards or the directory comes back
+	if failed {
+		fmt.Fprintf(os.Stderr, "%sKept %s because of the errors above; fix them and run uninstall again.%s\n", colorYellow, dataDir, colorReset)
+		os.Exit(1)
+	}
+	if err := removeDataDir(e, dataDir, *keepCreds); err != nil {
+		fmt.Fprintf(os.Stderr, "%s[FAIL]%s %v\n", colorRed, colorReset, err)
+		os.Exit(1)
+	}
+	if *keepCreds {
+		fmt.Printf("%s✓%s Removed %s (kept %s)\n", colorGreen, colorReset, dataDir, paths.CredentialsFile)
+	} else {
+		fmt.Printf("%s✓%s Removed %s\n", colorGreen, colorReset, dataDir)
+	}
+	fmt.Println()
+	fmt.Println("Restart your shell to use the original claude.")
+}
+
+func printPlan(label string, items []string) {
+	for _, item := range items {
+		fmt.Printf("  %s: %s\n", label, item)
+	}
+}
+
+// installedRCFiles returns the shell startup files carrying a Zeude block.
+func installedRCFiles(e env.Env) []string {
+	candidates, err := shellrc.Candidates(e)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, f := range candidates {
+		if ok, _ := shellrc.Installed(f.Path); ok {
+			files = append(files, f.Path)
+		}
+	}
+	return files
+}
+
+// removeDataDir deletes the Zeude data directory, optionally keeping the
+// credentials file, and the legacy ~/.zeude link left by an XDG migration.
+func removeDataDir(e env.Env, dir string, keepCredentials bool) error {
+	if keepCredentials {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Name() == paths.CredentialsFile {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if legacy, err := paths.LegacyDir(e); err == nil && legacy != dir {
+		if info, err := os.Lstat(legacy); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			os.Remove(legacy)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/mcpconfig"
+)
+
+// runWhoami asks the dashboard which account the agent key belongs to,
+// falling back to the cached identity when the dashboard is unreachable.
+func runWhoami() {
+	key := mcpconfig.AgentKey()
+	if key == "" {
+		fmt.Fprintf(os.Stderr, "%s✗ No agent key configured.%s Run 'zeude login' first.\n", colorRed, colorReset)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Dashboard: %s\n", mcpconfig.DashboardURL())
+	fmt.Printf("Agent key: %s\n", config.MaskAgentKey(key))
+
+	resp, err := mcpconfig.CheckAgentKey(context.Background(), key)
+	marker := ""
+	if err != nil {
+		var authErr *mcpconfig.AuthError
+		if errors.As(err, &authErr) {
+			fmt.Fprintf(os.Stderr, "%s✗ The dashboard rejected this key%s (HTTP %d: %s); it is invalid or was revoked. Run 'zeude login' with a new key.\n",
+				colorRed, colorReset, authErr.StatusCode, authErr.Message)
+			os.Exit(1)
+		}
+		cached, _ := mcpconfig.LoadCachedConfig()
+		if cached == nil {
+			fmt.Fprintf(os.Stderr, "%s✗ Could not reach the dashboard:%s %v\n", colorRed, colorReset, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s[WARN]%s Could not reach the dashboard (%v); showing the identity from the last sync\n", colorYellow, colorReset, err)
+		resp = &cached.Config
+		marker = " " + colorGray + "(cached)" + colorReset
+	}
+
+	fmt.Printf("Email:     %s%s\n", orDash(resp.UserEmail), marker)
+	fmt.Printf("User ID:   %s%s\n", orDash(resp.UserID), marker)
+	fmt.Printf("Team:      %s%s\n", orDash(resp.Team), marker)
+}
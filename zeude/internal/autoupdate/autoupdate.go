@@ -2,6 +2,9 @@
 package autoupdate
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,14 +14,38 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Version, Commit and BuildDate are set at build time via -ldflags
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
 )
 
-// Version is set at build time via -ldflags
-var Version = "dev"
+// logger records update failures. They are logged at warn level so a flaky
+// network doesn't print on every launch; they still land in ~/.zeude/logs.
+var logger = logging.Default().Component("autoupdate")
+
+// audit records each binary swap; tests replace it.
+var audit = logging.Audit()
+
+// executable locates the binary an update replaces; tests point it at a
+// scratch file.
+var executable = os.Executable
 
 const (
-	checkInterval       = 24 * time.Hour
-	forceUpdateInterval = 12 * time.Hour // Force update if not updated in 12 hours
+	checkInterval = 24 * time.Hour
+	// ForceUpdateInterval is how long a client may go without a successful
+	// update before it counts as out of date
+	ForceUpdateInterval = 12 * time.Hour
 	updateTimeout       = 30 * time.Second
 	defaultUpdateURL    = "https://your-dashboard-url/releases"
 )
@@ -27,76 +54,187 @@ const (
 // Returns true if update is required, false otherwise.
 // This is used to enforce periodic updates.
 func RequiresUpdate() bool {
+	return requiresUpdate(env.OS{})
+}
+
+func requiresUpdate(e env.Env) bool {
 	if Version == "dev" {
 		return false
 	}
 
-	configDir := filepath.Join(os.Getenv("HOME"), ".zeude")
-	lastSuccessFile := filepath.Join(configDir, "last_successful_update")
+	lastSuccessFile := filepath.Join(zeudeDir(e), paths.LastUpdateFile)
 
 	info, err := os.Stat(lastSuccessFile)
 	if err != nil {
 		// File doesn't exist - first time user or never updated successfully
 		// Be lenient: create the file and don't require update yet
-		touchFile(lastSuccessFile)
+		touchFile(e, lastSuccessFile)
 		return false
 	}
 
-	return time.Since(info.ModTime()) > forceUpdateInterval
+	return e.Now().Sub(info.ModTime()) > ForceUpdateInterval
 }
 
 // TimeSinceLastUpdate returns how long since the last successful update
 func TimeSinceLastUpdate() time.Duration {
-	configDir := filepath.Join(os.Getenv("HOME"), ".zeude")
-	lastSuccessFile := filepath.Join(configDir, "last_successful_update")
+	e := env.OS{}
+	lastSuccessFile := filepath.Join(zeudeDir(e), paths.LastUpdateFile)
 
 	info, err := os.Stat(lastSuccessFile)
 	if err != nil {
 		return 0
 	}
 
-	return time.Since(info.ModTime())
+	return e.Now().Sub(info.ModTime())
+}
+
+// LastUpdateTime returns when the last successful update check happened,
+// and false if none has been recorded.
+func LastUpdateTime() (time.Time, bool) {
+	info, err := os.Stat(filepath.Join(zeudeDir(env.OS{}), paths.LastUpdateFile))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
 }
 
 // MarkUpdateSuccess marks the current time as last successful update
 func MarkUpdateSuccess() {
-	configDir := filepath.Join(os.Getenv("HOME"), ".zeude")
-	lastSuccessFile := filepath.Join(configDir, "last_successful_update")
-	touchFile(lastSuccessFile)
+	markUpdateSuccess(env.OS{})
+}
+
+func markUpdateSuccess(e env.Env) {
+	lastSuccessFile := filepath.Join(zeudeDir(e), paths.LastUpdateFile)
+	touchFile(e, lastSuccessFile)
+	os.Remove(filepath.Join(zeudeDir(e), paths.UpdatePendingFile))
+}
+
+// Pending is an update that was found but could not be installed.
+type Pending struct {
+	Version     string    // the release that failed to install
+	LastSuccess time.Time // when this client was last known up to date
+}
+
+// PendingUpdate returns the update the last check failed to install, or
+// nil if the last check left this client up to date.
+func PendingUpdate(e env.Env) *Pending {
+	if Version == "dev" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(zeudeDir(e), paths.UpdatePendingFile))
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(zeudeDir(e), paths.LastUpdateFile))
+	if err != nil {
+		return nil
+	}
+	return &Pending{Version: strings.TrimSpace(string(data)), LastSuccess: info.ModTime()}
+}
+
+// markUpdatePending records that version is available but didn't install.
+func markUpdatePending(e env.Env, version string) {
+	path := filepath.Join(zeudeDir(e), paths.UpdatePendingFile)
+	if err := fsutil.WriteFileAtomic(path, []byte(version+"\n"), 0644); err != nil {
+		logger.Warn("failed to record pending update", "error", err)
+	}
+}
+
+// zeudeDir returns the Zeude data directory for the given environment.
+// Falls back to a relative .zeude, as before, when the home dir is unknown.
+func zeudeDir(e env.Env) string {
+	dir, err := paths.Dir(e)
+	if err != nil {
+		return paths.LegacyDirName
+	}
+	return dir
 }
 
 // touchFile creates or updates the modification time of a file
-func touchFile(path string) {
+// to the environment's current time.
+func touchFile(e env.Env, path string) {
 	// Ensure directory exists
 	os.MkdirAll(filepath.Dir(path), 0755)
 	f, err := os.Create(path)
-	if err == nil {
-		f.Close()
+	if err != nil {
+		logger.Warn("failed to touch marker file", "path", path, "error", err)
+		return
 	}
+	f.Close()
+	now := e.Now()
+	os.Chtimes(path, now, now)
 }
 
 // writeCurrentVersion writes the current version to ~/.zeude/current_version
 // This allows the update checker hook to compare versions
-func writeCurrentVersion() {
-	configDir := filepath.Join(os.Getenv("HOME"), ".zeude")
-	versionFile := filepath.Join(configDir, "current_version")
+func writeCurrentVersion(e env.Env) {
+	configDir := zeudeDir(e)
+	versionFile := filepath.Join(configDir, paths.CurrentVersionFile)
 
 	// Ensure directory exists
 	os.MkdirAll(configDir, 0755)
 
 	// Write version
-	os.WriteFile(versionFile, []byte(Version), 0644)
+	if err := os.WriteFile(versionFile, []byte(Version), 0644); err != nil {
+		logger.Warn("failed to write current version", "path", versionFile, "error", err)
+	}
 }
 
+// Release channels. Stable is the default; beta is opted into per machine.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+	// ChannelKey selects the channel in ~/.zeude/config.
+	ChannelKey = "channel"
+)
+
 // UpdateResult contains the result of an update check.
 type UpdateResult struct {
 	Skipped             bool   // True if check was skipped (checked recently)
 	NewVersionAvailable bool   // True if a new version is available
 	NewVersion          string // The new version string
 	Updated             bool   // True if update was successfully applied
+	Downgrade           bool   // True if NewVersion is older: the channel changed
+	Reinstalled         bool   // True if Force re-downloaded the installed version
+	Held                bool   // True if a version from 'zeude update --to' kept the update from running
+	Channel             string // Channel that was checked
 	Error               error  // Error if check or update failed
 }
 
+// ConfiguredChannel returns the channel from ~/.zeude/config, defaulting to stable.
+func ConfiguredChannel() string {
+	if ch := strings.ToLower(strings.TrimSpace(config.Get(ChannelKey))); ch == ChannelBeta {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+// channelURLs returns the version file and binary URLs for a channel.
+// Stable keeps the original layout so existing servers keep working.
+func channelURLs(channel string) (versionURL, binaryURL string) {
+	if channel == ChannelBeta {
+		return defaultUpdateURL + "/version/beta.txt", defaultUpdateURL + "/beta/" + PlatformArtifact()
+	}
+	return defaultUpdateURL + "/version.txt", defaultUpdateURL + "/" + PlatformArtifact()
+}
+
+// installedChannel returns the channel the running binary was installed
+// from, or stable when it was never recorded (every build before channels).
+func installedChannel(e env.Env) string {
+	data, err := os.ReadFile(filepath.Join(zeudeDir(e), paths.UpdateChannelFile))
+	if err == nil && strings.TrimSpace(string(data)) == ChannelBeta {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+func recordChannel(e env.Env, channel string) {
+	path := filepath.Join(zeudeDir(e), paths.UpdateChannelFile)
+	if err := os.WriteFile(path, []byte(channel+"\n"), 0644); err != nil {
+		logger.Warn("failed to record update channel", "path", path, "error", err)
+	}
+}
+
 // Check checks for updates and self-updates if a newer version is available.
 // This is fail-open: any error is logged and execution continues.
 // Deprecated: Use CheckWithResult for more detailed information.
@@ -107,10 +245,52 @@ func Check() {
 // CheckWithResult checks for updates and returns detailed result.
 // Always checks on startup (no skip). This is fail-open: any error is returned but execution continues.
 func CheckWithResult() UpdateResult {
-	result := UpdateResult{}
+	return CheckWithContext(context.Background())
+}
+
+// CheckWithContext is CheckWithResult bounded by ctx: cancelling it aborts
+// the version check or an in-progress download.
+func CheckWithContext(ctx context.Context) UpdateResult {
+	return CheckWithOptions(ctx, CheckOptions{})
+}
+
+// CheckOptions customizes an update check. The zero value checks the real machine.
+type CheckOptions struct {
+	// Env supplies the home directory, environment variables, and clock.
+	// nil means the real process environment.
+	Env env.Env
+	// Channel is the release channel to check; "" means ConfiguredChannel.
+	Channel string
+	// CheckOnly reports whether a newer version exists without downloading it.
+	CheckOnly bool
+	// Force reinstalls the channel's latest release even when it is not
+	// newer, to repair a corrupted binary.
+	Force bool
+	// NoReexec returns after an update instead of re-executing os.Args with
+	// the new binary. Commands like `zeude update --force` would otherwise
+	// run themselves again.
+	NoReexec bool
+	// Manual marks a check the user asked for. It ignores, and unless
+	// CheckOnly lifts, a hold placed by To.
+	Manual bool
+	// To installs this exact version instead of the channel's latest and
+	// holds it: automatic checks leave it until a newer release than the
+	// current latest ships, or with Pin, until a Manual update.
+	To  string
+	Pin bool
+}
+
+// CheckWithOptions is CheckWithContext with an explicit environment.
+func CheckWithOptions(ctx context.Context, opts CheckOptions) UpdateResult {
+	e := env.OrDefault(opts.Env)
+	channel := opts.Channel
+	if channel == "" {
+		channel = ConfiguredChannel()
+	}
+	result := UpdateResult{Channel: channel}
 
 	// Always write current version for hook to read
-	writeCurrentVersion()
+	writeCurrentVersion(e)
 
 	if Version == "dev" {
 		// Skip update check for development builds
@@ -118,33 +298,92 @@ func CheckWithResult() UpdateResult {
 		return result
 	}
 
+	if !opts.Manual && config.CI(e) {
+		// A pipeline's binaries must not change partway through it
+		result.Skipped = true
+		return result
+	}
+
+	if config.Offline(e) {
+		result.Skipped = true
+		if opts.Manual {
+			result.Error = fmt.Errorf("%s is set", config.OfflineEnv)
+		}
+		return result
+	}
+
+	if opts.To != "" {
+		return installVersion(ctx, e, opts, result)
+	}
+
 	// Check remote version
-	remoteVersion, err := fetchRemoteVersion()
+	versionURL, binaryURL := channelURLs(channel)
+	remoteVersion, err := fetchRemoteVersion(ctx, versionURL)
 	if err != nil {
+		logger.Warn("version check failed", "channel", channel, "error", err)
 		result.Error = err
 		return result
 	}
 
 	result.NewVersion = remoteVersion
 
-	// Compare versions
-	if !isNewer(remoteVersion, Version) {
-		// Already up to date - mark as successful
-		MarkUpdateSuccess()
-		return result
+	if h := loadHold(e); h != nil {
+		if !opts.Manual && h.holds(remoteVersion) {
+			// Count it as a successful check so the forced-update
+			// interval doesn't override the hold
+			markUpdateSuccess(e)
+			result.Held = true
+			return result
+		}
+		if !opts.CheckOnly {
+			clearHold(e)
+		}
 	}
 
-	result.NewVersionAvailable = true
+	// Compare versions. Moving from beta back to stable takes the stable
+	// release even when it is older than the installed beta.
+	switched := installedChannel(e) != channel
+	if compareVersions(remoteVersion, Version) <= 0 {
+		if SameVersion(remoteVersion, Version) || !(switched && channel == ChannelStable) {
+			// Already up to date - mark as successful
+			if switched && SameVersion(remoteVersion, Version) {
+				recordChannel(e, channel)
+			}
+			markUpdateSuccess(e)
+			if !opts.Force || opts.CheckOnly {
+				return result
+			}
+			result.Reinstalled = true
+		} else {
+			result.Downgrade = true
+		}
+	}
+
+	result.NewVersionAvailable = !result.Reinstalled
+	if opts.CheckOnly {
+		return result
+	}
 
 	// Perform update
-	if err := performUpdate(); err != nil {
+	if err := performUpdate(ctx, binaryURL); err != nil {
+		logger.Warn("update failed", "from", Version, "to", remoteVersion, "channel", channel, "error", err)
+		if ctx.Err() == nil {
+			markUpdatePending(e, remoteVersion)
+		}
 		result.Error = err
 		return result
 	}
 
 	// Mark update as successful
-	MarkUpdateSuccess()
+	markUpdateSuccess(e)
+	recordChannel(e, channel)
 	result.Updated = true
+	logger.Info("updated", "from", Version, "to", remoteVersion, "channel", channel, "forced", result.Reinstalled)
+
+	// Startup was cancelled (e.g. Ctrl-C): keep the update, skip the re-exec
+	if ctx.Err() != nil || opts.NoReexec {
+		return result
+	}
 
 	// Re-exec with new binary immediately
 	execPath, err := os.Executable()
@@ -152,35 +391,39 @@ func CheckWithResult() UpdateResult {
 		execPath, _ = filepath.EvalSymlinks(execPath)
 		fmt.Fprintf(os.Stderr, "\n")
 		// Replace current process with new binary
-		syscall.Exec(execPath, os.Args, os.Environ())
-		// If exec fails, continue with old binary
+		if err := syscall.Exec(execPath, os.Args, os.Environ()); err != nil {
+			// If exec fails, continue with old binary
+			logger.Warn("re-exec after update failed", "path", execPath, "error", err)
+		}
+	} else {
+		logger.Warn("cannot locate updated binary", "error", err)
 	}
 
 	return result
 }
 
 // shouldSkip returns true if we checked recently (within checkInterval)
-func shouldSkip(lastCheckFile string) bool {
+func shouldSkip(e env.Env, lastCheckFile string) bool {
 	info, err := os.Stat(lastCheckFile)
 	if err != nil {
 		return false // File doesn't exist, should check
 	}
-	return time.Since(info.ModTime()) < checkInterval
+	return e.Now().Sub(info.ModTime()) < checkInterval
 }
 
 // updateLastCheckTime updates the last check timestamp file
-func updateLastCheckTime(lastCheckFile string) {
-	// Touch the file
-	f, err := os.Create(lastCheckFile)
-	if err == nil {
-		f.Close()
-	}
+func updateLastCheckTime(e env.Env, lastCheckFile string) {
+	touchFile(e, lastCheckFile)
 }
 
-// fetchRemoteVersion fetches the latest version from the server
-func fetchRemoteVersion() (string, error) {
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(defaultUpdateURL + "/version.txt")
+// fetchRemoteVersion fetches the latest version from a channel's version file
+func fetchRemoteVersion(ctx context.Context, versionURL string) (string, error) {
+	req, err := httpclient.NewRequest(ctx, http.MethodGet, versionURL, nil)
+	if err != nil {
+		return "", err
+	}
+	client := httpclient.New(5 * time.Second)
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -198,36 +441,16 @@ func fetchRemoteVersion() (string, error) {
 	return strings.TrimSpace(string(body)), nil
 }
 
-// isNewer returns true if remote version is newer than local
-func isNewer(remote, local string) bool {
-	// Strip 'v' prefix if present
-	remote = strings.TrimPrefix(remote, "v")
-	local = strings.TrimPrefix(local, "v")
-
-	// Simple string comparison for semver (works for x.y.z format)
-	remoteParts := strings.Split(remote, ".")
-	localParts := strings.Split(local, ".")
-
-	for i := 0; i < len(remoteParts) && i < len(localParts); i++ {
-		if remoteParts[i] > localParts[i] {
-			return true
-		}
-		if remoteParts[i] < localParts[i] {
-			return false
-		}
-	}
-
-	return len(remoteParts) > len(localParts)
+// SameVersion compares versions ignoring a leading v.
+func SameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
 }
 
-// performUpdate downloads and replaces the current binary
-func performUpdate() error {
-	// Determine platform
-	platform := fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)
-	binaryURL := fmt.Sprintf("%s/claude-%s", defaultUpdateURL, platform)
+// performUpdate downloads binaryURL and replaces the current binary
+func performUpdate(ctx context.Context, binaryURL string) error {
 
 	// Get current executable path
-	execPath, err := os.Executable()
+	execPath, err := executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
@@ -239,8 +462,12 @@ func performUpdate() error {
 	}
 
 	// Download new binary to temp file
-	client := &http.Client{Timeout: updateTimeout}
-	resp, err := client.Get(binaryURL)
+	req, err := httpclient.NewRequest(ctx, http.MethodGet, binaryURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	client := httpclient.New(updateTimeout)
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
@@ -265,8 +492,12 @@ func performUpdate() error {
 		}
 	}()
 
-	// Copy downloaded content
-	_, err = io.Copy(tmpFile, resp.Body)
+	// Copy downloaded content and flush it before it replaces the binary
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hash), resp.Body)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
 	tmpFile.Close()
 	if err != nil {
 		return fmt.Errorf("failed to write update: %w", err)
@@ -285,16 +516,29 @@ func performUpdate() error {
 	}
 
 	// Move new binary into place
+	event := logging.AuditEvent{
+		Action:      logging.AuditSelfUpdate,
+		Path:        execPath,
+		ContentHash: "sha256:" + hex.EncodeToString(hash.Sum(nil)),
+	}
 	if err := os.Rename(tmpPath, execPath); err != nil {
 		// Try to restore backup
 		os.Rename(backupPath, execPath)
+		audit.RecordResult(event, err)
 		return fmt.Errorf("failed to install update: %w", err)
 	}
+	audit.Record(event)
 
 	// Clean up backup (on success, old binary is no longer needed)
 	os.Remove(backupPath)
 
 	success = true
+
+	// Commit the swap: a crash before the directory reaches disk could
+	// otherwise leave no claude binary at all
+	if err := fsutil.SyncDir(filepath.Dir(execPath)); err != nil {
+		logger.Debugf("failed to sync %s: %v", filepath.Dir(execPath), err)
+	}
 	return nil
 }
 
@@ -302,3 +546,41 @@ func performUpdate() error {
 func GetVersion() string {
 	return Version
 }
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildDate  string `json:"buildDate"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Executable string `json:"executable,omitempty"`
+}
+
+// GetBuildInfo returns the build metadata shared by all Zeude binaries.
+func GetBuildInfo() BuildInfo {
+	exe, _ := os.Executable()
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return BuildInfo{
+		Version:    Version,
+		Commit:     Commit,
+		BuildDate:  BuildDate,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Executable: exe,
+	}
+}
+
+// String formats the version with the short commit, e.g. "1.4.0 (3f2a9c1)".
+func (b BuildInfo) String() string {
+	if b.Commit == "" || b.Commit == "unknown" {
+		return b.Version
+	}
+	commit := b.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	return fmt.Sprintf("%s (%s)", b.Version, commit)
+}
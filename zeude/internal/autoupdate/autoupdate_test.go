@@ -0,0 +1,308 @@
+package autoupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+func TestMain(m *testing.M) {
+	// Keep test runs out of the real ~/.zeude logs and audit trail
+	logger = logging.New(logging.Options{}).Component("autoupdate")
+	audit = logging.NewAuditLog("", 0, 0)
+	os.Exit(m.Run())
+}
+
+// testEnv returns a fake environment with a fresh temp home and a fixed clock.
+func testEnv(t *testing.T) *env.Fake {
+	t.Helper()
+	return &env.Fake{
+		Home: t.TempDir(),
+		Vars: map[string]string{},
+		Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// setVersion sets the build version for the rest of the test.
+func setVersion(t *testing.T, v string) {
+	t.Helper()
+	old := Version
+	Version = v
+	t.Cleanup(func() { Version = old })
+}
+
+func TestShouldSkip(t *testing.T) {
+	tests := []struct {
+		name    string
+		checked bool
+		after   time.Duration
+		want    bool
+	}{
+		{"never checked", false, 0, false},
+		{"just checked", true, 0, true},
+		{"within interval", true, checkInterval - time.Minute, true},
+		{"interval passed", true, checkInterval + time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEnv(t)
+			lastCheck := filepath.Join(t.TempDir(), "last_check")
+			if tt.checked {
+				updateLastCheckTime(e, lastCheck)
+			}
+			e.Advance(tt.after)
+			if got := shouldSkip(e, lastCheck); got != tt.want {
+				t.Errorf("shouldSkip() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		marked  bool
+		after   time.Duration
+		want    bool
+	}{
+		{"dev build", "dev", true, 2 * ForceUpdateInterval, false},
+		{"first run", "1.2.0", false, 0, false},
+		{"recent success", "1.2.0", true, ForceUpdateInterval - time.Minute, false},
+		{"overdue", "1.2.0", true, ForceUpdateInterval + time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, tt.version)
+			e := testEnv(t)
+			if tt.marked {
+				markUpdateSuccess(e)
+			}
+			e.Advance(tt.after)
+			if got := requiresUpdate(e); got != tt.want {
+				t.Errorf("requiresUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiresUpdateFirstRunStartsTheClock(t *testing.T) {
+	setVersion(t, "1.2.0")
+	e := testEnv(t)
+	if requiresUpdate(e) {
+		t.Fatal("first run required an update")
+	}
+	if _, err := os.Stat(filepath.Join(zeudeDir(e), paths.LastUpdateFile)); err != nil {
+		t.Fatalf("first run didn't record a start time: %v", err)
+	}
+	e.Advance(ForceUpdateInterval + time.Minute)
+	if !requiresUpdate(e) {
+		t.Error("requiresUpdate() = false once the interval passed since the first run")
+	}
+}
+
+func TestPendingUpdate(t *testing.T) {
+	setVersion(t, "1.2.0")
+	e := testEnv(t)
+	markUpdateSuccess(e)
+	markUpdatePending(e, "1.3.0")
+	if p := PendingUpdate(e); p == nil || p.Version != "1.3.0" {
+		t.Fatalf("PendingUpdate() = %+v, want version 1.3.0", p)
+	}
+	// A later successful check clears it
+	markUpdateSuccess(e)
+	if p := PendingUpdate(e); p != nil {
+		t.Errorf("PendingUpdate() after success = %+v, want nil", p)
+	}
+}
+
+func TestOfflineSkipsUpdateCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CheckOptions
+		wantErr bool
+	}{
+		{"automatic", CheckOptions{}, false},
+		{"manual", CheckOptions{Manual: true}, true},
+		{"specific version", CheckOptions{Manual: true, To: "1.3.0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, "1.2.0")
+			e := testEnv(t)
+			e.Vars[config.OfflineEnv] = "1"
+			srv := serveUpdates(t, map[string]string{"/version.txt": "v1.3.0\n"})
+
+			opts := tt.opts
+			opts.Env, opts.Channel = e, ChannelStable
+			r := CheckWithOptions(context.Background(), opts)
+			if !r.Skipped || r.NewVersionAvailable || (r.Error != nil) != tt.wantErr {
+				t.Errorf("offline check = %+v", r)
+			}
+			if len(srv.requested) > 0 {
+				t.Errorf("offline check requested %v", srv.requested)
+			}
+		})
+	}
+}
+
+// stallingServer serves files like fakeUpdateServer but holds any other
+// request until its context is done, signalling started as it arrives.
+type stallingServer struct {
+	files   map[string]string
+	started chan string
+}
+
+func (s stallingServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.String(), defaultUpdateURL)
+	if body, ok := s.files[path]; ok {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	}
+	s.started <- path
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestCancelAbortsUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"version check", nil},
+		{"download", map[string]string{"/version.txt": "v1.3.0\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, "1.2.0")
+			e := testEnv(t)
+			srv := stallingServer{files: tt.files, started: make(chan string, 4)}
+			t.Cleanup(httpclient.SetTransport(srv))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan UpdateResult)
+			go func() {
+				done <- CheckWithOptions(ctx, CheckOptions{Env: e, Channel: ChannelStable, NoReexec: true})
+			}()
+			<-srv.started
+			cancel()
+			select {
+			case r := <-done:
+				if r.Updated || !errors.Is(r.Error, context.Canceled) {
+					t.Errorf("cancelled check = %+v, want a context.Canceled error", r)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("update still running after its context was cancelled")
+			}
+		})
+	}
+}
+
+// useScratchBinary points updates at a scratch binary holding content, so
+// a test can serve a real download, and returns its path.
+func useScratchBinary(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := executable
+	executable = func() (string, error) { return path, nil }
+	t.Cleanup(func() { executable = old })
+	return path
+}
+
+func TestPerformUpdateAudited(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string // binary content afterwards
+		audited bool
+	}{
+		{"swapped", map[string]string{"/bin/claude": "new build"}, "new build", true},
+		{"download fails", nil, "old build", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := useScratchBinary(t, "old build")
+			logPath := filepath.Join(t.TempDir(), "events.jsonl")
+			old := audit
+			audit = logging.NewAuditLog(logPath, 0, 0)
+			t.Cleanup(func() { audit = old })
+			serveUpdates(t, tt.files)
+
+			err := performUpdate(context.Background(), defaultUpdateURL+"/bin/claude")
+			if (err == nil) != tt.audited {
+				t.Fatalf("performUpdate = %v", err)
+			}
+			if data, _ := os.ReadFile(bin); string(data) != tt.want {
+				t.Errorf("binary = %q, want %q", data, tt.want)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(bin)); len(entries) != 1 {
+				t.Errorf("%d files next to the binary, want no temp file or backup left", len(entries))
+			}
+
+			data, _ := os.ReadFile(logPath)
+			if !tt.audited {
+				if len(data) > 0 {
+					t.Errorf("failed download audited: %s", data)
+				}
+				return
+			}
+			var ev logging.AuditEvent
+			if err := json.Unmarshal(data, &ev); err != nil {
+				t.Fatalf("audit log %q: %v", data, err)
+			}
+			if ev.Action != logging.AuditSelfUpdate || ev.Path != bin || ev.ContentHash != logging.ContentHash([]byte(tt.want)) || ev.Outcome != logging.AuditOK {
+				t.Errorf("audit record = %+v", ev)
+			}
+		})
+	}
+}
+
+func TestCISkipsAutomaticUpdates(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		opts    CheckOptions
+		skipped bool
+	}{
+		{"automatic in CI", map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"}, CheckOptions{}, true},
+		{"automatic in Jenkins", map[string]string{"JENKINS_URL": "https://jenkins.example.com/"}, CheckOptions{}, true},
+		{"manual in CI", map[string]string{"CI": "true"}, CheckOptions{Manual: true}, false},
+		{"CI detection overridden", map[string]string{"CI": "true", config.CIEnv: "0"}, CheckOptions{}, false},
+		{"workstation", nil, CheckOptions{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, "1.2.0")
+			e := testEnv(t)
+			for k, v := range tt.vars {
+				e.Vars[k] = v
+			}
+			srv := serveUpdates(t, map[string]string{"/version.txt": "v1.3.0\n"})
+
+			opts := tt.opts
+			opts.Env, opts.Channel, opts.CheckOnly = e, ChannelStable, true
+			r := CheckWithOptions(context.Background(), opts)
+			if r.Skipped != tt.skipped {
+				t.Errorf("check = %+v, want skipped %v", r, tt.skipped)
+			}
+			if checked := len(srv.requested) > 0; checked == tt.skipped {
+				t.Errorf("requested %v, want a version check %v", srv.requested, !tt.skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package autoupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// hold records a version installed with `zeude update --to`, so automatic
+// checks don't replace it right away. A plain `zeude update` lifts it.
+type hold struct {
+	Version string `json:"version"`
+	// Latest is the channel's release when the hold was placed. Automatic
+	// checks resume once something newer ships.
+	Latest string `json:"latest,omitempty"`
+	// Pinned holds the version until `zeude update`, whatever ships.
+	Pinned bool      `json:"pinned,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// holds reports whether an automatic check that found remote should leave
+// the held version alone.
+func (h *hold) holds(remote string) bool {
+	return h.Pinned || h.Latest == "" || compareVersions(remote, h.Latest) <= 0
+}
+
+func holdPath(e env.Env) string {
+	return filepath.Join(zeudeDir(e), paths.UpdateHoldFile)
+}
+
+func loadHold(e env.Env) *hold {
+	data, err := os.ReadFile(holdPath(e))
+	if err != nil {
+		return nil
+	}
+	var h hold
+	if err := json.Unmarshal(data, &h); err != nil || h.Version == "" {
+		logger.Warn("ignoring unreadable update hold", "path", holdPath(e), "error", err)
+		return nil
+	}
+	return &h
+}
+
+func saveHold(e env.Env, h hold) {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err == nil {
+		err = os.WriteFile(holdPath(e), data, 0644)
+	}
+	if err != nil {
+		logger.Warn("failed to record update hold", "path", holdPath(e), "error", err)
+	}
+}
+
+func clearHold(e env.Env) {
+	if err := os.Remove(holdPath(e)); err == nil {
+		logger.Info("update hold lifted")
+	}
+}
+
+// HeldVersion returns the version `zeude update --to` is holding and
+// whether it is pinned, or "" when automatic updates run normally.
+func HeldVersion() (string, bool) {
+	h := loadHold(env.OS{})
+	if h == nil {
+		return "", false
+	}
+	return h.Version, h.Pinned
+}
+
+// versionBinaryURL is where a specific release's shim is published:
+// <update URL>/v<version>/claude-<os>-<arch>.
+func versionBinaryURL(version string) string {
+	return defaultUpdateURL + "/v" + strings.TrimPrefix(version, "v") + "/" + PlatformArtifact()
+}
+
+// installVersion handles CheckOptions.To: it installs that exact release
+// through performUpdate, which leaves the current binary in place unless
+// the download succeeds, and holds it against automatic updates.
+func installVersion(ctx context.Context, e env.Env, opts CheckOptions, result UpdateResult) UpdateResult {
+	target := "v" + strings.TrimPrefix(strings.TrimSpace(opts.To), "v")
+	result.NewVersion = target
+
+	// The index, when the server has one, rejects unknown versions before
+	// anything is downloaded
+	index, err := fetchIndex(ctx)
+	if err != nil && !errors.Is(err, errNoIndex) {
+		result.Error = err
+		return result
+	}
+	if index != nil {
+		var release *Release
+		for i := range index.Releases {
+			if SameVersion(index.Releases[i].Version, target) {
+				release = &index.Releases[i]
+				break
+			}
+		}
+		switch {
+		case release == nil:
+			result.Error = fmt.Errorf("version %s is not on the update server (see 'zeude releases')", target)
+			return result
+		case len(release.Artifacts) > 0 && !release.HasArtifact(PlatformArtifact()):
+			result.Error = fmt.Errorf("version %s has no %s build", target, PlatformArtifact())
+			return result
+		}
+	}
+
+	h := hold{Version: target, Pinned: opts.Pin, Since: e.Now().UTC()}
+	if !opts.Pin {
+		versionURL, _ := channelURLs(result.Channel)
+		if latest, err := fetchRemoteVersion(ctx, versionURL); err == nil {
+			h.Latest = latest
+		} else if index != nil {
+			h.Latest = index.Latest
+		}
+	}
+
+	if SameVersion(target, Version) && !opts.Force {
+		saveHold(e, h)
+		return result
+	}
+	result.Downgrade = compareVersions(Version, target) > 0
+	result.Reinstalled = SameVersion(target, Version)
+	if err := performUpdate(ctx, versionBinaryURL(target)); err != nil {
+		logger.Warn("install of a specific version failed", "from", Version, "to", target, "error", err)
+		result.Error = fmt.Errorf("could not install %s: %w", target, err)
+		return result
+	}
+	saveHold(e, h)
+	markUpdateSuccess(e)
+	result.Updated = true
+	logger.Info("installed specific version", "from", Version, "to", target, "pinned", opts.Pin)
+	return result
+}
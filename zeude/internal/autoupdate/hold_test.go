@@ -0,0 +1,215 @@
+package autoupdate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeude/zeude/internal/httpclient"
+)
+
+// fakeUpdateServer answers requests to the update URL from files, keyed by
+// path under it, and 404s everything else. Tests only serve a binary after
+// useScratchBinary, so none ever replaces the running one.
+type fakeUpdateServer struct {
+	mu        sync.Mutex
+	files     map[string]string
+	requested []string
+}
+
+func (s *fakeUpdateServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.String(), defaultUpdateURL)
+	s.mu.Lock()
+	s.requested = append(s.requested, path)
+	body, ok := s.files[path]
+	s.mu.Unlock()
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: req, Body: io.NopCloser(strings.NewReader(body))}
+	if !ok {
+		resp.StatusCode = http.StatusNotFound
+	}
+	return resp, nil
+}
+
+// downloaded reports whether a binary download was attempted.
+func (s *fakeUpdateServer) downloaded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.requested {
+		if strings.HasSuffix(p, "/"+PlatformArtifact()) {
+			return true
+		}
+	}
+	return false
+}
+
+func serveUpdates(t *testing.T, files map[string]string) *fakeUpdateServer {
+	t.Helper()
+	s := &fakeUpdateServer{files: files}
+	t.Cleanup(httpclient.SetTransport(s))
+	return s
+}
+
+func TestHoldHolds(t *testing.T) {
+	tests := []struct {
+		name   string
+		hold   hold
+		remote string
+		want   bool
+	}{
+		{"pinned", hold{Version: "v1.0.0", Pinned: true, Latest: "v1.2.0"}, "v2.0.0", true},
+		{"latest unknown", hold{Version: "v1.0.0"}, "v2.0.0", true},
+		{"nothing newer", hold{Version: "v1.0.0", Latest: "v1.2.0"}, "v1.2.0", true},
+		{"newer release", hold{Version: "v1.0.0", Latest: "v1.2.0"}, "v1.3.0", false},
+		{"compared numerically", hold{Version: "v1.0.0", Latest: "v1.9.0"}, "v1.10.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hold.holds(tt.remote); got != tt.want {
+				t.Errorf("holds(%s) = %v, want %v", tt.remote, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTo(t *testing.T) {
+	index := fmt.Sprintf(`{"latest": "v1.10.0", "releases": [
+		{"version": "v1.10.0", "artifacts": [%q]},
+		{"version": "v1.9.0", "artifacts": [%q]},
+		{"version": "v1.2.0", "artifacts": ["claude-plan9-mips"]},
+		{"version": "v1.1.0"}
+	]}`, PlatformArtifact(), PlatformArtifact())
+
+	tests := []struct {
+		name         string
+		installed    string
+		files        map[string]string
+		to           string
+		pin          bool
+		wantErr      string
+		wantDownload bool
+		wantDowngr   bool
+		wantHold     *hold // nil: no hold saved
+	}{
+		{
+			name:      "installed version is held",
+			installed: "1.9.0", to: "1.9.0",
+			files:    map[string]string{"/index.json": index, "/version.txt": "v1.10.0\n"},
+			wantHold: &hold{Version: "v1.9.0", Latest: "v1.10.0"},
+		},
+		{
+			name:      "pin ignores latest",
+			installed: "1.9.0", to: "v1.9.0", pin: true,
+			files:    map[string]string{"/index.json": index, "/version.txt": "v1.10.0\n"},
+			wantHold: &hold{Version: "v1.9.0", Pinned: true},
+		},
+		{
+			name:      "latest from the index without version.txt",
+			installed: "1.9.0", to: "1.9.0",
+			files:    map[string]string{"/index.json": index},
+			wantHold: &hold{Version: "v1.9.0", Latest: "v1.10.0"},
+		},
+		{
+			name:      "older version is a downgrade",
+			installed: "1.10.0", to: "1.9.0",
+			files:   map[string]string{"/index.json": index},
+			wantErr: "could not install v1.9.0", wantDownload: true, wantDowngr: true,
+		},
+		{
+			name:      "newer version is not a downgrade",
+			installed: "1.9.0", to: "1.10.0",
+			files:   map[string]string{"/index.json": index},
+			wantErr: "could not install v1.10.0", wantDownload: true,
+		},
+		{
+			name:      "unknown version",
+			installed: "1.9.0", to: "1.5.0",
+			files:   map[string]string{"/index.json": index},
+			wantErr: "not on the update server",
+		},
+		{
+			name:      "no build for this platform",
+			installed: "1.9.0", to: "1.2.0",
+			files:   map[string]string{"/index.json": index},
+			wantErr: "has no " + PlatformArtifact() + " build",
+		},
+		{
+			name:      "release without an artifact list",
+			installed: "1.9.0", to: "1.1.0",
+			files:   map[string]string{"/index.json": index},
+			wantErr: "could not install v1.1.0", wantDownload: true, wantDowngr: true,
+		},
+		{
+			name:      "server without an index",
+			installed: "1.9.0", to: "2.0.0",
+			files:   map[string]string{"/version.txt": "v1.10.0\n"},
+			wantErr: "could not install v2.0.0", wantDownload: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, tt.installed)
+			e := testEnv(t)
+			srv := serveUpdates(t, tt.files)
+
+			r := CheckWithOptions(context.Background(), CheckOptions{Env: e, Channel: ChannelStable, Manual: true, NoReexec: true, To: tt.to, Pin: tt.pin})
+			if tt.wantErr == "" && r.Error != nil || tt.wantErr != "" && (r.Error == nil || !strings.Contains(r.Error.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", r.Error, tt.wantErr)
+			}
+			if r.Downgrade != tt.wantDowngr {
+				t.Errorf("Downgrade = %v, want %v", r.Downgrade, tt.wantDowngr)
+			}
+			if got := srv.downloaded(); got != tt.wantDownload {
+				t.Errorf("downloaded = %v, want %v", got, tt.wantDownload)
+			}
+
+			h := loadHold(e)
+			switch {
+			case tt.wantHold == nil && h != nil:
+				t.Errorf("hold saved: %+v", h)
+			case tt.wantHold != nil && h == nil:
+				t.Errorf("no hold saved, want %+v", tt.wantHold)
+			case h != nil && (h.Version != tt.wantHold.Version || h.Latest != tt.wantHold.Latest || h.Pinned != tt.wantHold.Pinned):
+				t.Errorf("hold = %+v, want %+v", h, tt.wantHold)
+			}
+		})
+	}
+}
+
+// TestHeldVersionSkipsAutomaticChecks checks that a hold keeps automatic
+// checks from updating until a newer release ships, or for a pin, at all.
+func TestHeldVersionSkipsAutomaticChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		hold     hold
+		remote   string
+		manual   bool
+		wantHeld bool
+	}{
+		{"nothing newer", hold{Version: "v1.9.0", Latest: "v1.10.0"}, "v1.10.0", false, true},
+		{"newer release", hold{Version: "v1.9.0", Latest: "v1.10.0"}, "v1.11.0", false, false},
+		{"pinned", hold{Version: "v1.9.0", Pinned: true}, "v1.11.0", false, true},
+		{"manual update", hold{Version: "v1.9.0", Pinned: true}, "v1.11.0", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVersion(t, "1.9.0")
+			e := testEnv(t)
+			serveUpdates(t, map[string]string{"/version.txt": tt.remote + "\n"})
+			writeCurrentVersion(e)
+			saveHold(e, tt.hold)
+
+			r := CheckWithOptions(context.Background(), CheckOptions{Env: e, Channel: ChannelStable, Manual: tt.manual, CheckOnly: true})
+			if r.Held != tt.wantHeld {
+				t.Errorf("Held = %v, want %v (error %v)", r.Held, tt.wantHeld, r.Error)
+			}
+			if !tt.wantHeld && !r.NewVersionAvailable {
+				t.Errorf("NewVersionAvailable = false once the hold no longer applies")
+			}
+		})
+	}
+}
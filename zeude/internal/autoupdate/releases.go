@@ -0,0 +1,147 @@
+package autoupdate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/httpclient"
+)
+
+// maxIndexSize bounds the release index download.
+const maxIndexSize = 1 << 20
+
+// Release is one published shim version.
+type Release struct {
+	Version   string    `json:"version"`
+	Date      time.Time `json:"date,omitempty"`
+	Artifacts []string  `json:"artifacts,omitempty"` // e.g. claude-darwin-arm64
+}
+
+// HasArtifact reports whether the release ships name.
+func (r Release) HasArtifact(name string) bool {
+	for _, a := range r.Artifacts {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseIndex lists the versions the update server offers.
+type ReleaseIndex struct {
+	Latest   string    `json:"latest"`
+	Releases []Release `json:"releases"` // newest first
+	// VersionOnly is set when the server has no index.json and only the
+	// latest version (version.txt) is known.
+	VersionOnly bool `json:"-"`
+}
+
+// errNoIndex means the update server doesn't publish index.json.
+var errNoIndex = errors.New("no release index")
+
+// PlatformArtifact returns the shim artifact name for this OS and arch.
+func PlatformArtifact() string {
+	return fmt.Sprintf("claude-%s-%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// ListReleases fetches the release index from the update server. Servers
+// that only publish version.txt yield an index holding just the latest version.
+func ListReleases(ctx context.Context) (*ReleaseIndex, error) {
+	index, err := fetchIndex(ctx)
+	if err == nil {
+		return index, nil
+	}
+	if !errors.Is(err, errNoIndex) {
+		return nil, err
+	}
+
+	versionURL, _ := channelURLs(ChannelStable)
+	latest, err := fetchRemoteVersion(ctx, versionURL)
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseIndex{
+		Latest:      latest,
+		Releases:    []Release{{Version: latest}},
+		VersionOnly: true,
+	}, nil
+}
+
+// fetchIndex downloads and parses <update URL>/index.json.
+func fetchIndex(ctx context.Context) (*ReleaseIndex, error) {
+	req, err := httpclient.NewRequest(ctx, http.MethodGet, defaultUpdateURL+"/index.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpclient.New(5 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNoIndex
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexSize))
+	if err != nil {
+		return nil, err
+	}
+	var index ReleaseIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("invalid release index: %w", err)
+	}
+
+	sort.SliceStable(index.Releases, func(i, j int) bool {
+		return compareVersions(index.Releases[i].Version, index.Releases[j].Version) > 0
+	})
+	if index.Latest == "" && len(index.Releases) > 0 {
+		index.Latest = index.Releases[0].Version
+	}
+	return &index, nil
+}
+
+// compareVersions compares dotted versions numerically (1.10 > 1.9),
+// ignoring a leading v. Non-numeric parts compare as strings.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xn != yn {
+				if xn > yn {
+					return 1
+				}
+				return -1
+			}
+		case x != y:
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
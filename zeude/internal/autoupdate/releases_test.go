@@ -0,0 +1,115 @@
+package autoupdate
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.1", "1.2", 1},
+		{"1.2", "1.2.1", -1},
+		{"0.0.0", "0.0.1", -1},
+		{"1.2.x", "1.2.x", 0},
+		{"1.2.b", "1.2.a", 1},
+		{"1.2.a", "1.2.b", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSameVersion(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.3", "v1.2.3", true},
+		{"v1.2.3", "v1.2.3", true},
+		{"1.2.3", "1.2.4", false},
+	}
+	for _, tt := range tests {
+		if got := SameVersion(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameVersion(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestListReleases(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantLatest  string
+		wantOrder   []string
+		versionOnly bool
+		wantErr     string
+	}{
+		{
+			name:       "sorted newest first",
+			files:      map[string]string{"/index.json": `{"releases": [{"version": "v1.9.0"}, {"version": "v1.10.0"}, {"version": "v1.2.0"}]}`},
+			wantLatest: "v1.10.0",
+			wantOrder:  []string{"v1.10.0", "v1.9.0", "v1.2.0"},
+		},
+		{
+			name:       "latest from the index",
+			files:      map[string]string{"/index.json": `{"latest": "v1.9.0", "releases": [{"version": "v1.10.0"}, {"version": "v1.9.0"}]}`},
+			wantLatest: "v1.9.0",
+			wantOrder:  []string{"v1.10.0", "v1.9.0"},
+		},
+		{
+			name:        "version.txt only",
+			files:       map[string]string{"/version.txt": "v1.4.0\n"},
+			wantLatest:  "v1.4.0",
+			wantOrder:   []string{"v1.4.0"},
+			versionOnly: true,
+		},
+		{
+			name:    "invalid index",
+			files:   map[string]string{"/index.json": `{"releases": [`},
+			wantErr: "invalid release index",
+		},
+		{
+			name:    "nothing published",
+			files:   map[string]string{},
+			wantErr: "404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serveUpdates(t, tt.files)
+
+			index, err := ListReleases(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if index.Latest != tt.wantLatest || index.VersionOnly != tt.versionOnly {
+				t.Errorf("Latest = %q, VersionOnly = %v; want %q, %v", index.Latest, index.VersionOnly, tt.wantLatest, tt.versionOnly)
+			}
+			var order []string
+			for _, r := range index.Releases {
+				order = append(order, r.Version)
+			}
+			if strings.Join(order, " ") != strings.Join(tt.wantOrder, " ") {
+				t.Errorf("releases = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
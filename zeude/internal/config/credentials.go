@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AgentKeyEnv overrides the agent key stored in ~/.zeude/credentials.
+	AgentKeyEnv = "ZEUDE_AGENT_KEY"
+	// AgentKeyPrefix is the prefix every dashboard-issued agent key carries.
+	AgentKeyPrefix = "zd_"
+	// agentKeyMinLen and agentKeyMaxLen bound a plausible key length.
+	// Current keys are zd_ followed by 64 hex characters.
+	agentKeyMinLen = len(AgentKeyPrefix) + 32
+	agentKeyMaxLen = len(AgentKeyPrefix) + 128
+)
+
+// utf8BOM is the byte order mark some editors prepend to text files.
+const utf8BOM = "\uFEFF"
+
+// AgentKeyName is the credentials key holding the agent key.
+const AgentKeyName = "agent_key"
+
+// Entry is one key=value line from a credentials-style file.
+type Entry struct {
+	Section string // "" before any [section] header
+	Key     string // lower-cased
+	Value   string // unquoted, comments stripped
+	Line    int    // 1-based line number
+}
+
+// ParseKeyValues parses INI-like key=value content as users actually write it:
+// a leading UTF-8 BOM, CRLF or CR line endings, full-line (# or ;) and trailing
+// comments, single/double/typographic quotes, an optional "export " prefix,
+// and [section] headers. Entries are returned in file order. Notes describe
+// repaired or skipped lines and never include values, so they are safe to print.
+// [FIX #6] Handles both LF and CRLF line endings.
+func ParseKeyValues(data []byte) ([]Entry, []string) {
+	var entries []Entry
+	var notes []string
+
+	content := string(data)
+	if strings.HasPrefix(content, utf8BOM) {
+		content = strings.TrimPrefix(content, utf8BOM)
+		notes = append(notes, "UTF-8 BOM ignored")
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+
+	section := ""
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			notes = append(notes, fmt.Sprintf("line %d: not a key=value line, ignored", lineNo))
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:eq]))
+		if key == "" {
+			notes = append(notes, fmt.Sprintf("line %d: empty key, ignored", lineNo))
+			continue
+		}
+
+		value, note := parseValue(strings.TrimSpace(line[eq+1:]))
+		if note != "" {
+			notes = append(notes, fmt.Sprintf("line %d: %s", lineNo, note))
+		}
+		entries = append(entries, Entry{Section: section, Key: key, Value: value, Line: lineNo})
+	}
+
+	return entries, notes
+}
+
+// parseValue unquotes a raw value and strips a trailing comment.
+// The note, if any, explains what was repaired.
+func parseValue(raw string) (string, string) {
+	for _, q := range [][2]string{{`"`, `"`}, {`'`, `'`}, {"“", "”"}, {"‘", "’"}} {
+		if !strings.HasPrefix(raw, q[0]) {
+			continue
+		}
+		rest := raw[len(q[0]):]
+		end := strings.Index(rest, q[1])
+		if end < 0 {
+			return strings.TrimSpace(rest), "unterminated quote"
+		}
+		value, tail := rest[:end], strings.TrimSpace(rest[end+len(q[1]):])
+		if tail != "" && !strings.HasPrefix(tail, "#") && !strings.HasPrefix(tail, ";") {
+			return value, "text after closing quote ignored"
+		}
+		return value, "quotes removed"
+	}
+
+	// Unquoted: a comment starts at # or ; preceded by whitespace
+	for i := 1; i < len(raw); i++ {
+		if (raw[i] == '#' || raw[i] == ';') && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return strings.TrimSpace(raw[:i]), ""
+		}
+	}
+	return raw, ""
+}
+
+// Lookup returns the last entry for key in section (last occurrence wins).
+func Lookup(entries []Entry, section, key string) (Entry, bool) {
+	var found Entry
+	ok := false
+	for _, e := range entries {
+		if e.Section == section && e.Key == key {
+			found, ok = e, true
+		}
+	}
+	return found, ok
+}
+
+// ParseAgentKey extracts the agent_key value from credentials file content.
+// Format: agent_key=zd_xxx (see ParseKeyValues for what else is tolerated).
+// When the key appears more than once, the last occurrence wins.
+func ParseAgentKey(data []byte) string {
+	key, _ := ParseAgentKeyWithNotes(data)
+	return key
+}
+
+// ParseAgentKeyWithNotes is ParseAgentKey that also returns printable notes
+// about repaired lines and duplicate keys. Keys outside any section or in
+// [default] are considered, so profile sections can be added later.
+func ParseAgentKeyWithNotes(data []byte) (string, []string) {
+	entries, notes := ParseKeyValues(data)
+
+	var matches []Entry
+	for _, e := range entries {
+		if e.Key == AgentKeyName && (e.Section == "" || e.Section == "default") {
+			matches = append(matches, e)
+		}
+	}
+	if len(matches) == 0 {
+		return "", notes
+	}
+
+	last := matches[len(matches)-1]
+	if len(matches) > 1 {
+		notes = append(notes, fmt.Sprintf("%d %s entries; using the last one (line %d)", len(matches), AgentKeyName, last.Line))
+	}
+	return last.Value, notes
+}
+
+// HasBOM reports whether data starts with a UTF-8 byte order mark.
+func HasBOM(data []byte) bool {
+	return strings.HasPrefix(string(data), utf8BOM)
+}
+
+// CleanPastedKey strips what pasting a key tends to bring along: surrounding
+// whitespace and newlines, a BOM, and quotes.
+func CleanPastedKey(key string) string {
+	key = strings.TrimPrefix(strings.TrimSpace(key), utf8BOM)
+	return strings.Trim(key, `"'`)
+}
+
+// ValidateAgentKey checks that a key looks like a dashboard-issued agent key.
+// Returns a list of human-readable problems; the key itself is never included
+// so the result is safe to print.
+func ValidateAgentKey(key string) []string {
+	var problems []string
+
+	if strings.HasPrefix(key, utf8BOM) {
+		problems = append(problems, "key starts with a UTF-8 BOM")
+		key = strings.TrimPrefix(key, utf8BOM)
+	}
+
+	if inner, ok := unquoteKey(key); ok {
+		problems = append(problems, "key appears quoted")
+		key = inner
+	} else if strings.ContainsAny(key, "\"'‘’“”") {
+		problems = append(problems, "key contains quote characters")
+	}
+
+	if strings.ContainsAny(key, " \t") {
+		problems = append(problems, "key contains whitespace")
+	}
+
+	if !strings.HasPrefix(key, AgentKeyPrefix) {
+		problems = append(problems, fmt.Sprintf("key does not start with %q", AgentKeyPrefix))
+	}
+
+	if len(key) < agentKeyMinLen || len(key) > agentKeyMaxLen {
+		problems = append(problems, fmt.Sprintf("key length %d outside expected range %d-%d", len(key), agentKeyMinLen, agentKeyMaxLen))
+	}
+
+	return problems
+}
+
+// unquoteKey strips matching ASCII or typographic quotes wrapping s and
+// reports whether there were any.
+func unquoteKey(s string) (string, bool) {
+	pairs := [][2]string{{`"`, `"`}, {`'`, `'`}, {"“", "”"}, {"‘", "’"}}
+	for _, p := range pairs {
+		if len(s) >= len(p[0])+len(p[1]) && strings.HasPrefix(s, p[0]) && strings.HasSuffix(s, p[1]) {
+			return s[len(p[0]) : len(s)-len(p[1])], true
+		}
+	}
+	return s, false
+}
+
+// MaskAgentKey returns key with all but its prefix and last four characters
+// hidden, for display.
+func MaskAgentKey(key string) string {
+	if len(key) <= len(AgentKeyPrefix)+8 {
+		return strings.Repeat("*", len(key))
+	}
+	rest := key
+	prefix := ""
+	if strings.HasPrefix(key, AgentKeyPrefix) {
+		prefix, rest = AgentKeyPrefix, key[len(AgentKeyPrefix):]
+	}
+	return prefix + "…" + rest[len(rest)-4:]
+}
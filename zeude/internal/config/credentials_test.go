@@ -0,0 +1,131 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKey = "zd_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseAgentKeyWithNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		want  string
+		notes []string
+	}{
+		{"plain", "agent_key=" + testKey + "\n", testKey, nil},
+		{"no newline", "agent_key=" + testKey, testKey, nil},
+		{"spaces around =", "  agent_key = " + testKey + "  \n", testKey, nil},
+		{"upper-case key", "AGENT_KEY=" + testKey, testKey, nil},
+		{"export prefix", "export agent_key=" + testKey, testKey, nil},
+		{"CRLF", "# zeude\r\nagent_key=" + testKey + "\r\n", testKey, nil},
+		{"old Mac line endings", "# zeude\ragent_key=" + testKey + "\r", testKey, nil},
+		{"BOM", "\uFEFFagent_key=" + testKey, testKey, []string{"UTF-8 BOM ignored"}},
+		{"comment lines", "# hash\n; semicolon\n\nagent_key=" + testKey, testKey, nil},
+		{"trailing # comment", "agent_key=" + testKey + " # work laptop", testKey, nil},
+		{"trailing ; comment", "agent_key=" + testKey + "\t; work laptop", testKey, nil},
+		{"# inside the value", "agent_key=zd_abc#def", "zd_abc#def", nil},
+		{"double quotes", `agent_key="` + testKey + `"`, testKey, []string{"line 1: quotes removed"}},
+		{"single quotes", `agent_key='` + testKey + `'`, testKey, []string{"line 1: quotes removed"}},
+		{"typographic quotes", "agent_key=“" + testKey + "”", testKey, []string{"line 1: quotes removed"}},
+		{"typographic single quotes", "agent_key=‘" + testKey + "’", testKey, []string{"line 1: quotes removed"}},
+		{"quotes and comment", `agent_key="` + testKey + `" # work`, testKey, []string{"line 1: quotes removed"}},
+		{"unterminated quote", `agent_key="` + testKey, testKey, []string{"line 1: unterminated quote"}},
+		{"text after quote", `agent_key="` + testKey + `"junk`, testKey, []string{"line 1: text after closing quote ignored"}},
+		{"last one wins", "agent_key=zd_old\nagent_key=" + testKey, testKey, []string{"2 agent_key entries; using the last one (line 2)"}},
+		{"default section", "[default]\nagent_key=" + testKey, testKey, nil},
+		{"other section ignored", "agent_key=" + testKey + "\n[staging]\nagent_key=zd_staging", testKey, nil},
+		{"only another section", "[Staging]\nagent_key=zd_staging", "", nil},
+		{"not key=value", "zd_pasted_alone\nagent_key=" + testKey, testKey, []string{"line 1: not a key=value line, ignored"}},
+		{"empty key", "=zd_x\nagent_key=" + testKey, testKey, []string{"line 1: empty key, ignored"}},
+		{"empty value", "agent_key=", "", nil},
+		{"missing", "other=1", "", nil},
+		{"empty file", "", "", nil},
+		{"binary junk", "\x00\x01\xff", "", []string{"line 1: not a key=value line, ignored"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes := ParseAgentKeyWithNotes([]byte(tt.file))
+			if got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+			if strings.Join(notes, "|") != strings.Join(tt.notes, "|") {
+				t.Errorf("notes = %q, want %q", notes, tt.notes)
+			}
+		})
+	}
+}
+
+func TestValidateAgentKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		problems []string
+	}{
+		{testKey, nil},
+		{"zd_" + strings.Repeat("a", 32), nil},
+		{"zd_" + strings.Repeat("a", 31), []string{"key length 34 outside expected range 35-131"}},
+		{"zd_" + strings.Repeat("a", 129), []string{"key length 132 outside expected range 35-131"}},
+		{"ak_" + testKey[3:], []string{`key does not start with "zd_"`}},
+		{`"` + testKey + `"`, []string{"key appears quoted"}},
+		{"“" + testKey + "”", []string{"key appears quoted"}},
+		{testKey[:40] + "'" + testKey[40:], []string{"key contains quote characters"}},
+		{testKey[:40] + " " + testKey[40:], []string{"key contains whitespace"}},
+		{"\uFEFF" + testKey, []string{"key starts with a UTF-8 BOM"}},
+		{"", []string{`key does not start with "zd_"`, "key length 0 outside expected range 35-131"}},
+	}
+	for _, tt := range tests {
+		got := ValidateAgentKey(tt.key)
+		if strings.Join(got, "|") != strings.Join(tt.problems, "|") {
+			t.Errorf("ValidateAgentKey(%q) = %q, want %q", tt.key, got, tt.problems)
+		}
+	}
+}
+
+func TestCleanPastedKey(t *testing.T) {
+	for _, in := range []string{testKey, " " + testKey + "\n", "\uFEFF" + testKey, `"` + testKey + `"`, "'" + testKey + "'\r\n"} {
+		if got := CleanPastedKey(in); got != testKey {
+			t.Errorf("CleanPastedKey(%q) = %q", in, got)
+		}
+	}
+}
+
+func TestMaskAgentKey(t *testing.T) {
+	tests := []struct{ key, want string }{
+		{testKey, "zd_…cdef"},
+		{"ak_0123456789abcdef", "…cdef"},
+		{"zd_short", "********"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MaskAgentKey(tt.key); got != tt.want {
+			t.Errorf("MaskAgentKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// FuzzParseKeyValues checks the parser never panics, and that its notes,
+// which are printed, never carry a value.
+func FuzzParseKeyValues(f *testing.F) {
+	for _, seed := range []string{
+		"agent_key=" + testKey,
+		"\uFEFF[default]\r\nexport agent_key = \"zd_x\" # c\r\n",
+		"agent_key='zd_x' tail\n=\n[\n]\nx",
+		"agent_key=“zd_x",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data string) {
+		entries, notes := ParseKeyValues([]byte(data))
+		for _, e := range entries {
+			if len(e.Value) < 8 {
+				continue // short values match note text by chance
+			}
+			for _, n := range notes {
+				if strings.Contains(n, e.Value) {
+					t.Errorf("note %q contains value %q", n, e.Value)
+				}
+			}
+		}
+	})
+}
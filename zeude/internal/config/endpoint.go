@@ -4,8 +4,10 @@ package config
 import (
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
 )
 
 const (
@@ -21,28 +23,61 @@ const (
 
 // GetCollectorEndpoint returns the OTel collector endpoint.
 // Priority: ZEUDE_ENDPOINT env > config file > defaultValue
+// When a failover list is configured this is the primary (first) entry;
+// see GetCollectorEndpoints.
 func GetCollectorEndpoint(defaultValue string) string {
+	if eps := GetCollectorEndpoints(); len(eps) > 0 {
+		return eps[0]
+	}
+	return defaultValue
+}
+
+// GetCollectorEndpoints returns the configured collector endpoints in
+// failover order. Both ZEUDE_ENDPOINT and endpoint= accept a comma-separated
+// list; the first is the primary. Returns nil when nothing is configured.
+func GetCollectorEndpoints() []string {
 	// Check environment variable first
-	if ep := os.Getenv("ZEUDE_ENDPOINT"); ep != "" {
-		return ep
+	raw := os.Getenv("ZEUDE_ENDPOINT")
+	if raw == "" {
+		raw = Get("endpoint")
 	}
+	return SplitEndpoints(raw)
+}
 
-	// Try to read from config file
-	home, err := os.UserHomeDir()
-	if err == nil {
-		configPath := filepath.Join(home, ".zeude", "config")
-		if data, err := os.ReadFile(configPath); err == nil {
-			lines := strings.Split(string(data), "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "endpoint=") {
-					value := strings.TrimPrefix(line, "endpoint=")
-					return strings.TrimSpace(value) // Properly trim whitespace
-				}
-			}
+// SplitEndpoints splits a comma-separated endpoint list, dropping blanks
+// and duplicates while keeping order.
+func SplitEndpoints(raw string) []string {
+	var eps []string
+	seen := make(map[string]bool)
+	for _, ep := range strings.Split(raw, ",") {
+		ep = strings.TrimSpace(ep)
+		if ep == "" || seen[ep] {
+			continue
 		}
+		seen[ep] = true
+		eps = append(eps, ep)
 	}
+	return eps
+}
 
-	return defaultValue
+// Get returns the value of key from the Zeude config file (~/.zeude/config), or "" if unset.
+func Get(key string) string {
+	configPath, err := paths.Config(env.OS{})
+	if err != nil {
+		return ""
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return ""
+	}
+
+	// Same parser as credentials: comments, quotes, and CRLF are tolerated
+	entries, _ := ParseKeyValues(data)
+	if entry, ok := Lookup(entries, "", strings.ToLower(key)); ok {
+		return entry.Value
+	}
+	return ""
 }
 
 // ParseEndpoint extracts host and port from an endpoint URL.
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// File is ~/.zeude/config held as its original lines, so editing one key
+// keeps comments, blank lines, ordering and keys Zeude doesn't know about.
+// Only top-level keys (outside any [section]) are read and written, matching Get.
+type File struct {
+	path  string
+	lines []string
+}
+
+// LoadFile reads the config file. A missing file loads as empty.
+func LoadFile(e env.Env) (*File, error) {
+	path, err := paths.Config(e)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize the same way ParseKeyValues does so entry line numbers
+	// index f.lines
+	content := strings.TrimPrefix(string(data), utf8BOM)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content != "" {
+		f.lines = strings.Split(content, "\n")
+	}
+	return f, nil
+}
+
+// Path returns the file's location.
+func (f *File) Path() string { return f.path }
+
+// entries parses the current lines.
+func (f *File) entries() []Entry {
+	entries, _ := ParseKeyValues([]byte(strings.Join(f.lines, "\n")))
+	return entries
+}
+
+// Get returns the value of key, last occurrence winning.
+func (f *File) Get(key string) (string, bool) {
+	entry, ok := Lookup(f.entries(), "", strings.ToLower(key))
+	return entry.Value, ok
+}
+
+// List returns the effective top-level settings, sorted by key.
+func (f *File) List() []Entry {
+	byKey := make(map[string]Entry)
+	for _, entry := range f.entries() {
+		if entry.Section == "" {
+			byKey[entry.Key] = entry
+		}
+	}
+	list := make([]Entry, 0, len(byKey))
+	for _, entry := range byKey {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list
+}
+
+// Set replaces the effective line for key in place, or appends one before
+// the first [section] so it stays top-level. Earlier duplicates are dropped
+// so the file has a single, obvious value.
+func (f *File) Set(key, value string) {
+	key = strings.ToLower(key)
+	line := key + "=" + formatValue(value)
+
+	var matches []int
+	firstSection := -1
+	for _, entry := range f.entries() {
+		if entry.Section == "" && entry.Key == key {
+			matches = append(matches, entry.Line-1)
+		}
+	}
+	for i, l := range f.lines {
+		t := strings.TrimSpace(l)
+		if strings.HasPrefix(t, "[") && strings.HasSuffix(t, "]") {
+			firstSection = i
+			break
+		}
+	}
+
+	if len(matches) == 0 {
+		if firstSection < 0 {
+			f.lines = append(f.lines, line)
+			return
+		}
+		f.lines = append(f.lines[:firstSection], append([]string{line}, f.lines[firstSection:]...)...)
+		return
+	}
+	f.lines[matches[len(matches)-1]] = line
+	f.removeLines(matches[:len(matches)-1])
+}
+
+// formatValue quotes value when ParseKeyValues would otherwise read part of
+// it as a comment or strip quotes or surrounding space from it.
+func formatValue(value string) string {
+	needsQuotes := value != strings.TrimSpace(value) ||
+		strings.ContainsAny(value, "\"'‘’“”") ||
+		strings.Contains(value, " #") || strings.Contains(value, "\t#") ||
+		strings.Contains(value, " ;") || strings.Contains(value, "\t;")
+	if !needsQuotes {
+		return value
+	}
+	if !strings.Contains(value, `"`) {
+		return `"` + value + `"`
+	}
+	return "'" + value + "'"
+}
+
+// Unset removes every top-level line for key and reports whether any existed.
+func (f *File) Unset(key string) bool {
+	key = strings.ToLower(key)
+	var matches []int
+	for _, entry := range f.entries() {
+		if entry.Section == "" && entry.Key == key {
+			matches = append(matches, entry.Line-1)
+		}
+	}
+	f.removeLines(matches)
+	return len(matches) > 0
+}
+
+// removeLines deletes the lines at the given ascending indexes.
+func (f *File) removeLines(indexes []int) {
+	for i := len(indexes) - 1; i >= 0; i-- {
+		n := indexes[i]
+		f.lines = append(f.lines[:n], f.lines[n+1:]...)
+	}
+}
+
+// Save writes the file atomically, keeping its permissions if it exists.
+func (f *File) Save() error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(f.path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	data := strings.Join(f.lines, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	return fsutil.WriteFileAtomic(f.path, []byte(data), perm)
+}
+
+// knownKeys maps each config key Zeude reads to a validator. Keys are
+// declared by the packages that read them; they are repeated here because
+// those packages import config.
+var knownKeys = map[string]func(string) error{
+	"endpoint":               validateEndpoints,
+	"dashboard_url":          validateURL,
+	"ca_bundle":              validateFile,
+	paths.XDGConfigKey:       validateBool,
+	DisableTelemetryKey:      validateBool,
+	TelemetryKey:             validateOnOff,
+	OfflineKey:               validateBool,
+	QuietKey:                 validateBool,
+	"self_telemetry":         validateBool,
+	"error_reporting":        validateBool,
+	"require_signed_content": validateBool,
+	"heartbeat_interval":     validateDuration,
+	"init_budget":            validateDuration,
+	"sync_timeout":           validatePositiveDuration,
+	"cache_ttl":              validatePositiveDuration,
+	"otlp_headers":           validateHeaders,
+	"channel":                validateChannel,
+	"provider":               validateProvider,
+	"aws_region":             validateSet,
+	"aws_profile":            validateSet,
+	"vertex_project":         validateSet,
+	"vertex_region":          validateSet,
+	"model":                  validateSet,
+	"small_fast_model":       validateSet,
+}
+
+// KnownKey reports whether Zeude reads key.
+func KnownKey(key string) bool {
+	_, ok := knownKeys[strings.ToLower(key)]
+	return ok
+}
+
+// ValidateValue checks value for a known key. Unknown keys are accepted as-is.
+func ValidateValue(key, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("value must be a single line")
+	}
+	validate, ok := knownKeys[strings.ToLower(key)]
+	if !ok {
+		return nil
+	}
+	return validate(value)
+}
+
+func validateEndpoints(value string) error {
+	eps := SplitEndpoints(value)
+	if len(eps) == 0 {
+		return fmt.Errorf("endpoint is empty")
+	}
+	for _, ep := range eps {
+		if strings.Contains(ep, "://") && !strings.HasPrefix(ep, "http://") && !strings.HasPrefix(ep, "https://") {
+			return fmt.Errorf("%s: scheme must be http or https", ep)
+		}
+		host, _, _, err := ParseEndpoint(ep)
+		if err != nil {
+			return fmt.Errorf("%s: %v", ep, err)
+		}
+		if host == "" {
+			return fmt.Errorf("%s: missing host", ep)
+		}
+	}
+	return nil
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%s: URL must start with http:// or https://", value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%s: missing host", value)
+	}
+	return nil
+}
+
+func validateFile(value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", value)
+	}
+	return nil
+}
+
+func validateBool(value string) error {
+	if value != "true" && value != "false" {
+		return fmt.Errorf("must be true or false")
+	}
+	return nil
+}
+
+func validateOnOff(value string) error {
+	if value != "on" && value != "off" {
+		return fmt.Errorf("must be on or off")
+	}
+	return nil
+}
+
+func validateProvider(value string) error {
+	switch value {
+	case "anthropic", "bedrock", "vertex":
+		return nil
+	}
+	return fmt.Errorf("must be anthropic, bedrock or vertex")
+}
+
+func validateSet(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	return nil
+}
+
+func validateChannel(value string) error {
+	if value != "stable" && value != "beta" {
+		return fmt.Errorf("must be stable or beta")
+	}
+	return nil
+}
+
+// validateHeaders checks the OTEL_EXPORTER_OTLP_HEADERS format:
+// comma-separated key=value pairs with percent-encoded values.
+func validateHeaders(value string) error {
+	for _, member := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(member, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("%q: want key=value pairs separated by commas", member)
+		}
+		if _, err := url.PathUnescape(strings.TrimSpace(val)); err != nil {
+			return fmt.Errorf("%q: bad percent-encoding", member)
+		}
+	}
+	return nil
+}
+
+func validatePositiveDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be a duration like 30s or 1h")
+	}
+	if d <= 0 {
+		return fmt.Errorf("must be more than 0")
+	}
+	return nil
+}
+
+func validateDuration(value string) error {
+	if value == "0" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be a duration like 6h or 30m, or 0 to disable")
+	}
+	if d < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	return nil
+}
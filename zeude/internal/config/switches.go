@@ -0,0 +1,102 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Environment switches that turn off network traffic Zeude can live without.
+const (
+	// OfflineEnv disables every dashboard and collector request: the shim
+	// applies the cached config and queues status reports. Also settable as
+	// offline=true in the config.
+	OfflineEnv = "ZEUDE_OFFLINE"
+	// DisableTelemetryEnv opts out of telemetry: the shim gives claude no
+	// OTel settings, and Zeude's own reporting (heartbeats, self-telemetry)
+	// stops. Sync is unaffected. Also settable as telemetry=off (or the
+	// older disable_telemetry=true) in the config.
+	DisableTelemetryEnv = "ZEUDE_DISABLE_TELEMETRY"
+	// NonEssentialTrafficEnv is Claude Code's own switch; Zeude honours it
+	// for anything that isn't needed to sync configuration.
+	NonEssentialTrafficEnv = "CLAUDE_CODE_DISABLE_NONESSENTIAL_TRAFFIC"
+	// SkipEnv makes the shim exec claude straight away, with no update
+	// check, sync, telemetry or banner: the escape hatch when Zeude itself
+	// is the problem.
+	SkipEnv = "ZEUDE_SKIP"
+	// QuietEnv hides the shim's banner, status line and warnings; errors
+	// that stop Zeude working are still printed. Also settable as
+	// quiet=true in the config.
+	QuietEnv = "ZEUDE_QUIET"
+	// CIEnv overrides CI detection: truthy treats the run as CI, any other
+	// non-empty value as a workstation.
+	CIEnv = "ZEUDE_CI"
+
+	// TelemetryKey set to off is the config file equivalent of
+	// DisableTelemetryEnv.
+	TelemetryKey = "telemetry"
+	// OfflineKey is the config file equivalent of OfflineEnv.
+	OfflineKey = "offline"
+	// QuietKey is the config file equivalent of QuietEnv.
+	QuietKey = "quiet"
+	// DisableTelemetryKey=true means the same as telemetry=off.
+	DisableTelemetryKey = "disable_telemetry"
+)
+
+// IsTruthy reports whether an env or config value means "on".
+func IsTruthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// Offline reports whether ZEUDE_OFFLINE or offline=true is set.
+func Offline(e env.Env) bool {
+	return IsTruthy(e.Getenv(OfflineEnv)) || IsTruthy(Get(OfflineKey))
+}
+
+// Quiet reports whether ZEUDE_QUIET or quiet=true is set.
+func Quiet(e env.Env) bool {
+	return IsTruthy(e.Getenv(QuietEnv)) || IsTruthy(Get(QuietKey))
+}
+
+// ciEnvs name CI systems by their own variables, for jobs that unset CI
+// and for systems that never set it (Jenkins, Azure Pipelines, TeamCity).
+var ciEnvs = []string{"GITHUB_ACTIONS", "GITLAB_CI", "BUILDKITE", "CIRCLECI", "JENKINS_URL", "TF_BUILD", "TEAMCITY_VERSION"}
+
+// CI reports whether this is a CI job, where nothing should prompt, draw
+// progress or update itself. ZEUDE_CI overrides the detection.
+func CI(e env.Env) bool {
+	if v := e.Getenv(CIEnv); v != "" {
+		return IsTruthy(v)
+	}
+	if IsTruthy(e.Getenv("CI")) {
+		return true
+	}
+	for _, name := range ciEnvs {
+		if e.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Skip reports whether ZEUDE_SKIP is set.
+func Skip(e env.Env) bool {
+	return IsTruthy(e.Getenv(SkipEnv))
+}
+
+// TelemetryDisabled reports whether the user opted out of telemetry via
+// the environment or ~/.zeude/config.
+func TelemetryDisabled(e env.Env) bool {
+	return IsTruthy(e.Getenv(DisableTelemetryEnv)) || IsTruthy(Get(DisableTelemetryKey)) ||
+		strings.EqualFold(strings.TrimSpace(Get(TelemetryKey)), "off")
+}
+
+// NonEssentialTrafficDisabled reports whether Claude's non-essential
+// traffic switch is set.
+func NonEssentialTrafficDisabled(e env.Env) bool {
+	return IsTruthy(e.Getenv(NonEssentialTrafficEnv))
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+func TestIsTruthy(t *testing.T) {
+	for v, want := range map[string]bool{
+		"1": true, "true": true, "TRUE": true, " yes ": true, "on": true,
+		"": false, "0": false, "false": false, "no": false, "off": false, "2": false, "y": false,
+	} {
+		if got := IsTruthy(v); got != want {
+			t.Errorf("IsTruthy(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestCI(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want bool
+	}{
+		{"workstation", nil, false},
+		{"CI=true", map[string]string{"CI": "true"}, true},
+		{"CI=1", map[string]string{"CI": "1"}, true},
+		{"CI=false", map[string]string{"CI": "false"}, false},
+		{"CI empty", map[string]string{"CI": ""}, false},
+		{"GitHub Actions", map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"}, true},
+		{"GitLab", map[string]string{"CI": "true", "GITLAB_CI": "true"}, true},
+		{"Jenkins", map[string]string{"JENKINS_URL": "https://jenkins.example.com/"}, true},
+		{"Azure Pipelines", map[string]string{"TF_BUILD": "True"}, true},
+		{"TeamCity", map[string]string{"TEAMCITY_VERSION": "2024.03"}, true},
+		{"Buildkite", map[string]string{"BUILDKITE": "true"}, true},
+		{"CircleCI", map[string]string{"CIRCLECI": "true"}, true},
+		{"CI=false but on GitHub", map[string]string{"CI": "false", "GITHUB_ACTIONS": "true"}, true},
+		{"forced on", map[string]string{CIEnv: "1"}, true},
+		{"forced off in GitHub Actions", map[string]string{CIEnv: "0", "CI": "true", "GITHUB_ACTIONS": "true"}, false},
+		{"forced off in Jenkins", map[string]string{CIEnv: "no", "JENKINS_URL": "https://jenkins.example.com/"}, false},
+		{"override any non-truthy value", map[string]string{CIEnv: "workstation", "CI": "true"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CI(&env.Fake{Vars: tt.vars}); got != tt.want {
+				t.Errorf("CI = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
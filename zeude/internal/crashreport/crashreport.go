@@ -0,0 +1,136 @@
+// Package crashreport captures panics and error-level log events into a
+// bounded local queue (~/.zeude/errors.jsonl) and uploads them to the
+// dashboard. It is strictly opt-in: nothing is recorded unless Install is
+// called, which the shim only does when error reporting is enabled.
+package crashreport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/logging"
+)
+
+// ConfigKey is the ~/.zeude/config key that opts into error reporting
+// (error_reporting=true).
+const ConfigKey = "error_reporting"
+
+// Report kinds.
+const (
+	KindPanic = "panic"
+	KindError = "error"
+)
+
+// Report is one deduplicated entry in the queue. It carries only a redacted
+// message and stack: never file contents, prompts, or environment values.
+type Report struct {
+	Fingerprint string    `json:"fingerprint"`
+	Kind        string    `json:"kind"`
+	Component   string    `json:"component,omitempty"`
+	Message     string    `json:"message"`
+	Stack       string    `json:"stack,omitempty"`
+	Version     string    `json:"version"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	Count       int       `json:"count"`
+	FirstSeen   time.Time `json:"firstSeen"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+var (
+	installed atomic.Bool
+	logger    = logging.Default().Component("crashreport")
+)
+
+// Enabled reports whether error reporting is on: the user opted in locally
+// and the dashboard policy allows it. Neither alone is enough.
+func Enabled(policy bool) bool {
+	return policy && config.Get(ConfigKey) == "true"
+}
+
+// Install starts capturing: error-level records from the default logger are
+// queued, and CapturePanic / CaptureAndRepanic become active.
+func Install() {
+	installed.Store(true)
+	logging.Default().SetErrorHook(captureLogError)
+}
+
+// CapturePanic queues a recovered panic value with its stack.
+func CapturePanic(v interface{}, stack []byte) {
+	if !installed.Load() {
+		return
+	}
+	record(KindPanic, "", fmt.Sprint(v), normalizeStack(string(stack)))
+}
+
+// CaptureAndRepanic is meant to be deferred directly at the top of a
+// goroutine: it records a panic, then re-panics so behaviour is unchanged.
+func CaptureAndRepanic() {
+	if r := recover(); r != nil {
+		CapturePanic(r, debug.Stack())
+		panic(r)
+	}
+}
+
+// captureLogError is the logging hook. Only the component field is kept;
+// other field values may contain paths or user data.
+func captureLogError(msg string, fields []interface{}) {
+	component := ""
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fmt.Sprint(fields[i]) == "component" {
+			component = fmt.Sprint(fields[i+1])
+		}
+	}
+	record(KindError, component, msg, "")
+}
+
+func record(kind, component, message, stack string) {
+	message = truncate(Redact(message), maxMessageLen)
+	stack = truncate(Redact(stack), maxStackLen)
+
+	now := time.Now().UTC()
+	r := Report{
+		Fingerprint: fingerprint(kind, component, message, stack),
+		Kind:        kind,
+		Component:   component,
+		Message:     message,
+		Stack:       stack,
+		Version:     autoupdate.GetVersion(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Count:       1,
+		FirstSeen:   now,
+		LastSeen:    now,
+	}
+	if err := enqueue(r); err != nil {
+		logger.Debug("failed to queue error report", "error", err)
+	}
+}
+
+// fingerprint identifies "the same problem" for dedup: the stack when there is
+// one, otherwise the message with digits folded so counters don't split it.
+func fingerprint(kind, component, message, stack string) string {
+	basis := stack
+	if basis == "" {
+		basis = foldDigits(message)
+	}
+	sum := sha256.Sum256([]byte(kind + "\x00" + component + "\x00" + basis))
+	return hex.EncodeToString(sum[:8])
+}
+
+func foldDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '#'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,178 @@
+package crashreport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTestHome points the queue and config at a temp Zeude home.
+func useTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ZEUDE_HOME", filepath.Join(home, ".zeude"))
+	return home
+}
+
+func TestEnabled(t *testing.T) {
+	home := useTestHome(t)
+	configPath := filepath.Join(home, ".zeude", "config")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		config string
+		policy bool
+		want   bool
+	}{
+		{"", false, false},
+		{"", true, false},
+		{"error_reporting=true\n", false, false},
+		{"error_reporting=true\n", true, true},
+		{"error_reporting=false\n", true, false},
+	} {
+		if err := os.WriteFile(configPath, []byte(tt.config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if got := Enabled(tt.policy); got != tt.want {
+			t.Errorf("Enabled(%v) with config %q = %v, want %v", tt.policy, tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestQueueCapKeepsNewest(t *testing.T) {
+	useTestHome(t)
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	total := MaxQueueEntries + 5
+	for i := 0; i < total; i++ {
+		seen := start.Add(time.Duration(i) * time.Minute)
+		if err := enqueue(Report{Fingerprint: fmt.Sprintf("fp%02d", i), Kind: KindError, Count: 1, FirstSeen: seen, LastSeen: seen}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != MaxQueueEntries {
+		t.Fatalf("queue has %d entries, want %d", len(reports), MaxQueueEntries)
+	}
+	kept := make(map[string]bool, len(reports))
+	for _, r := range reports {
+		kept[r.Fingerprint] = true
+	}
+	for i := 0; i < total; i++ {
+		fp := fmt.Sprintf("fp%02d", i)
+		if want := i >= total-MaxQueueEntries; kept[fp] != want {
+			t.Errorf("%s kept = %v, want %v", fp, kept[fp], want)
+		}
+	}
+}
+
+func TestSamePanicDedups(t *testing.T) {
+	useTestHome(t)
+	installed.Store(true)
+	t.Cleanup(func() { installed.Store(false) })
+
+	stack := "goroutine %d [running]:\n" +
+		"main.crash(%s)\n" +
+		"\t/src/zeude/cmd/claude/main.go:42 +0x%x\n" +
+		"main.main()\n" +
+		"\t/src/zeude/cmd/claude/main.go:10 +0x%x\n"
+	CapturePanic("boom", []byte(fmt.Sprintf(stack, 1, "0xc000012345, 0x2", 0x1d, 0x25)))
+	CapturePanic("boom", []byte(fmt.Sprintf(stack, 37, "0xc0000a8f00, 0x7", 0x3f, 0x31)))
+
+	reports, err := Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("queue has %d entries, want 1: %+v", len(reports), reports)
+	}
+	if r := reports[0]; r.Count != 2 || r.Kind != KindPanic {
+		t.Errorf("report = %+v, want one panic with Count 2", r)
+	}
+
+	CapturePanic("boom", []byte(strings.Replace(fmt.Sprintf(stack, 1, "0x1", 0x1d, 0x25), "main.go:42", "main.go:43", 1)))
+	if reports, _ := Pending(); len(reports) != 2 {
+		t.Errorf("a panic at another line merged into the first: %+v", reports)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	home := useTestHome(t)
+
+	tests := []struct {
+		name, in, want string
+	}{
+		{"agent key", "sync failed for zd_abc123XYZ", "sync failed for zd_[REDACTED]"},
+		{"bearer token", "header Authorization: Bearer eyJhbGciOi.x.y", "header Authorization: Bearer [REDACTED]"},
+		{"lowercase bearer", "bearer abc", "Bearer [REDACTED]"},
+		{"assignment", "env DATABASE_URL=postgres://u:p@h/db missing", "env DATABASE_URL=[REDACTED] missing"},
+		{"home directory", "open " + filepath.Join(home, "work", "app") + ": denied", "open " + filepath.Join("~", "work", "app") + ": denied"},
+		{"nothing to redact", "connection refused", "connection refused"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveSentKeepsRecurrences(t *testing.T) {
+	useTestHome(t)
+	path, err := QueuePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	sent := []Report{
+		{Fingerprint: "a", Kind: KindError, Count: 2, LastSeen: now},
+		{Fingerprint: "b", Kind: KindError, Count: 1, LastSeen: now},
+	}
+	if err := writeQueue(path, sent); err != nil {
+		t.Fatal(err)
+	}
+
+	// During the upload, "a" recurs and a new error "c" is queued.
+	if err := enqueue(Report{Fingerprint: "a", Kind: KindError, Count: 1, LastSeen: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enqueue(Report{Fingerprint: "c", Kind: KindError, Count: 1, LastSeen: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := removeSent(path, sent); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := readQueue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, r := range reports {
+		got[r.Fingerprint] = r.Count
+	}
+	if len(got) != 2 || got["a"] != 1 || got["c"] != 1 {
+		t.Errorf("after removeSent queue counts = %v, want a:1 c:1", got)
+	}
+
+	if err := removeSent(path, reports); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue file still exists after everything was sent: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package crashreport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/mcpconfig"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const (
+	// QueueFileName is the queue file under ~/.zeude.
+	QueueFileName = paths.ErrorsFile
+	// MaxQueueEntries caps distinct reports kept locally; the oldest are dropped.
+	MaxQueueEntries = 50
+	// flushTimeout bounds an upload so it never holds up the shim.
+	flushTimeout = 2 * time.Second
+	// queueLockWait bounds the wait for another process's queue rewrite,
+	// which only takes a moment, so an error log never stalls for long.
+	queueLockWait = time.Second
+)
+
+// queueMu serializes queue rewrites within this process; withQueueLock
+// also keeps other shims and the background sync out.
+var queueMu sync.Mutex
+
+// withQueueLock runs fn holding queueMu and the queue's file lock. The
+// queue has a lock of its own rather than the claude.json one: an error
+// logged while a sync holds that would have to wait for the sync.
+func withQueueLock(fn func() error) error {
+	lockPath, err := paths.ErrorsLock(env.OS{})
+	if err != nil {
+		return err
+	}
+	queueMu.Lock()
+	defer queueMu.Unlock()
+	return mcpconfig.WithLock(context.Background(), lockPath, queueLockWait, fn)
+}
+
+// QueuePath returns ~/.zeude/errors.jsonl.
+func QueuePath() (string, error) {
+	return paths.Errors(env.OS{})
+}
+
+// Pending returns the reports that would be sent on the next flush.
+func Pending() ([]Report, error) {
+	path, err := QueuePath()
+	if err != nil {
+		return nil, err
+	}
+	return readQueue(path)
+}
+
+// enqueue adds r, merging it into an existing entry with the same fingerprint.
+func enqueue(r Report) error {
+	path, err := QueuePath()
+	if err != nil {
+		return err
+	}
+
+	return withQueueLock(func() error {
+		reports, err := readQueue(path)
+		if err != nil {
+			return err
+		}
+		return writeQueue(path, mergeReport(reports, r))
+	})
+}
+
+// mergeReport adds r to reports, or counts it against an entry with the same
+// fingerprint, keeping the MaxQueueEntries most recently seen.
+func mergeReport(reports []Report, r Report) []Report {
+	merged := false
+	for i := range reports {
+		if reports[i].Fingerprint == r.Fingerprint {
+			reports[i].Count++
+			reports[i].LastSeen = r.LastSeen
+			reports[i].Version = r.Version
+			merged = true
+			break
+		}
+	}
+	if !merged {
+		reports = append(reports, r)
+	}
+
+	// Keep the most recently seen entries when over the cap
+	if len(reports) > MaxQueueEntries {
+		sort.SliceStable(reports, func(i, j int) bool {
+			return reports[i].LastSeen.Before(reports[j].LastSeen)
+		})
+		reports = reports[len(reports)-MaxQueueEntries:]
+	}
+	return reports
+}
+
+// Flush uploads queued reports to <dashboardURL>/api/errors and removes the
+// ones that were accepted. It is a no-op when the queue is empty.
+func Flush(ctx context.Context, dashboardURL, agentKey string) error {
+	if agentKey == "" {
+		return nil
+	}
+	path, err := QueuePath()
+	if err != nil {
+		return err
+	}
+
+	queueMu.Lock()
+	reports, err := readQueue(path)
+	queueMu.Unlock()
+	if err != nil || len(reports) == 0 {
+		return err
+	}
+
+	data, err := json.Marshal(struct {
+		Reports []Report `json:"reports"`
+	}{reports})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reports: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, flushTimeout)
+	defer cancel()
+
+	req, err := httpclient.NewRequest(ctx, http.MethodPost, dashboardURL+"/api/errors", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+agentKey)
+
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send reports: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("error report upload failed: %d", resp.StatusCode)
+	}
+
+	return removeSent(path, reports)
+}
+
+// removeSent drops uploaded reports, keeping any that recurred mid-upload.
+func removeSent(path string, sent []Report) error {
+	sentCount := make(map[string]int, len(sent))
+	for _, r := range sent {
+		sentCount[r.Fingerprint] = r.Count
+	}
+
+	return withQueueLock(func() error {
+		current, err := readQueue(path)
+		if err != nil {
+			return err
+		}
+		var remaining []Report
+		for _, r := range current {
+			n, ok := sentCount[r.Fingerprint]
+			if !ok {
+				remaining = append(remaining, r)
+			} else if r.Count > n {
+				r.Count -= n
+				remaining = append(remaining, r)
+			}
+		}
+		return writeQueue(path, remaining)
+	})
+}
+
+// readQueue loads the queue, skipping lines that fail to parse.
+func readQueue(path string) ([]Report, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var reports []Report
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Report
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.Fingerprint == "" {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	return reports, scanner.Err()
+}
+
+// writeQueue atomically replaces the queue; an empty queue removes the file.
+func writeQueue(path string, reports []Report) error {
+	if len(reports) == 0 {
+		err := os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range reports {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".errors-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package crashreport
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Size limits for a single report.
+const (
+	maxMessageLen = 512
+	maxStackLen   = 4096
+)
+
+var (
+	agentKeyRe   = regexp.MustCompile(`zd_[0-9A-Za-z]+`)
+	bearerRe     = regexp.MustCompile(`(?i)bearer\s+\S+`)
+	envAssignRe  = regexp.MustCompile(`\b([A-Z][A-Z0-9_]*)=\S+`)
+	stackOffset  = regexp.MustCompile(` \+0x[0-9a-f]+`)
+	stackArgs    = regexp.MustCompile(`\((?:[^()]*0x[^()]*|\.\.\.)\)$`)
+	goroutineHdr = regexp.MustCompile(`^goroutine \d+ \[`)
+)
+
+// Redact removes secrets and personal details from a message or stack:
+// agent keys, bearer tokens, VAR=value assignments, and the home directory.
+func Redact(s string) string {
+	if s == "" {
+		return s
+	}
+	s = agentKeyRe.ReplaceAllString(s, "zd_[REDACTED]")
+	s = bearerRe.ReplaceAllString(s, "Bearer [REDACTED]")
+	s = envAssignRe.ReplaceAllString(s, "$1=[REDACTED]")
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	return s
+}
+
+// normalizeStack strips per-run noise (goroutine IDs, argument values, PC
+// offsets) so the same crash always hashes the same way.
+func normalizeStack(stack string) string {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if goroutineHdr.MatchString(line) {
+			line = goroutineHdr.ReplaceAllString(line, "goroutine [")
+		}
+		line = stackOffset.ReplaceAllString(line, "")
+		line = stackArgs.ReplaceAllString(line, "(...)")
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "...[truncated]"
+}
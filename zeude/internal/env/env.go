@@ -0,0 +1,89 @@
+// Package env abstracts the process environment (home directory, environment
+// variables, clock, and working directory) so core packages can be driven
+// against temp homes and fake clocks instead of the real machine.
+package env
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// Env is the environment core packages read from.
+type Env interface {
+	HomeDir() (string, error)
+	Getenv(key string) string
+	Now() time.Time
+	Getwd() (string, error)
+}
+
+// OS is the production Env backed by the os and time packages.
+type OS struct{}
+
+// HomeDir returns the current user's home directory.
+func (OS) HomeDir() (string, error) { return os.UserHomeDir() }
+
+// Getenv returns the value of the environment variable key.
+func (OS) Getenv(key string) string { return os.Getenv(key) }
+
+// Now returns the current local time.
+func (OS) Now() time.Time { return time.Now() }
+
+// Getwd returns the current working directory.
+func (OS) Getwd() (string, error) { return os.Getwd() }
+
+// OrDefault returns e, or OS when e is nil so zero-value options work.
+func OrDefault(e Env) Env {
+	if e == nil {
+		return OS{}
+	}
+	return e
+}
+
+// Override is Env with some environment variables replaced.
+type Override struct {
+	Env
+	Vars map[string]string
+}
+
+// Getenv returns Vars[key] if set, else the underlying variable.
+func (o Override) Getenv(key string) string {
+	if v, ok := o.Vars[key]; ok {
+		return v
+	}
+	return o.Env.Getenv(key)
+}
+
+// Fake is an Env for tests: a fixed home and working directory, only the
+// variables in Vars, and a clock that moves only when told to.
+type Fake struct {
+	Home string
+	Wd   string
+	Vars map[string]string
+	Time time.Time
+}
+
+// HomeDir returns Home, or an error when it is empty.
+func (f *Fake) HomeDir() (string, error) {
+	if f.Home == "" {
+		return "", errors.New("no home directory")
+	}
+	return f.Home, nil
+}
+
+// Getenv returns Vars[key].
+func (f *Fake) Getenv(key string) string { return f.Vars[key] }
+
+// Now returns Time.
+func (f *Fake) Now() time.Time { return f.Time }
+
+// Getwd returns Wd, or Home when Wd is empty.
+func (f *Fake) Getwd() (string, error) {
+	if f.Wd == "" {
+		return f.HomeDir()
+	}
+	return f.Wd, nil
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) { f.Time = f.Time.Add(d) }
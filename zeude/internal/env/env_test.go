@@ -0,0 +1,52 @@
+package env
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverrideGetenv(t *testing.T) {
+	base := &Fake{Vars: map[string]string{"A": "base", "B": "base"}}
+	o := Override{Env: base, Vars: map[string]string{"A": "override", "C": ""}}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"A", "override"},
+		{"B", "base"},
+		{"C", ""}, // an empty override still hides the base value
+		{"D", ""},
+	}
+	for _, tt := range tests {
+		if got := o.Getenv(tt.key); got != tt.want {
+			t.Errorf("Getenv(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestOrDefault(t *testing.T) {
+	if _, ok := OrDefault(nil).(OS); !ok {
+		t.Errorf("OrDefault(nil) = %T, want OS", OrDefault(nil))
+	}
+	f := &Fake{}
+	if got := OrDefault(f); got != f {
+		t.Errorf("OrDefault(f) = %v, want f", got)
+	}
+}
+
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := &Fake{Home: "/home/u", Time: start}
+
+	if wd, err := f.Getwd(); err != nil || wd != "/home/u" {
+		t.Errorf("Getwd() = %q, %v; want the home directory", wd, err)
+	}
+	f.Advance(time.Hour)
+	if got := f.Now(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("Now() after Advance = %v, want %v", got, start.Add(time.Hour))
+	}
+	if _, err := (&Fake{}).HomeDir(); err == nil {
+		t.Error("HomeDir() with no Home returned no error")
+	}
+}
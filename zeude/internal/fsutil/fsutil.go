@@ -0,0 +1,16 @@
+// Package fsutil holds small filesystem helpers shared by the sync and
+// update paths.
+package fsutil
+
+// SyncDir flushes a directory's entries to stable storage.
+//
+// Renaming a fully synced temp file over a target is only atomic in memory:
+// on ext4, APFS and most other journaling filesystems the new directory
+// entry may still be in the page cache when the rename returns. A crash or
+// power loss at that point can leave the old file, no file, or (with
+// delayed allocation) a zero-length one. Fsyncing the parent directory
+// after the rename commits the entry, so the file that survives is exactly
+// the one we synced.
+func SyncDir(dir string) error {
+	return syncDir(dir)
+}
@@ -0,0 +1,27 @@
+//go:build unix || darwin || linux
+
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Sync(); err != nil {
+		// Some filesystems (certain network and FUSE mounts) don't support
+		// fsync on directories; there is nothing more we can do there.
+		if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
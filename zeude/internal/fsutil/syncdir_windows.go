@@ -0,0 +1,11 @@
+//go:build windows
+
+package fsutil
+
+// syncDir is a no-op on Windows. Directory handles can't be flushed with
+// FlushFileBuffers (it fails with access denied), and NTFS journals
+// metadata changes such as the rename itself, so a crash can't leave the
+// directory entry pointing at unsynced data.
+func syncDir(dir string) error {
+	return nil
+}
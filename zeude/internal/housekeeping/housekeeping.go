@@ -0,0 +1,237 @@
+// Package housekeeping removes debris that accumulates in the Zeude data
+// directory: temp files from interrupted atomic writes, leftovers from failed
+// self-updates, backups beyond the retention count, rotated logs beyond the
+// retention count, and stale claim markers.
+//
+// Only files matching names Zeude itself creates are ever deleted, and only
+// directly inside the data directory and its bin, logs and backups
+// subdirectories. Anything else is left alone.
+package housekeeping
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const (
+	// Interval is how often MaybeClean actually does anything.
+	Interval = 24 * time.Hour
+	// TempMaxAge is how old a temp file must be before it counts as orphaned;
+	// younger ones may belong to a write in progress.
+	TempMaxAge = time.Hour
+	// MarkerMaxAge is when a claim marker is considered abandoned.
+	MarkerMaxAge = time.Hour
+	// MaxBackups is how many backups of each file are kept.
+	MaxBackups = 5
+	// BackupSuffix ends every backup file name (<name>-<timestamp>.bak).
+	BackupSuffix = ".bak"
+)
+
+var logger = logging.Default().Component("housekeeping")
+
+// audit records each deletion; tests replace it.
+var audit = logging.Audit()
+
+// tempPrefixes are the temp file names Zeude creates (see writeFileAtomic,
+// the crash report queue, and performUpdate).
+var tempPrefixes = []string{".tmp-", ".errors-", "claude-update-"}
+
+// markerSuffixes are short-lived marker files left behind by crashed processes.
+var markerSuffixes = []string{".claim"}
+
+// Report lists what a cleanup pass removed.
+type Report struct {
+	Removed []string
+	Failed  []string
+}
+
+// MaybeClean runs Clean at most once per Interval, tracked by a marker in
+// the data directory. Failures are logged, never returned.
+func MaybeClean(e env.Env) {
+	marker, err := paths.LastCleanup(e)
+	if err != nil {
+		return
+	}
+	now := e.Now()
+	if info, err := os.Stat(marker); err == nil && now.Sub(info.ModTime()) < Interval {
+		return
+	}
+	// Touch first so parallel launches don't all clean at once
+	if err := touch(marker, now); err != nil {
+		logger.Debug("failed to touch cleanup marker", "error", err)
+		return
+	}
+
+	report := Clean(e)
+	if len(report.Removed) > 0 || len(report.Failed) > 0 {
+		logger.Info("housekeeping done", "removed", len(report.Removed), "failed", len(report.Failed))
+	}
+}
+
+// Clean removes stale Zeude files from the data directory now.
+func Clean(e env.Env) Report {
+	var r Report
+	dir, err := paths.Dir(e)
+	if err != nil {
+		return r
+	}
+	now := e.Now()
+
+	binDir, _ := paths.Bin(e)
+	logsDir, _ := paths.Logs(e)
+	backupsDir, _ := paths.Backups(e)
+
+	// The audit log rotates in the data dir, the main log in logs/
+	for _, d := range []string{dir, binDir, logsDir} {
+		for _, entry := range readDir(d) {
+			name := entry.Name()
+			path := filepath.Join(d, name)
+			switch {
+			case isTempName(name), d == binDir && isUpdateLeftover(name):
+				r.removeIfOlder(path, entry, now, TempMaxAge)
+			case hasAnySuffix(name, markerSuffixes):
+				r.removeIfOlder(path, entry, now, MarkerMaxAge)
+			case isExcessRotation(name, logging.DefaultMaxBackups):
+				r.remove(path)
+			}
+		}
+	}
+
+	r.pruneBackups(backupsDir)
+	return r
+}
+
+// pruneBackups keeps the newest MaxBackups backups of each file.
+func (r *Report) pruneBackups(dir string) {
+	groups := make(map[string][]string)
+	for _, entry := range readDir(dir) {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, BackupSuffix) {
+			continue
+		}
+		base, ok := backupBase(name)
+		if !ok {
+			continue
+		}
+		groups[base] = append(groups[base], name)
+	}
+	for _, names := range groups {
+		if len(names) <= MaxBackups {
+			continue
+		}
+		// Timestamps sort lexically, newest last
+		sort.Strings(names)
+		for _, name := range names[:len(names)-MaxBackups] {
+			r.remove(filepath.Join(dir, name))
+		}
+	}
+}
+
+// backupBase returns the file a <name>-<timestamp>.bak backup is of. The
+// timestamp is digits and dashes (e.g. 20250601-120000), so the name ends
+// at the first dash of the trailing run of them.
+func backupBase(name string) (string, bool) {
+	stem := strings.TrimSuffix(name, BackupSuffix)
+	i := len(stem)
+	for i > 0 && (stem[i-1] >= '0' && stem[i-1] <= '9' || stem[i-1] == '-') {
+		i--
+	}
+	dash := strings.IndexByte(stem[i:], '-')
+	if dash < 0 {
+		return "", false
+	}
+	base, stamp := stem[:i+dash], stem[i+dash+1:]
+	if base == "" || strings.Trim(stamp, "-") == "" {
+		return "", false
+	}
+	return base, true
+}
+
+func (r *Report) removeIfOlder(path string, entry os.DirEntry, now time.Time, age time.Duration) {
+	info, err := entry.Info()
+	if err != nil || !info.Mode().IsRegular() || now.Sub(info.ModTime()) < age {
+		return
+	}
+	r.remove(path)
+}
+
+func (r *Report) remove(path string) {
+	if err := os.Remove(path); err != nil {
+		if !os.IsNotExist(err) {
+			r.Failed = append(r.Failed, path)
+			logger.Debug("failed to remove", "path", path, "error", err)
+		}
+		return
+	}
+	r.Removed = append(r.Removed, path)
+	audit.Record(logging.AuditEvent{Action: logging.AuditDelete, Path: path, Key: "housekeeping"})
+}
+
+func isTempName(name string) bool {
+	for _, p := range tempPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUpdateLeftover matches the <binary>.old backup performUpdate leaves when
+// it is interrupted. Only checked in the bin directory.
+func isUpdateLeftover(name string) bool {
+	return strings.HasSuffix(name, ".old") && !strings.Contains(strings.TrimSuffix(name, ".old"), ".")
+}
+
+// isExcessRotation matches <file>.N with N beyond the retention count.
+func isExcessRotation(name string, keep int) bool {
+	dot := strings.LastIndexByte(name, '.')
+	if dot <= 0 {
+		return false
+	}
+	n, err := strconv.Atoi(name[dot+1:])
+	if err != nil || n <= keep {
+		return false
+	}
+	base := name[:dot]
+	return base == logging.LogFileName || base == paths.EventsFile
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, s := range suffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func readDir(dir string) []os.DirEntry {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	return entries
+}
+
+func touch(path string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Chtimes(path, now, now)
+}
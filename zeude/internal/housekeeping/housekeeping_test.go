@@ -0,0 +1,208 @@
+package housekeeping
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+func TestMain(m *testing.M) {
+	// Keep test runs out of the real ~/.zeude logs and audit trail
+	logger = logging.New(logging.Options{}).Component("housekeeping")
+	audit = logging.NewAuditLog("", 0, 0)
+	os.Exit(m.Run())
+}
+
+func testEnv(t *testing.T) *env.Fake {
+	t.Helper()
+	return &env.Fake{
+		Home: t.TempDir(),
+		Vars: map[string]string{},
+		Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// seed creates path under home with the given age by the fake clock.
+func seed(t *testing.T, e *env.Fake, path string, age time.Duration) {
+	t.Helper()
+	full := filepath.Join(e.Home, path)
+	if strings.HasSuffix(path, "/") {
+		if err := os.MkdirAll(full, 0700); err != nil {
+			t.Fatal(err)
+		}
+	} else {
+		os.MkdirAll(filepath.Dir(full), 0700)
+		if err := os.WriteFile(full, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mtime := e.Now().Add(-age)
+	if err := os.Chtimes(full, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seededFile is a file TestCleanMessyHome creates, and whether Clean
+// should remove it.
+type seededFile struct {
+	path    string
+	age     time.Duration
+	removed bool
+}
+
+func TestCleanMessyHome(t *testing.T) {
+	const old, young = 2 * time.Hour, 10 * time.Minute
+	files := []seededFile{
+		// Temp files
+		{".zeude/.tmp-1234", old, true},
+		{".zeude/.tmp-5678", young, false}, // may be a write in progress
+		{".zeude/.errors-1", old, true},
+		{".zeude/claude-update-99", old, true},
+		{".zeude/bin/.tmp-1", old, true},
+		{".zeude/logs/.tmp-1", old, true},
+		{".zeude/.tmp-dir/", old, false}, // not a regular file
+		{".zeude/skills/.tmp-1", old, false},
+		{".tmp-outside", old, false},
+		{".claude/.tmp-1", old, false},
+
+		// Update leftovers, in bin only
+		{".zeude/bin/claude", 48 * time.Hour, false},
+		{".zeude/bin/claude.old", old, true},
+		{".zeude/bin/claude.old.keep", old, false},
+		{".zeude/bin/claude.tar.old", old, false},
+		{".zeude/claude.old", old, false},
+
+		// Claim markers
+		{".zeude/last_heartbeat.claim", old, true},
+		{".zeude/last_heartbeat.claim2", old, false},
+		{".zeude/sync.claim", young, false},
+
+		// Rotated logs beyond logging.DefaultMaxBackups (3)
+		{".zeude/logs/zeude.log", old, false},
+		{".zeude/logs/zeude.log.3", old, false},
+		{".zeude/logs/zeude.log.4", young, true},
+		{".zeude/logs/zeude.log.10", old, true},
+		{".zeude/logs/zeude.log.old", old, false},
+		{".zeude/logs/hooks.log.9", old, false},
+		{".zeude/events.jsonl.1", old, false},
+		{".zeude/events.jsonl.4", old, true},
+		{".zeude/backups/events.jsonl.4", old, false},
+
+		// Unrelated user files
+		{".zeude/notes.txt", 30 * 24 * time.Hour, false},
+		{".zeude/credentials", 30 * 24 * time.Hour, false},
+		{".zeude/my.tmp-file", old, false},
+		{".zeude/tmp-1234", old, false},
+	}
+
+	// Backups: the newest MaxBackups (5) of each file are kept
+	for i := 0; i < 7; i++ {
+		files = append(files, seededFile{fmt.Sprintf(".zeude/backups/claude.json-2025050%d-120000.bak", i+1), young, i < 2})
+	}
+	for i := 0; i < 3; i++ {
+		files = append(files, seededFile{fmt.Sprintf(".zeude/backups/settings.json-2025050%d-120000.bak", i+1), old, false})
+	}
+	files = append(files, []seededFile{
+		{".zeude/backups/README", old, false},
+		{".zeude/backups/nodash.bak", old, false},
+		{".zeude/backups/20250601-120000/", old, false}, // a directory backup
+	}...)
+
+	e := testEnv(t)
+	var wantRemoved []string
+	for _, f := range files {
+		seed(t, e, f.path, f.age)
+		if f.removed {
+			wantRemoved = append(wantRemoved, filepath.Join(e.Home, f.path))
+		}
+	}
+
+	r := Clean(e)
+	sort.Strings(r.Removed)
+	sort.Strings(wantRemoved)
+	if strings.Join(r.Removed, "\n") != strings.Join(wantRemoved, "\n") {
+		t.Errorf("removed:\n%s\nwant:\n%s", strings.Join(r.Removed, "\n"), strings.Join(wantRemoved, "\n"))
+	}
+	if len(r.Failed) > 0 {
+		t.Errorf("failed: %v", r.Failed)
+	}
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(e.Home, f.path))
+		if exists := err == nil; exists == f.removed {
+			t.Errorf("%s: exists %v, want removed %v", f.path, exists, f.removed)
+		}
+	}
+
+	// A second pass finds nothing left to do
+	if r := Clean(e); len(r.Removed) > 0 {
+		t.Errorf("second pass removed %v", r.Removed)
+	}
+}
+
+func TestCleanWithoutDataDir(t *testing.T) {
+	if r := Clean(testEnv(t)); len(r.Removed) > 0 || len(r.Failed) > 0 {
+		t.Errorf("Clean on an empty home = %+v", r)
+	}
+}
+
+func TestMaybeCleanOncePerInterval(t *testing.T) {
+	e := testEnv(t)
+	tmp := ".zeude/.tmp-1"
+	exists := func() bool {
+		_, err := os.Stat(filepath.Join(e.Home, tmp))
+		return err == nil
+	}
+
+	seed(t, e, tmp, 2*time.Hour)
+	MaybeClean(e)
+	if exists() {
+		t.Fatal("first MaybeClean didn't clean")
+	}
+	marker, _ := paths.LastCleanup(e)
+	if info, err := os.Stat(marker); err != nil || !info.ModTime().Equal(e.Now()) {
+		t.Fatalf("marker not touched: %v", err)
+	}
+
+	e.Advance(Interval - time.Minute)
+	seed(t, e, tmp, 2*time.Hour)
+	MaybeClean(e)
+	if !exists() {
+		t.Error("MaybeClean cleaned again within the interval")
+	}
+
+	e.Advance(time.Minute)
+	MaybeClean(e)
+	if exists() {
+		t.Error("MaybeClean didn't clean once the interval passed")
+	}
+}
+
+func TestBackupBase(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		ok   bool
+	}{
+		{"claude.json-20250601-120000.bak", "claude.json", true},
+		{"settings.json-1717243200.bak", "settings.json", true},
+		{"file2-20250601.bak", "file2", true},
+		{"my-file-20250601-120000.bak", "my-file", true},
+		{"nodash.bak", "", false},
+		{"trailing-.bak", "", false},
+		{"-20250601.bak", "", false},
+		{"claude.json-old.bak", "", false},
+	}
+	for _, tt := range tests {
+		if base, ok := backupBase(tt.name); base != tt.base || ok != tt.ok {
+			t.Errorf("backupBase(%q) = %q, %v, want %q, %v", tt.name, base, ok, tt.base, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package httpclient provides the shared HTTP client configuration for Zeude.
+// All outgoing requests use one pooled transport with proxy support from the
+// environment, an optional custom CA bundle, and a consistent User-Agent.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+)
+
+// CABundleEnv points at a PEM file with extra root CAs (e.g. a corporate proxy).
+// The ca_bundle= key in ~/.zeude/config is used when the env var is unset.
+const CABundleEnv = "ZEUDE_CA_BUNDLE"
+
+var (
+	mu        sync.Mutex
+	version   = "dev"
+	transport http.RoundTripper
+)
+
+// SetVersion sets the version reported in the User-Agent.
+func SetVersion(v string) {
+	mu.Lock()
+	defer mu.Unlock()
+	version = v
+}
+
+// UserAgent returns the User-Agent sent with every Zeude request.
+func UserAgent() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return fmt.Sprintf("zeude-cli/%s (%s/%s)", version, runtime.GOOS, runtime.GOARCH)
+}
+
+// Transport returns the shared transport, building it on first use.
+func Transport() http.RoundTripper {
+	mu.Lock()
+	defer mu.Unlock()
+	if transport == nil {
+		transport = newTransport()
+	}
+	return transport
+}
+
+// SetTransport replaces the shared transport and returns a function that
+// restores the previous one. Intended for tests (e.g. httptest servers).
+func SetTransport(rt http.RoundTripper) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	prev := transport
+	transport = rt
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		transport = prev
+	}
+}
+
+// New returns a client on the shared transport with the given overall timeout.
+// A zero timeout means no client-level timeout (rely on the request context).
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: userAgentTransport{},
+		Timeout:   timeout,
+	}
+}
+
+// NewRequest builds a request with the standard User-Agent set.
+func NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent())
+	return req, nil
+}
+
+// userAgentTransport fills in the User-Agent and delegates to the shared
+// transport, resolving it per request so SetTransport takes effect everywhere.
+type userAgentTransport struct{}
+
+func (userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent())
+	}
+	return Transport().RoundTrip(req)
+}
+
+// newTransport builds the default transport: proxy from environment,
+// optional custom CA bundle, and limits suited to a short-lived CLI.
+func newTransport() http.RoundTripper {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          10,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       30 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	if pool := loadCABundle(); pool != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return t
+}
+
+// loadCABundle returns the system pool plus any configured extra CAs,
+// or nil when no bundle is configured or it can't be loaded.
+func loadCABundle() *x509.CertPool {
+	path := os.Getenv(CABundleEnv)
+	if path == "" {
+		path = config.Get("ca_bundle")
+	}
+	if path == "" {
+		return nil
+	}
+
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil
+	}
+	return pool
+}
+
+// RetryPolicy controls DoWithRetry. The zero value performs a single attempt.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first (<=1 means no retry)
+	Backoff     time.Duration // delay before the second attempt, doubled after each retry
+	// RetryOn decides whether to retry; nil retries network errors and 5xx.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+// NoRetry performs exactly one attempt.
+var NoRetry = RetryPolicy{}
+
+// DefaultRetryOn retries transport errors and 5xx responses, but never
+// a cancelled or expired context.
+func DefaultRetryOn(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode >= 500
+}
+
+// DoWithRetry sends the request built by newReq, retrying per policy.
+// newReq is called for every attempt so request bodies can be recreated.
+// The response of the final attempt is returned; earlier ones are closed.
+func DoWithRetry(client *http.Client, newReq func() (*http.Request, error), policy RetryPolicy) (*http.Response, error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	retryOn := policy.RetryOn
+	if retryOn == nil {
+		retryOn = DefaultRetryOn
+	}
+
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if attempt >= attempts || !retryOn(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if backoff > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
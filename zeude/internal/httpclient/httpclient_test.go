@@ -0,0 +1,223 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUserAgent(t *testing.T) {
+	SetVersion("1.2.3")
+	t.Cleanup(func() { SetVersion("dev") })
+
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.UserAgent())
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	want := fmt.Sprintf("zeude-cli/1.2.3 (%s/%s)", runtime.GOOS, runtime.GOARCH)
+	if ua := UserAgent(); ua != want {
+		t.Errorf("UserAgent() = %q, want %q", ua, want)
+	}
+
+	// A plain client request gets it filled in, NewRequest sets it, and a
+	// caller's own is left alone
+	client := New(5 * time.Second)
+	if resp, err := client.Get(srv.URL); err == nil {
+		resp.Body.Close()
+	}
+	req, _ := NewRequest(context.Background(), http.MethodGet, srv.URL, nil)
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+	req, _ = NewRequest(context.Background(), http.MethodGet, srv.URL, nil)
+	req.Header.Set("User-Agent", "custom/1")
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(got, "|") != strings.Join([]string{want, want, "custom/1"}, "|") {
+		t.Errorf("server saw %q", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestSetTransport(t *testing.T) {
+	client := New(0) // made before the swap, still uses it
+	calls := 0
+	restore := SetTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusTeapot, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}))
+	resp, err := client.Get("http://example.invalid/")
+	if err != nil || resp.StatusCode != http.StatusTeapot || calls != 1 {
+		t.Fatalf("swapped transport not used: %v %v, %d calls", resp, err, calls)
+	}
+	resp.Body.Close()
+
+	restore()
+	restore = SetTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("second")
+	}))
+	defer restore()
+	if _, err := client.Get("http://example.invalid/"); err == nil || !strings.Contains(err.Error(), "second") {
+		t.Errorf("after another swap: %v", err)
+	}
+}
+
+func TestDoWithRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // per attempt; the last repeats
+		policy   RetryPolicy
+		want     int
+		attempts int
+	}{
+		{"no retry by default", []int{500, 200}, RetryPolicy{}, 500, 1},
+		{"retry a 5xx", []int{503, 200}, RetryPolicy{MaxAttempts: 3}, 200, 2},
+		{"give up after MaxAttempts", []int{500}, RetryPolicy{MaxAttempts: 3}, 500, 3},
+		{"4xx is final", []int{404, 200}, RetryPolicy{MaxAttempts: 3}, 404, 1},
+		{"custom RetryOn", []int{429, 200}, RetryPolicy{MaxAttempts: 2, RetryOn: func(resp *http.Response, err error) bool {
+			return err == nil && resp.StatusCode == http.StatusTooManyRequests
+		}}, 200, 2},
+		{"backoff", []int{500, 500, 200}, RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 200, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				i := attempts
+				attempts++
+				mu.Unlock()
+				if string(body) != "payload" {
+					t.Errorf("attempt %d got body %q", i+1, body)
+				}
+				if i >= len(tt.statuses) {
+					i = len(tt.statuses) - 1
+				}
+				w.WriteHeader(tt.statuses[i])
+			}))
+			defer srv.Close()
+
+			newReq := func() (*http.Request, error) {
+				return NewRequest(context.Background(), http.MethodPost, srv.URL, strings.NewReader("payload"))
+			}
+			resp, err := DoWithRetry(New(5*time.Second), newReq, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want || attempts != tt.attempts {
+				t.Errorf("status %d after %d attempts, want %d after %d", resp.StatusCode, attempts, tt.want, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryStopsWhenCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := DoWithRetry(New(0), func() (*http.Request, error) {
+		return NewRequest(ctx, http.MethodGet, srv.URL, nil)
+	}, RetryPolicy{MaxAttempts: 5, Backoff: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("waited out the backoff after the context ended")
+	}
+}
+
+func TestDefaultRetryOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"network error", 0, errors.New("connection refused"), true},
+		{"cancelled", 0, fmt.Errorf("get: %w", context.Canceled), false},
+		{"timed out", 0, fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+		{"500", 500, nil, true},
+		{"502", 502, nil, true},
+		{"429", 429, nil, false},
+		{"200", 200, nil, false},
+	}
+	for _, tt := range tests {
+		var resp *http.Response
+		if tt.err == nil {
+			resp = &http.Response{StatusCode: tt.status}
+		}
+		if got := DefaultRetryOn(resp, tt.err); got != tt.want {
+			t.Errorf("%s: DefaultRetryOn = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCABundle checks a server whose certificate is only in the
+// configured bundle is trusted, and not without it.
+func TestCABundle(t *testing.T) {
+	home := t.TempDir() // ca_bundle in ~/.zeude/config must not apply
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ZEUDE_HOME", "")
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	bundle := filepath.Join(home, "ca.pem")
+	if err := os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		bundle string
+		ok     bool
+	}{
+		{"no bundle", "", false},
+		{"bundle", bundle, true},
+		{"missing bundle", filepath.Join(home, "nope.pem"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(CABundleEnv, tt.bundle)
+			client := &http.Client{Transport: newTransport(), Timeout: 5 * time.Second}
+			resp, err := client.Get(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Audit actions.
+const (
+	AuditWrite        = "file.write"
+	AuditDelete       = "file.delete"
+	AuditSettings     = "settings.write"
+	AuditClaudeConfig = "claude_config.merge"
+	AuditSelfUpdate   = "binary.update"
+)
+
+// Audit outcomes.
+const (
+	AuditOK     = "ok"
+	AuditFailed = "error"
+)
+
+// AuditEvent is one change Zeude made to the machine.
+type AuditEvent struct {
+	Time          time.Time `json:"timestamp"`
+	Action        string    `json:"action"`
+	Path          string    `json:"path,omitempty"`
+	Key           string    `json:"key,omitempty"` // e.g. the settings or config section touched
+	ContentHash   string    `json:"contentHash,omitempty"`
+	ConfigVersion string    `json:"configVersion,omitempty"`
+	Outcome       string    `json:"outcome"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// AuditLog appends AuditEvents as JSON lines to ~/.zeude/events.jsonl,
+// rotated like the main log. It answers "what has Zeude written to this
+// machine, and when".
+//
+// Recording is best-effort and never fails the caller. Each record is
+// written straight to the file (no buffering), so it survives the process
+// crashing right after the change it describes.
+type AuditLog struct {
+	mu            sync.Mutex
+	file          *rotatingFile
+	now           func() time.Time
+	configVersion string
+}
+
+// NewAuditLog creates an audit log at path ("" disables recording).
+func NewAuditLog(path string, maxSize int64, maxBackups int) *AuditLog {
+	a := &AuditLog{now: time.Now}
+	if path != "" {
+		if maxSize <= 0 {
+			maxSize = DefaultMaxSize
+		}
+		if maxBackups <= 0 {
+			maxBackups = DefaultMaxBackups
+		}
+		a.file = &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	}
+	return a
+}
+
+var (
+	auditOnce    sync.Once
+	defaultAudit *AuditLog
+)
+
+// Audit returns the process-wide audit log.
+func Audit() *AuditLog {
+	auditOnce.Do(func() {
+		path, err := paths.Events(env.OS{})
+		if err != nil {
+			path = ""
+		}
+		defaultAudit = NewAuditLog(path, 0, 0)
+	})
+	return defaultAudit
+}
+
+// SetConfigVersion tags subsequent records with the dashboard config version
+// being applied.
+func (a *AuditLog) SetConfigVersion(v string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.configVersion = v
+}
+
+// Record appends ev, filling in the time and config version when unset.
+func (a *AuditLog) Record(ev AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return
+	}
+
+	if ev.Time.IsZero() {
+		ev.Time = a.now().UTC()
+	}
+	if ev.ConfigVersion == "" {
+		ev.ConfigVersion = a.configVersion
+	}
+	if ev.Outcome == "" {
+		ev.Outcome = AuditOK
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	a.file.write(string(line) + "\n")
+}
+
+// RecordResult records ev with its outcome taken from err.
+func (a *AuditLog) RecordResult(ev AuditEvent, err error) {
+	if err != nil {
+		ev.Outcome = AuditFailed
+		ev.Error = err.Error()
+	}
+	a.Record(ev)
+}
+
+// ContentHash returns the hash recorded for written content.
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
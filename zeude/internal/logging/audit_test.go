@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// readAudit returns the records in an audit log file.
+func readAudit(t *testing.T, path string) []AuditEvent {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []AuditEvent
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var ev AuditEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("unreadable record %q: %v", line, err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestAuditRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	a := NewAuditLog(path, 0, 0)
+	a.now = fixedNow
+
+	a.Record(AuditEvent{Action: AuditWrite, Path: "/home/u/.claude/settings.json", ContentHash: ContentHash([]byte("{}"))})
+	// Written straight through, so it is there before anything is closed
+	if got := readAudit(t, path); len(got) != 1 {
+		t.Fatalf("%d records after the first Record, want 1", len(got))
+	}
+
+	a.SetConfigVersion("v7")
+	a.Record(AuditEvent{Action: AuditDelete, Path: "/tmp/x"})
+	explicit := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.Record(AuditEvent{Action: AuditSelfUpdate, Time: explicit, ConfigVersion: "v1", Outcome: AuditFailed})
+	a.RecordResult(AuditEvent{Action: AuditSettings, Key: "hooks"}, errors.New("disk full"))
+	a.RecordResult(AuditEvent{Action: AuditClaudeConfig}, nil)
+
+	want := []AuditEvent{
+		{Time: fixedNow(), Action: AuditWrite, Path: "/home/u/.claude/settings.json", ContentHash: ContentHash([]byte("{}")), Outcome: AuditOK},
+		{Time: fixedNow(), Action: AuditDelete, Path: "/tmp/x", ConfigVersion: "v7", Outcome: AuditOK},
+		{Time: explicit, Action: AuditSelfUpdate, ConfigVersion: "v1", Outcome: AuditFailed},
+		{Time: fixedNow(), Action: AuditSettings, Key: "hooks", ConfigVersion: "v7", Outcome: AuditFailed, Error: "disk full"},
+		{Time: fixedNow(), Action: AuditClaudeConfig, ConfigVersion: "v7", Outcome: AuditOK},
+	}
+	got := readAudit(t, path)
+	if len(got) != len(want) {
+		t.Fatalf("%d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) {
+			t.Errorf("record %d time = %v, want %v", i, got[i].Time, want[i].Time)
+		}
+		got[i].Time = want[i].Time
+		if got[i] != want[i] {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAuditRecordIsBestEffort(t *testing.T) {
+	// Disabled
+	NewAuditLog("", 0, 0).Record(AuditEvent{Action: AuditWrite})
+
+	// Unwritable: the parent is a regular file
+	parent := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(parent, nil, 0600)
+	a := NewAuditLog(filepath.Join(parent, "events.jsonl"), 0, 0)
+	a.Record(AuditEvent{Action: AuditWrite})
+	a.RecordResult(AuditEvent{Action: AuditDelete}, errors.New("gone"))
+}
+
+func TestAuditRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	a := NewAuditLog(path, 200, 2)
+	a.now = fixedNow
+	for i := 0; i < 10; i++ {
+		a.Record(AuditEvent{Action: AuditWrite, Path: "/tmp/" + strings.Repeat("p", i)})
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("%d files, want the log and 2 backups", len(entries))
+	}
+	for _, name := range []string{"events.jsonl", "events.jsonl.1", "events.jsonl.2"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || info.Size() > 200 {
+			t.Errorf("%s: %v", name, err)
+		}
+		readAudit(t, filepath.Join(dir, name))
+	}
+}
+
+func TestConcurrentAuditRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	a := NewAuditLog(path, 1<<30, 0)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				a.Record(AuditEvent{Action: AuditWrite, Path: "/tmp/x"})
+			}
+		}()
+	}
+	wg.Wait()
+	if got := readAudit(t, path); len(got) != 400 {
+		t.Errorf("%d records, want 400", len(got))
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	if got := ContentHash(nil); got != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("ContentHash(nil) = %s", got)
+	}
+	if ContentHash([]byte("a")) == ContentHash([]byte("b")) {
+		t.Error("different content, same hash")
+	}
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook execution log written by the generated hook scripts themselves, one
+// tab-separated line per run:
+//
+//	<RFC3339 time>\t<hook>\t<event>\t<exit code>\t<duration ms>\t<stderr>
+//
+// Scripts open the file with O_APPEND, so concurrent hooks interleave whole
+// lines, and move it to hooks.log.1 once it passes HookLogMaxSize.
+const (
+	HookLogFileName = "hooks.log"
+	HookLogMaxSize  = 512 << 10
+	// HookStderrLimit is how many bytes of a hook's stderr are logged.
+	HookStderrLimit = 300
+)
+
+// HookRecord is one line of the hook execution log.
+type HookRecord struct {
+	Time       time.Time `json:"time"`
+	Hook       string    `json:"hook"`
+	Event      string    `json:"event"`
+	ExitCode   int       `json:"exitCode"`
+	DurationMs int64     `json:"durationMs"`
+	Stderr     string    `json:"stderr,omitempty"`
+}
+
+// ParseHookRecord parses a hook log line, reporting false for lines in
+// another format.
+func ParseHookRecord(line string) (HookRecord, bool) {
+	parts := strings.SplitN(line, "\t", 6)
+	if len(parts) < 5 {
+		return HookRecord{}, false
+	}
+	t, err := time.Parse(time.RFC3339, parts[0])
+	if err != nil {
+		return HookRecord{}, false
+	}
+	code, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return HookRecord{}, false
+	}
+	ms, _ := strconv.ParseInt(parts[4], 10, 64)
+	rec := HookRecord{Time: t, Hook: parts[1], Event: parts[2], ExitCode: code, DurationMs: ms}
+	if len(parts) == 6 {
+		rec.Stderr = strings.TrimSpace(parts[5])
+	}
+	return rec, true
+}
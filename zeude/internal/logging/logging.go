@@ -0,0 +1,363 @@
+// Package logging provides Zeude's leveled, structured logger.
+// Records go to stderr (filtered by ZEUDE_DEBUG / ZEUDE_LOG_LEVEL) and to a
+// size-rotated file under ~/.zeude/logs so problems can be debugged after the fact.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Level is a log severity. Lower values are more severe.
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+// LevelOff as a stderr or file threshold writes nothing there.
+const LevelOff Level = -1
+
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "error"
+	case LevelWarn:
+		return "warn"
+	case LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// ParseLevel parses a level name (error, warn, info, debug).
+func ParseLevel(s string) (Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "error":
+		return LevelError, true
+	case "warn", "warning":
+		return LevelWarn, true
+	case "info":
+		return LevelInfo, true
+	case "debug":
+		return LevelDebug, true
+	}
+	return LevelError, false
+}
+
+const (
+	// DefaultMaxSize is the log file size that triggers rotation.
+	DefaultMaxSize = 1 << 20
+	// DefaultMaxBackups is how many rotated files (zeude.log.1 ...) are kept.
+	DefaultMaxBackups = 3
+	// LogFileName is the main log file under ~/.zeude/logs.
+	LogFileName = "zeude.log"
+)
+
+// Options configures a Logger created with New.
+type Options struct {
+	StderrLevel Level     // records at or above this severity go to Stderr
+	FileLevel   Level     // records at or above this severity go to FilePath
+	Stderr      io.Writer // nil disables stderr output
+	FilePath    string    // "" disables file output
+	MaxSize     int64     // rotation threshold in bytes (0 = DefaultMaxSize)
+	MaxBackups  int       // rotated files to keep (0 = DefaultMaxBackups)
+	Now         func() time.Time
+}
+
+// Logger writes leveled key=value records. It is safe for concurrent use;
+// loggers derived with Component or With share the same outputs.
+type Logger struct {
+	sink   *sink
+	fields []interface{}
+}
+
+// sink holds the outputs shared by a family of loggers.
+type sink struct {
+	mu          sync.Mutex
+	stderrLevel Level
+	fileLevel   Level
+	maxLevel    Level
+	stderr      io.Writer
+	file        *rotatingFile
+	now         func() time.Time
+	errorHook   ErrorHook
+}
+
+// ErrorHook receives every error-level record, independent of output filters.
+// It runs outside the logger's lock but must not log at error level itself.
+type ErrorHook func(msg string, fields []interface{})
+
+// New creates a logger with explicit outputs (useful for tests).
+func New(opts Options) *Logger {
+	s := &sink{
+		stderrLevel: opts.StderrLevel,
+		fileLevel:   opts.FileLevel,
+		stderr:      opts.Stderr,
+		now:         opts.Now,
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	if opts.FilePath != "" {
+		s.file = &rotatingFile{path: opts.FilePath, maxSize: opts.MaxSize, maxBackups: opts.MaxBackups}
+		if s.file.maxSize <= 0 {
+			s.file.maxSize = DefaultMaxSize
+		}
+		if s.file.maxBackups <= 0 {
+			s.file.maxBackups = DefaultMaxBackups
+		}
+	}
+
+	s.maxLevel = LevelError
+	if s.stderr != nil && s.stderrLevel > s.maxLevel {
+		s.maxLevel = s.stderrLevel
+	}
+	if s.file != nil && s.fileLevel > s.maxLevel {
+		s.maxLevel = s.fileLevel
+	}
+
+	return &Logger{sink: s}
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultLogger *Logger
+)
+
+// Default returns the process-wide logger configured from the environment:
+// stderr shows errors only unless ZEUDE_DEBUG=1 or ZEUDE_LOG_LEVEL is set,
+// and the file under ~/.zeude/logs records info and above (debug when enabled).
+func Default() *Logger {
+	defaultOnce.Do(func() {
+		stderrLevel, fileLevel := LevelError, LevelInfo
+		if os.Getenv("ZEUDE_DEBUG") == "1" {
+			stderrLevel, fileLevel = LevelDebug, LevelDebug
+		}
+		if lvl, ok := ParseLevel(os.Getenv("ZEUDE_LOG_LEVEL")); ok {
+			stderrLevel = lvl
+			if lvl > fileLevel {
+				fileLevel = lvl
+			}
+		}
+
+		opts := Options{StderrLevel: stderrLevel, FileLevel: fileLevel, Stderr: os.Stderr}
+		if dir := DefaultDir(); dir != "" {
+			opts.FilePath = filepath.Join(dir, LogFileName)
+		}
+		defaultLogger = New(opts)
+	})
+	return defaultLogger
+}
+
+// DefaultDir returns the log directory (~/.zeude/logs), or "" if it can't be resolved.
+func DefaultDir() string {
+	dir, err := paths.Logs(env.OS{})
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// Component returns a logger that tags every record with component=name.
+func (l *Logger) Component(name string) *Logger {
+	return l.With("component", name)
+}
+
+// With returns a logger that adds the given key/value pairs to every record.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &Logger{sink: l.sink, fields: fields}
+}
+
+// SetErrorHook registers fn for error-level records on this logger and every
+// logger sharing its outputs. nil removes the hook.
+func (l *Logger) SetErrorHook(fn ErrorHook) {
+	l.sink.mu.Lock()
+	defer l.sink.mu.Unlock()
+	l.sink.errorHook = fn
+}
+
+// SetStderrLevel changes the stderr threshold for this logger and every
+// logger sharing its outputs (e.g. for a --verbose flag). Call it before
+// logging from other goroutines starts.
+func (l *Logger) SetStderrLevel(level Level) {
+	s := l.sink
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stderrLevel = level
+	if s.stderr != nil && level > s.maxLevel {
+		s.maxLevel = level
+	}
+}
+
+// Enabled reports whether a record at level would be written anywhere.
+func (l *Logger) Enabled(level Level) bool {
+	return level <= l.sink.maxLevel
+}
+
+// Error logs msg with key/value fields at error level.
+func (l *Logger) Error(msg string, kv ...interface{}) { l.log(LevelError, msg, kv) }
+
+// Warn logs msg with key/value fields at warn level.
+func (l *Logger) Warn(msg string, kv ...interface{}) { l.log(LevelWarn, msg, kv) }
+
+// Info logs msg with key/value fields at info level.
+func (l *Logger) Info(msg string, kv ...interface{}) { l.log(LevelInfo, msg, kv) }
+
+// Debug logs msg with key/value fields at debug level.
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.log(LevelDebug, msg, kv) }
+
+// Errorf logs a formatted message at error level.
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logf(LevelError, format, args) }
+
+// Warnf logs a formatted message at warn level.
+func (l *Logger) Warnf(format string, args ...interface{}) { l.logf(LevelWarn, format, args) }
+
+// Infof logs a formatted message at info level.
+func (l *Logger) Infof(format string, args ...interface{}) { l.logf(LevelInfo, format, args) }
+
+// Debugf logs a formatted message at debug level.
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logf(LevelDebug, format, args) }
+
+// logf formats only when the level is enabled.
+func (l *Logger) logf(level Level, format string, args []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.log(level, fmt.Sprintf(format, args...), nil)
+}
+
+func (l *Logger) log(level Level, msg string, kv []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	s := l.sink
+	var b strings.Builder
+	b.WriteString(s.now().UTC().Format(time.RFC3339))
+	b.WriteString(" level=")
+	b.WriteString(level.String())
+	writeFields(&b, l.fields)
+	b.WriteString(" msg=")
+	b.WriteString(quote(msg))
+	writeFields(&b, kv)
+	b.WriteByte('\n')
+	line := b.String()
+
+	s.mu.Lock()
+	if s.stderr != nil && level <= s.stderrLevel {
+		io.WriteString(s.stderr, line)
+	}
+	if s.file != nil && level <= s.fileLevel {
+		s.file.write(line)
+	}
+	hook := s.errorHook
+	s.mu.Unlock()
+
+	if hook != nil && level == LevelError {
+		fields := make([]interface{}, 0, len(l.fields)+len(kv))
+		fields = append(fields, l.fields...)
+		fields = append(fields, kv...)
+		hook(msg, fields)
+	}
+}
+
+// writeFields appends " key=value" pairs; a dangling key gets an empty value.
+func writeFields(b *strings.Builder, kv []interface{}) {
+	for i := 0; i < len(kv); i += 2 {
+		b.WriteByte(' ')
+		b.WriteString(fmt.Sprint(kv[i]))
+		b.WriteByte('=')
+		if i+1 < len(kv) {
+			b.WriteString(quote(fmt.Sprint(kv[i+1])))
+		}
+	}
+}
+
+// quote wraps values containing spaces, quotes, or '=' so lines stay parseable.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// rotatingFile appends to a file and rotates it once it exceeds maxSize.
+// The file is opened lazily so nothing is created until something is logged.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+	failed     bool // stop retrying after an open failure
+}
+
+func (r *rotatingFile) write(line string) {
+	if r.failed {
+		return
+	}
+	if r.f == nil && !r.open() {
+		return
+	}
+	if r.size > 0 && r.size+int64(len(line)) > r.maxSize {
+		r.rotate()
+		if r.f == nil {
+			return
+		}
+	}
+	n, err := r.f.WriteString(line)
+	r.size += int64(n)
+	if err != nil {
+		r.f.Close()
+		r.f = nil
+	}
+}
+
+func (r *rotatingFile) open() bool {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		r.failed = true
+		return false
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		r.failed = true
+		return false
+	}
+	r.f = f
+	r.size = 0
+	if info, err := f.Stat(); err == nil {
+		r.size = info.Size()
+	}
+	return true
+}
+
+// rotate shifts zeude.log -> zeude.log.1 -> ... dropping the oldest backup.
+func (r *rotatingFile) rotate() {
+	r.f.Close()
+	r.f = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	os.Rename(r.path, r.path+".1")
+
+	r.open()
+}
@@ -0,0 +1,228 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func fixedNow() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+		ok   bool
+	}{
+		{"error", LevelError, true},
+		{"WARN", LevelWarn, true},
+		{" warning ", LevelWarn, true},
+		{"info", LevelInfo, true},
+		{"Debug", LevelDebug, true},
+		{"trace", LevelError, false},
+		{"", LevelError, false},
+	}
+	for _, tt := range tests {
+		if got, ok := ParseLevel(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoggerLevelsAndFields(t *testing.T) {
+	var stderr bytes.Buffer
+	dir := t.TempDir()
+	file := filepath.Join(dir, LogFileName)
+	l := New(Options{StderrLevel: LevelWarn, FileLevel: LevelDebug, Stderr: &stderr, FilePath: file, Now: fixedNow}).Component("sync")
+
+	l.Error("fetch failed", "url", "http://x", "error", "connection refused")
+	l.Warn("odd config", "key")
+	l.Info("synced", "hooks", 3)
+	l.Debugf("took %dms", 12)
+	l.With("session", "abc").Warn(`say "hi"`, "path", "a=b")
+
+	wantStderr := `2025-06-01T12:00:00Z level=error component=sync msg="fetch failed" url=http://x error="connection refused"
+2025-06-01T12:00:00Z level=warn component=sync msg="odd config" key=
+2025-06-01T12:00:00Z level=warn component=sync session=abc msg="say \"hi\"" path="a=b"
+`
+	if stderr.String() != wantStderr {
+		t.Errorf("stderr:\n%s\nwant:\n%s", stderr.String(), wantStderr)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"level=info component=sync msg=synced hooks=3", "level=debug component=sync msg=\"took 12ms\""} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log file lacks %q:\n%s", want, data)
+		}
+	}
+	if n := strings.Count(string(data), "\n"); n != 5 {
+		t.Errorf("log file has %d records, want 5", n)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want Level // most verbose level enabled
+	}{
+		{"no outputs", Options{StderrLevel: LevelDebug, FileLevel: LevelDebug}, LevelError},
+		{"stderr only", Options{StderrLevel: LevelInfo, Stderr: &bytes.Buffer{}}, LevelInfo},
+		{"file is more verbose", Options{StderrLevel: LevelWarn, FileLevel: LevelDebug, Stderr: &bytes.Buffer{}, FilePath: "unused"}, LevelDebug},
+		{"stderr off", Options{StderrLevel: LevelOff, Stderr: &bytes.Buffer{}}, LevelError},
+	}
+	for _, tt := range tests {
+		l := New(tt.opts)
+		for level := LevelError; level <= LevelDebug; level++ {
+			if got := l.Enabled(level); got != (level <= tt.want) {
+				t.Errorf("%s: Enabled(%v) = %v", tt.name, level, got)
+			}
+		}
+	}
+}
+
+func TestSetStderrLevel(t *testing.T) {
+	var stderr bytes.Buffer
+	l := New(Options{StderrLevel: LevelError, Stderr: &stderr, Now: fixedNow})
+	l.Info("hidden")
+	l.Component("x").SetStderrLevel(LevelDebug) // shared by every derived logger
+	l.Debug("shown")
+	l.SetStderrLevel(LevelOff)
+	l.Error("hidden too")
+	if got := stderr.String(); got != "2025-06-01T12:00:00Z level=debug msg=shown\n" {
+		t.Errorf("stderr = %q", got)
+	}
+}
+
+func TestDisabledLevelDoesNotAllocate(t *testing.T) {
+	l := New(Options{StderrLevel: LevelError, Stderr: &bytes.Buffer{}})
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Debug("not written")
+		l.Debugf("not written either")
+	})
+	if allocs != 0 {
+		t.Errorf("disabled level allocated %.0f times per call", allocs)
+	}
+}
+
+func TestErrorHook(t *testing.T) {
+	l := New(Options{})
+	var got []string
+	l.Component("sync").SetErrorHook(func(msg string, fields []interface{}) {
+		got = append(got, fmt.Sprint(msg, fields))
+	})
+	l.Warn("not an error")
+	l.Component("auth").Error("expired", "status", 401)
+	if strings.Join(got, "|") != "expired[component auth status 401]" {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+// TestRotationAtSizeBoundary fills the file exactly to MaxSize, which
+// must not rotate, then writes one more byte's worth, which must.
+func TestRotationAtSizeBoundary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LogFileName)
+	r := &rotatingFile{path: path, maxSize: 20, maxBackups: 2}
+
+	r.write("0123456789\n") // 11 bytes
+	r.write("abcdefghi\n")  // 11+10 > 20: rotates first
+	assertFiles(t, dir, map[string]string{
+		LogFileName:        "abcdefghi\n",
+		LogFileName + ".1": "0123456789\n",
+	})
+
+	r.write("123456789\n") // 10+10 = 20: exactly the limit, no rotation
+	assertFiles(t, dir, map[string]string{
+		LogFileName:        "abcdefghi\n123456789\n",
+		LogFileName + ".1": "0123456789\n",
+	})
+
+	r.write("x\n")                          // 22: rotates, shifting .1 to .2
+	r.write(strings.Repeat("y", 30) + "\n") // a record over the limit still goes in, alone
+	r.write("z\n")
+	assertFiles(t, dir, map[string]string{
+		LogFileName:        "z\n",
+		LogFileName + ".1": strings.Repeat("y", 30) + "\n",
+		LogFileName + ".2": "x\n",
+	})
+	r.f.Close()
+}
+
+func TestRotationReopensExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, LogFileName)
+	os.WriteFile(path, []byte("0123456789\n"), 0600)
+
+	r := &rotatingFile{path: path, maxSize: 15, maxBackups: 1}
+	r.write("abcde\n") // counts the 11 bytes already there
+	r.f.Close()
+	assertFiles(t, dir, map[string]string{
+		LogFileName:        "abcde\n",
+		LogFileName + ".1": "0123456789\n",
+	})
+}
+
+// TestConcurrentLogging writes from many goroutines, as Sync does, and
+// checks every record arrives whole.
+func TestConcurrentLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFileName)
+	l := New(Options{FileLevel: LevelInfo, FilePath: path, MaxSize: 1 << 30})
+
+	const writers, records = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			c := l.Component(fmt.Sprint("w", w))
+			for i := 0; i < records; i++ {
+				c.Info("record", "i", i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for s := bufio.NewScanner(f); s.Scan(); lines++ {
+		if !strings.Contains(s.Text(), " level=info component=w") || !strings.Contains(s.Text(), " msg=record i=") {
+			t.Fatalf("garbled record %q", s.Text())
+		}
+	}
+	if lines != writers*records {
+		t.Errorf("%d records, want %d", lines, writers*records)
+	}
+}
+
+// assertFiles checks dir holds exactly want, by name and content.
+func assertFiles(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(want) {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("files %v, want %d", names, len(want))
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q (%v), want %q", name, data, err, content)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Record is a log line split into the parts readers filter on.
+type Record struct {
+	Time      time.Time // zero if the line has no parseable timestamp
+	Level     string
+	Component string
+	Line      string // the line as written, without the trailing newline
+}
+
+// ParseRecord splits a line written by Logger. Lines in an unexpected format
+// come back with only Line set, so callers can still show them.
+func ParseRecord(line string) Record {
+	rec := Record{Line: line}
+	head := line
+	// Fields passed to Component/With are written before msg=
+	if i := strings.Index(head, " msg="); i >= 0 {
+		head = head[:i]
+	}
+	parts := strings.Fields(head)
+	if len(parts) == 0 {
+		return rec
+	}
+	if t, err := time.Parse(time.RFC3339, parts[0]); err == nil {
+		rec.Time = t
+	}
+	for _, p := range parts[1:] {
+		switch {
+		case strings.HasPrefix(p, "level="):
+			rec.Level = strings.TrimPrefix(p, "level=")
+		case strings.HasPrefix(p, "component="):
+			rec.Component = strings.TrimPrefix(p, "component=")
+		}
+	}
+	return rec
+}
+
+// Files returns path and its rotated backups (path.1, path.2, ...) that
+// exist, oldest first, so reading them in order gives chronological output.
+func Files(path string) []string {
+	var backups []string
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(name); err != nil {
+			break
+		}
+		backups = append(backups, name)
+	}
+
+	files := make([]string, 0, len(backups)+1)
+	for i := len(backups) - 1; i >= 0; i-- {
+		files = append(files, backups[i])
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
@@ -0,0 +1,137 @@
+package mcpconfig
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeude/zeude/internal/logging"
+)
+
+// recordAudit sends the audit log to a temp file for the rest of the test
+// and returns a function reading back what has been recorded.
+func recordAudit(t *testing.T) func() []logging.AuditEvent {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	old := audit
+	audit = logging.NewAuditLog(path, 0, 0)
+	t.Cleanup(func() { audit = old })
+
+	return func() []logging.AuditEvent {
+		t.Helper()
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var events []logging.AuditEvent
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var ev logging.AuditEvent
+			if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+				t.Fatalf("unreadable audit record %q: %v", scanner.Text(), err)
+			}
+			events = append(events, ev)
+		}
+		return events
+	}
+}
+
+const auditConfig = `{
+  "configVersion": "v1",
+  "mcpServers": {"fs": {"command": "npx", "args": ["fs-server"]}},
+  "hooks": [
+    {"id": "h1", "name": "log-prompt", "event": "UserPromptSubmit", "script": "echo hi"},
+    {"id": "h2", "name": "guard", "event": "PreToolUse", "matcher": "Bash", "script": "exit 0"}
+  ]
+}`
+
+// findEvent returns the first event with action on path.
+func findEvent(events []logging.AuditEvent, action, path string) (logging.AuditEvent, bool) {
+	for _, ev := range events {
+		if ev.Action == action && ev.Path == path {
+			return ev, true
+		}
+	}
+	return logging.AuditEvent{}, false
+}
+
+func TestSyncAuditTrail(t *testing.T) {
+	events := recordAudit(t)
+	d := newFakeDashboard(t, auditConfig)
+	e := syncEnv(t, d)
+	claudeJSON, _ := getClaudeConfigPath(e)
+	settings, _ := getClaudeSettingsPath(e)
+	hooksDir := filepath.Join(e.Home, ".claude", "hooks")
+	guard := filepath.Join(hooksDir, "PreToolUse", "guard.sh")
+	logPrompt := filepath.Join(hooksDir, "UserPromptSubmit", "log-prompt.sh")
+
+	// Install
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success {
+		t.Fatalf("sync failed: %+v", r)
+	}
+	installed := events()
+	for _, want := range []struct{ action, path string }{
+		{logging.AuditClaudeConfig, claudeJSON},
+		{logging.AuditSettings, settings},
+		{logging.AuditWrite, guard},
+		{logging.AuditWrite, logPrompt},
+	} {
+		ev, ok := findEvent(installed, want.action, want.path)
+		if !ok {
+			t.Errorf("no %s record for %s", want.action, want.path)
+			continue
+		}
+		data, _ := os.ReadFile(want.path)
+		if ev.ConfigVersion != "v1" || ev.Outcome != logging.AuditOK || ev.ContentHash != logging.ContentHash(data) || ev.Time.IsZero() {
+			t.Errorf("%s record for %s = %+v", want.action, want.path, ev)
+		}
+	}
+	if ev, _ := findEvent(installed, logging.AuditClaudeConfig, claudeJSON); ev.Key != "mcpServers" {
+		t.Errorf("claude.json record key = %q, want mcpServers", ev.Key)
+	}
+
+	// A hook dropped by the dashboard is deleted
+	d.setConfig(`{"configVersion": "v2", "hooks": [{"id": "h1", "name": "log-prompt", "event": "UserPromptSubmit", "script": "echo hi"}]}`)
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success {
+		t.Fatalf("sync failed: %+v", r)
+	}
+	updated := events()[len(installed):]
+	if ev, ok := findEvent(updated, logging.AuditDelete, guard); !ok || ev.ConfigVersion != "v2" || ev.Outcome != logging.AuditOK {
+		t.Errorf("delete record for the dropped hook = %+v (found %v)", ev, ok)
+	}
+	if _, ok := findEvent(updated, logging.AuditWrite, logPrompt); ok {
+		t.Error("unchanged hook script recorded as written again")
+	}
+
+	// Uninstall
+	before := len(events())
+	if _, err := Uninstall(context.Background(), UninstallOptions{Env: e}); err != nil {
+		t.Fatal(err)
+	}
+	removed := events()[before:]
+	if _, ok := findEvent(removed, logging.AuditDelete, logPrompt); !ok {
+		t.Errorf("no delete record for %s in %+v", logPrompt, removed)
+	}
+	if _, ok := findEvent(removed, logging.AuditSettings, settings); !ok {
+		t.Errorf("no settings record for unregistering the hooks in %+v", removed)
+	}
+}
+
+func TestFailedWriteAudited(t *testing.T) {
+	events := recordAudit(t)
+	path := filepath.Join(t.TempDir(), "missing", "settings.json")
+	if err := writeFileAtomicWithOptions(path, []byte("{}"), 0600, atomicWriteOptions{AuditAction: logging.AuditSettings}); err == nil {
+		t.Fatal("write into a missing directory succeeded")
+	}
+	ev, ok := findEvent(events(), logging.AuditSettings, path)
+	if !ok || ev.Outcome != logging.AuditFailed || ev.Error == "" {
+		t.Errorf("failed write record = %+v (found %v)", ev, ok)
+	}
+}
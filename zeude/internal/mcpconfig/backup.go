@@ -0,0 +1,305 @@
+package mcpconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/housekeeping"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// backupIDLayout names snapshot directories; IDs sort oldest first.
+const backupIDLayout = "20060102-150405"
+
+// ErrNoBackups means there is no snapshot to restore.
+var ErrNoBackups = errors.New("no backups")
+
+// Backup is one snapshot directory under ~/.zeude/backups.
+type Backup struct {
+	ID    string    `json:"id"`
+	Time  time.Time `json:"time"`
+	Dir   string    `json:"dir"`
+	Files []string  `json:"files"` // names of the files it holds, see backupTargets
+}
+
+// backupTarget is a file a snapshot covers.
+type backupTarget struct {
+	name string // file name inside the snapshot
+	path func(env.Env) (string, error)
+}
+
+// backupTargets are the files a bad merge could damage: Claude's config
+// and settings, and the manifest that says which entries in them are Zeude's.
+// The legacy manifests stay listed so older snapshots restore; they come
+// after managed.json, so a restored one is newer and loadManifest prefers it.
+var backupTargets = []backupTarget{
+	{"claude.json", paths.ClaudeConfig},
+	{"settings.json", paths.ClaudeSettings},
+	{paths.ManifestFile, paths.Manifest},
+	{paths.ManagedKeysFile, paths.ManagedKeys},
+	{paths.ManagedHooksFile, paths.ManagedHooks},
+	{paths.ManagedSkillsFile, paths.ManagedSkills},
+}
+
+// CreateBackup snapshots claude.json, settings.json and the managed lists
+// into ~/.zeude/backups/<timestamp>/, keeping the newest
+// housekeeping.MaxBackups snapshots.
+func CreateBackup(ctx context.Context) (*Backup, error) {
+	e := env.OS{}
+	var backup *Backup
+	err := withFileLock(ctx, e, func() (err error) {
+		backup, err = createBackup(e)
+		return err
+	})
+	return backup, err
+}
+
+// createBackup is CreateBackup for callers already holding the file lock.
+func createBackup(e env.Env) (*Backup, error) {
+	dir, err := paths.Backups(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backups dir: %w", err)
+	}
+
+	now := e.Now().UTC()
+	backup := &Backup{ID: now.Format(backupIDLayout), Time: now}
+	// Two snapshots in the same second get -2, -3, ...
+	for n := 2; ; n++ {
+		backup.Dir = filepath.Join(dir, backup.ID)
+		err := os.Mkdir(backup.Dir, 0700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create backup dir: %w", err)
+		}
+		backup.ID = now.Format(backupIDLayout) + "-" + strconv.Itoa(n)
+	}
+
+	for _, target := range backupTargets {
+		src, err := target.path(e)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(src)
+		if err != nil {
+			continue // nothing to back up yet
+		}
+		data, err := os.ReadFile(src)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(backup.Dir, target.name), data, info.Mode().Perm())
+		}
+		if err != nil {
+			os.RemoveAll(backup.Dir)
+			return nil, fmt.Errorf("failed to back up %s: %w", src, err)
+		}
+		backup.Files = append(backup.Files, target.name)
+	}
+	logDebug("created backup %s (%s)", backup.ID, strings.Join(backup.Files, ", "))
+	markBackedUp(e)
+
+	pruneBackups(dir)
+	return backup, nil
+}
+
+// pruneBackups removes all but the newest housekeeping.MaxBackups snapshots.
+func pruneBackups(dir string) {
+	backups := readBackups(dir)
+	for i := housekeeping.MaxBackups; i < len(backups); i++ {
+		if err := os.RemoveAll(backups[i].Dir); err != nil {
+			logDebug("failed to prune backup %s: %v", backups[i].ID, err)
+			continue
+		}
+		audit.Record(logging.AuditEvent{Action: logging.AuditDelete, Path: backups[i].Dir, Key: "backup"})
+	}
+}
+
+// ListBackups returns the snapshots, newest first.
+func ListBackups() ([]Backup, error) {
+	dir, err := paths.Backups(env.OS{})
+	if err != nil {
+		return nil, err
+	}
+	return readBackups(dir), nil
+}
+
+func readBackups(dir string) []Backup {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []Backup
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue // flat <name>-<timestamp>.bak files belong to housekeeping
+		}
+		name := entry.Name()
+		if len(name) < len(backupIDLayout) {
+			continue
+		}
+		t, err := time.Parse(backupIDLayout, name[:len(backupIDLayout)])
+		if err != nil {
+			continue
+		}
+		backup := Backup{ID: name, Time: t, Dir: filepath.Join(dir, name)}
+		for _, target := range backupTargets {
+			if _, err := os.Stat(filepath.Join(backup.Dir, target.name)); err == nil {
+				backup.Files = append(backup.Files, target.name)
+			}
+		}
+		backups = append(backups, backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backupSeq(backups[i].ID) > backupSeq(backups[j].ID)
+	})
+	return backups
+}
+
+// backupSeq returns the same-second sequence number of an ID like
+// 20261014-130820-2 (1 if none).
+func backupSeq(id string) int {
+	if n, err := strconv.Atoi(strings.TrimPrefix(id[len(backupIDLayout):], "-")); err == nil {
+		return n
+	}
+	return 1
+}
+
+// RestoreLatestBackup restores the newest snapshot; see RestoreBackup.
+func RestoreLatestBackup(ctx context.Context) (*Backup, error) {
+	return RestoreBackup(ctx, "")
+}
+
+// RestoreBackup puts the files of snapshot id ("" for the newest) back
+// atomically, under the file lock. The current state is snapshotted first
+// so a restore can itself be undone. Files the snapshot doesn't hold
+// (they didn't exist at the time) are left as they are.
+func RestoreBackup(ctx context.Context, id string) (*Backup, error) {
+	e := env.OS{}
+	var backup *Backup
+	err := withFileLock(ctx, e, func() (err error) {
+		backup, err = restoreBackup(e, id)
+		return err
+	})
+	return backup, err
+}
+
+func restoreBackup(e env.Env, id string) (*Backup, error) {
+	dir, err := paths.Backups(e)
+	if err != nil {
+		return nil, err
+	}
+	backups := readBackups(dir)
+	if len(backups) == 0 {
+		return nil, ErrNoBackups
+	}
+	backup := &backups[0]
+	if id != "" {
+		backup = nil
+		for i := range backups {
+			if backups[i].ID == id {
+				backup = &backups[i]
+				break
+			}
+		}
+		if backup == nil {
+			return nil, fmt.Errorf("no backup %q (see 'zeude restore --list')", id)
+		}
+	}
+
+	// Read everything before the safety snapshot, which may prune this one
+	type restoreFile struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}
+	var files []restoreFile
+	for _, target := range backupTargets {
+		src := filepath.Join(backup.Dir, target.name)
+		info, err := os.Stat(src)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		dst, err := target.path(e)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, restoreFile{dst, data, info.Mode().Perm()})
+	}
+
+	if _, err := createBackup(e); err != nil {
+		return nil, fmt.Errorf("failed to snapshot current files before restoring: %w", err)
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomicWithOptions(f.path, f.data, f.perm, atomicWriteOptions{AuditKey: "restore " + backup.ID}); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", f.path, err)
+		}
+	}
+	logger.Info("restored backup", "id", backup.ID, "files", len(files))
+	return backup, nil
+}
+
+// autoBackups records which claude.json paths this process has already
+// snapshotted, so only the first write of each process takes a backup.
+var autoBackups struct {
+	sync.Mutex
+	done map[string]bool
+}
+
+// backupBeforeFirstWrite snapshots before this process first rewrites
+// claude.json or settings.json; one snapshot holds both, and an explicit
+// one taken earlier counts. Failure is logged, not returned: a missing
+// backup shouldn't block the sync.
+func backupBeforeFirstWrite(e env.Env) {
+	configPath, err := getClaudeConfigPath(e)
+	if err != nil {
+		return
+	}
+	autoBackups.Lock()
+	done := autoBackups.done[configPath]
+	autoBackups.Unlock()
+	if done {
+		return
+	}
+	if _, err := createBackup(e); err != nil {
+		logError("failed to back up before writing Claude's config: %v", err)
+		// Not retried on every write of this process
+		markBackedUp(e)
+	}
+}
+
+// markBackedUp records that this process has a snapshot of e's files.
+func markBackedUp(e env.Env) {
+	configPath, err := getClaudeConfigPath(e)
+	if err != nil {
+		return
+	}
+	autoBackups.Lock()
+	defer autoBackups.Unlock()
+	if autoBackups.done == nil {
+		autoBackups.done = make(map[string]bool)
+	}
+	autoBackups.done[configPath] = true
+}
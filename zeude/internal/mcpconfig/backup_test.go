@@ -0,0 +1,74 @@
+package mcpconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const userClaudeConfig = `{
+  "projects": {"/src/app": {"allowedTools": ["Bash"]}},
+  "mcpServers": {
+    "mine": {"command": "my-server", "args": []}
+  }
+}
+`
+
+// failWrites makes atomic writes to path fail until the returned func is
+// called.
+func failWrites(path string) (undo func()) {
+	old := atomicWrite
+	atomicWrite = func(target string, data []byte, perm os.FileMode, opts atomicWriteOptions) error {
+		if target == path {
+			return errors.New("disk full")
+		}
+		return old(target, data, perm, opts)
+	}
+	return func() { atomicWrite = old }
+}
+
+// TestFailedMergeLeavesBackup fails the claude.json write of a sync and
+// checks the snapshot taken before it restores the user's file.
+func TestFailedMergeLeavesBackup(t *testing.T) {
+	d := newFakeDashboard(t, `{"configVersion": "v1", "mcpServers": {"team": {"command": "npx", "args": ["team-mcp"]}}}`)
+	e := syncEnv(t, d)
+	configPath, _ := getClaudeConfigPath(e)
+	if err := os.WriteFile(configPath, []byte(userClaudeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	undo := failWrites(configPath)
+	defer undo()
+
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); r.Err == nil {
+		t.Fatal("sync succeeded although claude.json couldn't be written")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != userClaudeConfig {
+		t.Errorf("claude.json changed by the failed write:\n%s", data)
+	}
+
+	dir, _ := paths.Backups(e)
+	backups := readBackups(dir)
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+	saved, err := os.ReadFile(filepath.Join(backups[0].Dir, "claude.json"))
+	if err != nil || string(saved) != userClaudeConfig {
+		t.Fatalf("backup holds %q (%v), want the user's claude.json", saved, err)
+	}
+
+	// Whatever happens to the file afterwards, the backup brings it back
+	undo()
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers": {`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restoreBackup(e, ""); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != userClaudeConfig {
+		t.Errorf("restored claude.json = %q, want the user's file", data)
+	}
+}
@@ -0,0 +1,64 @@
+package mcpconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// CachePath returns the path of the config cache (config-cache.json).
+func CachePath() (string, error) {
+	return getCachePath(env.OS{})
+}
+
+// ClearCacheOptions configures ClearCache.
+type ClearCacheOptions struct {
+	Env env.Env
+	// All also removes the lists of servers, hooks and skills Zeude manages.
+	// Without them the next sync can't tell which entries it added before.
+	All bool
+}
+
+// ClearCache removes the cached config so the next sync refetches it from
+// the dashboard. It returns whether a cache file was removed.
+func ClearCache(ctx context.Context, opts ClearCacheOptions) (bool, error) {
+	e := env.OrDefault(opts.Env)
+	var existed bool
+	err := withFileLock(ctx, e, func() (err error) {
+		existed, err = clearCacheFiles(e, opts.All)
+		return err
+	})
+	return existed, err
+}
+
+// clearCacheFiles does the work of ClearCache. The caller holds the lock.
+func clearCacheFiles(e env.Env, all bool) (bool, error) {
+	cachePath, err := getCachePath(e)
+	if err != nil {
+		return false, err
+	}
+	_, statErr := os.Stat(cachePath)
+	existed := statErr == nil
+
+	if all {
+		clearCache(e)
+		// clearCache predates skills and leaves their list in place
+		if err := updateManifest(e, func(m *Manifest) { m.Skills = nil }); err != nil {
+			return existed, fmt.Errorf("failed to clear managed skills list: %w", err)
+		}
+		return existed, nil
+	}
+	if err := removeFile(cachePath); err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to remove cache: %w", err)
+	}
+	return existed, nil
+}
+
+// etagVersion turns an ETag header into the bare config version.
+func etagVersion(etag string) string {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	return strings.Trim(etag, `"`)
+}
@@ -0,0 +1,122 @@
+package mcpconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEtagVersion(t *testing.T) {
+	tests := []struct {
+		etag string
+		want string
+	}{
+		{`"abc123"`, "abc123"},
+		{`W/"abc123"`, "abc123"},
+		{` "abc123" `, "abc123"},
+		{"abc123", "abc123"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := etagVersion(tt.etag); got != tt.want {
+			t.Errorf("etagVersion(%q) = %q, want %q", tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestIsCacheValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		cached  *CachedConfig
+		version string
+		want    bool
+	}{
+		{"no cache", nil, "v1", false},
+		{"no server version", &CachedConfig{Version: "v1"}, "", false},
+		{"same version", &CachedConfig{Version: "v1"}, "v1", true},
+		{"different version", &CachedConfig{Version: "v1"}, "v2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCacheValid(tt.cached, tt.version); got != tt.want {
+				t.Errorf("isCacheValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCachedConfigExpiry(t *testing.T) {
+	e := testEnv(t)
+	if err := saveCachedConfig(e, &ConfigResponse{ConfigVersion: "v1"}); err != nil {
+		t.Fatalf("saveCachedConfig: %v", err)
+	}
+	ttl := cacheTTL(e).Value
+
+	tests := []struct {
+		name        string
+		after       time.Duration
+		wantExpired bool
+	}{
+		{"just saved", 0, false},
+		{"before ttl", ttl - time.Second, false},
+		{"past ttl", ttl + time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := *e
+			clock.Advance(tt.after)
+			cached, expired := loadCachedConfig(&clock)
+			if cached == nil {
+				t.Fatal("loadCachedConfig returned no cache")
+			}
+			// An expired cache is still returned as an offline fallback
+			if expired != tt.wantExpired || cached.Version != "v1" {
+				t.Errorf("loadCachedConfig() = version %q, expired %v; want v1, %v", cached.Version, expired, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestFetchConfigETag(t *testing.T) {
+	var gotAuth, gotIfNoneMatch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotIfNoneMatch = r.Header.Get("Authorization"), r.Header.Get("If-None-Match")
+		if gotIfNoneMatch == "v2" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `W/"v2"`)
+		fmt.Fprint(w, `{"configVersion":"v2","serverCount":0}`)
+	}))
+	defer srv.Close()
+	e := testEnv(t, "ZEUDE_DASHBOARD_URL", srv.URL)
+
+	tests := []struct {
+		name       string
+		cached     string
+		wantErr    error
+		wantHeader string
+	}{
+		{"first fetch", "", nil, ""},
+		{"unchanged", "v2", ErrNotModified, "v2"},
+		{"changed", "v1", nil, "v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := fetchConfig(context.Background(), e, "zd_test", tt.cached)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("fetchConfig() error = %v, want %v", err, tt.wantErr)
+			}
+			if gotAuth != "Bearer zd_test" || gotIfNoneMatch != tt.wantHeader {
+				t.Errorf("request had Authorization %q, If-None-Match %q; want Bearer zd_test, %q", gotAuth, gotIfNoneMatch, tt.wantHeader)
+			}
+			if err == nil && config.etag != "v2" {
+				t.Errorf("etag = %q, want v2", config.etag)
+			}
+		})
+	}
+}
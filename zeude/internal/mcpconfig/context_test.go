@@ -0,0 +1,91 @@
+package mcpconfig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/httpclient"
+)
+
+// stallingTransport holds every request until its context is done, and
+// signals started as each one arrives.
+type stallingTransport struct{ started chan string }
+
+func (s stallingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.started <- req.URL.Path
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestCancelAbortsInFlightFetch(t *testing.T) {
+	e := testEnv(t, "ZEUDE_DASHBOARD_URL", "http://dashboard.test", "ZEUDE_AGENT_KEY", "zd_test", SyncTimeoutEnv, "1m")
+	stall := stallingTransport{started: make(chan string, 4)}
+	t.Cleanup(httpclient.SetTransport(stall))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan SyncResult)
+	go func() { done <- Sync(ctx, SyncOptions{Env: e, SkipStatusReport: true}) }()
+
+	if path := <-stall.started; path != "/api/config/_" {
+		t.Fatalf("first request to %s", path)
+	}
+	start := time.Now()
+	cancel()
+	select {
+	case r := <-done:
+		if r.Success || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("cancelled sync = %+v, want a context.Canceled error", r)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("sync took %v to notice the cancel", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync still running after its context was cancelled")
+	}
+}
+
+// memoryDashboard answers config and status requests in process, so no
+// connection goroutines are left behind to confuse a goroutine count.
+type memoryDashboard struct{ config string }
+
+func (d memoryDashboard) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "{}"
+	if req.URL.Path == "/api/config/_" {
+		body = d.config
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSyncLeavesNoGoroutines(t *testing.T) {
+	e := testEnv(t, "ZEUDE_DASHBOARD_URL", "http://dashboard.test", "ZEUDE_AGENT_KEY", "zd_test")
+	t.Cleanup(httpclient.SetTransport(memoryDashboard{config: hookConfig}))
+
+	before := runtime.NumGoroutine()
+	// Status reports on: they run inline under Sync's context
+	if r := Sync(context.Background(), SyncOptions{Env: e}); !r.Success {
+		t.Fatalf("sync failed: %+v", r)
+	}
+	// Give exiting goroutines a moment to finish
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("%d goroutines before Sync, %d after:\n%s", before, after, buf[:runtime.Stack(buf, true)])
+	}
+}
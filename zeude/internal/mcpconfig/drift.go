@@ -0,0 +1,224 @@
+package mcpconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/textdiff"
+)
+
+// Drift states reported by CheckDrift.
+const (
+	DriftModified = "modified" // differs from what the cached config would write
+	DriftMissing  = "missing"  // managed but gone from disk
+	DriftExtra    = "extra"    // managed, but the cached config no longer has it
+)
+
+// ErrNoCachedConfig means there is nothing to compare against yet.
+var ErrNoCachedConfig = errors.New("no cached config; run 'zeude sync' first")
+
+// DriftEntry is one Zeude-owned item whose on-disk state has drifted.
+type DriftEntry struct {
+	Kind  string `json:"kind"` // "server", "hook" or "skill"
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	State string `json:"state"`
+	Diff  string `json:"diff,omitempty"` // unified diff, expected -> on disk
+}
+
+// DriftReport compares every managed entry with the cached config.
+type DriftReport struct {
+	ConfigVersion string       `json:"configVersion"`
+	Checked       int          `json:"checked"`
+	Entries       []DriftEntry `json:"entries"`
+}
+
+// agentKeyLike matches agent keys so diffs never print them in full.
+var agentKeyLike = regexp.MustCompile(regexp.QuoteMeta(config.AgentKeyPrefix) + `[0-9A-Za-z_-]{8,}`)
+
+// CheckDrift verifies that each server, hook and skill in the managed lists
+// still matches what the cached config would generate. No network access
+// and nothing is modified.
+func CheckDrift() (*DriftReport, error) {
+	return checkDrift(env.OS{})
+}
+
+func checkDrift(e env.Env) (*DriftReport, error) {
+	cached, _ := loadCachedConfig(e)
+	if cached == nil {
+		return nil, ErrNoCachedConfig
+	}
+	cfg := &cached.Config
+	report := &DriftReport{ConfigVersion: cached.Version, Entries: []DriftEntry{}}
+	state := loadManagedState(e)
+
+	if err := serverDrift(e, report, cfg, state.Servers); err != nil {
+		return nil, err
+	}
+	if err := hookDrift(e, report, cfg, state.Hooks); err != nil {
+		return nil, err
+	}
+	if err := skillDrift(e, report, cfg, state.Skills); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func serverDrift(e env.Env, report *DriftReport, cfg *ConfigResponse, managed []string) error {
+	doc, err := readClaudeConfig(e)
+	if err != nil {
+		return err
+	}
+	configPath, err := getClaudeConfigPath(e)
+	if err != nil {
+		return err
+	}
+	onDisk := make(map[string][]byte, len(doc.mcpServers))
+	for _, m := range doc.mcpServers {
+		onDisk[m.Key] = m.Value // later duplicates win, as when Claude reads it
+	}
+
+	overrides := loadOverrides(e)
+	for _, key := range managed {
+		report.Checked++
+		entry := DriftEntry{Kind: "server", Name: key, Path: configPath}
+		server, known := cfg.MCPServers[key]
+		actual, present := onDisk[key]
+		switch {
+		case overrides.serverDisabled(key):
+			// Disabled locally: expected to be absent
+			if !present {
+				continue
+			}
+			entry.State = DriftExtra
+		case !known:
+			entry.State = DriftExtra
+		case !present:
+			entry.State = DriftMissing
+		default:
+			expected, err := serverEntry(server)
+			if err != nil {
+				return err
+			}
+			want, got := normalizeServer(expected), normalizeServer(actual)
+			if want == got {
+				continue
+			}
+			entry.State = DriftModified
+			entry.Diff = textdiff.Unified("mcpServers."+key+" (expected)", "mcpServers."+key, want, got)
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return nil
+}
+
+func hookDrift(e env.Env, report *DriftReport, cfg *ConfigResponse, managed []string) error {
+	hooksDir, err := getClaudeHooksDir(e)
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]Hook, len(cfg.Hooks))
+	for _, hook := range cfg.Hooks {
+		byPath[hookFilePath(hooksDir, hook)] = hook
+	}
+	agentKey, dashboardURL, hookLog := getAgentKey(e), getDashboardURL(e), hookLogPath(e)
+
+	for _, path := range managed {
+		report.Checked++
+		hook, known := byPath[path]
+		entry := DriftEntry{Kind: "hook", Name: hook.Name, Path: path}
+		if !known {
+			entry.Name = filepath.Base(path)
+		}
+		expected := func() []byte {
+			return hookScriptContent(hook, hookLog, agentKey, dashboardURL, cfg.UserEmail, cfg.Team)
+		}
+		if !fileDrift(&entry, known, expected) {
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+	return nil
+}
+
+func skillDrift(e env.Env, report *DriftReport, cfg *ConfigResponse, managed []string) error {
+	commandsDir, err := paths.ClaudeCommands(e)
+	if err != nil {
+		return err
+	}
+	byPath := make(map[string]Skill, len(cfg.Skills))
+	for _, skill := range cfg.Skills {
+		path := skillFilePath(commandsDir, skill)
+		// installSkills keeps the first skill for a path
+		if _, dup := byPath[path]; skill.Slug != "" && !dup {
+			byPath[path] = skill
+		}
+	}
+
+	for _, path := range managed {
+		report.Checked++
+		skill, known := byPath[path]
+		entry := DriftEntry{Kind: "skill", Name: "/" + skill.Command(), Path: path}
+		if !known {
+			entry.Name = filepath.Base(path)
+		}
+		expected := func() []byte { return skillFileContent(skill) }
+		if !fileDrift(&entry, known, expected) {
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+	return nil
+}
+
+// fileDrift fills in entry for a managed file and reports whether it is in
+// sync. An unreadable file counts as missing.
+func fileDrift(entry *DriftEntry, known bool, expected func() []byte) bool {
+	if !known {
+		entry.State = DriftExtra
+		return false
+	}
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		entry.State = DriftMissing
+		return false
+	}
+	want := string(expected())
+	if string(data) == want {
+		return true
+	}
+	entry.State = DriftModified
+	entry.Diff = textdiff.Unified(entry.Path+" (expected)", entry.Path, redactKeys(want), redactKeys(string(data)))
+	return false
+}
+
+// normalizeServer re-indents a server entry so formatting alone isn't
+// drift, and replaces env values with a short hash so diffs show that a
+// secret changed without printing it.
+func normalizeServer(data []byte) string {
+	obj, err := decodeJSONObject(data)
+	if err != nil {
+		return string(data) + "\n"
+	}
+	if envVars, ok := obj["env"].(map[string]interface{}); ok {
+		for name, value := range envVars {
+			sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+			envVars[name] = "<redacted " + hex.EncodeToString(sum[:4]) + ">"
+		}
+	}
+	out, err := marshalIndentJSON(obj, "", "  ")
+	if err != nil {
+		return string(data) + "\n"
+	}
+	return redactKeys(string(out)) + "\n"
+}
+
+func redactKeys(s string) string {
+	return agentKeyLike.ReplaceAllStringFunc(s, config.MaskAgentKey)
+}
@@ -0,0 +1,70 @@
+package mcpconfig
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Skill frontmatter keys, in the order skillFileContent writes them.
+const (
+	frontmatterName         = "name"
+	frontmatterDescription  = "description"
+	frontmatterAllowedTools = "allowed-tools"
+	frontmatterArgumentHint = "argument-hint"
+	frontmatterModel        = "model"
+)
+
+// yamlKeywords are plain scalars YAML reads as something other than a
+// string.
+var yamlKeywords = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true, ".inf": true, "-.inf": true, ".nan": true,
+}
+
+// yamlScalar returns s as a YAML scalar: as is when YAML reads it back as
+// the same string, double-quoted otherwise. Plain strings stay plain, so
+// frontmatter written before quoting existed is unchanged.
+func yamlScalar(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || yamlKeywords[strings.ToLower(s)] ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") ||
+		strings.IndexFunc(s, func(r rune) bool { return r < ' ' || r == 0x7f }) >= 0 {
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// yamlUnquote reads back a scalar from one frontmatter line: double-quoted
+// (the form yamlScalar writes), single-quoted, or plain.
+func yamlUnquote(s string) string {
+	s = strings.TrimSpace(s)
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+		return s[1 : len(s)-1]
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
+
+// yamlList reads a one-line list value: a flow sequence ("[Read, Edit]")
+// or the comma-separated string Claude also accepts ("Read, Edit").
+func yamlList(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = yamlUnquote(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
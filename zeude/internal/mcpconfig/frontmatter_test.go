@@ -0,0 +1,172 @@
+package mcpconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Review a PR", "Review a PR"},
+		{"Bash(git diff:*)", "Bash(git diff:*)"},
+		{"café", "café"},
+		{"", `""`},
+		{" padded", `" padded"`},
+		{"yes", `"yes"`},
+		{"Null", `"Null"`},
+		{"1.5", `"1.5"`},
+		{"42", `"42"`},
+		{"- dash", `"- dash"`},
+		{"#tag", `"#tag"`},
+		{"[issue-number]", `"[issue-number]"`},
+		{"<pr> [--draft]", "<pr> [--draft]"},
+		{"note: read this", `"note: read this"`},
+		{"trailing:", `"trailing:"`},
+		{"a #comment", `"a #comment"`},
+		{`"quoted"`, `"\"quoted\""`},
+		{"'single'", `"'single'"`},
+		{"two\nlines", `"two\nlines"`},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSkillFrontmatterRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		skill Skill
+	}{
+		{"name only", Skill{Name: "review", Content: "Review the diff.\n"}},
+		{"every field", Skill{
+			Name:         "release",
+			Description:  "Cut a release",
+			AllowedTools: []string{"Bash(git tag:*)", "Read", "Edit"},
+			ArgumentHint: "<version> [--dry-run]",
+			Model:        "claude-sonnet-4-5",
+			Content:      "Tag $ARGUMENTS.\n",
+		}},
+		{"values that need quoting", Skill{
+			Name:         "yes",
+			Description:  "note: runs \"deploy\" #prod",
+			AllowedTools: []string{"- odd", "true", "Bash(echo 'hi':*)"},
+			ArgumentHint: "[env]",
+			Model:        "1.5",
+			Content:      "Deploy.\n",
+		}},
+		{"control characters", Skill{Name: "tab\tname", Description: "line\nbreak", Content: "x"}},
+		{"content with a separator", Skill{Name: "doc", Content: "\nbefore\n---\nafter\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := skillFileContent(tt.skill)
+			got := ParseSkillFile(data)
+			if !reflect.DeepEqual(got, tt.skill) {
+				t.Errorf("round trip = %+v, want %+v\nfile:\n%s", got, tt.skill, data)
+			}
+			if again := skillFileContent(got); string(again) != string(data) {
+				t.Errorf("second render differs:\n%s\nvs\n%s", again, data)
+			}
+		})
+	}
+}
+
+func TestParseSkillFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want Skill
+	}{
+		{
+			name: "no frontmatter",
+			file: "Just a prompt.\n",
+			want: Skill{Content: "Just a prompt.\n"},
+		},
+		{
+			name: "unterminated frontmatter",
+			file: "---\nname: x\nbody",
+			want: Skill{Content: "---\nname: x\nbody"},
+		},
+		{
+			name: "flow list and single quotes",
+			file: "---\nname: 'it''s'\nallowed-tools: [Read, \"Bash(ls:*)\"]\n---\nbody",
+			want: Skill{Name: "it's", AllowedTools: []string{"Read", "Bash(ls:*)"}, Content: "body"},
+		},
+		{
+			name: "comma-separated tools",
+			file: "---\nallowed-tools: Read, Edit\nmodel: opus\n---\n\nbody",
+			want: Skill{AllowedTools: []string{"Read", "Edit"}, Model: "opus", Content: "body"},
+		},
+		{
+			name: "CRLF line endings",
+			file: "---\r\nname: x\r\nargument-hint: <file>\r\n---\r\n\r\nbody\r\n",
+			want: Skill{Name: "x", ArgumentHint: "<file>", Content: "body\n"},
+		},
+		{
+			name: "unknown keys ignored",
+			file: "---\nname: x\ncolor: blue\nallowed-tools:\n  - Read\nmodel: haiku\n---\nbody",
+			want: Skill{Name: "x", AllowedTools: []string{"Read"}, Model: "haiku", Content: "body"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSkillFile([]byte(tt.file)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSkillFile = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSyncLeavesUnchangedSkillsAlone checks that skills with and without
+// the optional frontmatter are written once and not again by later syncs.
+func TestSyncLeavesUnchangedSkillsAlone(t *testing.T) {
+	d := newFakeDashboard(t, `{
+  "configVersion": "v1",
+  "skills": [
+    {"name": "plain", "slug": "plain", "content": "Plain."},
+    {"name": "tools", "slug": "tools", "content": "Tools.", "allowedTools": ["Read", "Bash(git:*)"], "argumentHint": "[ref]", "model": "haiku"}
+  ]
+}`)
+	e := syncEnv(t, d)
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success || r.SkillCount != 2 {
+		t.Fatalf("first sync: %+v", r)
+	}
+
+	dir := filepath.Join(e.Home, ".claude", "commands")
+	first := map[string]os.FileInfo{}
+	for _, name := range []string{"plain.md", "tools.md"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("skill not installed: %v", err)
+		}
+		first[name] = info
+	}
+	if got := ParseSkillFile(mustRead(t, filepath.Join(dir, "tools.md"))); got.ArgumentHint != "[ref]" || len(got.AllowedTools) != 2 {
+		t.Errorf("tools.md frontmatter = %+v", got)
+	}
+
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true, ForceRefresh: true}); !r.Success {
+		t.Fatalf("second sync: %+v", r)
+	}
+	for name, before := range first {
+		if after, _ := os.Stat(filepath.Join(dir, name)); !os.SameFile(before, after) {
+			t.Errorf("second sync rewrote %s", name)
+		}
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
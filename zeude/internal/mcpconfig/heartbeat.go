@@ -0,0 +1,236 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often a machine checks in when the
+	// config doesn't say otherwise.
+	DefaultHeartbeatInterval = 12 * time.Hour
+	// HeartbeatIntervalKey sets the interval in ~/.zeude/config as a Go
+	// duration (e.g. heartbeat_interval=6h); 0 disables heartbeats.
+	HeartbeatIntervalKey = "heartbeat_interval"
+	// NoHeartbeatEnv opts out of heartbeats and session-start reports.
+	NoHeartbeatEnv = "ZEUDE_NO_HEARTBEAT"
+
+	// heartbeatClaimStale is when a leftover claim marker from a crashed
+	// process is cleared.
+	heartbeatClaimStale = time.Minute
+	// collectorDialTimeout bounds the reachability probe.
+	collectorDialTimeout = 500 * time.Millisecond
+)
+
+// Heartbeat tells the dashboard this machine is still running Zeude.
+type Heartbeat struct {
+	Version            string    `json:"version"`
+	OS                 string    `json:"os"`
+	Arch               string    `json:"arch"`
+	LastSyncAt         time.Time `json:"lastSyncAt"`
+	LastSyncSource     string    `json:"lastSyncSource"` // "dashboard" or "cache"
+	HookCount          int       `json:"hookCount"`
+	ServerCount        int       `json:"serverCount"`
+	CollectorReachable bool      `json:"collectorReachable"`
+}
+
+// HeartbeatReport is the status payload when a heartbeat is sent on its own.
+type HeartbeatReport struct {
+	Heartbeat *Heartbeat `json:"heartbeat"`
+}
+
+// SessionStart tells the dashboard claude was launched on this machine.
+type SessionStart struct {
+	SessionID     string    `json:"sessionId"`
+	Version       string    `json:"version"`                 // the shim's
+	ClaudeVersion string    `json:"claudeVersion,omitempty"` // as `claude --version` prints it
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	StartedAt     time.Time `json:"startedAt"`
+}
+
+// SessionStartReport is the status payload for a session start.
+type SessionStartReport struct {
+	SessionStart *SessionStart `json:"sessionStart"`
+}
+
+// heartbeatClaim records that this process owns the current heartbeat slot.
+type heartbeatClaim struct {
+	path string
+	prev []byte // previous timestamp file content, nil if there was none
+}
+
+// release gives the slot back after a failed send so the next launch retries.
+func (c *heartbeatClaim) release() {
+	if c.prev == nil {
+		os.Remove(c.path)
+		return
+	}
+	writeFileAtomicWithOptions(c.path, c.prev, 0600, atomicWriteOptions{NoDirSync: true})
+}
+
+// heartbeatInterval returns the configured interval; 0 means disabled.
+func heartbeatInterval() time.Duration {
+	v := config.Get(HeartbeatIntervalKey)
+	if v == "" {
+		return DefaultHeartbeatInterval
+	}
+	if v == "0" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		logDebug("invalid %s %q, using default", HeartbeatIntervalKey, v)
+		return DefaultHeartbeatInterval
+	}
+	return d
+}
+
+// heartbeatSuppressed reports whether any opt-out switch forbids heartbeats.
+func heartbeatSuppressed(e env.Env) bool {
+	return config.Offline(e) || config.TelemetryDisabled(e) || config.NonEssentialTrafficDisabled(e) ||
+		config.IsTruthy(e.Getenv(NoHeartbeatEnv))
+}
+
+// SessionReportsEnabled reports whether ReportSessionStart would send
+// anything, so callers can skip gathering the report.
+func SessionReportsEnabled() bool {
+	e := env.OS{}
+	return !heartbeatSuppressed(e) && getAgentKey(e) != ""
+}
+
+// ReportSessionStart sends start to the dashboard unless heartbeats are
+// turned off. OS, Arch and StartedAt are filled in when left empty.
+func ReportSessionStart(ctx context.Context, start SessionStart) error {
+	return reportSessionStart(ctx, env.OS{}, start)
+}
+
+func reportSessionStart(ctx context.Context, e env.Env, start SessionStart) error {
+	agentKey := getAgentKey(e)
+	if heartbeatSuppressed(e) || agentKey == "" {
+		return nil
+	}
+	if start.Version == "" {
+		start.Version = "dev"
+	}
+	if start.OS == "" {
+		start.OS, start.Arch = runtime.GOOS, runtime.GOARCH
+	}
+	if start.StartedAt.IsZero() {
+		start.StartedAt = e.Now().UTC()
+	}
+	if err := reportStatusToAPI(ctx, e, agentKey, SessionStartReport{SessionStart: &start}); err != nil {
+		return err
+	}
+	logDebug("reported session start %s", start.SessionID)
+	return nil
+}
+
+// heartbeatDue reports whether interval has passed since the timestamp in data.
+func heartbeatDue(data []byte, now time.Time, interval time.Duration) bool {
+	last, err := time.Parse(time.RFC3339, string(bytes.TrimSpace(data)))
+	if err != nil {
+		return true
+	}
+	// A timestamp in the future (clock moved back) counts as due
+	return now.Sub(last) >= interval || last.After(now)
+}
+
+// claimHeartbeat builds a heartbeat if one is due and claims the slot by
+// advancing the timestamp before sending, so parallel launches don't
+// double-send. It returns nil when no heartbeat should be sent.
+func claimHeartbeat(ctx context.Context, e env.Env, version string, result SyncResult) (*Heartbeat, *heartbeatClaim) {
+	if !result.Success || heartbeatSuppressed(e) {
+		return nil, nil
+	}
+	interval := heartbeatInterval()
+	if interval == 0 {
+		return nil, nil
+	}
+
+	path, err := paths.LastHeartbeat(e)
+	if err != nil {
+		return nil, nil
+	}
+	now := e.Now()
+	if data, _ := os.ReadFile(path); !heartbeatDue(data, now, interval) {
+		return nil, nil
+	}
+
+	// An exclusive marker serializes the check-and-advance below
+	marker := path + ".claim"
+	f, err := os.OpenFile(marker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if info, statErr := os.Stat(marker); statErr == nil && time.Since(info.ModTime()) > heartbeatClaimStale {
+			os.Remove(marker)
+		}
+		return nil, nil
+	}
+	f.Close()
+	defer os.Remove(marker)
+
+	prev, err := os.ReadFile(path)
+	if err != nil {
+		prev = nil
+	}
+	if !heartbeatDue(prev, now, interval) {
+		// Another launch sent it while we were checking
+		return nil, nil
+	}
+	if err := writeFileAtomicWithOptions(path, []byte(now.UTC().Format(time.RFC3339)+"\n"), 0600, atomicWriteOptions{NoDirSync: true}); err != nil {
+		logDebug("failed to record heartbeat: %v", err)
+		return nil, nil
+	}
+
+	if version == "" {
+		version = "dev"
+	}
+	source := "dashboard"
+	if result.FromCache {
+		source = "cache"
+	}
+	hb := &Heartbeat{
+		Version:            version,
+		OS:                 runtime.GOOS,
+		Arch:               runtime.GOARCH,
+		LastSyncAt:         now.UTC(),
+		LastSyncSource:     source,
+		HookCount:          result.HookCount,
+		ServerCount:        result.ServerCount,
+		CollectorReachable: collectorReachable(ctx),
+	}
+	return hb, &heartbeatClaim{path: path, prev: prev}
+}
+
+// collectorReachable reports whether the collector accepts TCP connections.
+func collectorReachable(ctx context.Context) bool {
+	host, port, _, err := config.ParseEndpoint(config.GetCollectorEndpoint(config.DefaultCollectorEndpoint))
+	if err != nil || host == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, collectorDialTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// reportHeartbeat sends a heartbeat on its own.
+func reportHeartbeat(ctx context.Context, e env.Env, agentKey string, hb *Heartbeat) error {
+	if err := reportStatusToAPI(ctx, e, agentKey, HeartbeatReport{Heartbeat: hb}); err != nil {
+		return err
+	}
+	logDebug("sent heartbeat (version %s)", hb.Version)
+	return nil
+}
@@ -0,0 +1,182 @@
+package mcpconfig
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// heartbeatEnv returns a test environment whose ~/.zeude exists, with the
+// process home (read by config.Get) a fresh temp dir holding configLines.
+// The collector endpoint is a closed local port, so the reachability probe
+// fails fast.
+func heartbeatEnv(t *testing.T, configLines string) *env.Fake {
+	t.Helper()
+	home := useTestHome(t)
+	if configLines != "" {
+		path, _ := paths.Config(env.OS{})
+		os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(configLines), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	t.Setenv("ZEUDE_ENDPOINT", "http://"+ln.Addr().String())
+
+	e := testEnv(t)
+	e.Home = home
+	dir, _ := paths.Dir(e)
+	os.MkdirAll(dir, 0700)
+	return e
+}
+
+var syncedOK = SyncResult{Success: true, HookCount: 2, ServerCount: 1}
+
+func TestHeartbeatDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	stamp := func(d time.Duration) []byte { return []byte(now.Add(d).Format(time.RFC3339) + "\n") }
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"never sent", nil, true},
+		{"unreadable", []byte("yesterday"), true},
+		{"recent", stamp(-time.Hour), false},
+		{"just under", stamp(-12*time.Hour + time.Second), false},
+		{"exactly the interval", stamp(-12 * time.Hour), true},
+		{"overdue", stamp(-48 * time.Hour), true},
+		{"clock moved back", stamp(time.Hour), true},
+	}
+	for _, tt := range tests {
+		if got := heartbeatDue(tt.data, now, DefaultHeartbeatInterval); got != tt.want {
+			t.Errorf("%s: heartbeatDue = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClaimHeartbeatInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		steps  []time.Duration // clock advance before each claim
+		want   []bool
+	}{
+		{"default interval", "", []time.Duration{0, 0, 11 * time.Hour, time.Hour}, []bool{true, false, false, true}},
+		{"configured interval", "heartbeat_interval=1h\n", []time.Duration{0, 30 * time.Minute, 30 * time.Minute}, []bool{true, false, true}},
+		{"disabled", "heartbeat_interval=0\n", []time.Duration{0, 24 * time.Hour}, []bool{false, false}},
+		{"invalid falls back", "heartbeat_interval=soon\n", []time.Duration{0, time.Hour, 12 * time.Hour}, []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := heartbeatEnv(t, tt.config)
+			for i, d := range tt.steps {
+				e.Advance(d)
+				hb, _ := claimHeartbeat(context.Background(), e, "1.2.0", syncedOK)
+				if (hb != nil) != tt.want[i] {
+					t.Fatalf("claim %d: heartbeat = %+v, want sent %v", i+1, hb, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClaimHeartbeatContents(t *testing.T) {
+	e := heartbeatEnv(t, "")
+	hb, _ := claimHeartbeat(context.Background(), e, "", SyncResult{Success: true, FromCache: true, HookCount: 2, ServerCount: 1})
+	if hb == nil {
+		t.Fatal("no heartbeat")
+	}
+	if hb.Version != "dev" || hb.LastSyncSource != "cache" || hb.HookCount != 2 || hb.ServerCount != 1 ||
+		!hb.LastSyncAt.Equal(e.Now()) || hb.CollectorReachable {
+		t.Errorf("heartbeat = %+v", hb)
+	}
+
+	if hb, _ := claimHeartbeat(context.Background(), heartbeatEnv(t, ""), "1.2.0", SyncResult{}); hb != nil {
+		t.Errorf("heartbeat after a failed sync: %+v", hb)
+	}
+}
+
+func TestHeartbeatReleaseRetries(t *testing.T) {
+	e := heartbeatEnv(t, "")
+	path, _ := paths.LastHeartbeat(e)
+
+	// First ever claim: a failed send removes the timestamp
+	_, claim := claimHeartbeat(context.Background(), e, "1.2.0", syncedOK)
+	claim.release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("timestamp left after releasing the first claim: %v", err)
+	}
+
+	// Later claims put the previous timestamp back
+	claimHeartbeat(context.Background(), e, "1.2.0", syncedOK)
+	sent, _ := os.ReadFile(path)
+	e.Advance(13 * time.Hour)
+	_, claim = claimHeartbeat(context.Background(), e, "1.2.0", syncedOK)
+	claim.release()
+	if data, _ := os.ReadFile(path); string(data) != string(sent) {
+		t.Errorf("timestamp after release = %q, want %q", data, sent)
+	}
+	if hb, _ := claimHeartbeat(context.Background(), e, "1.2.0", syncedOK); hb == nil {
+		t.Error("released heartbeat not retried")
+	}
+}
+
+func TestParallelClaimsSendOnce(t *testing.T) {
+	e := heartbeatEnv(t, "")
+	path, _ := paths.LastHeartbeat(e)
+	// A claim in progress elsewhere holds the marker
+	if err := os.WriteFile(path+".claim", nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if hb, _ := claimHeartbeat(context.Background(), e, "1.2.0", syncedOK); hb != nil {
+		t.Errorf("heartbeat sent while another launch holds the claim: %+v", hb)
+	}
+}
+
+func TestHeartbeatSuppressed(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		config string
+	}{
+		{"offline", config.OfflineEnv, ""},
+		{"offline in config", "", "offline=true\n"},
+		{"telemetry opt-out", config.DisableTelemetryEnv, ""},
+		{"telemetry off in config", "", "telemetry=off\n"},
+		{"non-essential traffic", config.NonEssentialTrafficEnv, ""},
+		{"heartbeat opt-out", NoHeartbeatEnv, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := heartbeatEnv(t, tt.config)
+			if tt.env != "" {
+				e.Vars[tt.env] = "1"
+			}
+			if !heartbeatSuppressed(e) {
+				t.Error("heartbeat not suppressed")
+			}
+			if hb, _ := claimHeartbeat(context.Background(), e, "1.2.0", syncedOK); hb != nil {
+				t.Errorf("heartbeat claimed: %+v", hb)
+			}
+			path, _ := paths.LastHeartbeat(e)
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("suppressed heartbeat recorded a timestamp: %v", err)
+			}
+		})
+	}
+	if heartbeatSuppressed(heartbeatEnv(t, "")) {
+		t.Error("heartbeat suppressed with no switch set")
+	}
+}
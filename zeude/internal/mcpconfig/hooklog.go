@@ -0,0 +1,305 @@
+package mcpconfig
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// hookLogPath returns ~/.zeude/logs/hooks.log, or "" if it can't be
+// resolved, in which case hooks are written without logging.
+func hookLogPath(e env.Env) string {
+	dir, err := paths.Logs(e)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, logging.HookLogFileName)
+}
+
+// hookLoggingWrapper returns the preamble that makes a generated hook log
+// its runs to logPath: the script reruns itself as a child with
+// ZEUDE_HOOK_CHILD set, passes its stdin and stdout through, replays its
+// stderr, appends a logging.HookRecord line, and exits with the child's
+// code. Logging failures are swallowed, and if the child can't be started
+// the hook runs inline, unlogged, so logging never changes what the hook
+// does. See logging.HookLogFileName for the line format.
+func hookLoggingWrapper(hook Hook, logPath string) string {
+	if logPath == "" {
+		return ""
+	}
+	// Tabs and newlines would break the line format
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	name, event := clean.Replace(hook.Name), clean.Replace(hook.Event)
+
+	var tmpl string
+	var quote func(string) string
+	switch hook.ScriptType {
+	case "python":
+		tmpl = pythonHookLogging
+		quote = func(s string) string { return "'" + escapePythonValue(s) + "'" }
+	case "node":
+		tmpl = nodeHookLogging
+		quote = func(s string) string { return "'" + escapeJSValue(s) + "'" }
+	case "deno":
+		tmpl = denoHookLogging
+		quote = func(s string) string { return "'" + escapeJSValue(s) + "'" }
+	case "ruby":
+		tmpl = rubyHookLogging
+		quote = func(s string) string { return "'" + escapeRubyValue(s) + "'" }
+	case "powershell":
+		tmpl = powershellHookLogging
+		quote = func(s string) string { return "'" + escapePowerShellValue(s) + "'" }
+	default:
+		tmpl = shellHookLogging
+		quote = func(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" }
+	}
+	return strings.NewReplacer(
+		"@LOG@", quote(logPath),
+		"@HOOK@", quote(name),
+		"@EVENT@", quote(event),
+		"@MAX@", strconv.Itoa(logging.HookLogMaxSize),
+		"@LIMIT@", strconv.Itoa(logging.HookStderrLimit),
+	).Replace(tmpl)
+}
+
+const shellHookLogging = `# Zeude hook logging: run the hook as a child and record how it went
+if [ -z "$ZEUDE_HOOK_CHILD" ] && __zeude_err=$(mktemp 2>/dev/null); then
+  __zeude_ms() {
+    if [ -n "$EPOCHREALTIME" ]; then
+      local t=${EPOCHREALTIME/[.,]/}
+      echo $(( 10#$t / 1000 ))
+    else
+      echo $(( $(date +%s) * 1000 ))
+    fi
+  }
+  __zeude_start=$(__zeude_ms)
+  ZEUDE_HOOK_CHILD=1 "${BASH:-bash}" "$0" "$@" 2>"$__zeude_err"
+  __zeude_rc=$?
+  cat "$__zeude_err" >&2
+  {
+    umask 077
+    __zeude_log=@LOG@
+    mkdir -p "${__zeude_log%/*}"
+    if [ -f "$__zeude_log" ] && [ "$(wc -c <"$__zeude_log")" -gt @MAX@ ]; then
+      mv -f "$__zeude_log" "$__zeude_log.1"
+    fi
+    printf '%s\t%s\t%s\t%s\t%s\t%s\n' "$(date -u +%Y-%m-%dT%H:%M:%SZ)" @HOOK@ @EVENT@ \
+      "$__zeude_rc" "$(( $(__zeude_ms) - __zeude_start ))" \
+      "$(head -c @LIMIT@ "$__zeude_err" | tr '\t\r\n' '   ')" >>"$__zeude_log"
+  } 2>/dev/null
+  rm -f "$__zeude_err"
+  exit $__zeude_rc
+fi
+unset ZEUDE_HOOK_CHILD
+
+`
+
+const pythonHookLogging = `# Zeude hook logging: run the hook as a child and record how it went
+import os
+import sys
+if not os.environ.get('ZEUDE_HOOK_CHILD'):
+    import subprocess
+    import time
+
+    def _zeude_log_hook(rc, ms, err):
+        try:
+            path = @LOG@
+            os.makedirs(os.path.dirname(path), exist_ok=True)
+            try:
+                if os.path.getsize(path) > @MAX@:
+                    os.replace(path, path + '.1')
+            except OSError:
+                pass
+            msg = err[:@LIMIT@].decode('utf-8', 'replace')
+            for c in '\t\r\n':
+                msg = msg.replace(c, ' ')
+            line = '\t'.join([time.strftime('%Y-%m-%dT%H:%M:%SZ', time.gmtime()), @HOOK@, @EVENT@, str(rc), str(ms), msg]) + '\n'
+            fd = os.open(path, os.O_WRONLY | os.O_APPEND | os.O_CREAT, 0o600)
+            try:
+                os.write(fd, line.encode('utf-8'))
+            finally:
+                os.close(fd)
+        except Exception:
+            pass
+
+    _zeude_start = time.time()
+    try:
+        _zeude_proc = subprocess.run([sys.executable, os.path.abspath(__file__)] + sys.argv[1:],
+                                     env=dict(os.environ, ZEUDE_HOOK_CHILD='1'), stderr=subprocess.PIPE)
+    except OSError:
+        _zeude_proc = None
+    if _zeude_proc is not None:
+        _zeude_rc = _zeude_proc.returncode if _zeude_proc.returncode >= 0 else 128 - _zeude_proc.returncode
+        try:
+            sys.stderr.buffer.write(_zeude_proc.stderr)
+            sys.stderr.flush()
+        except Exception:
+            pass
+        _zeude_log_hook(_zeude_rc, int((time.time() - _zeude_start) * 1000), _zeude_proc.stderr)
+        sys.exit(_zeude_rc)
+os.environ.pop('ZEUDE_HOOK_CHILD', None)
+
+`
+
+// nodeHookLogging uses only globals and no top-level return, so it works in
+// CommonJS and ES module hooks alike. Node before getBuiltinModule has no
+// require in an ES module, and runs those hooks inline, unlogged.
+const nodeHookLogging = `// Zeude hook logging: run the hook as a child and record how it went
+if (!process.env.ZEUDE_HOOK_CHILD) {
+  const zeudeLoad = typeof process.getBuiltinModule === 'function' ? process.getBuiltinModule
+    : typeof require === 'function' ? require : null;
+  const zeudeStart = Date.now();
+  const zeudeChild = zeudeLoad && zeudeLoad('child_process').spawnSync(process.execPath, process.execArgv.concat(process.argv.slice(1)), {
+    stdio: ['inherit', 'inherit', 'pipe'],
+    env: Object.assign({}, process.env, { ZEUDE_HOOK_CHILD: '1' }),
+    maxBuffer: 64 * 1024 * 1024,
+  });
+  if (zeudeChild && zeudeChild.pid) {
+    const rc = zeudeChild.status !== null ? zeudeChild.status : 1;
+    const err = zeudeChild.stderr || Buffer.alloc(0);
+    const fs = zeudeLoad('fs');
+    try {
+      for (let n = 0; n < err.length;) n += fs.writeSync(2, err, n);
+    } catch (e) {}
+    try {
+      const logPath = @LOG@;
+      fs.mkdirSync(zeudeLoad('path').dirname(logPath), { recursive: true });
+      try {
+        if (fs.statSync(logPath).size > @MAX@) fs.renameSync(logPath, logPath + '.1');
+      } catch (e) {}
+      const msg = err.subarray(0, @LIMIT@).toString('utf8').replace(/[\t\r\n]/g, ' ');
+      const line = [new Date().toISOString().replace(/\.\d+Z$/, 'Z'), @HOOK@, @EVENT@, rc, Date.now() - zeudeStart, msg].join('\t') + '\n';
+      fs.appendFileSync(logPath, line, { mode: 0o600 });
+    } catch (e) {}
+    // Exiting here stands in for a top-level return; stderr is already
+    // written synchronously
+    process.exit(rc);
+  }
+}
+delete process.env.ZEUDE_HOOK_CHILD;
+
+`
+
+const denoHookLogging = `// Zeude hook logging: run the hook as a child and record how it went
+if (!Deno.env.get('ZEUDE_HOOK_CHILD')) {
+  const zeudeStart = Date.now();
+  let zeudeChild = null;
+  try {
+    zeudeChild = new Deno.Command(Deno.execPath(), {
+      args: ['run', '-A', import.meta.filename, ...Deno.args],
+      stdin: 'inherit',
+      stdout: 'inherit',
+      stderr: 'piped',
+      env: { ZEUDE_HOOK_CHILD: '1' },
+    }).outputSync();
+  } catch (e) {}
+  if (zeudeChild) {
+    const rc = zeudeChild.code;
+    const err = zeudeChild.stderr;
+    try {
+      for (let n = 0; n < err.length;) n += Deno.stderr.writeSync(err.subarray(n));
+    } catch (e) {}
+    try {
+      const logPath = @LOG@;
+      Deno.mkdirSync(logPath.replace(/[\\/][^\\/]*$/, ''), { recursive: true });
+      try {
+        if (Deno.statSync(logPath).size > @MAX@) Deno.renameSync(logPath, logPath + '.1');
+      } catch (e) {}
+      const msg = new TextDecoder().decode(err.subarray(0, @LIMIT@)).replace(/[\t\r\n]/g, ' ');
+      const line = [new Date().toISOString().replace(/\.\d+Z$/, 'Z'), @HOOK@, @EVENT@, rc, Date.now() - zeudeStart, msg].join('\t') + '\n';
+      Deno.writeTextFileSync(logPath, line, { append: true, mode: 0o600 });
+    } catch (e) {}
+    Deno.exit(rc);
+  }
+}
+Deno.env.delete('ZEUDE_HOOK_CHILD');
+
+`
+
+const rubyHookLogging = `# Zeude hook logging: run the hook as a child and record how it went
+unless ENV['ZEUDE_HOOK_CHILD']
+  require 'rbconfig'
+  zeude_start = Process.clock_gettime(Process::CLOCK_MONOTONIC, :millisecond)
+  zeude_r, zeude_w = IO.pipe
+  zeude_pid = begin
+    Process.spawn({ 'ZEUDE_HOOK_CHILD' => '1' }, RbConfig.ruby, File.expand_path(__FILE__), *ARGV, err: zeude_w)
+  rescue SystemCallError
+    nil
+  end
+  zeude_w.close
+  if zeude_pid
+    zeude_err = zeude_r.read
+    zeude_r.close
+    Process.wait(zeude_pid)
+    zeude_rc = $?.exitstatus || 128 + $?.termsig.to_i
+    begin
+      $stderr.write(zeude_err)
+      $stderr.flush
+    rescue StandardError
+    end
+    begin
+      require 'fileutils'
+      path = @LOG@
+      FileUtils.mkdir_p(File.dirname(path))
+      File.rename(path, path + '.1') if File.size?(path).to_i > @MAX@
+      msg = zeude_err.byteslice(0, @LIMIT@).force_encoding('UTF-8').scrub.tr("\t\r\n", '   ')
+      ms = Process.clock_gettime(Process::CLOCK_MONOTONIC, :millisecond) - zeude_start
+      line = [Time.now.utc.strftime('%Y-%m-%dT%H:%M:%SZ'), @HOOK@, @EVENT@, zeude_rc, ms, msg].join("\t") + "\n"
+      File.open(path, File::WRONLY | File::APPEND | File::CREAT, 0o600) { |f| f.write(line) }
+    rescue StandardError
+    end
+    exit zeude_rc
+  end
+  zeude_r.close
+end
+ENV.delete('ZEUDE_HOOK_CHILD')
+
+`
+
+const powershellHookLogging = `# Zeude hook logging: run the hook as a child and record how it went
+if (-not $env:ZEUDE_HOOK_CHILD) {
+  $zeudeRc = $null
+  try {
+    $zeudeStart = [Diagnostics.Stopwatch]::StartNew()
+    $zeudePsi = [Diagnostics.ProcessStartInfo]::new((Get-Process -Id $PID).Path)
+    foreach ($zeudeArg in @('-NoProfile', '-NonInteractive', '-File', $PSCommandPath) + $args) {
+      $zeudePsi.ArgumentList.Add($zeudeArg)
+    }
+    $zeudePsi.UseShellExecute = $false
+    $zeudePsi.RedirectStandardError = $true
+    $zeudePsi.Environment['ZEUDE_HOOK_CHILD'] = '1'
+    $zeudeProc = [Diagnostics.Process]::Start($zeudePsi)
+    $zeudeErr = $zeudeProc.StandardError.ReadToEnd()
+    $zeudeProc.WaitForExit()
+    $zeudeRc = $zeudeProc.ExitCode
+  } catch {}
+  if ($null -ne $zeudeRc) {
+    try { [Console]::Error.Write($zeudeErr) } catch {}
+    try {
+      $zeudeLog = @LOG@
+      [void][IO.Directory]::CreateDirectory([IO.Path]::GetDirectoryName($zeudeLog))
+      if ((Test-Path -LiteralPath $zeudeLog) -and (Get-Item -LiteralPath $zeudeLog).Length -gt @MAX@) {
+        Move-Item -LiteralPath $zeudeLog -Destination ($zeudeLog + '.1') -Force
+      }
+      $zeudeMsg = $zeudeErr
+      if ($zeudeMsg.Length -gt @LIMIT@) { $zeudeMsg = $zeudeMsg.Substring(0, @LIMIT@) }
+      $zeudeMsg = $zeudeMsg -replace '[\t\r\n]', ' '
+      $zeudeLine = @((Get-Date).ToUniversalTime().ToString('s') + 'Z', @HOOK@, @EVENT@, $zeudeRc, $zeudeStart.ElapsedMilliseconds, $zeudeMsg) -join [char]9
+      # Create the log owner-only, like the other languages; where the
+      # runtime can't, logging fails and the hook is unaffected
+      $zeudeOpts = [IO.FileStreamOptions]@{ Mode = 'Append'; Access = 'Write'; Share = 'ReadWrite' }
+      if (-not $IsWindows) { $zeudeOpts.UnixCreateMode = [IO.UnixFileMode]'UserRead, UserWrite' }
+      $zeudeBytes = [Text.Encoding]::UTF8.GetBytes($zeudeLine + [char]10)
+      $zeudeStream = [IO.FileStream]::new($zeudeLog, $zeudeOpts)
+      try { $zeudeStream.Write($zeudeBytes, 0, $zeudeBytes.Length) } finally { $zeudeStream.Dispose() }
+    } catch {}
+    exit $zeudeRc
+  }
+}
+Remove-Item Env:ZEUDE_HOOK_CHILD -ErrorAction SilentlyContinue
+
+`
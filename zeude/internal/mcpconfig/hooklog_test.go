@@ -0,0 +1,224 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeude/zeude/internal/logging"
+)
+
+// hookLogLangs are the script types whose logging wrapper is run for real,
+// each with a body that echoes its stdin, writes a line to stderr and exits
+// with the given code.
+var hookLogLangs = []struct {
+	name, scriptType, interpreter, body string
+	esm                                 bool
+}{
+	{"bash", "bash", "bash", "read -r input\necho \"got $input\"\necho \"stderr %[1]d\" >&2\nexit %[1]d\n", false},
+	{"python", "python", "python3", "data = sys.stdin.read()\nprint('got ' + data.strip())\nprint('stderr %[1]d', file=sys.stderr)\nsys.exit(%[1]d)\n", false},
+	{"node", "node", "node", "const data = require('fs').readFileSync(0, 'utf8');\nconsole.log('got ' + data.trim());\nconsole.error('stderr %[1]d');\nprocess.exitCode = %[1]d;\n", false},
+	{"node ES module", "node", "node", "import { readFileSync } from 'fs';\nconst data = readFileSync(0, 'utf8');\nconsole.log('got ' + data.trim());\nconsole.error('stderr %[1]d');\nprocess.exitCode = %[1]d;\n", true},
+}
+
+// writeLoggedHook generates a scriptType hook whose body is run with code,
+// logging to logPath, writes it into dir and returns its path.
+func writeLoggedHook(t *testing.T, dir, scriptType, body string, esm bool, code int, logPath string) string {
+	t.Helper()
+	if esm {
+		// Makes .js files in dir ES modules
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"type": "module"}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hook := Hook{Name: "it's a hook", Event: "PreToolUse", ScriptType: scriptType, Script: fmt.Sprintf(body, code)}
+	path := filepath.Join(dir, fmt.Sprintf("hook%d%s", code, hookScriptExts[scriptType]))
+	if err := os.WriteFile(path, hookScriptContent(hook, logPath, "zd_test", "https://dashboard.example.com", "dev@example.com", "team"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// runLoggedHook runs the script at path with interpreter, returning its exit
+// code, stdout and stderr. It is safe to call from other goroutines.
+func runLoggedHook(t *testing.T, interpreter, path string) (int, string, string) {
+	t.Helper()
+	cmd := exec.Command(interpreter, path)
+	cmd.Stdin = strings.NewReader("input\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Errorf("failed to run %s: %v", path, err)
+		return -1, "", ""
+	}
+	return cmd.ProcessState.ExitCode(), stdout.String(), stderr.String()
+}
+
+// readHookLog returns the parsed lines of the hook log at path, failing on
+// any that is malformed.
+func readHookLog(t *testing.T, path string) []logging.HookRecord {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading hook log: %v", err)
+	}
+	var records []logging.HookRecord
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		rec, ok := logging.ParseHookRecord(line)
+		if !ok {
+			t.Fatalf("malformed hook log line %q", line)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestHookLoggingWrapper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are run with Unix interpreters")
+	}
+	for _, lang := range hookLogLangs {
+		t.Run(lang.name, func(t *testing.T) {
+			interpreter, err := exec.LookPath(lang.interpreter)
+			if err != nil {
+				t.Skipf("%s not installed", lang.interpreter)
+			}
+
+			for _, code := range []int{0, 2, 7} {
+				dir := t.TempDir()
+				logPath := filepath.Join(dir, "logs", logging.HookLogFileName)
+				path := writeLoggedHook(t, dir, lang.scriptType, lang.body, lang.esm, code, logPath)
+
+				rc, stdout, stderr := runLoggedHook(t, interpreter, path)
+				if rc != code {
+					t.Errorf("exit %d: exit code = %d, stderr %q", code, rc, stderr)
+				}
+				if stdout != "got input\n" {
+					t.Errorf("exit %d: stdout = %q, want the hook's output", code, stdout)
+				}
+				if want := fmt.Sprintf("stderr %d", code); strings.TrimSpace(stderr) != want {
+					t.Errorf("exit %d: stderr = %q, want %q passed through", code, stderr, want)
+				}
+
+				records := readHookLog(t, logPath)
+				if len(records) != 1 {
+					t.Fatalf("exit %d: %d log records, want 1: %+v", code, len(records), records)
+				}
+				rec := records[0]
+				if rec.Hook != "it's a hook" || rec.Event != "PreToolUse" || rec.ExitCode != code || rec.Stderr != fmt.Sprintf("stderr %d", code) {
+					t.Errorf("exit %d: record = %+v", code, rec)
+				}
+				if info, err := os.Stat(logPath); err != nil || info.Mode().Perm() != 0600 {
+					t.Errorf("hook log mode: %v, %v; want 0600", info, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHookLoggingConcurrentRuns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are run with Unix interpreters")
+	}
+	const runs = 8
+	for _, lang := range hookLogLangs {
+		t.Run(lang.name, func(t *testing.T) {
+			interpreter, err := exec.LookPath(lang.interpreter)
+			if err != nil {
+				t.Skipf("%s not installed", lang.interpreter)
+			}
+			dir := t.TempDir()
+			logPath := filepath.Join(dir, "logs", logging.HookLogFileName)
+			path := writeLoggedHook(t, dir, lang.scriptType, lang.body, lang.esm, 7, logPath)
+
+			var wg sync.WaitGroup
+			codes := make([]int, runs)
+			for i := range codes {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					codes[i], _, _ = runLoggedHook(t, interpreter, path)
+				}(i)
+			}
+			wg.Wait()
+
+			for i, rc := range codes {
+				if rc != 7 {
+					t.Errorf("run %d exited %d, want 7", i, rc)
+				}
+			}
+			records := readHookLog(t, logPath)
+			if len(records) != runs {
+				t.Fatalf("%d log records, want %d", len(records), runs)
+			}
+			for _, rec := range records {
+				if rec.ExitCode != 7 || rec.Stderr != "stderr 7" {
+					t.Errorf("record = %+v", rec)
+				}
+			}
+		})
+	}
+}
+
+func TestHookLogRotation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are run with Unix interpreters")
+	}
+	for _, lang := range hookLogLangs {
+		t.Run(lang.name, func(t *testing.T) {
+			interpreter, err := exec.LookPath(lang.interpreter)
+			if err != nil {
+				t.Skipf("%s not installed", lang.interpreter)
+			}
+			dir := t.TempDir()
+			logPath := filepath.Join(dir, "logs", logging.HookLogFileName)
+			path := writeLoggedHook(t, dir, lang.scriptType, lang.body, lang.esm, 0, logPath)
+
+			// At the limit the log is kept; past it, it moves to hooks.log.1
+			full := bytes.Repeat([]byte("x"), logging.HookLogMaxSize)
+			if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(logPath, full, 0600); err != nil {
+				t.Fatal(err)
+			}
+			runLoggedHook(t, interpreter, path)
+			if _, err := os.Stat(logPath + ".1"); !os.IsNotExist(err) {
+				t.Fatalf("log rotated at exactly HookLogMaxSize: %v", err)
+			}
+
+			runLoggedHook(t, interpreter, path)
+			old, err := os.ReadFile(logPath + ".1")
+			if err != nil {
+				t.Fatalf("log not rotated past HookLogMaxSize: %v", err)
+			}
+			if !bytes.HasPrefix(old, full) {
+				t.Error("hooks.log.1 does not hold the old log")
+			}
+			if records := readHookLog(t, logPath); len(records) != 1 {
+				t.Errorf("%d records after rotation, want 1", len(records))
+			}
+		})
+	}
+}
+
+// TestPowerShellHookLogOwnerOnly checks the PowerShell wrapper, which can't
+// run here, creates hooks.log owner-only like the others.
+func TestPowerShellHookLogOwnerOnly(t *testing.T) {
+	script := string(hookScriptContent(Hook{Name: "h", Event: "Stop", ScriptType: "powershell"}, "/logs/hooks.log", "zd_test", "", "", ""))
+	if strings.Contains(script, "AppendAllText") {
+		t.Error("PowerShell wrapper appends with the default file mode")
+	}
+	if !strings.Contains(script, "UnixCreateMode = [IO.UnixFileMode]'UserRead, UserWrite'") {
+		t.Error("PowerShell wrapper doesn't create hooks.log as 0600")
+	}
+}
@@ -11,6 +11,12 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
 )
 
 // packageJSON represents the structure of package.json for version extraction.
@@ -28,10 +34,17 @@ type InstallStatus struct {
 // InstallStatusReport is the payload sent to the dashboard.
 type InstallStatusReport struct {
 	InstallStatus []InstallStatus `json:"installStatus"`
+	Heartbeat     *Heartbeat      `json:"heartbeat,omitempty"`
 }
 
 // CheckInstallStatus checks the installation status of MCP servers.
 func CheckInstallStatus(servers map[string]MCPServer) []InstallStatus {
+	return CheckInstallStatusContext(context.Background(), servers)
+}
+
+// CheckInstallStatusContext is CheckInstallStatus with package-manager
+// subprocesses bound to ctx.
+func CheckInstallStatusContext(ctx context.Context, servers map[string]MCPServer) []InstallStatus {
 	results := make([]InstallStatus, 0, len(servers))
 
 	for name, server := range servers {
@@ -40,23 +53,30 @@ func CheckInstallStatus(servers map[string]MCPServer) []InstallStatus {
 			Installed:  false,
 		}
 
+		// Remote servers have nothing installed; check they answer instead
+		if server.Remote() {
+			status.Installed = checkServerReachable(ctx, server.URL)
+			results = append(results, status)
+			continue
+		}
+
 		// Determine package type based on command
 		switch server.Command {
 		case "npx":
-			status.Installed, status.Version = checkNpxPackage(server.Args)
+			status.Installed, status.Version = checkNpxPackage(ctx, server.Args)
 		case "uvx":
-			status.Installed, status.Version = checkUvxPackage(server.Args)
+			status.Installed, status.Version = checkUvxPackage(ctx, server.Args)
 		case "node":
 			// Direct node execution - check if script exists
 			if len(server.Args) > 0 {
-				status.Installed = checkFileExists(server.Args[0])
+				status.Installed = checkFileExists(ctx, server.Args[0])
 			}
 		case "python", "python3":
 			// Python package - check with pip
-			status.Installed, status.Version = checkPythonPackage(server.Args)
+			status.Installed, status.Version = checkPythonPackage(ctx, server.Args)
 		default:
 			// Unknown command type - assume installed if command exists
-			status.Installed = checkCommandExists(server.Command)
+			status.Installed = checkCommandExists(ctx, server.Command)
 		}
 
 		results = append(results, status)
@@ -67,7 +87,7 @@ func CheckInstallStatus(servers map[string]MCPServer) []InstallStatus {
 
 // checkNpxPackage checks if an npm package is installed globally.
 // Args typically look like ["-y", "@package/name"] or ["@package/name"]
-func checkNpxPackage(args []string) (bool, string) {
+func checkNpxPackage(ctx context.Context, args []string) (bool, string) {
 	// Extract package name from args
 	packageName := ""
 	for _, arg := range args {
@@ -84,7 +104,7 @@ func checkNpxPackage(args []string) (bool, string) {
 	}
 
 	// Try npm list -g first
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "npm", "list", "-g", "--depth=0", packageName)
@@ -92,7 +112,7 @@ func checkNpxPackage(args []string) (bool, string) {
 	if err != nil {
 		logDebug("npm list failed for %s: %v", packageName, err)
 		// Fallback: check if npx would download or use cached
-		return checkNpxCache(packageName)
+		return checkNpxCache(ctx, packageName)
 	}
 
 	// Parse version from npm list output
@@ -102,8 +122,8 @@ func checkNpxPackage(args []string) (bool, string) {
 
 // checkNpxCache checks if npx has the package cached by checking npm's cache directory.
 // Note: npm cache ls was removed in npm v5, so we check if the package exists in the global node_modules.
-func checkNpxCache(packageName string) (bool, string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func checkNpxCache(ctx context.Context, packageName string) (bool, string) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	// Check if package exists in global node_modules (npm v5+ compatible)
@@ -122,7 +142,7 @@ func checkNpxCache(packageName string) (bool, string) {
 
 	// Check if the package directory exists in global node_modules
 	pkgPath := globalPath + "/" + packageName
-	if checkFileExists(pkgPath) {
+	if checkFileExists(ctx, pkgPath) {
 		// Try to read package version from package.json using Go native JSON
 		pkgJSONPath := pkgPath + "/package.json"
 		if data, err := os.ReadFile(pkgJSONPath); err == nil {
@@ -149,7 +169,7 @@ func parseNpmListVersion(output, packageName string) string {
 }
 
 // checkUvxPackage checks if a Python package is available via uvx.
-func checkUvxPackage(args []string) (bool, string) {
+func checkUvxPackage(ctx context.Context, args []string) (bool, string) {
 	// Extract package name from args
 	packageName := ""
 	for _, arg := range args {
@@ -164,7 +184,7 @@ func checkUvxPackage(args []string) (bool, string) {
 	}
 
 	// Check with uv pip show
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "uv", "pip", "show", packageName)
@@ -180,7 +200,7 @@ func checkUvxPackage(args []string) (bool, string) {
 }
 
 // checkPythonPackage checks if a Python package is installed.
-func checkPythonPackage(args []string) (bool, string) {
+func checkPythonPackage(ctx context.Context, args []string) (bool, string) {
 	// Try to find a module name in args
 	moduleName := ""
 	for i, arg := range args {
@@ -194,7 +214,7 @@ func checkPythonPackage(args []string) (bool, string) {
 		return false, ""
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "pip", "show", moduleName)
@@ -223,8 +243,8 @@ func parsePipShowVersion(output string) string {
 }
 
 // checkFileExists checks if a file exists.
-func checkFileExists(path string) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+func checkFileExists(ctx context.Context, path string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "test", "-f", path)
@@ -232,37 +252,80 @@ func checkFileExists(path string) bool {
 }
 
 // checkCommandExists checks if a command is available in PATH.
-func checkCommandExists(command string) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+func checkCommandExists(ctx context.Context, command string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "which", command)
 	return cmd.Run() == nil
 }
 
+// checkServerReachable reports whether anything answers HTTP at url. Any
+// response counts, an auth error included: the server's headers may hold
+// credentials, so they aren't sent. Offline, nothing is checked.
+func checkServerReachable(ctx context.Context, url string) bool {
+	if url == "" || config.Offline(env.OS{}) {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := httpclient.NewRequest(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		logDebug("bad MCP server URL %s: %v", url, err)
+		return false
+	}
+	// An SSE endpoint streams forever; the headers are enough
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logDebug("MCP server %s unreachable: %v", url, err)
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+// SessionHeader carries the shim's session ID on status reports so the
+// dashboard can match them to that session's telemetry.
+const SessionHeader = "X-Zeude-Session"
+
 // reportStatusToAPI sends a JSON payload to the dashboard status API.
-// This is a shared helper to avoid code duplication.
-func reportStatusToAPI(agentKey string, payload interface{}) error {
+// This is a shared helper to avoid code duplication. Offline or rate
+// limited, the report is queued on disk for the next sync that reaches the
+// dashboard.
+func reportStatusToAPI(ctx context.Context, e env.Env, agentKey string, payload interface{}) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal status: %w", err)
 	}
+	if config.Offline(e) || !rateLimitedUntil(e).IsZero() {
+		return queueStatusReport(e, data)
+	}
+	return postStatus(ctx, e, agentKey, data, e.Getenv(telemetry.SessionIDEnv))
+}
 
-	url := fmt.Sprintf("%s/api/status/_", getDashboardURL())
+// postStatus POSTs an encoded status report made during session.
+func postStatus(ctx context.Context, e env.Env, agentKey string, data []byte, session string) error {
+	url := fmt.Sprintf("%s/api/status/_", getDashboardURL(e))
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	// Status reports are off the critical path, so one retry on 5xx is cheap
+	newReq := func() (*http.Request, error) {
+		req, err := httpclient.NewRequest(ctx, "POST", url, bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+agentKey)
+		if session != "" {
+			req.Header.Set(SessionHeader, session)
+		}
+		return req, nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "zeude-cli/1.0")
-	req.Header.Set("Authorization", "Bearer "+agentKey)
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpclient.DoWithRetry(httpClient, newReq, httpclient.RetryPolicy{MaxAttempts: 2, Backoff: 200 * time.Millisecond})
 	if err != nil {
 		return fmt.Errorf("failed to send status: %w", err)
 	}
@@ -275,13 +338,82 @@ func reportStatusToAPI(agentKey string, payload interface{}) error {
 	return nil
 }
 
+// LocalInstallStatus is the last install check on this machine, kept in
+// ~/.zeude/status.json so it can be shown without the dashboard.
+type LocalInstallStatus struct {
+	CheckedAt time.Time       `json:"checkedAt"`
+	Servers   []InstallStatus `json:"servers"`
+}
+
+// saveInstallStatus records the result of CheckInstallStatus locally.
+func saveInstallStatus(e env.Env, status []InstallStatus) error {
+	path, err := paths.Status(e)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(LocalInstallStatus{CheckedAt: e.Now().UTC(), Servers: status}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomicWithOptions(path, data, 0600, atomicWriteOptions{NoDirSync: true})
+}
+
+// LoadInstallStatus returns the last locally recorded install check, or nil.
+func LoadInstallStatus() *LocalInstallStatus {
+	return loadInstallStatus(env.OS{})
+}
+
+func loadInstallStatus(e env.Env) *LocalInstallStatus {
+	path, err := paths.Status(e)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var status LocalInstallStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil
+	}
+	return &status
+}
+
+// CheckCachedServers runs the install check synchronously for the servers
+// in the cached config and records the result locally, as sync does. It
+// returns the servers checked alongside their status.
+func CheckCachedServers(ctx context.Context) (map[string]MCPServer, []InstallStatus, error) {
+	return checkCachedServers(ctx, env.OS{})
+}
+
+func checkCachedServers(ctx context.Context, e env.Env) (map[string]MCPServer, []InstallStatus, error) {
+	cached, _ := loadCachedConfig(e)
+	if cached == nil {
+		return nil, nil, ErrNoCachedConfig
+	}
+	servers := loadOverrides(e).enabledServers(applicableServers(e, cached.Config.MCPServers))
+	status := CheckInstallStatusContext(ctx, servers)
+	if ctx.Err() == nil {
+		if err := saveInstallStatus(e, status); err != nil {
+			logError("failed to save install status: %v", err)
+		}
+	}
+	return servers, status, nil
+}
+
 // ReportInstallStatus sends installation status to the dashboard.
 func ReportInstallStatus(agentKey string, status []InstallStatus) error {
-	if len(status) == 0 {
+	return reportInstallStatus(context.Background(), env.OS{}, agentKey, status, nil)
+}
+
+// reportInstallStatus sends install status, carrying hb along when a
+// heartbeat is due so it doesn't cost a request of its own.
+func reportInstallStatus(ctx context.Context, e env.Env, agentKey string, status []InstallStatus, hb *Heartbeat) error {
+	if len(status) == 0 && hb == nil {
 		return nil
 	}
-	report := InstallStatusReport{InstallStatus: status}
-	if err := reportStatusToAPI(agentKey, report); err != nil {
+	report := InstallStatusReport{InstallStatus: status, Heartbeat: hb}
+	if err := reportStatusToAPI(ctx, e, agentKey, report); err != nil {
 		return err
 	}
 	logDebug("reported install status for %d servers", len(status))
@@ -293,6 +425,7 @@ type HookInstallStatus struct {
 	HookID    string `json:"hookId"`
 	Installed bool   `json:"installed"`
 	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"` // why the hook was not installed
 }
 
 // HookInstallStatusReport is the payload sent to the dashboard for hooks.
@@ -302,11 +435,15 @@ type HookInstallStatusReport struct {
 
 // ReportHookInstallStatus sends hook installation status to the dashboard.
 func ReportHookInstallStatus(agentKey string, status []HookInstallStatus) error {
+	return reportHookInstallStatus(context.Background(), env.OS{}, agentKey, status)
+}
+
+func reportHookInstallStatus(ctx context.Context, e env.Env, agentKey string, status []HookInstallStatus) error {
 	if len(status) == 0 {
 		return nil
 	}
 	report := HookInstallStatusReport{HookInstallStatus: status}
-	if err := reportStatusToAPI(agentKey, report); err != nil {
+	if err := reportStatusToAPI(ctx, e, agentKey, report); err != nil {
 		return err
 	}
 
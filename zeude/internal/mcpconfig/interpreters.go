@@ -0,0 +1,78 @@
+package mcpconfig
+
+import (
+	"os/exec"
+	"path/filepath"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// hookInterpreters maps hook script types to the program the generated
+// shebang runs.
+var hookInterpreters = map[string]string{
+	"":           "bash",
+	"bash":       "bash",
+	"python":     "python3",
+	"node":       "node",
+	"deno":       "deno",
+	"ruby":       "ruby",
+	"powershell": "pwsh",
+}
+
+// interpreterLookup finds hook interpreters on PATH, looking each one up
+// once. The zero value is not usable; make one with newInterpreterLookup.
+type interpreterLookup struct {
+	found map[string]bool // interpreter -> on PATH
+}
+
+func newInterpreterLookup() *interpreterLookup {
+	return &interpreterLookup{found: make(map[string]bool)}
+}
+
+// missing returns the interpreter a hook of scriptType runs with when it
+// isn't on PATH, or "" when it is or the type is unknown.
+func (l *interpreterLookup) missing(scriptType string) string {
+	name, ok := hookInterpreters[scriptType]
+	if !ok {
+		return ""
+	}
+	found, checked := l.found[name]
+	if !checked {
+		_, err := exec.LookPath(name)
+		found = err == nil
+		l.found[name] = found
+	}
+	if found {
+		return ""
+	}
+	return name
+}
+
+// MissingHookInterpreters returns, for each interpreter not on PATH, the
+// installed hooks that need it. Hooks disabled locally don't run, so they
+// are left out.
+func MissingHookInterpreters() (map[string][]string, error) {
+	return missingHookInterpreters(env.OS{})
+}
+
+func missingHookInterpreters(e env.Env) (map[string][]string, error) {
+	hooks, err := listHooks(e)
+	if err != nil {
+		return nil, err
+	}
+	lookup := newInterpreterLookup()
+	missing := make(map[string][]string)
+	for _, h := range hooks {
+		if !h.Exists || h.Disabled {
+			continue
+		}
+		if name := lookup.missing(h.ScriptType); name != "" {
+			label := h.Name
+			if label == "" {
+				label = filepath.Base(h.Path)
+			}
+			missing[name] = append(missing[name], label)
+		}
+	}
+	return missing, nil
+}
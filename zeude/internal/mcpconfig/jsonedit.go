@@ -0,0 +1,297 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Targeted JSON editing for ~/.claude.json.
+//
+// Claude keeps per-project history in that file and it can grow past 50MB.
+// Decoding it into a generic map just to touch mcpServers costs hundreds of
+// milliseconds and a lot of memory, and re-marshaling reorders keys and
+// rewrites numbers. Instead we scan the top-level object for byte offsets,
+// decode only the member we edit, and splice the new value back in: every
+// other byte of the document is copied through unchanged.
+
+// jsonMember is a member of a JSON object located by byte offsets.
+type jsonMember struct {
+	Key        string
+	KeyStart   int // offset of the opening quote of the key
+	ValueStart int
+	ValueEnd   int // exclusive
+}
+
+var errInvalidJSON = errors.New("syntax error")
+
+// scanObject returns the members of the object whose '{' is at data[start],
+// and the offset just past its closing '}'. Member values are skipped, not
+// decoded, so cost is a single pass with no allocations per nested value.
+func scanObject(data []byte, start int) ([]jsonMember, int, error) {
+	if start >= len(data) || data[start] != '{' {
+		return nil, 0, fmt.Errorf("%w: expected object", errInvalidJSON)
+	}
+
+	var members []jsonMember
+	i := skipSpace(data, start+1)
+	if i < len(data) && data[i] == '}' {
+		return members, i + 1, nil
+	}
+
+	for {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return nil, 0, fmt.Errorf("%w: expected key at offset %d", errInvalidJSON, i)
+		}
+		keyStart := i
+		keyEnd, err := skipString(data, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		var key string
+		if err := json.Unmarshal(data[keyStart:keyEnd], &key); err != nil {
+			return nil, 0, fmt.Errorf("%w: bad key at offset %d", errInvalidJSON, keyStart)
+		}
+
+		i = skipSpace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return nil, 0, fmt.Errorf("%w: expected ':' at offset %d", errInvalidJSON, i)
+		}
+		valueStart := skipSpace(data, i+1)
+		valueEnd, err := skipValue(data, valueStart)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, jsonMember{Key: key, KeyStart: keyStart, ValueStart: valueStart, ValueEnd: valueEnd})
+
+		i = skipSpace(data, valueEnd)
+		if i >= len(data) {
+			return nil, 0, fmt.Errorf("%w: unterminated object", errInvalidJSON)
+		}
+		switch data[i] {
+		case ',':
+			i++
+		case '}':
+			return members, i + 1, nil
+		default:
+			return nil, 0, fmt.Errorf("%w: unexpected %q at offset %d", errInvalidJSON, data[i], i)
+		}
+	}
+}
+
+// skipValue returns the offset just past the JSON value starting at data[i].
+func skipValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return 0, fmt.Errorf("%w: unexpected end of input", errInvalidJSON)
+	}
+	switch c := data[i]; {
+	case c == '"':
+		return skipString(data, i)
+	case c == '{' || c == '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				end, err := skipString(data, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return 0, fmt.Errorf("%w: unterminated %q at offset %d", errInvalidJSON, c, i)
+	default:
+		// Number, true, false, null: runs until a delimiter
+		j := i
+		for j < len(data) && !isJSONDelim(data[j]) {
+			j++
+		}
+		if j == i || !json.Valid(data[i:j]) {
+			return 0, fmt.Errorf("%w: bad literal at offset %d", errInvalidJSON, i)
+		}
+		return j, nil
+	}
+}
+
+// skipString returns the offset just past the string whose quote is at data[i].
+func skipString(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated string at offset %d", errInvalidJSON, i)
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func isJSONDelim(c byte) bool {
+	return c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// topLevelObject validates that doc is a single JSON object and returns its
+// members and the offset of its closing brace.
+func topLevelObject(doc []byte) ([]jsonMember, int, error) {
+	start := skipSpace(doc, 0)
+	members, end, err := scanObject(doc, start)
+	if err != nil {
+		return nil, 0, err
+	}
+	if skipSpace(doc, end) != len(doc) {
+		return nil, 0, fmt.Errorf("%w: trailing data after object", errInvalidJSON)
+	}
+	return members, end - 1, nil
+}
+
+// findMember returns the last member named key (later duplicates win, as in
+// encoding/json).
+func findMember(members []jsonMember, key string) (jsonMember, bool) {
+	var found jsonMember
+	ok := false
+	for _, m := range members {
+		if m.Key == key {
+			found, ok = m, true
+		}
+	}
+	return found, ok
+}
+
+// setTopLevelMember returns doc with the top-level member key set to value.
+// An existing value is replaced in place; otherwise the member is appended
+// before the closing brace. All other bytes are preserved.
+func setTopLevelMember(doc []byte, key string, value []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(doc)) == 0 {
+		doc = []byte("{}")
+	}
+	members, closeBrace, err := topLevelObject(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(doc) + len(value) + len(key) + 8)
+
+	if m, ok := findMember(members, key); ok {
+		out.Write(doc[:m.ValueStart])
+		out.Write(value)
+		out.Write(doc[m.ValueEnd:])
+		return out.Bytes(), nil
+	}
+
+	quotedKey, _ := json.Marshal(key)
+	if len(members) == 0 {
+		out.Write(doc[:closeBrace])
+		out.WriteString("\n  ")
+		out.Write(quotedKey)
+		out.WriteString(": ")
+		out.Write(value)
+		out.WriteString("\n")
+		out.Write(doc[closeBrace:])
+		return out.Bytes(), nil
+	}
+
+	last := members[len(members)-1]
+	out.Write(doc[:last.ValueEnd])
+	out.WriteString(",\n  ")
+	out.Write(quotedKey)
+	out.WriteString(": ")
+	out.Write(value)
+	out.Write(doc[last.ValueEnd:])
+	return out.Bytes(), nil
+}
+
+// rawMember is an object member whose value is kept as raw bytes.
+type rawMember struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// objectMembers returns the members of a JSON object value in document order.
+func objectMembers(value []byte) ([]rawMember, error) {
+	members, _, err := scanObject(value, skipSpace(value, 0))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]rawMember, 0, len(members))
+	for _, m := range members {
+		out = append(out, rawMember{Key: m.Key, Value: json.RawMessage(value[m.ValueStart:m.ValueEnd])})
+	}
+	return out, nil
+}
+
+// encodeObject writes members as an object nested one level deep in a
+// 2-space indented document (the format Claude writes). Raw values are
+// emitted verbatim.
+func encodeObject(members []rawMember) []byte {
+	if len(members) == 0 {
+		return []byte("{}")
+	}
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, m := range members {
+		key, _ := json.Marshal(m.Key)
+		b.WriteString("    ")
+		b.Write(key)
+		b.WriteString(": ")
+		b.Write(m.Value)
+		if i < len(members)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("  }")
+	return []byte(b.String())
+}
+
+// decodeJSONObject decodes an object into a generic map without losing
+// fidelity: numbers stay json.Number so large integers (timestamps, token
+// counts) and high-precision floats are written back exactly as read rather
+// than round-tripped through float64.
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: trailing data after object", errInvalidJSON)
+	}
+	if obj == nil {
+		obj = map[string]interface{}{}
+	}
+	return obj, nil
+}
+
+// marshalIndentJSON is json.MarshalIndent without HTML escaping, so shell
+// commands containing &, < or > are written as-is instead of \u0026 etc.
+func marshalIndentJSON(v interface{}, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
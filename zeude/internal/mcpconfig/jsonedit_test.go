@@ -0,0 +1,290 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetTopLevelMember(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name:  "replace keeps surrounding bytes",
+			doc:   "{\"a\":1,   \"mcpServers\" :\t{\"x\": {}} ,\n\"z\": [1,2]}\n",
+			key:   "mcpServers",
+			value: `{"y": {}}`,
+			want:  "{\"a\":1,   \"mcpServers\" :\t{\"y\": {}} ,\n\"z\": [1,2]}\n",
+		},
+		{
+			name:  "replace scalar with object",
+			doc:   `{"mcpServers": null, "b": "}"}`,
+			key:   "mcpServers",
+			value: `{}`,
+			want:  `{"mcpServers": {}, "b": "}"}`,
+		},
+		{
+			name:  "append after last member",
+			doc:   "{\n  \"a\": 1\n}",
+			key:   "mcpServers",
+			value: `{}`,
+			want:  "{\n  \"a\": 1,\n  \"mcpServers\": {}\n}",
+		},
+		{
+			name:  "append to empty object",
+			doc:   `{}`,
+			key:   "mcpServers",
+			value: `{}`,
+			want:  "{\n  \"mcpServers\": {}\n}",
+		},
+		{
+			name:  "empty document",
+			doc:   "  \n",
+			key:   "mcpServers",
+			value: `{}`,
+			want:  "{\n  \"mcpServers\": {}\n}",
+		},
+		{
+			name:  "later duplicate wins",
+			doc:   `{"k": 1, "k": 2}`,
+			key:   "k",
+			value: `3`,
+			want:  `{"k": 1, "k": 3}`,
+		},
+		{
+			name:  "escaped key and strings",
+			doc:   `{"s": "a\"}{", "mcpServers": 1}`,
+			key:   "mcpServers",
+			value: `2`,
+			want:  `{"s": "a\"}{", "mcpServers": 2}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setTopLevelMember([]byte(tt.doc), tt.key, []byte(tt.value))
+			if err != nil {
+				t.Fatalf("setTopLevelMember: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setTopLevelMember() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("result is not valid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestSetTopLevelMemberInvalid(t *testing.T) {
+	docs := []string{
+		`[]`,
+		`{"a": 1`,
+		`{"a": 1} {}`,
+		`{"a" 1}`,
+		`{"a": tru}`,
+		`{"a": "unterminated}`,
+		`{a: 1}`,
+	}
+	for _, doc := range docs {
+		if _, err := setTopLevelMember([]byte(doc), "mcpServers", []byte(`{}`)); !errors.Is(err, errInvalidJSON) {
+			t.Errorf("setTopLevelMember(%s) error = %v, want a syntax error", doc, err)
+		}
+	}
+}
+
+func TestObjectMembersRoundTrip(t *testing.T) {
+	value := `{"a": {"command": "x", "args": ["&<>"]}, "b": 12345678901234567890, "c": "é"}`
+	members, err := objectMembers([]byte(value))
+	if err != nil {
+		t.Fatalf("objectMembers: %v", err)
+	}
+	want := []rawMember{
+		{"a", json.RawMessage(`{"command": "x", "args": ["&<>"]}`)},
+		{"b", json.RawMessage(`12345678901234567890`)},
+		{"c", json.RawMessage(`"é"`)},
+	}
+	if len(members) != len(want) {
+		t.Fatalf("objectMembers() returned %d members, want %d", len(members), len(want))
+	}
+	for i := range want {
+		if members[i].Key != want[i].Key || !bytes.Equal(members[i].Value, want[i].Value) {
+			t.Errorf("member %d = %s: %s, want %s: %s", i, members[i].Key, members[i].Value, want[i].Key, want[i].Value)
+		}
+	}
+
+	encoded := encodeObject(members)
+	again, err := objectMembers(encoded)
+	if err != nil {
+		t.Fatalf("objectMembers(encodeObject()): %v", err)
+	}
+	for i := range want {
+		if !bytes.Equal(again[i].Value, want[i].Value) {
+			t.Errorf("re-encoded member %s = %s, want %s", want[i].Key, again[i].Value, want[i].Value)
+		}
+	}
+}
+
+// TestWriteClaudeConfigPreservesDocument checks that a merge rewrites
+// mcpServers and leaves every other byte of claude.json as it was.
+func TestWriteClaudeConfigPreservesDocument(t *testing.T) {
+	e := testEnv(t)
+	path, _ := getClaudeConfigPath(e)
+	prefix := "{\n\t\"numStartups\": 12345678901234567890,\n  \"projects\": {\"/p\": {\"history\": [{\"display\": \"caf\\u00e9 \\\"q\\\"\", \"t\": 1.000000000000000001}]}},\n  \"mcpServers\": "
+	suffix := ",\n  \"oauthAccount\":{\"x\":true}   \n}\n"
+	original := prefix + `{"mine": {"command": "a"}}` + suffix
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := readClaudeConfig(e)
+	if err != nil {
+		t.Fatalf("readClaudeConfig: %v", err)
+	}
+	servers := append(doc.mcpServers, rawMember{Key: "zeude", Value: json.RawMessage(`{"command": "z"}`)})
+	if err := writeClaudeConfig(e, doc, servers); err != nil {
+		t.Fatalf("writeClaudeConfig: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(got, []byte(prefix)) || !bytes.HasSuffix(got, []byte(suffix)) {
+		t.Fatalf("bytes outside mcpServers changed:\n%s", got)
+	}
+	reread, err := readClaudeConfig(e)
+	if err != nil {
+		t.Fatalf("readClaudeConfig after write: %v", err)
+	}
+	if len(reread.mcpServers) != 2 || reread.mcpServers[0].Key != "mine" || reread.mcpServers[1].Key != "zeude" {
+		t.Errorf("mcpServers after write = %v, want mine then zeude", reread.mcpServers)
+	}
+}
+
+// syntheticClaudeConfig builds a claude.json of about size bytes, most of
+// it per-project history as in real files.
+func syntheticClaudeConfig(size int) []byte {
+	var b strings.Builder
+	b.WriteString("{\n  \"numStartups\": 42,\n  \"projects\": {\n")
+	for i := 0; b.Len() < size; i++ {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, "    \"/home/u/project-%d\": {\"history\": [{\"display\": \"refactor the parser %d\", \"pastedContents\": {}}], \"lastCost\": 0.%06d}", i, i, i)
+	}
+	b.WriteString("\n  },\n  \"mcpServers\": {\"a\": {\"command\": \"a\"}}\n}\n")
+	return []byte(b.String())
+}
+
+func BenchmarkSetMCPServers(b *testing.B) {
+	doc := syntheticClaudeConfig(50 << 20)
+	value := []byte(`{"a": {"command": "a"}, "b": {"command": "b"}}`)
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := setTopLevelMember(doc, "mcpServers", value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSetMCPServersGenericMap is the decode-everything approach
+// setTopLevelMember replaced, for comparison.
+func BenchmarkSetMCPServersGenericMap(b *testing.B) {
+	doc := syntheticClaudeConfig(50 << 20)
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var m map[string]interface{}
+		if err := json.Unmarshal(doc, &m); err != nil {
+			b.Fatal(err)
+		}
+		m["mcpServers"] = map[string]interface{}{"a": map[string]interface{}{"command": "a"}}
+		if _, err := json.MarshalIndent(m, "", "  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeJSONObjectKeepsNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+	}{
+		{"max int64", "9223372036854775807"},
+		{"min int64", "-9223372036854775808"},
+		{"past float64 precision", "9007199254740993"},
+		{"past uint64", "123456789012345678901234567890"},
+		{"high precision float", "0.1000000000000000055511151231257827"},
+		{"exponent", "1.5e-300"},
+		{"past float64 range", "1e400"},
+		{"trailing zeros", "2.50"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := []byte(`{"deep": {"a": [[{"n": ` + tt.number + `}]]}, "n": ` + tt.number + `}`)
+			obj, err := decodeJSONObject(doc)
+			if err != nil {
+				t.Fatalf("decodeJSONObject: %v", err)
+			}
+			out, err := marshalIndentJSON(obj, "", "")
+			if err != nil {
+				t.Fatalf("marshalIndentJSON: %v", err)
+			}
+			want := `{"deep":{"a":[[{"n":` + tt.number + `}]]},"n":` + tt.number + `}`
+			if string(out) != want {
+				t.Errorf("round trip = %s, want %s", out, want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONObjectInvalid(t *testing.T) {
+	for _, doc := range []string{`{"a": 1} {"b": 2}`, `[1]`, `{"a": }`} {
+		if _, err := decodeJSONObject([]byte(doc)); err == nil {
+			t.Errorf("decodeJSONObject(%s) returned no error", doc)
+		}
+	}
+	obj, err := decodeJSONObject([]byte(`null`))
+	if err != nil || obj == nil {
+		t.Errorf("decodeJSONObject(null) = %v, %v; want an empty map", obj, err)
+	}
+}
+
+// TestClaudeSettingsRoundTripKeepsUnknownMembers checks that settings Zeude
+// doesn't own survive a read and write with their values intact.
+func TestClaudeSettingsRoundTripKeepsUnknownMembers(t *testing.T) {
+	e := testEnv(t)
+	path, _ := getClaudeSettingsPath(e)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	original := `{"cleanupPeriodDays": 9007199254740993, "feedbackSurveyState": {"lastShownTime": 1735689600123}, "ratio": 0.30000000000000004, "nested": {"a": [{"b": [1, 2.0, -0]}]}, "cmd": "a && b > c"}`
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := readClaudeSettings(e)
+	if err != nil {
+		t.Fatalf("readClaudeSettings: %v", err)
+	}
+	settings["hooks"] = map[string]interface{}{}
+	if err := writeClaudeSettings(e, settings); err != nil {
+		t.Fatalf("writeClaudeSettings: %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	compact := strings.Join(strings.Fields(string(got)), "")
+	for _, want := range []string{`9007199254740993`, `1735689600123`, `0.30000000000000004`, `[1,2.0,-0]`, `"a&&b>c"`} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("settings.json lost %s:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package mcpconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// StaleLockAge is how old a lock without a live holder must be before it is
+// considered abandoned. Only Windows lock files can be (see Stale).
+const StaleLockAge = time.Minute
+
+const (
+	// FileLockWait is how long to wait for the claude.json lock.
+	FileLockWait = 5 * time.Second
+	// SyncLockWait is how long a sync waits for another to finish writing.
+	// Holders only write local files, so this is generous.
+	SyncLockWait = 15 * time.Second
+)
+
+// LockFileInfo describes a lock file found on disk.
+type LockFileInfo struct {
+	Path        string
+	Age         time.Duration
+	PID         int  // 0 when the lock predates PID stamping
+	HolderAlive bool // only meaningful when PID > 0
+}
+
+// Stale reports whether the lock is safe to remove. Only the Windows lock,
+// held by the file existing, can outlive its holder; the kernel drops a
+// flock when its holder exits, so on unix a held lock is never stale.
+func (l LockFileInfo) Stale() bool {
+	if !lockFilesGoStale {
+		return false
+	}
+	if l.PID > 0 {
+		return !l.HolderAlive
+	}
+	return l.Age > StaleLockAge
+}
+
+// LockPaths returns every lock file path Zeude may create.
+// Shared with the doctor so renames stay in sync.
+func LockPaths() ([]string, error) {
+	lockPath, err := getLockPath(env.OS{})
+	if err != nil {
+		return nil, err
+	}
+	syncLock, err := paths.SyncLock(env.OS{})
+	if err != nil {
+		return nil, err
+	}
+	errorsLock, err := paths.ErrorsLock(env.OS{})
+	if err != nil {
+		return nil, err
+	}
+	return []string{syncLock, lockPath, errorsLock}, nil
+}
+
+// withFileLock runs fn holding the claude.json lock, which everything that
+// writes claude.json, settings.json, the managed lists or the cache takes.
+func withFileLock(ctx context.Context, e env.Env, fn func() error) error {
+	lockPath, err := getLockPath(e)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	lock, err := lockFile(ctx, lockPath, FileLockWait)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	defer unlockFile(lock, lockPath)
+	return fn()
+}
+
+// WithLock runs fn holding the lock at lockPath, waiting up to wait. It is
+// the lock withFileLock takes, on a file of the caller's choosing, for
+// other files shims rewrite concurrently.
+func WithLock(ctx context.Context, lockPath string, wait time.Duration, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	lock, err := lockFile(ctx, lockPath, wait)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	defer unlockFile(lock, lockPath)
+	return fn()
+}
+
+// acquireSyncLock takes the Zeude-wide sync lock, held from the merge to
+// the last manifest write so two shims starting at once don't interleave
+// their settings.json and hook writes. The claude.json lock is still taken
+// inside it, for writers that only take that one. Call release when done.
+func acquireSyncLock(ctx context.Context, e env.Env) (release func(), err error) {
+	lockPath, err := paths.SyncLock(e)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureZeudeDir(e); err != nil {
+		return nil, err
+	}
+	lock, err := lockFile(ctx, lockPath, SyncLockWait)
+	if err != nil {
+		return nil, err
+	}
+	return func() { unlockFile(lock, lockPath) }, nil
+}
+
+// InspectLocks returns the locks currently held. On unix the lock files
+// stay after a sync finishes, and free ones are left out.
+func InspectLocks() ([]LockFileInfo, error) {
+	paths, err := LockPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	var locks []LockFileInfo
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !lockHeld(path) {
+			continue
+		}
+		locks = append(locks, inspectLock(path, info))
+	}
+	return locks, nil
+}
+
+// inspectLock describes the lock file at path, whose stat is info.
+func inspectLock(path string, info os.FileInfo) LockFileInfo {
+	lock := LockFileInfo{Path: path, Age: time.Since(info.ModTime()), PID: readLockHolder(path)}
+	if lock.PID > 0 {
+		lock.HolderAlive = processAlive(lock.PID)
+	}
+	return lock
+}
+
+// RemoveStaleLock deletes a lock file after re-confirming its holder is gone.
+func RemoveStaleLock(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !inspectLock(path, info).Stale() {
+		return fmt.Errorf("lock %s is still in use", path)
+	}
+
+	return os.Remove(path)
+}
+
+// writeLockHolder records the current PID in an acquired lock file.
+func writeLockHolder(lock *os.File) {
+	if err := lock.Truncate(0); err != nil {
+		logDebug("failed to truncate lock file: %v", err)
+		return
+	}
+	if _, err := lock.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+		logDebug("failed to write lock holder: %v", err)
+	}
+}
+
+// readLockHolder returns the PID stored in a lock file, or 0 if unknown.
+func readLockHolder(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,184 @@
+package mcpconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockFileInfoStale(t *testing.T) {
+	tests := []struct {
+		name string
+		info LockFileInfo
+		// want is the answer where lock files can go stale (Windows);
+		// elsewhere a lock is never stale.
+		want bool
+	}{
+		{"live holder", LockFileInfo{PID: 1, HolderAlive: true, Age: time.Hour}, false},
+		{"dead holder", LockFileInfo{PID: 1, HolderAlive: false}, true},
+		{"no PID, recent", LockFileInfo{Age: StaleLockAge - time.Second}, false},
+		{"no PID, old", LockFileInfo{Age: StaleLockAge + time.Second}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want && lockFilesGoStale
+			if got := tt.info.Stale(); got != want {
+				t.Errorf("Stale() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadLockHolder(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{"1234", 1234},
+		{" 42\n", 42},
+		{"", 0},
+		{"abc", 0},
+		{"-5", 0},
+		{"0", 0},
+	}
+	dir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(dir, strconv.Itoa(i))
+		os.WriteFile(path, []byte(tt.content), 0600)
+		if got := readLockHolder(path); got != tt.want {
+			t.Errorf("readLockHolder(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+	if got := readLockHolder(filepath.Join(dir, "missing")); got != 0 {
+		t.Errorf("readLockHolder(missing) = %d, want 0", got)
+	}
+}
+
+func TestWithFileLock(t *testing.T) {
+	e := testEnv(t)
+	lockPath, _ := getLockPath(e)
+	errFn := errors.New("fn failed")
+
+	tests := []struct {
+		name string
+		fn   error
+	}{
+		{"fn succeeds", nil},
+		{"fn fails", errFn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := false
+			err := withFileLock(context.Background(), e, func() error {
+				ran = true
+				if !lockHeld(lockPath) {
+					t.Error("lock not held while fn runs")
+				}
+				if pid := readLockHolder(lockPath); pid != os.Getpid() {
+					t.Errorf("lock holder = %d, want %d", pid, os.Getpid())
+				}
+				return tt.fn
+			})
+			if !ran || !errors.Is(err, tt.fn) {
+				t.Errorf("withFileLock() = %v (ran %v), want %v", err, ran, tt.fn)
+			}
+			if lockHeld(lockPath) {
+				t.Error("lock still held after withFileLock returned")
+			}
+		})
+	}
+}
+
+func TestWithFileLockContended(t *testing.T) {
+	e := testEnv(t)
+	lockPath, _ := getLockPath(e)
+	held, err := lockFile(context.Background(), lockPath, time.Second)
+	if err != nil {
+		t.Fatalf("lockFile: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err = withFileLock(ctx, e, func() error {
+		t.Error("fn ran while another holder had the lock")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("withFileLock() = %v, want the context deadline", err)
+	}
+
+	// Once released, the next caller gets it
+	unlockFile(held, lockPath)
+	if err := withFileLock(context.Background(), e, func() error { return nil }); err != nil {
+		t.Errorf("withFileLock() after release = %v", err)
+	}
+}
+
+func TestSyncLockSerializes(t *testing.T) {
+	e := testEnv(t)
+	var holders, maxHolders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireSyncLock(context.Background(), e)
+			if err != nil {
+				t.Errorf("acquireSyncLock: %v", err)
+				return
+			}
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				m := atomic.LoadInt32(&maxHolders)
+				if n <= m || atomic.CompareAndSwapInt32(&maxHolders, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if maxHolders != 1 {
+		t.Errorf("%d goroutines held the sync lock at once, want 1", maxHolders)
+	}
+}
+
+func TestInspectLocks(t *testing.T) {
+	home := useTestHome(t)
+	e := testEnv(t)
+	e.Home = home
+	lockPath, _ := getLockPath(e)
+
+	locks, err := InspectLocks()
+	if err != nil || len(locks) != 0 {
+		t.Fatalf("InspectLocks() with no locks = %v, %v", locks, err)
+	}
+
+	held, err := lockFile(context.Background(), lockPath, time.Second)
+	if err != nil {
+		t.Fatalf("lockFile: %v", err)
+	}
+	locks, err = InspectLocks()
+	if err != nil || len(locks) != 1 {
+		t.Fatalf("InspectLocks() while held = %v, %v; want one lock", locks, err)
+	}
+	if l := locks[0]; l.Path != lockPath || l.PID != os.Getpid() || !l.HolderAlive || l.Stale() {
+		t.Errorf("InspectLocks() = %+v, want %s held by this live process", l, lockPath)
+	}
+	if err := RemoveStaleLock(lockPath); err == nil {
+		t.Error("RemoveStaleLock removed a lock that is held")
+	}
+
+	unlockFile(held, lockPath)
+	if locks, _ := InspectLocks(); len(locks) != 0 {
+		t.Errorf("InspectLocks() after release = %v, want none", locks)
+	}
+}
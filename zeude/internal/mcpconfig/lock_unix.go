@@ -3,38 +3,42 @@
 package mcpconfig
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"syscall"
 	"time"
 )
 
-// acquireFileLock acquires an exclusive lock on the config file.
+// lockFile takes an exclusive flock on lockPath, waiting up to wait.
+// Waiting stops early when ctx is cancelled.
 // [FIX #2] Unix-specific implementation using flock.
-func acquireFileLock() (*os.File, string, error) {
-	lockPath, err := getLockPath()
-	if err != nil {
-		return nil, "", err
-	}
-
+func lockFile(ctx context.Context, lockPath string, wait time.Duration) (*os.File, error) {
 	lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to open lock file: %w", err)
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
 	}
 
 	// Try to acquire exclusive lock with timeout
-	deadline := time.Now().Add(5 * time.Second)
+	deadline := time.Now().Add(wait)
 	for time.Now().Before(deadline) {
 		err = syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
 		if err == nil {
-			logDebug("acquired file lock (unix flock)")
-			return lock, lockPath, nil
+			writeLockHolder(lock)
+			logDebug("acquired %s (unix flock)", filepath.Base(lockPath))
+			return lock, nil
+		}
+		select {
+		case <-ctx.Done():
+			lock.Close()
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
 		}
-		time.Sleep(50 * time.Millisecond)
 	}
 
 	lock.Close()
-	return nil, "", fmt.Errorf("timeout waiting for file lock")
+	return nil, fmt.Errorf("timeout waiting for %s", filepath.Base(lockPath))
 }
 
 // releaseFileLock releases the file lock.
@@ -46,3 +50,36 @@ func releaseFileLock(lock *os.File) {
 	lock.Close()
 	logDebug("released file lock")
 }
+
+// lockFilesGoStale is false: a flock goes away with its holder, so a lock
+// file left behind blocks nobody.
+const lockFilesGoStale = false
+
+// lockHeld reports whether some process holds the flock on path.
+func lockHeld(path string) bool {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return true
+	}
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false
+}
+
+// unlockFile releases a lock taken with lockFile and leaves the file in
+// place. Removing it would let a process already waiting on the old inode
+// lock that one while a newcomer creates and locks a new file at the same
+// path, and both would run.
+func unlockFile(lock *os.File, lockPath string) {
+	releaseFileLock(lock)
+}
+
+// processAlive reports whether a process with the given PID exists.
+// EPERM means the process exists but belongs to another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
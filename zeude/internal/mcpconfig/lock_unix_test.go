@@ -0,0 +1,63 @@
+//go:build unix || darwin || linux
+
+package mcpconfig
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// TestUnlockKeepsLockFile: removing the file would let a waiter on the
+// old inode and a newcomer on a new one both hold "the" lock.
+func TestUnlockKeepsLockFile(t *testing.T) {
+	e := testEnv(t)
+	release, err := acquireSyncLock(context.Background(), e)
+	if err != nil {
+		t.Fatalf("acquireSyncLock: %v", err)
+	}
+	path, _ := paths.SyncLock(e)
+	before, _ := os.Stat(path)
+	release()
+
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("sync lock file removed on release: %v", err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("sync lock file replaced on release")
+	}
+}
+
+// TestLeftoverLockBlocksNothing fabricates the lock file a crashed sync
+// leaves behind. The flock died with its holder, so the lock is neither
+// reported nor in the way.
+func TestLeftoverLockBlocksNothing(t *testing.T) {
+	home := useTestHome(t)
+	e := testEnv(t)
+	e.Home = home
+	if err := ensureZeudeDir(e); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := paths.SyncLock(e)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(deadPID(t))), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(path, old, old)
+
+	if locks, err := InspectLocks(); err != nil || len(locks) != 0 {
+		t.Errorf("InspectLocks() = %v, %v; want no held locks", locks, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	release, err := acquireSyncLock(ctx, e)
+	if err != nil {
+		t.Fatalf("acquireSyncLock behind a leftover lock file: %v", err)
+	}
+	release()
+}
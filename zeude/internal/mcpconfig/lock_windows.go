@@ -3,45 +3,47 @@
 package mcpconfig
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 )
 
-// acquireFileLock acquires an exclusive lock on the config file.
+// lockFile creates lockPath exclusively, waiting up to wait. The caller
+// releases it with unlockFile, which also removes it.
+// Waiting stops early when ctx is cancelled.
 // [FIX #2] Windows-specific implementation using file creation as advisory lock.
 // Windows doesn't have flock, so we use exclusive file creation.
-func acquireFileLock() (*os.File, string, error) {
-	lockPath, err := getLockPath()
-	if err != nil {
-		return nil, "", err
-	}
-
+func lockFile(ctx context.Context, lockPath string, wait time.Duration) (*os.File, error) {
 	// Try to acquire exclusive lock with timeout
-	deadline := time.Now().Add(5 * time.Second)
+	deadline := time.Now().Add(wait)
 	for time.Now().Before(deadline) {
 		// Try to create lock file exclusively
 		// O_CREATE|O_EXCL fails if file exists
 		lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
 		if err == nil {
-			logDebug("acquired file lock (windows exclusive create)")
-			return lock, lockPath, nil
+			writeLockHolder(lock)
+			logDebug("acquired %s (windows exclusive create)", filepath.Base(lockPath))
+			return lock, nil
 		}
 
-		// Check if lock file is stale (older than 1 minute)
-		if info, statErr := os.Stat(lockPath); statErr == nil {
-			if time.Since(info.ModTime()) > time.Minute {
-				// Stale lock, remove it
-				os.Remove(lockPath)
-				logDebug("removed stale lock file")
-				continue
-			}
+		// Remove the lock only if its holder is gone; a live holder keeps
+		// it however long it runs. Unstamped locks go by age.
+		if info, statErr := os.Stat(lockPath); statErr == nil && inspectLock(lockPath, info).Stale() {
+			os.Remove(lockPath)
+			logDebug("removed stale lock file")
+			continue
 		}
 
-		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
 	}
 
-	return nil, "", fmt.Errorf("timeout waiting for file lock")
+	return nil, fmt.Errorf("timeout waiting for %s", filepath.Base(lockPath))
 }
 
 // releaseFileLock releases the file lock.
@@ -52,3 +54,32 @@ func releaseFileLock(lock *os.File) {
 	lock.Close()
 	logDebug("released file lock")
 }
+
+// lockFilesGoStale is true: a crashed holder leaves its lock file behind,
+// and nothing else removes it.
+const lockFilesGoStale = true
+
+// lockHeld reports whether the lock file at path exists, which is what
+// holds it.
+func lockHeld(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// unlockFile releases a lock taken with lockFile. The file's existence is
+// the lock, so it is removed.
+func unlockFile(lock *os.File, lockPath string) {
+	releaseFileLock(lock)
+	os.Remove(lockPath)
+}
+
+// processAlive reports whether a process with the given PID exists.
+// On Windows FindProcess opens a handle and fails for unknown PIDs.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
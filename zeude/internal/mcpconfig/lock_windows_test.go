@@ -0,0 +1,72 @@
+//go:build windows
+
+package mcpconfig
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// TestStaleLockRemoved fabricates the lock file a crashed sync leaves
+// behind: it is reported stale and RemoveStaleLock deletes it.
+func TestStaleLockRemoved(t *testing.T) {
+	home := useTestHome(t)
+	e := testEnv(t)
+	e.Home = home
+	if err := ensureZeudeDir(e); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := paths.SyncLock(e)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(deadPID(t))), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	locks, err := InspectLocks()
+	if err != nil || len(locks) != 1 {
+		t.Fatalf("InspectLocks() = %v, %v; want the leftover lock", locks, err)
+	}
+	if l := locks[0]; l.HolderAlive || !l.Stale() {
+		t.Errorf("InspectLocks() = %+v, want a stale lock with a dead holder", l)
+	}
+	if err := RemoveStaleLock(path); err != nil {
+		t.Fatalf("RemoveStaleLock: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file still present after RemoveStaleLock: %v", err)
+	}
+}
+
+// TestOldLockWithLiveHolderKept checks lockFile waits out a lock older than
+// StaleLockAge whose holder is still running, instead of taking it over.
+func TestOldLockWithLiveHolderKept(t *testing.T) {
+	home := useTestHome(t)
+	e := testEnv(t)
+	e.Home = home
+	if err := ensureZeudeDir(e); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := paths.SyncLock(e)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * StaleLockAge)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if lock, err := lockFile(context.Background(), path, 200*time.Millisecond); err == nil {
+		unlockFile(lock, path)
+		t.Fatal("lockFile took over a lock whose holder is alive")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("live holder's lock file was removed: %v", err)
+	}
+	if err := RemoveStaleLock(path); err == nil {
+		t.Error("RemoveStaleLock removed a lock whose holder is alive")
+	}
+}
@@ -0,0 +1,214 @@
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// CheckAgentKey asks the dashboard for the config with key, exactly as a
+// sync would. A rejected key yields an *AuthError; nothing is cached or written.
+func CheckAgentKey(ctx context.Context, key string) (*ConfigResponse, error) {
+	return fetchConfig(ctx, env.OS{}, key, "")
+}
+
+// SaveAgentKey replaces the credentials file with key, readable only by the
+// user. Only call it with a key the dashboard accepted.
+func SaveAgentKey(key string) error {
+	e := env.OS{}
+	if err := ensureZeudeDir(e); err != nil {
+		return err
+	}
+	credPath, err := paths.Credentials(e)
+	if err != nil {
+		return err
+	}
+	// [FIX #4] 0600: the key authenticates as the user
+	return writeFileAtomic(credPath, []byte(config.AgentKeyName+"="+key+"\n"), 0600)
+}
+
+// ErrAgentKeyFromEnv means the key in use comes from ZEUDE_AGENT_KEY, which
+// Zeude can't update after rotating it.
+var ErrAgentKeyFromEnv = fmt.Errorf("the agent key comes from %s; rotate it where that variable is set", config.AgentKeyEnv)
+
+// RotationSaveError means the dashboard issued a new key but it could not
+// be saved. The old key may already be revoked, so NewKey must be shown to
+// the user rather than lost.
+type RotationSaveError struct {
+	NewKey string
+	Err    error
+}
+
+func (e *RotationSaveError) Error() string {
+	return fmt.Sprintf("failed to save the new agent key: %v", e.Err)
+}
+
+func (e *RotationSaveError) Unwrap() error { return e.Err }
+
+// rotateKeyResponse is the dashboard's answer to a rotation request.
+type rotateKeyResponse struct {
+	AgentKey string `json:"agentKey"`
+}
+
+// RotateAgentKey asks the dashboard to replace the stored agent key, then
+// writes the new key to the credentials file and drops the config cache so
+// the next sync rewrites everything that embeds the key. If the dashboard
+// call fails the credentials are left untouched. The caller should sync
+// right after, since hook scripts still carry the old key until then.
+func RotateAgentKey(ctx context.Context) (string, error) {
+	return rotateAgentKey(ctx, env.OS{})
+}
+
+func rotateAgentKey(ctx context.Context, e env.Env) (string, error) {
+	if strings.TrimSpace(e.Getenv(config.AgentKeyEnv)) != "" {
+		return "", ErrAgentKeyFromEnv
+	}
+	oldKey := getAgentKey(e)
+	if oldKey == "" {
+		return "", fmt.Errorf("no agent key configured; run 'zeude login' first")
+	}
+
+	newKey, err := requestKeyRotation(ctx, e, oldKey)
+	if err != nil {
+		return "", err
+	}
+
+	err = withFileLock(ctx, e, func() error {
+		credPath, err := paths.Credentials(e)
+		if err == nil {
+			// [FIX #4] 0600: the key authenticates as the user
+			err = writeFileAtomic(credPath, []byte(config.AgentKeyName+"="+newKey+"\n"), 0600)
+		}
+		if err != nil {
+			return err
+		}
+		if cachePath, err := getCachePath(e); err == nil {
+			if err := removeFile(cachePath); err != nil && !os.IsNotExist(err) {
+				logError("failed to remove cache after key rotation: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return newKey, &RotationSaveError{NewKey: newKey, Err: err}
+	}
+	logger.Info("rotated agent key", "old", config.MaskAgentKey(oldKey), "new", config.MaskAgentKey(newKey))
+	return newKey, nil
+}
+
+// requestKeyRotation calls the dashboard's rotation endpoint. Not retried:
+// a lost response to a request that succeeded would leave a retry
+// authenticating with the revoked key.
+func requestKeyRotation(ctx context.Context, e env.Env, oldKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout(e).Value)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/agent-keys/rotate", getDashboardURL(e))
+	req, err := httpclient.NewRequest(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+oldKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("rotation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read rotation response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", &AuthError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("the dashboard does not support key rotation (HTTP 404)")
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("key rotation failed: %d", resp.StatusCode)
+	}
+
+	var out rotateKeyResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("invalid rotation response: %w", err)
+	}
+	newKey := strings.TrimSpace(out.AgentKey)
+	if problems := config.ValidateAgentKey(newKey); len(problems) > 0 {
+		return "", fmt.Errorf("dashboard returned an invalid agent key: %s", strings.Join(problems, "; "))
+	}
+	if newKey == oldKey {
+		return "", fmt.Errorf("dashboard returned the same agent key")
+	}
+	return newKey, nil
+}
+
+// LogoutOptions customizes a logout. The zero value logs out the real machine
+// and removes everything Zeude synced.
+type LogoutOptions struct {
+	// Env supplies the home directory and environment. nil means the real
+	// process environment.
+	Env env.Env
+	// KeepLocal removes only the credentials and the config cache, leaving
+	// synced servers, hooks and skills (and the lists tracking them) in place
+	// so the next login's sync reconciles them.
+	KeepLocal bool
+}
+
+// LogoutResult lists what Logout removed.
+type LogoutResult struct {
+	CredentialsRemoved bool
+	Managed            UninstallResult // empty with KeepLocal
+}
+
+// Logout deletes the stored agent key and cleans up the way an auth
+// revocation does, under the claude.json lock so a running sync can't
+// re-add what is being removed.
+func Logout(ctx context.Context, opts LogoutOptions) (LogoutResult, error) {
+	e := env.OrDefault(opts.Env)
+	var result LogoutResult
+	err := withFileLock(ctx, e, func() (err error) {
+		result, err = logout(e, opts.KeepLocal)
+		return err
+	})
+	return result, err
+}
+
+// logout does the work of Logout. The caller holds the lock.
+func logout(e env.Env, keepLocal bool) (LogoutResult, error) {
+	var result LogoutResult
+	credPath, err := paths.Credentials(e)
+	if err != nil {
+		return result, err
+	}
+	if err := removeFile(credPath); err == nil {
+		result.CredentialsRemoved = true
+	} else if !os.IsNotExist(err) {
+		return result, fmt.Errorf("failed to remove credentials: %w", err)
+	}
+
+	if keepLocal {
+		if cachePath, err := getCachePath(e); err == nil {
+			if err := removeFile(cachePath); err != nil && !os.IsNotExist(err) {
+				return result, fmt.Errorf("failed to remove cache: %w", err)
+			}
+		}
+		return result, nil
+	}
+
+	result.Managed, err = removeManaged(e, false)
+	if err != nil {
+		return result, err
+	}
+	clearCache(e)
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package mcpconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+)
+
+func TestMain(m *testing.M) {
+	// Keep test runs out of the real ~/.zeude logs and audit trail
+	logger = logging.New(logging.Options{}).Component("sync")
+	audit = logging.NewAuditLog("", 0, 0)
+	os.Exit(m.Run())
+}
+
+// testEnv returns a fake environment with a fresh temp home and a fixed
+// clock. vars are alternating keys and values.
+func testEnv(t testing.TB, vars ...string) *env.Fake {
+	t.Helper()
+	e := &env.Fake{
+		Home: t.TempDir(),
+		Vars: map[string]string{},
+		Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for i := 0; i+1 < len(vars); i += 2 {
+		e.Vars[vars[i]] = vars[i+1]
+	}
+	return e
+}
+
+// fakeDashboard serves config as /api/config/_, answering a matching
+// If-None-Match with 304, and accepts every other request.
+type fakeDashboard struct {
+	*httptest.Server
+	mu       sync.Mutex
+	config   string
+	requests map[string]int
+	keys     map[string]bool // Authorization headers seen
+}
+
+func newFakeDashboard(t *testing.T, config string) *fakeDashboard {
+	t.Helper()
+	d := &fakeDashboard{config: config, requests: map[string]int{}, keys: map[string]bool{}}
+	d.Server = httptest.NewServer(http.HandlerFunc(d.serve))
+	t.Cleanup(d.Close)
+	return d
+}
+
+func (d *fakeDashboard) serve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	d.requests[r.URL.Path]++
+	d.keys[r.Header.Get("Authorization")] = true
+	config := d.config
+	d.mu.Unlock()
+
+	if r.URL.Path != "/api/config/_" {
+		fmt.Fprint(w, "{}")
+		return
+	}
+	var v struct {
+		ConfigVersion string `json:"configVersion"`
+	}
+	json.Unmarshal([]byte(config), &v)
+	if v.ConfigVersion != "" && r.Header.Get("If-None-Match") == v.ConfigVersion {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", `"`+v.ConfigVersion+`"`)
+	fmt.Fprint(w, config)
+}
+
+// setConfig changes the config served from now on.
+func (d *fakeDashboard) setConfig(config string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.config = config
+}
+
+// syncEnv returns a test environment with an agent key, pointed at d.
+func syncEnv(t *testing.T, d *fakeDashboard) *env.Fake {
+	t.Helper()
+	return testEnv(t, "ZEUDE_DASHBOARD_URL", d.URL, "ZEUDE_AGENT_KEY", "zd_test")
+}
+
+// useTestHome points the process environment at a temp home, for code
+// that reads the real environment (env.OS), and returns it.
+func useTestHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	for _, key := range []string{"ZEUDE_HOME", "ZEUDE_USE_XDG", "XDG_DATA_HOME", "CLAUDE_CONFIG_DIR"} {
+		t.Setenv(key, "")
+	}
+	return home
+}
+
+// deadPID returns the PID of a process that has already exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a short-lived process: %v", err)
+	}
+	return cmd.Process.Pid
+}
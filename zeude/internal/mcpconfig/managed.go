@@ -0,0 +1,424 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/textdiff"
+)
+
+// ManagedState lists what the last sync put under Zeude's management.
+type ManagedState struct {
+	Servers []string // MCP server keys in claude.json
+	Hooks   []string // hook script paths
+	Skills  []string // skill file paths
+}
+
+// LoadManagedState reads the managed servers, hooks and skills lists (see
+// Manifest). Missing or unreadable lists are empty. No network access.
+func LoadManagedState() ManagedState {
+	return loadManagedState(env.OS{})
+}
+
+func loadManagedState(e env.Env) ManagedState {
+	m := loadManifest(e)
+	return ManagedState{Servers: m.Servers, Hooks: m.Hooks, Skills: m.Skills}
+}
+
+// Skill file states reported by ListSkills.
+const (
+	SkillSynced   = "synced"   // on-disk content matches the last sync
+	SkillModified = "modified" // edited since it was synced
+	SkillMissing  = "missing"  // managed but deleted from disk
+	SkillUnknown  = "unknown"  // managed, but absent from the cached config
+)
+
+// SkillStatus describes one Zeude-managed skill file.
+type SkillStatus struct {
+	Name  string `json:"name,omitempty"`
+	Slug  string `json:"slug,omitempty"` // invoked as /Slug; see Skill.Command
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	State string `json:"state"`
+}
+
+// ListSkills reports each managed skill, comparing the file on disk with
+// what the cached config would write. No network access.
+func ListSkills() ([]SkillStatus, error) {
+	return listSkills(env.OS{})
+}
+
+func listSkills(e env.Env) ([]SkillStatus, error) {
+	commandsDir, err := paths.ClaudeCommands(e)
+	if err != nil {
+		return nil, err
+	}
+	bySkillPath := make(map[string]Skill)
+	if cached, _ := loadCachedConfig(e); cached != nil {
+		for _, skill := range cached.Config.Skills {
+			path := skillFilePath(commandsDir, skill)
+			// installSkills keeps the first skill for a path
+			if _, dup := bySkillPath[path]; skill.Slug != "" && !dup {
+				bySkillPath[path] = skill
+			}
+		}
+	}
+
+	var list []SkillStatus
+	for _, path := range loadManagedState(e).Skills {
+		status := SkillStatus{Path: path, State: SkillUnknown}
+		skill, known := bySkillPath[path]
+		if known {
+			status.Name, status.Slug = skill.Name, skill.Command()
+		}
+
+		data, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			status.State = SkillMissing
+		case err != nil:
+			return nil, err
+		default:
+			status.Size = int64(len(data))
+			if known {
+				status.State = SkillSynced
+				if !bytes.Equal(data, skillFileContent(skill)) {
+					status.State = SkillModified
+				}
+			}
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// zeudeHookHeader marks scripts written by installHooks.
+const zeudeHookHeader = "# Auto-generated by Zeude"
+
+// HookStatus describes a Zeude hook as seen from the managed list, the
+// hooks directory and settings.json. Problem explains why it may not fire.
+type HookStatus struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Event      string `json:"event"`
+	ScriptType string `json:"scriptType"`
+	Path       string `json:"path"`
+	Managed    bool   `json:"managed"`    // listed in managed.json
+	Exists     bool   `json:"exists"`     // script file is present
+	Executable bool   `json:"executable"` // script has an execute bit
+	Registered bool   `json:"registered"` // settings.json runs this path
+	Disabled   bool   `json:"disabled"`   // turned off locally with 'zeude hooks disable'
+	Problem    string `json:"problem,omitempty"`
+	Matcher    string `json:"matcher,omitempty"`
+	Timeout    int    `json:"timeout,omitempty"` // seconds, clamped; 0 is Claude's default
+}
+
+// ListHooks cross-references managed hooks, Zeude scripts on disk, and
+// settings.json registrations. No network access.
+func ListHooks() ([]HookStatus, error) {
+	return listHooks(env.OS{})
+}
+
+func listHooks(e env.Env) ([]HookStatus, error) {
+	hooksDir, err := getClaudeHooksDir(e)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := readClaudeSettings(e)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]Hook)
+	if cached, _ := loadCachedConfig(e); cached != nil {
+		for _, hook := range cached.Config.Hooks {
+			known[hookFilePath(hooksDir, hook)] = hook
+		}
+	}
+	overrides := loadOverrides(e)
+
+	// Collect every path any source knows about, in a stable order
+	byPath := make(map[string]*HookStatus)
+	var order []string
+	add := func(path string) *HookStatus {
+		if h, ok := byPath[path]; ok {
+			return h
+		}
+		h := &HookStatus{Path: path}
+		byPath[path] = h
+		order = append(order, path)
+		return h
+	}
+
+	for _, path := range loadManagedHooks(e) {
+		add(path).Managed = true
+	}
+	if section, ok := settings["hooks"].(map[string]interface{}); ok {
+		for _, entries := range section {
+			list, _ := entries.([]interface{})
+			for _, entry := range list {
+				cmd := hookCommand(entry)
+				if cmd != "" && isWithin(hooksDir, cmd) {
+					add(cmd).Registered = true
+				}
+			}
+		}
+	}
+	eventDirs, _ := os.ReadDir(hooksDir)
+	for _, dir := range eventDirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, _ := os.ReadDir(filepath.Join(hooksDir, dir.Name()))
+		for _, file := range files {
+			path := filepath.Join(hooksDir, dir.Name(), file.Name())
+			if _, known := byPath[path]; known || file.IsDir() {
+				continue
+			}
+			if data, err := os.ReadFile(path); err == nil && bytes.Contains(data, []byte(zeudeHookHeader)) {
+				add(path)
+			}
+		}
+	}
+
+	list := make([]HookStatus, 0, len(order))
+	for _, path := range order {
+		h := byPath[path]
+		h.ID, h.Name, h.Matcher = known[path].ID, known[path].Name, known[path].Matcher
+		h.Timeout, _ = clampHookTimeout(known[path].TimeoutSeconds)
+		h.Disabled = overrides.hookDisabled(h.ID)
+		h.Event = filepath.Base(filepath.Dir(path))
+		h.ScriptType = "bash"
+		for scriptType, ext := range hookScriptExts {
+			if filepath.Ext(path) == ext {
+				h.ScriptType = scriptType
+			}
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			h.Exists = true
+			h.Executable = info.Mode()&0111 != 0
+		}
+		h.Problem = hookProblem(h)
+		list = append(list, *h)
+	}
+	return list, nil
+}
+
+// hookProblem explains why a hook won't fire, or "" if nothing looks wrong.
+func hookProblem(h *HookStatus) string {
+	switch {
+	case h.Disabled && h.Registered:
+		return "disabled locally but still registered; run 'zeude sync'"
+	case h.Disabled:
+		return ""
+	case !h.Managed && !h.Registered:
+		return "leftover Zeude script, neither managed nor registered"
+	case h.Registered && !h.Exists:
+		return "registered in settings.json but the script is missing"
+	case !h.Exists:
+		return "script is missing"
+	case !h.Executable:
+		return "script is not executable"
+	case !h.Registered:
+		return "not registered in settings.json"
+	case !h.Managed:
+		return "not in managed.json; the next sync won't update or remove it"
+	}
+	return ""
+}
+
+// isWithin reports whether path is inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// ServerStatus describes an MCP server in claude.json. Env values are never
+// included, only their names.
+type ServerStatus struct {
+	Name      string   `json:"name"`
+	Managed   bool     `json:"managed"`            // listed in managed.json
+	Disabled  bool     `json:"disabled,omitempty"` // turned off locally; not in claude.json
+	Type      string   `json:"type,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Args      []string `json:"args,omitempty"`
+	URL       string   `json:"url,omitempty"`
+	EnvNames  []string `json:"envNames,omitempty"`
+	Installed *bool    `json:"installed,omitempty"` // from the last install check; nil if never checked
+	Version   string   `json:"version,omitempty"`
+}
+
+// ListServers reports the servers in claude.json in file order, marking the
+// ones Zeude manages. No network access.
+func ListServers() ([]ServerStatus, error) {
+	return listServers(env.OS{})
+}
+
+func listServers(e env.Env) ([]ServerStatus, error) {
+	doc, err := readClaudeConfig(e)
+	if err != nil {
+		return nil, err
+	}
+	managed := loadManagedKeys(e)
+	installed := make(map[string]InstallStatus)
+	if status := loadInstallStatus(e); status != nil {
+		for _, s := range status.Servers {
+			installed[s.ServerName] = s
+		}
+	}
+
+	list := make([]ServerStatus, 0, len(doc.mcpServers))
+	for _, m := range doc.mcpServers {
+		var value struct {
+			Type    string                 `json:"type"`
+			Command string                 `json:"command"`
+			Args    []string               `json:"args"`
+			URL     string                 `json:"url"`
+			Env     map[string]interface{} `json:"env"`
+		}
+		// A malformed entry is still listed, just without details
+		json.Unmarshal(m.Value, &value)
+
+		s := ServerStatus{
+			Name:    m.Key,
+			Managed: contains(managed, m.Key),
+			Type:    value.Type,
+			Command: value.Command,
+			Args:    value.Args,
+			URL:     value.URL,
+		}
+		for name := range value.Env {
+			s.EnvNames = append(s.EnvNames, name)
+		}
+		sort.Strings(s.EnvNames)
+		if st, ok := installed[m.Key]; ok {
+			s.Installed = &st.Installed
+			s.Version = st.Version
+		}
+		list = append(list, s)
+	}
+
+	// Disabled servers are gone from claude.json but still Zeude's
+	overrides := loadOverrides(e)
+	if cached, _ := loadCachedConfig(e); cached != nil {
+		var disabled []ServerStatus
+		for key, server := range cached.Config.MCPServers {
+			if overrides.serverDisabled(key) {
+				disabled = append(disabled, ServerStatus{Name: key, Managed: true, Disabled: true, Type: server.Type, Command: server.Command, Args: server.Args, URL: server.URL})
+			}
+		}
+		sort.Slice(disabled, func(i, j int) bool { return disabled[i].Name < disabled[j].Name })
+		list = append(list, disabled...)
+	}
+	return list, nil
+}
+
+// ErrSkillNotFound means no managed skill or command file has the slug.
+var ErrSkillNotFound = errors.New("skill not found")
+
+// SkillDetail is one skill file with its contents, for `zeude skills show`.
+type SkillDetail struct {
+	SkillStatus
+	Managed bool   `json:"managed"`        // false: a user-owned file in the commands dir
+	Content string `json:"content"`        // on-disk contents
+	Diff    string `json:"diff,omitempty"` // cached config -> on disk, when modified
+}
+
+// ShowSkill finds a skill by slug (with or without the leading "/"). A
+// command file Zeude doesn't manage is returned with Managed false; when
+// neither exists the error is ErrSkillNotFound. No network access.
+func ShowSkill(slug string) (*SkillDetail, error) {
+	return showSkill(env.OS{}, slug)
+}
+
+func showSkill(e env.Env, slug string) (*SkillDetail, error) {
+	slug = strings.TrimPrefix(slug, "/")
+	commandsDir, err := paths.ClaudeCommands(e)
+	if err != nil {
+		return nil, err
+	}
+	skills, err := listSkills(e)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range skills {
+		if status.Slug != slug && skillCommandFromPath(commandsDir, status.Path) != slug {
+			continue
+		}
+		detail := &SkillDetail{SkillStatus: status, Managed: true}
+		if status.State == SkillMissing {
+			return detail, nil
+		}
+		data, err := os.ReadFile(status.Path)
+		if err != nil {
+			return nil, err
+		}
+		detail.Content = string(data)
+		if status.State == SkillModified {
+			if expected := cachedSkillContent(e, status.Slug); expected != nil {
+				detail.Diff = textdiff.Unified(status.Path+" (synced)", status.Path, string(expected), detail.Content)
+			}
+		}
+		return detail, nil
+	}
+
+	// Not managed; it may still be one of the user's own commands
+	namespace, name, found := strings.Cut(slug, ":")
+	if !found {
+		namespace, name = "", slug
+	}
+	path := skillFilePath(commandsDir, Skill{Namespace: namespace, Slug: name})
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrSkillNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &SkillDetail{
+		SkillStatus: SkillStatus{Slug: slug, Path: path, Size: int64(len(data)), State: SkillUnknown},
+		Content:     string(data),
+	}, nil
+}
+
+// ManagedSkillSlugs lists the slugs in the managed skills manifest, for
+// shell completion. No network access.
+func ManagedSkillSlugs() []string {
+	e := env.OS{}
+	commandsDir, _ := paths.ClaudeCommands(e)
+	var slugs []string
+	for _, path := range loadManagedState(e).Skills {
+		slugs = append(slugs, skillCommandFromPath(commandsDir, path))
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// skillCommandFromPath returns what the command file at path is invoked
+// by: its name, prefixed by namespace: when it is in a subdirectory.
+func skillCommandFromPath(commandsDir, path string) string {
+	rel, err := filepath.Rel(commandsDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(rel), ".md"), "/", ":")
+}
+
+func cachedSkillContent(e env.Env, slug string) []byte {
+	cached, _ := loadCachedConfig(e)
+	if cached == nil {
+		return nil
+	}
+	for _, skill := range cached.Config.Skills {
+		if skill.Command() == slug {
+			return skillFileContent(skill)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// manifestVersion is the schema version written to managed.json.
+const manifestVersion = 1
+
+// Manifest is ~/.zeude/managed.json: everything a sync put under Zeude's
+// management, in one file. It replaces managed-keys.json, managed-hooks.json
+// and managed_skills.json, which older versions wrote separately.
+type Manifest struct {
+	Version   int       `json:"version"`
+	Servers   []string  `json:"servers"` // MCP server keys in claude.json
+	Hooks     []string  `json:"hooks"`   // hook script paths
+	Skills    []string  `json:"skills"`  // skill file paths
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// legacyManifest is one of the per-kind files managed.json replaces.
+type legacyManifest struct {
+	name  string
+	path  func(env.Env) (string, error)
+	field string // object field holding the list; older files were bare arrays
+	list  func(*Manifest) *[]string
+}
+
+var legacyManifests = []legacyManifest{
+	{paths.ManagedKeysFile, paths.ManagedKeys, "keys", func(m *Manifest) *[]string { return &m.Servers }},
+	{paths.ManagedHooksFile, paths.ManagedHooks, "hooks", func(m *Manifest) *[]string { return &m.Hooks }},
+	{paths.ManagedSkillsFile, paths.ManagedSkills, "", func(m *Manifest) *[]string { return &m.Skills }},
+}
+
+// manifestMu serializes read-modify-write of managed.json within a process.
+// Hooks and skills are installed outside the file lock, so updateManifest
+// re-reads right before writing to keep the window for other processes small.
+var manifestMu sync.Mutex
+
+// loadManifest reads managed.json, taking each section from its legacy file
+// instead when that file is at least as new: either nothing has migrated it
+// yet, or an older zeude (or a restored backup) wrote it since. Missing or
+// unreadable files leave their section empty.
+func loadManifest(e env.Env) Manifest {
+	var m Manifest
+	var manifestTime time.Time
+	if path, err := paths.Manifest(e); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			data, err := os.ReadFile(path)
+			if err == nil {
+				err = json.Unmarshal(data, &m)
+			}
+			if err != nil {
+				logError("ignoring unreadable %s: %v", paths.ManifestFile, err)
+				m = Manifest{}
+			} else {
+				manifestTime = info.ModTime()
+			}
+		}
+	}
+	for _, legacy := range legacyManifests {
+		list, info, ok := readLegacyManifest(e, legacy)
+		if ok && !info.ModTime().Before(manifestTime) {
+			*legacy.list(&m) = list
+		}
+	}
+	return m
+}
+
+// readLegacyManifest decodes a legacy list file, either {"<field>": [...]}
+// or a bare array.
+func readLegacyManifest(e env.Env, legacy legacyManifest) ([]string, os.FileInfo, bool) {
+	path, err := legacy.path(e)
+	if err != nil {
+		return nil, nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, info, true
+	}
+	var obj map[string]json.RawMessage
+	if legacy.field == "" || json.Unmarshal(data, &obj) != nil {
+		logError("ignoring unreadable %s", legacy.name)
+		return nil, nil, false
+	}
+	if raw, ok := obj[legacy.field]; ok && json.Unmarshal(raw, &list) != nil {
+		logError("ignoring unreadable %s", legacy.name)
+		return nil, nil, false
+	}
+	return list, info, true
+}
+
+// saveManifest writes managed.json and then removes the legacy files, whose
+// contents loadManifest already folded into m.
+func saveManifest(e env.Env, m Manifest) error {
+	if err := ensureZeudeDir(e); err != nil {
+		return err
+	}
+	path, err := paths.Manifest(e)
+	if err != nil {
+		return err
+	}
+	m.Version = manifestVersion
+	m.UpdatedAt = e.Now()
+	for _, list := range []*[]string{&m.Servers, &m.Hooks, &m.Skills} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	for _, legacy := range legacyManifests {
+		if legacyPath, err := legacy.path(e); err == nil {
+			if err := removeFile(legacyPath); err != nil && !os.IsNotExist(err) {
+				logError("failed to remove %s: %v", legacy.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// updateManifest applies change to the current manifest and saves it.
+func updateManifest(e env.Env, change func(*Manifest)) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	m := loadManifest(e)
+	change(&m)
+	return saveManifest(e, m)
+}
+
+// removeManifest deletes managed.json and any legacy files.
+func removeManifest(e env.Env) {
+	if path, err := paths.Manifest(e); err == nil {
+		removeFile(path)
+	}
+	for _, legacy := range legacyManifests {
+		if path, err := legacy.path(e); err == nil {
+			removeFile(path)
+		}
+	}
+}
+
+// legacyManifestFiles returns the names of the legacy files present.
+func legacyManifestFiles(e env.Env) []string {
+	var names []string
+	for _, legacy := range legacyManifests {
+		if path, err := legacy.path(e); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				names = append(names, legacy.name)
+			}
+		}
+	}
+	return names
+}
+
+// ManifestMigration describes what MigrateManifest converted.
+type ManifestMigration struct {
+	Path    string   `json:"path"`    // managed.json
+	Files   []string `json:"files"`   // legacy files folded in and removed
+	Servers int      `json:"servers"` // entries in the resulting manifest
+	Hooks   int      `json:"hooks"`
+	Skills  int      `json:"skills"`
+}
+
+// Migrated reports whether there was anything to convert.
+func (m *ManifestMigration) Migrated() bool { return len(m.Files) > 0 }
+
+// MigrateOptions configures MigrateManifest.
+type MigrateOptions struct {
+	Env env.Env
+	// DryRun reports what would be converted without writing anything.
+	DryRun bool
+}
+
+// MigrateManifest converts legacy managed-keys.json, managed-hooks.json and
+// managed_skills.json into managed.json, under the file lock. Running it
+// again once they are gone changes nothing.
+func MigrateManifest(ctx context.Context, opts MigrateOptions) (*ManifestMigration, error) {
+	e := env.OrDefault(opts.Env)
+	var migration *ManifestMigration
+	err := withFileLock(ctx, e, func() (err error) {
+		migration, err = migrateManifest(e, opts.DryRun)
+		return err
+	})
+	return migration, err
+}
+
+// migrateManifest is MigrateManifest for callers already holding the lock.
+func migrateManifest(e env.Env, dryRun bool) (*ManifestMigration, error) {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	result := &ManifestMigration{}
+	path, err := paths.Manifest(e)
+	if err != nil {
+		return nil, err
+	}
+	result.Path = path
+	result.Files = legacyManifestFiles(e)
+
+	m := loadManifest(e)
+	result.Servers, result.Hooks, result.Skills = len(m.Servers), len(m.Hooks), len(m.Skills)
+	if !result.Migrated() || dryRun {
+		return result, nil
+	}
+	// The snapshot keeps the legacy files, so restoring it undoes this too
+	backupBeforeFirstWrite(e)
+	if err := saveManifest(e, m); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", paths.ManifestFile, err)
+	}
+	logger.Info("migrated managed lists", "files", len(result.Files), "servers", result.Servers, "hooks", result.Hooks, "skills", result.Skills)
+	return result, nil
+}
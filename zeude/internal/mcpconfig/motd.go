@@ -0,0 +1,88 @@
+package mcpconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// MOTD severities. Anything else is shown as info.
+const (
+	MOTDInfo     = "info"
+	MOTDWarning  = "warning"
+	MOTDCritical = "critical"
+)
+
+// motdSeenRetention is how long a shown message is remembered after it was
+// last shown; older entries are dropped when the file is rewritten.
+const motdSeenRetention = 30 * 24 * time.Hour
+
+// MOTD is a message the dashboard wants shown in the startup banner.
+type MOTD struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Severity  string    `json:"severity,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"` // zero: never expires
+}
+
+// key identifies the message in the seen file; messages without an ID are
+// told apart by their text.
+func (m *MOTD) key() string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Text
+}
+
+// TakeMOTD returns m if it should be shown now, recording that it was: a
+// message is shown at most once a day, and never after it expires. It
+// returns nil otherwise.
+func TakeMOTD(m *MOTD) *MOTD {
+	return takeMOTD(env.OS{}, m)
+}
+
+func takeMOTD(e env.Env, m *MOTD) *MOTD {
+	if m == nil || m.Text == "" {
+		return nil
+	}
+	now := e.Now()
+	if !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt) {
+		return nil
+	}
+	path, err := paths.MOTDSeen(e)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[string]time.Time{}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &seen)
+	}
+	if last, ok := seen[m.key()]; ok && sameDay(last, now) {
+		return nil
+	}
+
+	for id, last := range seen {
+		if now.Sub(last) > motdSeenRetention {
+			delete(seen, id)
+		}
+	}
+	seen[m.key()] = now
+	if data, err := json.Marshal(seen); err == nil && os.MkdirAll(filepath.Dir(path), 0700) == nil {
+		if err := fsutil.WriteFileAtomic(path, data, 0600); err != nil {
+			logDebug("failed to record motd: %v", err)
+		}
+	}
+	return m
+}
+
+// sameDay reports whether a and b fall on the same local calendar day.
+func sameDay(a, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.YearDay() == b.YearDay() && a.Year() == b.Year()
+}
@@ -0,0 +1,88 @@
+package mcpconfig
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// noNetwork fails the test on any request made through httpclient.
+type noNetwork struct{ t *testing.T }
+
+func (n noNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	n.t.Errorf("request while offline: %s %s", req.Method, req.URL)
+	return nil, errors.New("offline")
+}
+
+// goOffline sets ZEUDE_OFFLINE in e and fails the test on any request.
+func goOffline(t *testing.T, e *env.Fake) {
+	t.Helper()
+	e.Vars[config.OfflineEnv] = "1"
+	t.Cleanup(httpclient.SetTransport(noNetwork{t}))
+}
+
+func TestOfflineSyncAppliesExpiredCache(t *testing.T) {
+	d := newFakeDashboard(t, hookConfig)
+	e := syncEnv(t, d)
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success {
+		t.Fatalf("online sync failed: %+v", r)
+	}
+	settings, _ := getClaudeSettingsPath(e)
+	os.Remove(settings) // so the offline sync has something to do
+
+	e.Advance(30 * 24 * time.Hour)
+	goOffline(t, e)
+	r := Sync(context.Background(), SyncOptions{Env: e, ForceRefresh: true})
+	if !r.Success || !r.Offline || !r.FromCache || r.HookCount != 2 {
+		t.Fatalf("offline sync = %+v", r)
+	}
+	if _, err := os.Stat(settings); err != nil {
+		t.Errorf("offline sync didn't reinstall the hooks: %v", err)
+	}
+}
+
+func TestOfflineSyncWithoutCache(t *testing.T) {
+	d := newFakeDashboard(t, hookConfig)
+	e := syncEnv(t, d)
+	goOffline(t, e)
+
+	r := Sync(context.Background(), SyncOptions{Env: e})
+	if r.Success || !r.Offline || r.Err == nil {
+		t.Errorf("offline sync without a cache = %+v", r)
+	}
+}
+
+func TestOfflineStatusReportsAreQueued(t *testing.T) {
+	e := testEnv(t, "ZEUDE_DASHBOARD_URL", "http://dashboard.invalid")
+	goOffline(t, e)
+
+	ctx := context.Background()
+	if err := reportInstallStatus(ctx, e, "zd_test", []InstallStatus{{ServerName: "fs", Installed: true}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := reportHookInstallStatus(ctx, e, "zd_test", []HookInstallStatus{{HookID: "h1", Installed: true}}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := paths.StatusQueue(e)
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("nothing queued: %v", err)
+	}
+	defer f.Close()
+	lines := 0
+	for s := bufio.NewScanner(f); s.Scan(); lines++ {
+	}
+	if lines != 2 {
+		t.Errorf("queued %d reports, want 2", lines)
+	}
+}
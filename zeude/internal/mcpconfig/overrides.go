@@ -0,0 +1,183 @@
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// localOverrides are this machine's opt-outs from the team's config, kept in
+// ~/.zeude/overrides.json. Entries are IDs so they survive renames.
+type localOverrides struct {
+	DisabledHooks   []string `json:"disabledHooks,omitempty"`
+	DisabledServers []string `json:"disabledServers,omitempty"` // MCP server keys
+}
+
+func loadOverrides(e env.Env) localOverrides {
+	var o localOverrides
+	path, err := paths.Overrides(e)
+	if err != nil {
+		return o
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return o
+	}
+	if err := json.Unmarshal(data, &o); err != nil {
+		logError("ignoring unreadable %s: %v", path, err)
+	}
+	return o
+}
+
+func saveOverrides(e env.Env, o localOverrides) error {
+	if err := ensureZeudeDir(e); err != nil {
+		return err
+	}
+	path, err := paths.Overrides(e)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// hookDisabled reports whether the hook was turned off with SetHookDisabled.
+func (o localOverrides) hookDisabled(id string) bool {
+	return id != "" && contains(o.DisabledHooks, id)
+}
+
+// serverDisabled reports whether the server was turned off with
+// SetServerDisabled.
+func (o localOverrides) serverDisabled(key string) bool {
+	return contains(o.DisabledServers, key)
+}
+
+// enabledServers returns servers without the locally disabled ones.
+func (o localOverrides) enabledServers(servers map[string]MCPServer) map[string]MCPServer {
+	if len(o.DisabledServers) == 0 {
+		return servers
+	}
+	enabled := make(map[string]MCPServer, len(servers))
+	for key, server := range servers {
+		if !o.serverDisabled(key) {
+			enabled[key] = server
+		}
+	}
+	return enabled
+}
+
+// toggle returns list with id added (on) or removed, sorted.
+func toggle(list []string, id string, on bool) []string {
+	out := make([]string, 0, len(list)+1)
+	for _, v := range list {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	if on {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SetHookDisabled turns a managed hook off or back on for this machine.
+// Disabling takes its entry out of settings.json but leaves the script, and
+// later syncs keep it unregistered; enabling registers it again. h comes
+// from ListHooks and must have an ID, i.e. be in the last synced config.
+func SetHookDisabled(ctx context.Context, h HookStatus, disabled bool) error {
+	return setHookDisabled(ctx, env.OS{}, h, disabled)
+}
+
+func setHookDisabled(ctx context.Context, e env.Env, h HookStatus, disabled bool) error {
+	if h.ID == "" {
+		return fmt.Errorf("%s is not in the last synced config; run 'zeude sync' first", h.Path)
+	}
+	return withFileLock(ctx, e, func() error {
+		o := loadOverrides(e)
+		o.DisabledHooks = toggle(o.DisabledHooks, h.ID, disabled)
+		if err := saveOverrides(e, o); err != nil {
+			return fmt.Errorf("failed to save overrides: %w", err)
+		}
+
+		var err error
+		if disabled {
+			_, err = registerHooksInSettings(e, nil, nil, []string{h.Path})
+		} else if h.Exists {
+			_, err = registerHooksInSettings(e, nil, map[string][]hookRegistration{h.Event: {{Path: h.Path, Matcher: h.Matcher, Timeout: h.Timeout}}}, nil)
+		}
+		if err != nil {
+			return err
+		}
+		logger.Info("hook override changed", "hook", h.ID, "disabled", disabled)
+		return nil
+	})
+}
+
+// SetServerDisabled turns a Zeude-managed MCP server off or back on for this
+// machine. Disabling removes its claude.json entry, and later syncs leave it
+// out while still tracking it as managed; enabling puts back the entry from
+// the last synced config. Only that one entry is touched.
+func SetServerDisabled(ctx context.Context, key string, disabled bool) error {
+	return setServerDisabled(ctx, env.OS{}, key, disabled)
+}
+
+func setServerDisabled(ctx context.Context, e env.Env, key string, disabled bool) error {
+	var servers map[string]MCPServer
+	if cached, _ := loadCachedConfig(e); cached != nil {
+		servers = cached.Config.MCPServers
+	}
+	server, known := servers[key]
+
+	return withFileLock(ctx, e, func() error {
+		o := loadOverrides(e)
+		if !known && !contains(loadManagedKeys(e), key) && !o.serverDisabled(key) {
+			return fmt.Errorf("no Zeude-managed server %q (see 'zeude servers list')", key)
+		}
+		o.DisabledServers = toggle(o.DisabledServers, key, disabled)
+		if err := saveOverrides(e, o); err != nil {
+			return fmt.Errorf("failed to save overrides: %w", err)
+		}
+
+		doc, err := readClaudeConfig(e)
+		if err != nil {
+			return err
+		}
+		merged := make([]rawMember, 0, len(doc.mcpServers)+1)
+		present, changed := false, false
+		for _, m := range doc.mcpServers {
+			if m.Key == key {
+				present = true
+				if disabled {
+					changed = true
+					continue
+				}
+			}
+			merged = append(merged, m)
+		}
+		// An entry missing from the cache comes back with the next sync
+		if !disabled && !present && known {
+			value, err := serverEntry(server)
+			if err != nil {
+				return fmt.Errorf("failed to encode server %s: %w", key, err)
+			}
+			merged = append(merged, rawMember{Key: key, Value: value})
+			changed = true
+		}
+		if changed {
+			if err := writeClaudeConfig(e, doc, merged); err != nil {
+				return err
+			}
+		}
+		logger.Info("server override changed", "server", key, "disabled", disabled)
+		return nil
+	})
+}
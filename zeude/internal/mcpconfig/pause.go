@@ -0,0 +1,91 @@
+package mcpconfig
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// PauseState says whether sync is paused by 'zeude pause'.
+type PauseState struct {
+	Paused bool
+	Until  time.Time // zero when paused until 'zeude resume'
+}
+
+// Pause stops sync from fetching or writing anything until Resume is
+// called or, when d > 0, until d has passed.
+func Pause(d time.Duration) (PauseState, error) {
+	e := env.OS{}
+	path, err := paths.Paused(e)
+	if err != nil {
+		return PauseState{}, err
+	}
+	if err := ensureZeudeDir(e); err != nil {
+		return PauseState{}, err
+	}
+
+	state := PauseState{Paused: true}
+	content := ""
+	if d > 0 {
+		state.Until = e.Now().Add(d).UTC().Truncate(time.Second)
+		content = state.Until.Format(time.RFC3339) + "\n"
+	}
+	if err := writeFileAtomicWithOptions(path, []byte(content), 0600, atomicWriteOptions{NoDirSync: true}); err != nil {
+		return PauseState{}, err
+	}
+	return state, nil
+}
+
+// Resume removes the pause marker. It reports whether sync was paused.
+func Resume() (bool, error) {
+	path, err := paths.Paused(env.OS{})
+	if err != nil {
+		return false, err
+	}
+	if err := removeFile(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CurrentPause reports whether sync is paused right now.
+func CurrentPause() PauseState {
+	return pauseState(env.OS{})
+}
+
+// pauseState reads the pause marker. An expired marker is removed so the
+// pause ends on its own; an unreadable expiry counts as paused until resumed.
+func pauseState(e env.Env) PauseState {
+	path, err := paths.Paused(e)
+	if err != nil {
+		return PauseState{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PauseState{}
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return PauseState{Paused: true}
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logDebug("invalid pause expiry %q, pausing until resumed", value)
+		return PauseState{Paused: true}
+	}
+	if !e.Now().Before(until) {
+		logDebug("sync pause expired at %v", until)
+		if err := removeFile(path); err != nil && !os.IsNotExist(err) {
+			logError("failed to remove expired pause marker: %v", err)
+		}
+		return PauseState{}
+	}
+	return PauseState{Paused: true, Until: until}
+}
@@ -0,0 +1,162 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Plan actions.
+const (
+	PlanAdd        = "add"        // server added to claude.json or .mcp.json
+	PlanUpdate     = "update"     // server entry rewritten
+	PlanRemove     = "remove"     // server entry dropped
+	PlanWrite      = "write"      // hook or skill file (re)written
+	PlanDelete     = "delete"     // hook or skill file deleted
+	PlanRegister   = "register"   // hook added to settings.json
+	PlanUnregister = "unregister" // hook dropped from settings.json
+)
+
+// Plan reasons.
+const (
+	ReasonNew            = "new"
+	ReasonChanged        = "content changed"
+	ReasonLocallyDeleted = "locally deleted"
+	ReasonRemoved        = "removed from dashboard"
+	ReasonDisabled       = "disabled locally"
+	ReasonUnregistered   = "missing from settings.json"
+	ReasonUninstallAll   = "uninstalled by your organization"
+)
+
+// ErrSyncPaused means 'zeude pause' is in effect, so a sync would do nothing.
+var ErrSyncPaused = errors.New("sync is paused; run 'zeude resume' first")
+
+// PlanItem is one change a sync makes.
+type PlanItem struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// SyncPlan lists what a sync changes on disk, and why. Sync records one
+// as it goes; Plan runs the same code with every write skipped, so the
+// two can't disagree.
+type SyncPlan struct {
+	ConfigVersion string     `json:"configVersion"`
+	FromCache     bool       `json:"fromCache"`
+	UninstallAll  bool       `json:"uninstallAll,omitempty"` // the dashboard asked for everything to be removed
+	Servers       []PlanItem `json:"servers"`
+	Hooks         []PlanItem `json:"hooks"`
+	Skills        []PlanItem `json:"skills"`
+
+	dryRun bool
+}
+
+// Empty reports whether the sync changes nothing.
+func (p *SyncPlan) Empty() bool {
+	return len(p.Servers)+len(p.Hooks)+len(p.Skills) == 0
+}
+
+// apply reports whether changes are to be written; a nil plan writes.
+func (p *SyncPlan) apply() bool {
+	return p == nil || !p.dryRun
+}
+
+func (p *SyncPlan) server(action, name, path, reason string) {
+	if p != nil {
+		p.Servers = append(p.Servers, PlanItem{Name: name, Path: path, Action: action, Reason: reason})
+	}
+}
+
+func (p *SyncPlan) hook(action, name, path, reason string) {
+	if p != nil {
+		p.Hooks = append(p.Hooks, PlanItem{Name: name, Path: path, Action: action, Reason: reason})
+	}
+}
+
+func (p *SyncPlan) skill(action, name, path, reason string) {
+	if p != nil {
+		p.Skills = append(p.Skills, PlanItem{Name: name, Path: path, Action: action, Reason: reason})
+	}
+}
+
+// servers records the outcome of mergeServerMembers for the file at path.
+// Managed servers missing from the file were deleted there by hand.
+func (p *SyncPlan) servers(path string, added, updated, removed, oldKeys []string, disabled func(string) bool) {
+	for _, key := range added {
+		reason := ReasonNew
+		if contains(oldKeys, key) {
+			reason = ReasonLocallyDeleted
+		}
+		p.server(PlanAdd, key, path, reason)
+	}
+	for _, key := range updated {
+		p.server(PlanUpdate, key, path, ReasonChanged)
+	}
+	for _, key := range removed {
+		reason := ReasonRemoved
+		if disabled(key) {
+			reason = ReasonDisabled
+		}
+		p.server(PlanRemove, key, path, reason)
+	}
+}
+
+// uninstall records what removeManaged removes for uninstallAll.
+func (p *SyncPlan) uninstall(e env.Env, removed UninstallResult) {
+	if p == nil {
+		return
+	}
+	p.UninstallAll = true
+	configPath, _ := getClaudeConfigPath(e)
+	for _, key := range removed.Servers {
+		p.server(PlanRemove, key, configPath, ReasonUninstallAll)
+	}
+	for _, path := range removed.ProjectFiles {
+		p.server(PlanRemove, filepath.Base(filepath.Dir(path)), path, ReasonUninstallAll)
+	}
+	for _, path := range removed.HookFiles {
+		p.hook(PlanDelete, filepath.Base(path), path, ReasonUninstallAll)
+	}
+	for _, path := range removed.SkillFiles {
+		p.skill(PlanDelete, filepath.Base(path), path, ReasonUninstallAll)
+	}
+}
+
+// changeReason says why path must be rewritten to hold data, or returns ""
+// when it already does. managed is whether an earlier sync wrote it.
+func changeReason(path string, data []byte, managed bool) string {
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(existing, data):
+		return ""
+	case os.IsNotExist(err) && managed:
+		return ReasonLocallyDeleted
+	case os.IsNotExist(err):
+		return ReasonNew
+	}
+	return ReasonChanged
+}
+
+// Plan fetches the config the way Sync does, or takes it from the cache,
+// and returns what a sync with opts would change, without writing
+// anything. The config fetched isn't cached and no status is reported, so
+// the next sync fetches again.
+func Plan(ctx context.Context, opts SyncOptions) (*SyncPlan, error) {
+	plan := &SyncPlan{dryRun: true}
+	result := runSync(ctx, opts, plan)
+	switch {
+	case result.Paused:
+		return nil, ErrSyncPaused
+	case result.NoAgentKey:
+		return nil, errors.New("no agent key configured")
+	case result.Err != nil:
+		return nil, result.Err
+	}
+	return plan, nil
+}
@@ -0,0 +1,139 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/project"
+)
+
+// ProjectHeader carries the project ID on config fetches so the dashboard
+// can include servers targeted at it.
+const ProjectHeader = "X-Zeude-Project"
+
+// currentProject returns the project file for the working directory, or
+// nil. A project file that can't be read is ignored with a warning.
+func currentProject(e env.Env) *project.Config {
+	p, err := project.Find(e)
+	if err != nil {
+		logger.Warn("ignoring unreadable project file", "error", err)
+		return nil
+	}
+	return p
+}
+
+// trustedProject returns the project file for the working directory if
+// the user trusted it, or nil. An untrusted file still names the project,
+// but none of its overrides apply.
+func trustedProject(e env.Env) *project.Config {
+	p := currentProject(e)
+	if p == nil {
+		return nil
+	}
+	if !p.Trusted(e) {
+		if p.Overrides() {
+			logDebug("ignoring overrides in untrusted %s", p.Path)
+		}
+		return nil
+	}
+	return p
+}
+
+// dashboardProject returns the trusted project file that points syncs at
+// its own dashboard, or nil when they go to the global one. That dashboard
+// only gets the project's key, and what it sends only reaches the
+// project's .mcp.json.
+func dashboardProject(e env.Env) *project.Config {
+	if e.Getenv("ZEUDE_DASHBOARD_URL") != "" {
+		return nil
+	}
+	if p := trustedProject(e); p != nil && p.Get(project.DashboardURLKey) != "" {
+		return p
+	}
+	return nil
+}
+
+// projectAgentKey returns the key from the variable a trusted project file
+// names in agent_key_env, and that name, or "".
+func projectAgentKey(e env.Env) (string, string) {
+	p := trustedProject(e)
+	if p == nil {
+		return "", ""
+	}
+	return p.AgentKey(e), p.Get(project.AgentKeyEnvKey)
+}
+
+// DashboardFromProject reports whether the dashboard comes from a trusted
+// project file rather than the global settings.
+func DashboardFromProject() bool {
+	return dashboardProject(env.OS{}) != nil
+}
+
+// ProjectRegistration describes a project for RegisterProject.
+type ProjectRegistration struct {
+	Name      string `json:"name"`
+	GitRemote string `json:"gitRemote,omitempty"`
+}
+
+// RegisterProject records a project with the dashboard and returns the ID
+// it assigned. A rejected key yields an *AuthError.
+func RegisterProject(ctx context.Context, reg ProjectRegistration) (string, error) {
+	return registerProject(ctx, env.OS{}, reg)
+}
+
+func registerProject(ctx context.Context, e env.Env, reg ProjectRegistration) (string, error) {
+	agentKey := getAgentKey(e)
+	if agentKey == "" {
+		return "", fmt.Errorf("no agent key configured; run 'zeude login' first")
+	}
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout(e).Value)
+	defer cancel()
+	req, err := httpclient.NewRequest(ctx, http.MethodPost, getDashboardURL(e)+"/api/projects", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+agentKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("project registration failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", &AuthError{StatusCode: resp.StatusCode, Message: "access denied or revoked"}
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("the dashboard does not support projects (HTTP 404)")
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		return "", fmt.Errorf("project registration failed: %d", resp.StatusCode)
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", fmt.Errorf("invalid registration response: %w", err)
+	}
+	if out.ID == "" || strings.ContainsAny(out.ID, "\r\n") {
+		return "", fmt.Errorf("dashboard returned an invalid project ID")
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,263 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/project"
+)
+
+// Server scopes. Global servers (the default) go to ~/.claude.json;
+// project servers go to the .mcp.json of the repositories they match.
+const (
+	ScopeGlobal  = "global"
+	ScopeProject = "project"
+)
+
+// projectServersFile is where Claude Code reads a project's servers from.
+const projectServersFile = ".mcp.json"
+
+// ProjectMatch selects the repositories a project-scoped server is for.
+// Every field that is set must match; a match with none set matches
+// nothing.
+type ProjectMatch struct {
+	// GitRemote is the origin URL in any form: git@host:org/repo.git and
+	// https://host/org/repo are the same repository.
+	GitRemote string `json:"gitRemote,omitempty"`
+	// Path is a glob the repository root must match; a leading ~/ is the
+	// home directory.
+	Path string `json:"path,omitempty"`
+}
+
+// mcpProject is the repository a sync runs in.
+type mcpProject struct {
+	root   string
+	remote string // origin URL, or ""
+}
+
+// currentMCPProject returns the git repository enclosing the working
+// directory, or the directory of the project's .zeude file outside git. It
+// returns nil outside any project.
+func currentMCPProject(e env.Env) *mcpProject {
+	wd, err := e.Getwd()
+	if err != nil {
+		return nil
+	}
+	root := project.GitRoot(wd)
+	if root == "" {
+		p := currentProject(e)
+		if p == nil {
+			return nil
+		}
+		root = p.Dir
+	}
+	return &mcpProject{root: root, remote: project.GitRemote(root)}
+}
+
+// matches reports whether p is one of the repositories m selects.
+func (m *ProjectMatch) matches(e env.Env, p *mcpProject) bool {
+	if m == nil || p == nil || (m.GitRemote == "" && m.Path == "") {
+		return false
+	}
+	if m.GitRemote != "" && (p.remote == "" || normalizeRemote(m.GitRemote) != normalizeRemote(p.remote)) {
+		return false
+	}
+	if m.Path != "" {
+		pattern := m.Path
+		if strings.HasPrefix(pattern, "~/") {
+			home, err := e.HomeDir()
+			if err != nil {
+				return false
+			}
+			pattern = filepath.Join(home, pattern[2:])
+		}
+		if ok, err := filepath.Match(filepath.Clean(pattern), p.root); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeRemote reduces a git URL to lower-case host/path, so the
+// scp-like, ssh and https forms of one repository compare equal. Hosts
+// like GitHub ignore case in paths too.
+func normalizeRemote(url string) string {
+	url = strings.TrimSpace(url)
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 && !strings.Contains(url[:i], "/") {
+		url = url[:i] + "/" + url[i+1:] // git@host:org/repo
+	}
+	if i := strings.Index(url, "@"); i >= 0 && i < strings.Index(url+"/", "/") {
+		url = url[i+1:]
+	}
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git"))
+}
+
+// splitServers separates the servers for ~/.claude.json from the project
+// servers that match proj. Project servers matching no project here are
+// in neither.
+func splitServers(e env.Env, servers map[string]MCPServer, proj *mcpProject) (global, projectScoped map[string]MCPServer) {
+	global = make(map[string]MCPServer, len(servers))
+	projectScoped = make(map[string]MCPServer)
+	for key, server := range servers {
+		switch {
+		case server.Scope != ScopeProject:
+			global[key] = server
+		case server.Project.matches(e, proj):
+			projectScoped[key] = server
+		}
+	}
+	return global, projectScoped
+}
+
+// applicableServers returns the servers that apply in the working
+// directory: the global ones and the project ones matching it.
+func applicableServers(e env.Env, servers map[string]MCPServer) map[string]MCPServer {
+	global, projectScoped := splitServers(e, servers, currentMCPProject(e))
+	for key, server := range projectScoped {
+		global[key] = server
+	}
+	return global
+}
+
+// projectManifest lists the servers a sync wrote to one project's
+// .mcp.json, so they can be removed from it later. It lives in
+// ~/.zeude/projects, not in the project.
+type projectManifest struct {
+	Root      string    `json:"root"`
+	Servers   []string  `json:"servers"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// projectManifestPath returns the manifest file for the project at root.
+func projectManifestPath(e env.Env, root string) (string, error) {
+	return projectStatePath(e, root, ".json")
+}
+
+// projectStatePath returns the file with extension ext that ~/.zeude/projects
+// keeps for the project at root.
+func projectStatePath(e env.Env, root, ext string) (string, error) {
+	dir, err := paths.Projects(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+ext), nil
+}
+
+func loadProjectManifest(path string) projectManifest {
+	var m projectManifest
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &m); err != nil {
+			logError("ignoring unreadable %s: %v", path, err)
+		}
+	}
+	return m
+}
+
+// mergeProjectServers brings the managed servers in proj's .mcp.json in
+// line with servers, the same way mergeClaudeConfig does for claude.json.
+// A project Zeude never wrote to and has no servers for is left alone.
+// The caller holds the claude.json lock.
+func mergeProjectServers(e env.Env, plan *SyncPlan, proj *mcpProject, servers map[string]MCPServer, overrides localOverrides, changes *SyncChanges) error {
+	manifestPath, err := projectManifestPath(e, proj.root)
+	if err != nil {
+		return err
+	}
+	old := loadProjectManifest(manifestPath)
+	if len(servers) == 0 && len(old.Servers) == 0 {
+		return nil
+	}
+
+	path := filepath.Join(proj.root, projectServersFile)
+	doc, err := readServersFile(path)
+	if err != nil {
+		return err
+	}
+	managed, keys, err := encodeServers(servers, overrides)
+	if err != nil {
+		return err
+	}
+	merged, added, updated, removed := mergeServerMembers(doc.mcpServers, managed, keys, old.Servers, overrides.serverDisabled)
+	plan.servers(path, added, updated, removed, old.Servers, overrides.serverDisabled)
+	if !plan.apply() {
+		return nil
+	}
+
+	data, err := setTopLevelMember(doc.data, "mcpServers", encodeObject(merged))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, doc.data) {
+		// Committed alongside the code, so readable like the rest of it
+		if err := writeFileAtomicWithOptions(path, data, 0644, atomicWriteOptions{AuditKey: "mcpServers"}); err != nil {
+			return err
+		}
+	}
+	changes.ServersAdded = append(changes.ServersAdded, added...)
+	changes.ServersUpdated = append(changes.ServersUpdated, updated...)
+	changes.ServersRemoved = append(changes.ServersRemoved, removed...)
+
+	if len(keys) == 0 {
+		// Nothing of ours is left in the project
+		if err := os.Remove(manifestPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	out, err := json.MarshalIndent(projectManifest{Root: proj.root, Servers: keys, UpdatedAt: e.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(manifestPath, out, 0600)
+}
+
+// removeProjectServers removes the servers recorded in every project
+// manifest from that project's .mcp.json, and the manifests, returning the
+// files changed. A project that can't be updated keeps its manifest, so a
+// later uninstall tries again.
+func removeProjectServers(e env.Env, dryRun bool) []string {
+	dir, err := paths.Projects(e)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		m := loadProjectManifest(filepath.Join(dir, entry.Name()))
+		if m.Root == "" || len(m.Servers) == 0 {
+			continue
+		}
+		path := filepath.Join(m.Root, projectServersFile)
+		var changes SyncChanges
+		if !dryRun {
+			if err := mergeProjectServers(e, nil, &mcpProject{root: m.Root}, nil, localOverrides{}, &changes); err != nil {
+				logError("failed to update %s: %v", path, err)
+				continue
+			}
+			if len(changes.ServersRemoved) == 0 {
+				continue
+			}
+		}
+		files = append(files, path)
+	}
+	return files
+}
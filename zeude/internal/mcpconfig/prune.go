@@ -0,0 +1,191 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Orphan kinds reported by Prune.
+const (
+	OrphanHook     = "hook"     // Zeude hook script not in managed.json
+	OrphanSkill    = "skill"    // Zeude skill file not in managed.json
+	OrphanSettings = "settings" // settings.json entry for a hook script that is gone
+)
+
+// Orphan is one Zeude artifact no manifest accounts for.
+type Orphan struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"` // file, or the command of a settings.json entry
+}
+
+// PruneOptions configures Prune.
+type PruneOptions struct {
+	Env env.Env
+	// DryRun only reports the orphans.
+	DryRun bool
+}
+
+// PruneResult lists the orphans found, and with DryRun false, removed.
+type PruneResult struct {
+	Orphans []Orphan
+	Backup  *Backup // snapshot taken before settings.json was edited
+}
+
+// Prune finds Zeude-written hook scripts and skill files that the managed
+// lists don't know about (left by versions before tracking, or renamed on
+// the server), plus settings.json hook entries whose script no longer
+// exists, and removes them unless DryRun. It runs under the claude.json
+// lock so a concurrent sync can't re-register what is being removed.
+func Prune(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	e := env.OrDefault(opts.Env)
+	var result PruneResult
+	err := withFileLock(ctx, e, func() (err error) {
+		result, err = prune(e, opts.DryRun)
+		return err
+	})
+	return result, err
+}
+
+// prune does the work of Prune. The caller holds the lock.
+func prune(e env.Env, dryRun bool) (PruneResult, error) {
+	var result PruneResult
+	files, err := orphanFiles(e)
+	if err != nil {
+		return result, err
+	}
+	removing := make(map[string]bool, len(files))
+	for _, o := range files {
+		removing[o.Path] = true
+	}
+
+	settings, err := readClaudeSettings(e)
+	if err != nil {
+		return result, fmt.Errorf("failed to read settings: %w", err)
+	}
+	hooksSection, _ := settings["hooks"].(map[string]interface{})
+	stale := dropZeudeHooks(e, hooksSection, func(cmd string, _ interface{}) bool {
+		if removing[cmd] {
+			return true
+		}
+		_, err := os.Stat(cmd)
+		return os.IsNotExist(err)
+	})
+
+	result.Orphans = files
+	for _, cmd := range stale {
+		result.Orphans = append(result.Orphans, Orphan{Kind: OrphanSettings, Path: cmd})
+	}
+	if dryRun || len(result.Orphans) == 0 {
+		return result, nil
+	}
+
+	if len(stale) > 0 {
+		if result.Backup, err = createBackup(e); err != nil {
+			return result, fmt.Errorf("failed to back up before editing settings.json: %w", err)
+		}
+		if err := writeClaudeSettings(e, settings); err != nil {
+			return result, fmt.Errorf("failed to write settings: %w", err)
+		}
+	}
+	for _, o := range files {
+		if err := removeFile(o.Path); err != nil && !os.IsNotExist(err) {
+			return result, fmt.Errorf("failed to remove %s: %w", o.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// orphanFiles scans the hooks and commands directories for files Zeude
+// wrote that aren't in the managed lists.
+func orphanFiles(e env.Env) ([]Orphan, error) {
+	var orphans []Orphan
+
+	hooksDir, err := getClaudeHooksDir(e)
+	if err != nil {
+		return nil, err
+	}
+	managedHooks := make(map[string]bool)
+	for _, path := range loadManagedHooks(e) {
+		managedHooks[path] = true
+	}
+	eventDirs, _ := os.ReadDir(hooksDir)
+	for _, dir := range eventDirs {
+		if !dir.IsDir() {
+			continue
+		}
+		files, _ := os.ReadDir(filepath.Join(hooksDir, dir.Name()))
+		for _, file := range files {
+			path := filepath.Join(hooksDir, dir.Name(), file.Name())
+			if file.IsDir() || managedHooks[path] {
+				continue
+			}
+			if data, err := os.ReadFile(path); err == nil && bytes.Contains(data, []byte(zeudeHookHeader)) {
+				orphans = append(orphans, Orphan{Kind: OrphanHook, Path: path})
+			}
+		}
+	}
+
+	commandsDir, err := paths.ClaudeCommands(e)
+	if err != nil {
+		return nil, err
+	}
+	managedSkills := make(map[string]bool)
+	for _, path := range loadManagedState(e).Skills {
+		managedSkills[path] = true
+	}
+	// Skills sit at the top level or one namespace directory down
+	dirs := []string{commandsDir}
+	files, _ := os.ReadDir(commandsDir)
+	for _, file := range files {
+		if file.IsDir() {
+			dirs = append(dirs, filepath.Join(commandsDir, file.Name()))
+		}
+	}
+	for _, dir := range dirs {
+		files, _ := os.ReadDir(dir)
+		for _, file := range files {
+			path := filepath.Join(dir, file.Name())
+			if file.IsDir() || filepath.Ext(path) != ".md" || managedSkills[path] {
+				continue
+			}
+			if data, err := os.ReadFile(path); err == nil && isZeudeSkillFile(data) {
+				orphans = append(orphans, Orphan{Kind: OrphanSkill, Path: path})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// isZeudeSkillFile reports whether data starts with exactly the frontmatter
+// skillFileContent writes: a name, then the optional fields in order, then
+// a blank line. Hand-written commands rarely match it, but prune still
+// lists every file before deleting.
+func isZeudeSkillFile(data []byte) bool {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) < 5 || lines[0] != "---" || !strings.HasPrefix(lines[1], frontmatterName+": ") {
+		return false
+	}
+	rest := lines[2:]
+	optional := func(key string) {
+		if len(rest) > 0 && strings.HasPrefix(rest[0], key+": ") {
+			rest = rest[1:]
+		}
+	}
+	optional(frontmatterDescription)
+	if len(rest) > 1 && rest[0] == frontmatterAllowedTools+":" && strings.HasPrefix(rest[1], "  - ") {
+		rest = rest[1:]
+		for len(rest) > 0 && strings.HasPrefix(rest[0], "  - ") {
+			rest = rest[1:]
+		}
+	}
+	optional(frontmatterArgumentHint)
+	optional(frontmatterModel)
+	return len(rest) >= 2 && rest[0] == "---" && rest[1] == ""
+}
@@ -0,0 +1,179 @@
+package mcpconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/httpclient"
+)
+
+// HookScriptTypes lists the script types installHooks knows how to run.
+func HookScriptTypes() []string {
+	types := make([]string, 0, len(hookScriptExts))
+	for t := range hookScriptExts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// HookScriptTypeForExt returns the script type whose file extension is ext
+// (".py", ...), or "".
+func HookScriptTypeForExt(ext string) string {
+	for t, e := range hookScriptExts {
+		if e == ext {
+			return t
+		}
+	}
+	return ""
+}
+
+// ParseSkillFile splits a command file into its frontmatter fields and
+// body, the inverse of skillFileContent. Files without frontmatter are all
+// body.
+func ParseSkillFile(data []byte) Skill {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return Skill{Content: text}
+	}
+	end := strings.Index(text[4:], "\n---\n")
+	if end < 0 {
+		return Skill{Content: text}
+	}
+	var skill Skill
+	inTools := false // reading the "- tool" lines under allowed-tools
+	for _, line := range strings.Split(text[4:4+end], "\n") {
+		if item := strings.TrimSpace(line); inTools && strings.HasPrefix(item, "- ") {
+			skill.AllowedTools = append(skill.AllowedTools, yamlUnquote(item[2:]))
+			continue
+		}
+		inTools = false
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case frontmatterName:
+			skill.Name = yamlUnquote(value)
+		case frontmatterDescription:
+			skill.Description = yamlUnquote(value)
+		case frontmatterAllowedTools:
+			skill.AllowedTools = yamlList(value)
+			inTools = strings.TrimSpace(value) == ""
+		case frontmatterArgumentHint:
+			skill.ArgumentHint = yamlUnquote(value)
+		case frontmatterModel:
+			skill.Model = yamlUnquote(value)
+		}
+	}
+	skill.Content = strings.TrimPrefix(text[4+end+len("\n---\n"):], "\n")
+	return skill
+}
+
+// PushHook uploads a hook script to the dashboard as a draft of hook.Name
+// and returns the ID it was stored under. The hook reaches this machine
+// only through the next sync, like any other.
+func PushHook(ctx context.Context, hook Hook) (string, error) {
+	return pushHook(ctx, env.OS{}, hook)
+}
+
+func pushHook(ctx context.Context, e env.Env, hook Hook) (string, error) {
+	if _, ok := hookScriptExts[hook.ScriptType]; !ok {
+		return "", fmt.Errorf("unsupported script type %q (want %s)", hook.ScriptType, strings.Join(HookScriptTypes(), ", "))
+	}
+	if hook.Name == "" || hook.Event == "" {
+		return "", fmt.Errorf("a hook needs a name and an event")
+	}
+	if _, clamped := clampHookTimeout(hook.TimeoutSeconds); clamped {
+		return "", fmt.Errorf("timeout must be %d to %d seconds", MinHookTimeout, MaxHookTimeout)
+	}
+	if strings.Contains(hook.Script, zeudeHookHeader) {
+		// Installed scripts carry the agent key; never send one back
+		return "", fmt.Errorf("this is a script Zeude generated; push the source script instead")
+	}
+	if len(hook.Script) > MaxResponseSize {
+		return "", fmt.Errorf("script is %d bytes; the limit is %d", len(hook.Script), MaxResponseSize)
+	}
+	hook.ID, hook.Signature = "", ""
+	return pushContent(ctx, e, "/api/hooks", hook)
+}
+
+// PushSkill uploads a skill to the dashboard under skill.Slug and returns
+// the ID it was stored under.
+func PushSkill(ctx context.Context, skill Skill) (string, error) {
+	return pushSkill(ctx, env.OS{}, skill)
+}
+
+func pushSkill(ctx context.Context, e env.Env, skill Skill) (string, error) {
+	if skill.Slug == "" || sanitizeFilename(skill.Slug) != skill.Slug {
+		return "", fmt.Errorf("invalid slug %q: use lowercase letters, digits, - and _", skill.Slug)
+	}
+	if sanitizeFilename(skill.Namespace) != skill.Namespace {
+		return "", fmt.Errorf("invalid namespace %q: use lowercase letters, digits, - and _", skill.Namespace)
+	}
+	if strings.TrimSpace(skill.Content) == "" {
+		return "", fmt.Errorf("skill %s has no content", skill.Slug)
+	}
+	if len(skill.Content) > MaxResponseSize {
+		return "", fmt.Errorf("skill is %d bytes; the limit is %d", len(skill.Content), MaxResponseSize)
+	}
+	skill.Signature = ""
+	return pushContent(ctx, e, "/api/skills", skill)
+}
+
+// pushContent POSTs item as JSON to the dashboard path and returns the ID
+// from the response. A rejected key yields an *AuthError.
+func pushContent(ctx context.Context, e env.Env, path string, item interface{}) (string, error) {
+	agentKey := getAgentKey(e)
+	if agentKey == "" {
+		return "", fmt.Errorf("no agent key configured; run 'zeude login' first")
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout(e).Value)
+	defer cancel()
+	req, err := httpclient.NewRequest(ctx, http.MethodPost, getDashboardURL(e)+path, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+agentKey)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("push failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var out struct {
+		ID    string `json:"id"`
+		Error string `json:"error"`
+	}
+	json.Unmarshal(body, &out)
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return "", &AuthError{StatusCode: resp.StatusCode, Message: "access denied or revoked"}
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("the dashboard does not accept pushes (HTTP 404)")
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		if out.Error != "" {
+			return "", fmt.Errorf("push rejected (HTTP %d): %s", resp.StatusCode, out.Error)
+		}
+		return "", fmt.Errorf("push failed: %d", resp.StatusCode)
+	}
+	return out.ID, nil
+}
@@ -0,0 +1,90 @@
+package mcpconfig
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const (
+	// defaultRateLimitBackoff applies when a 429 has no usable Retry-After.
+	defaultRateLimitBackoff = time.Minute
+	// maxRateLimitBackoff caps Retry-After, so a bad header can't stop
+	// syncing for days.
+	maxRateLimitBackoff = time.Hour
+)
+
+// RateLimitError is a 429 from the dashboard. Until then, syncs apply the
+// cached config without making requests.
+type RateLimitError struct {
+	Until time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("dashboard rate limited this client until %s", e.Until.Local().Format("15:04:05"))
+}
+
+// parseRetryAfter reads a Retry-After value, either seconds or an HTTP
+// date, as a wait from now within the backoff bounds.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	var d time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		d = at.Sub(now)
+	}
+	switch {
+	case d <= 0:
+		return defaultRateLimitBackoff
+	case d > maxRateLimitBackoff:
+		return maxRateLimitBackoff
+	}
+	return d
+}
+
+// rateLimited records a 429 and returns the error for it.
+func rateLimited(e env.Env, header http.Header) *RateLimitError {
+	until := e.Now().Add(parseRetryAfter(header.Get("Retry-After"), e.Now())).UTC().Truncate(time.Second)
+	logger.Warn("dashboard rate limited this client", "until", until.Format(time.RFC3339))
+	if path, err := paths.RateLimit(e); err == nil && ensureZeudeDir(e) == nil {
+		if err := writeFileAtomicWithOptions(path, []byte(until.Format(time.RFC3339)+"\n"), 0600, atomicWriteOptions{NoDirSync: true}); err != nil {
+			logError("failed to save rate limit: %v", err)
+		}
+	}
+	return &RateLimitError{Until: until}
+}
+
+// rateLimitedUntil returns when the current backoff ends, or the zero time
+// when there is none. A backoff that has passed is left for the next
+// successful fetch to clear.
+func rateLimitedUntil(e env.Env) time.Time {
+	path, err := paths.RateLimit(e)
+	if err != nil {
+		return time.Time{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	until, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil || !e.Now().Before(until) {
+		return time.Time{}
+	}
+	return until
+}
+
+// clearRateLimit ends the backoff once the dashboard answers again.
+func clearRateLimit(e env.Env) {
+	if path, err := paths.RateLimit(e); err == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logDebug("failed to clear rate limit: %v", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package mcpconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Config sections, named by their keys in ConfigResponse and ConfigHashes.
+const (
+	sectionServers = "mcpServers"
+	sectionSkills  = "skills"
+	sectionHooks   = "hooks"
+)
+
+var configSections = []string{sectionServers, sectionSkills, sectionHooks}
+
+// SectionsHeader lists the sections a config response carries. A server
+// that sends it on full responses also serves /api/config/_/hashes and
+// answers ?sections=; one that doesn't only ever gets full requests.
+const SectionsHeader = "X-Zeude-Sections"
+
+// responseSections returns the sections listed in header, or nil when the
+// header is absent.
+func responseSections(header http.Header) []string {
+	if _, ok := header[http.CanonicalHeaderKey(SectionsHeader)]; !ok {
+		return nil
+	}
+	sections := []string{}
+	for _, section := range strings.Split(header.Get(SectionsHeader), ",") {
+		if section = strings.TrimSpace(section); section != "" {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// sectionHash returns the hash of section in h.
+func sectionHash(h ConfigHashes, section string) string {
+	switch section {
+	case sectionServers:
+		return h.MCPServers
+	case sectionSkills:
+		return h.Skills
+	case sectionHooks:
+		return h.Hooks
+	}
+	return ""
+}
+
+// fetchChangedSections asks the dashboard for its current hashes, then
+// downloads only the sections whose hash differs from cached and takes
+// the rest from cached. The result has the same hashes as a full fetch
+// would. Anything the server doesn't support, or a config that changes
+// between the two requests, falls back to fetchConfig.
+func fetchChangedSections(ctx context.Context, e env.Env, agentKey string, cached *CachedConfig) (*ConfigResponse, error) {
+	base := fmt.Sprintf("%s/api/config/_", getDashboardURL(e))
+	full := func(why string) (*ConfigResponse, error) {
+		logDebug("%s; fetching the full config", why)
+		return fetchConfig(ctx, e, agentKey, cached.Version)
+	}
+
+	var hashes ConfigHashes
+	if _, err := getConfigJSON(ctx, e, agentKey, base+"/hashes", cached.Version, &hashes); err != nil {
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && (statusErr.code == http.StatusNotFound || statusErr.code == http.StatusMethodNotAllowed || statusErr.code == http.StatusNotImplemented) {
+			return full("dashboard has no hashes endpoint")
+		}
+		return nil, err
+	}
+	old := cached.Config.Hashes
+	if hashes.Root != "" && hashes.Root == old.Root {
+		return nil, ErrNotModified
+	}
+	var changed []string
+	for _, section := range configSections {
+		if h := sectionHash(hashes, section); h == "" || h != sectionHash(old, section) {
+			changed = append(changed, section)
+		}
+	}
+	if len(changed) == len(configSections) {
+		return full("every section changed")
+	}
+
+	// An empty list fetches only the top-level fields: user, policy, MOTD
+	var config ConfigResponse
+	header, err := getConfigJSON(ctx, e, agentKey, base+"?sections="+url.QueryEscape(strings.Join(changed, ",")), "", &config)
+	if err != nil {
+		return nil, err
+	}
+	got := responseSections(header)
+	if got == nil {
+		// The server ignored ?sections=, so this is all of it
+		config.etag = etagVersion(header.Get("ETag"))
+		return &config, nil
+	}
+	for _, section := range configSections {
+		if contains(got, section) {
+			continue
+		}
+		if contains(changed, section) {
+			return full("dashboard left out section " + section)
+		}
+		if sectionHash(config.Hashes, section) != sectionHash(old, section) {
+			return full("section " + section + " changed since the hashes were fetched")
+		}
+		switch section {
+		case sectionServers:
+			config.MCPServers = cached.Config.MCPServers
+		case sectionSkills:
+			config.Skills = cached.Config.Skills
+		case sectionHooks:
+			config.Hooks = cached.Config.Hooks
+		}
+	}
+	config.etag = etagVersion(header.Get("ETag"))
+	config.sections = configSections
+	logDebug("fetched sections [%s] (version: %s)", strings.Join(got, ", "), config.ConfigVersion)
+	return &config, nil
+}
+
+// AppliedSections records, for each config section, the dashboard hash
+// last applied on this machine and a fingerprint of the local state it
+// left. A sync whose section hash and fingerprint both still match skips
+// installing that section.
+type AppliedSections struct {
+	Servers AppliedSection `json:"servers"`
+	Hooks   AppliedSection `json:"hooks"`
+	Skills  AppliedSection `json:"skills"`
+}
+
+// AppliedSection is one section of AppliedSections.
+type AppliedSection struct {
+	Hash        string `json:"hash,omitempty"`        // section hash from ConfigHashes
+	Fingerprint string `json:"fingerprint,omitempty"` // see fingerprintFiles
+	Rejected    int    `json:"rejected,omitempty"`    // items the signing policy refused
+}
+
+// newAppliedSection records a section just installed. Without a hash from
+// the dashboard there is nothing to compare next time, so nothing is kept.
+func newAppliedSection(hash string, fingerprint func() string, rejected int) AppliedSection {
+	if hash == "" {
+		return AppliedSection{}
+	}
+	return AppliedSection{Hash: hash, Fingerprint: fingerprint(), Rejected: rejected}
+}
+
+// unchanged reports whether a section with this hash and local fingerprint
+// is already applied. Without a hash from the dashboard nothing is.
+func (s AppliedSection) unchanged(hash, fingerprint string) bool {
+	return hash != "" && s.Hash == hash && s.Fingerprint == fingerprint
+}
+
+// fingerprintFiles hashes inputs with the size, mode and modification time
+// of files. Only stat is called, so checking costs far less than
+// reinstalling; a file deleted or edited locally changes the result.
+func fingerprintFiles(files []string, inputs ...string) string {
+	h := sha256.New()
+	for _, in := range inputs {
+		fmt.Fprintf(h, "%q\n", in)
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			fmt.Fprintf(h, "%q missing\n", file)
+			continue
+		}
+		fmt.Fprintf(h, "%q %d %o %d\n", file, info.Size(), info.Mode(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// sectionFiles resolves paths, dropping any that can't be resolved.
+func sectionFiles(e env.Env, resolve ...func(env.Env) (string, error)) []string {
+	files := make([]string, 0, len(resolve))
+	for _, path := range resolve {
+		if p, err := path(e); err == nil {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// serversFingerprint covers ~/.claude.json and the project's .mcp.json.
+// Claude Code rewrites claude.json as it runs, so servers are mostly
+// skipped between syncs with no session in between.
+func serversFingerprint(e env.Env, proj *mcpProject, version string) string {
+	files := sectionFiles(e, getClaudeConfigPath, paths.Overrides)
+	root, remote := "", ""
+	if proj != nil {
+		root, remote = proj.root, proj.remote
+		files = append(files, filepath.Join(proj.root, projectServersFile))
+	}
+	return fingerprintFiles(files, version, root, remote)
+}
+
+// hooksFingerprint covers the installed scripts, settings.json, and what
+// goes into generating each script.
+func hooksFingerprint(e env.Env, verifier *contentVerifier, version, agentKey, dashboardURL, userEmail, team string) string {
+	files := append(sectionFiles(e, getClaudeSettingsPath, paths.Overrides), loadManagedHooks(e)...)
+	return fingerprintFiles(files, version, verifier.state(), agentKey, dashboardURL, userEmail, team, hookLogPath(e))
+}
+
+// skillsFingerprint covers the installed command files.
+func skillsFingerprint(e env.Env, verifier *contentVerifier, version string) string {
+	return fingerprintFiles(loadManagedSkills(e), version, verifier.state())
+}
+
+// saveAppliedSections records applied in the config cache, unless it is
+// what the cache already holds.
+func saveAppliedSections(e env.Env, applied AppliedSections) error {
+	cached, _ := loadCachedConfig(e)
+	if cached == nil || cached.Applied == applied {
+		return nil
+	}
+	cached.Applied = applied
+	return writeCachedConfig(e, cached)
+}
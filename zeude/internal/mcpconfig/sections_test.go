@@ -0,0 +1,447 @@
+package mcpconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// sectionedDashboard serves a config whose sections carry hashes, along
+// with /api/config/_/hashes and ?sections= when sectioned is set.
+type sectionedDashboard struct {
+	*httptest.Server
+	mu        sync.Mutex
+	servers   map[string]MCPServer
+	skills    []Skill
+	hooks     []Hook
+	sectioned bool
+	omit      string   // a section sectioned responses leave out
+	onHashes  func()   // runs after each hashes response, under mu
+	requests  []string // request URIs, in order
+}
+
+func newSectionedDashboard(t testing.TB) *sectionedDashboard {
+	t.Helper()
+	d := &sectionedDashboard{
+		servers: map[string]MCPServer{"fs": {Command: "npx", Args: []string{"server-filesystem"}}},
+		skills:  []Skill{{Name: "review", Slug: "review", Content: "Review the diff."}},
+		hooks:   []Hook{{ID: "h1", Name: "log-prompt", Event: "UserPromptSubmit", Script: "echo hi"}},
+
+		sectioned: true,
+	}
+	d.Server = httptest.NewServer(http.HandlerFunc(d.serve))
+	t.Cleanup(d.Close)
+	return d
+}
+
+// testHash hashes v's JSON the way the fake dashboard hashes sections.
+func testHash(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (d *sectionedDashboard) hashes() ConfigHashes {
+	h := ConfigHashes{MCPServers: testHash(d.servers), Skills: testHash(d.skills), Hooks: testHash(d.hooks)}
+	h.Root = testHash([]string{h.MCPServers, h.Skills, h.Hooks})
+	return h
+}
+
+func (d *sectionedDashboard) serve(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, r.URL.RequestURI())
+	hashes := d.hashes()
+
+	switch r.URL.Path {
+	case "/api/config/_/hashes":
+		if !d.sectioned {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == hashes.Root {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(hashes)
+		if d.onHashes != nil {
+			d.onHashes()
+		}
+	case "/api/config/_":
+		if r.Header.Get("If-None-Match") == hashes.Root {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		sections := configSections
+		if _, ok := r.URL.Query()["sections"]; ok && d.sectioned {
+			sections = nil
+			for _, s := range strings.Split(r.URL.Query().Get("sections"), ",") {
+				if s != "" && s != d.omit {
+					sections = append(sections, s)
+				}
+			}
+		}
+		body := map[string]interface{}{"configVersion": hashes.Root, "hashes": hashes}
+		for _, s := range sections {
+			switch s {
+			case sectionServers:
+				body[s] = d.servers
+			case sectionSkills:
+				body[s] = d.skills
+			case sectionHooks:
+				body[s] = d.hooks
+			}
+		}
+		if d.sectioned {
+			w.Header().Set(SectionsHeader, strings.Join(sections, ","))
+		}
+		w.Header().Set("ETag", `"`+hashes.Root+`"`)
+		json.NewEncoder(w).Encode(body)
+	default:
+		w.Write([]byte("{}"))
+	}
+}
+
+// change runs fn on the served config and forgets earlier requests.
+func (d *sectionedDashboard) change(fn func(d *sectionedDashboard)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fn(d)
+	d.requests = nil
+}
+
+// configRequests returns the config requests made since the last change.
+func (d *sectionedDashboard) configRequests() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var config []string
+	for _, r := range d.requests {
+		if strings.HasPrefix(r, "/api/config/") {
+			config = append(config, r)
+		}
+	}
+	return config
+}
+
+func syncOnce(t testing.TB, opts SyncOptions) SyncResult {
+	t.Helper()
+	opts.SkipStatusReport = true
+	r := Sync(context.Background(), opts)
+	if !r.Success {
+		t.Fatalf("sync failed: %+v", r)
+	}
+	return r
+}
+
+func TestFingerprintFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "hook.sh")
+	os.WriteFile(file, []byte("echo hi\n"), 0755)
+	before := fingerprintFiles([]string{file}, "v1")
+
+	if again := fingerprintFiles([]string{file}, "v1"); again != before {
+		t.Fatal("fingerprint of unchanged state differs")
+	}
+	tests := []struct {
+		name   string
+		change func()
+		inputs []string
+	}{
+		{"other input", func() {}, []string{"v2"}},
+		{"edited", func() { os.WriteFile(file, []byte("echo bye, then more\n"), 0755) }, nil},
+		{"touched", func() { os.Chtimes(file, time.Now(), time.Now().Add(time.Hour)) }, nil},
+		{"mode", func() { os.Chmod(file, 0644) }, nil},
+		{"deleted", func() { os.Remove(file) }, nil},
+	}
+	prev := before
+	for _, tt := range tests {
+		tt.change()
+		inputs := tt.inputs
+		if inputs == nil {
+			inputs = []string{"v1"}
+		}
+		got := fingerprintFiles([]string{file}, inputs...)
+		if got == prev {
+			t.Errorf("%s: fingerprint unchanged", tt.name)
+		}
+		if tt.inputs == nil {
+			prev = got
+		}
+	}
+}
+
+func TestAppliedSectionUnchanged(t *testing.T) {
+	s := newAppliedSection("abc", func() string { return "fp" }, 0)
+	tests := []struct {
+		hash, fingerprint string
+		want              bool
+	}{
+		{"abc", "fp", true},
+		{"abd", "fp", false},
+		{"abc", "other", false},
+		{"", "fp", false},
+	}
+	for _, tt := range tests {
+		if got := s.unchanged(tt.hash, tt.fingerprint); got != tt.want {
+			t.Errorf("unchanged(%q, %q) = %v, want %v", tt.hash, tt.fingerprint, got, tt.want)
+		}
+	}
+	if empty := newAppliedSection("", func() string { return "fp" }, 0); empty.unchanged("", "fp") {
+		t.Error("a section without a hash counted as applied")
+	}
+}
+
+// TestDeletedFileRepairedDespiteMatchingHashes deletes something a sync
+// installed and checks the next sync puts it back, although every section
+// hash still matches what was applied.
+func TestDeletedFileRepairedDespiteMatchingHashes(t *testing.T) {
+	tests := []struct {
+		name string
+		path func(e *env.Fake) string
+		// check reports whether the repaired file is right
+		check func(data []byte) bool
+	}{
+		{"settings.json", func(e *env.Fake) string { p, _ := getClaudeSettingsPath(e); return p },
+			func(data []byte) bool { return strings.Contains(string(data), "UserPromptSubmit") }},
+		{"hook script", func(e *env.Fake) string { return loadManagedHooks(e)[0] },
+			func(data []byte) bool { return strings.Contains(string(data), "echo hi") }},
+		{"skill file", func(e *env.Fake) string { return filepath.Join(e.Home, ".claude", "commands", "review.md") },
+			func(data []byte) bool { return strings.Contains(string(data), "Review the diff.") }},
+		{"claude.json", func(e *env.Fake) string { p, _ := getClaudeConfigPath(e); return p },
+			func(data []byte) bool { return strings.Contains(string(data), "server-filesystem") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newSectionedDashboard(t)
+			e := testEnv(t, "ZEUDE_DASHBOARD_URL", d.URL, "ZEUDE_AGENT_KEY", "zd_test")
+			syncOnce(t, SyncOptions{Env: e})
+			if cached, _ := loadCachedConfig(e); cached == nil || cached.Applied.Hooks.Hash == "" || cached.Applied.Skills.Hash == "" || cached.Applied.Servers.Hash == "" {
+				t.Fatalf("sections not recorded as applied: %+v", cached)
+			}
+
+			path := tt.path(e)
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+			syncOnce(t, SyncOptions{Env: e})
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("not repaired: %v", err)
+			}
+			if !tt.check(data) {
+				t.Errorf("repaired with the wrong content:\n%s", data)
+			}
+		})
+	}
+}
+
+// TestUnchangedSectionsSkipped checks that a section whose hash and
+// files are as last applied isn't installed again, until --force.
+func TestUnchangedSectionsSkipped(t *testing.T) {
+	d := newSectionedDashboard(t)
+	e := testEnv(t, "ZEUDE_DASHBOARD_URL", d.URL, "ZEUDE_AGENT_KEY", "zd_test")
+	syncOnce(t, SyncOptions{Env: e})
+
+	// An edit the fingerprint can't see: same size and modification time
+	skill := filepath.Join(e.Home, ".claude", "commands", "review.md")
+	info, err := os.Stat(skill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(skill)
+	edited := strings.Replace(string(data), "Review", "Reveal", 1)
+	os.WriteFile(skill, []byte(edited), 0644)
+	os.Chtimes(skill, info.ModTime(), info.ModTime())
+
+	syncOnce(t, SyncOptions{Env: e})
+	if data, _ := os.ReadFile(skill); string(data) != edited {
+		t.Errorf("unchanged skills section was installed again")
+	}
+	syncOnce(t, SyncOptions{Env: e, ForceRefresh: true})
+	if data, _ := os.ReadFile(skill); string(data) == edited {
+		t.Errorf("forced sync left the edited skill")
+	}
+}
+
+// benchmarkSync times a sync of 100 hooks and 100 skills whose cached
+// config is current. With skip set, the sections are recorded as applied,
+// as after any sync; without, each sync installs them all again.
+func benchmarkSync(b *testing.B, skip bool) {
+	d := newSectionedDashboard(b)
+	d.hooks, d.skills = nil, nil
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprint(i)
+		d.hooks = append(d.hooks, Hook{ID: "h" + id, Name: "hook-" + id, Event: "PreToolUse", Matcher: "Bash", Script: "echo " + id})
+		d.skills = append(d.skills, Skill{Name: "skill-" + id, Slug: "skill-" + id, Content: strings.Repeat("Step "+id+".\n", 50)})
+	}
+	e := testEnv(b, "ZEUDE_DASHBOARD_URL", d.URL, "ZEUDE_AGENT_KEY", "zd_test")
+	syncOnce(b, SyncOptions{Env: e})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !skip {
+			b.StopTimer()
+			saveAppliedSections(e, AppliedSections{})
+			b.StartTimer()
+		}
+		syncOnce(b, SyncOptions{Env: e})
+	}
+}
+
+func BenchmarkSyncUnchangedSections(b *testing.B) { benchmarkSync(b, true) }
+
+func BenchmarkSyncReinstallSections(b *testing.B) { benchmarkSync(b, false) }
+
+func TestFetchChangedSections(t *testing.T) {
+	const (
+		hashesURI = "/api/config/_/hashes"
+		fullURI   = "/api/config/_"
+	)
+	newSkills := []Skill{{Name: "review", Slug: "review", Content: "Review the diff twice."}}
+	newHooks := []Hook{{ID: "h2", Name: "audit", Event: "PreToolUse", Matcher: "Bash", Script: "echo audit"}}
+
+	tests := []struct {
+		name      string
+		change    func(d *sectionedDashboard)
+		want      []string // config requests
+		sectioned bool     // the cache still says the dashboard is sectioned
+		check     func(t *testing.T, home string)
+	}{
+		{
+			name:      "nothing changed",
+			change:    func(d *sectionedDashboard) {},
+			want:      []string{hashesURI},
+			sectioned: true,
+		},
+		{
+			name:      "one section",
+			change:    func(d *sectionedDashboard) { d.skills = newSkills },
+			want:      []string{hashesURI, fullURI + "?sections=skills"},
+			sectioned: true,
+			check: func(t *testing.T, home string) {
+				wantFile(t, filepath.Join(home, ".claude", "commands", "review.md"), "Review the diff twice.")
+			},
+		},
+		{
+			name:      "two sections",
+			change:    func(d *sectionedDashboard) { d.skills, d.hooks = newSkills, newHooks },
+			want:      []string{hashesURI, fullURI + "?sections=skills%2Chooks"},
+			sectioned: true,
+			check: func(t *testing.T, home string) {
+				wantFile(t, filepath.Join(home, ".claude", "settings.json"), "PreToolUse")
+			},
+		},
+		{
+			name:      "section emptied",
+			change:    func(d *sectionedDashboard) { d.skills = []Skill{} },
+			want:      []string{hashesURI, fullURI + "?sections=skills"},
+			sectioned: true,
+			check: func(t *testing.T, home string) {
+				if _, err := os.Stat(filepath.Join(home, ".claude", "commands", "review.md")); !os.IsNotExist(err) {
+					t.Errorf("removed skill still installed: %v", err)
+				}
+			},
+		},
+		{
+			name: "every section",
+			change: func(d *sectionedDashboard) {
+				d.skills, d.hooks = newSkills, newHooks
+				d.servers = map[string]MCPServer{"git": {Command: "uvx", Args: []string{"mcp-server-git"}}}
+			},
+			want:      []string{hashesURI, fullURI},
+			sectioned: true,
+		},
+		{
+			name: "changed section left out",
+			change: func(d *sectionedDashboard) {
+				d.skills = newSkills
+				d.omit = sectionSkills
+			},
+			want:      []string{hashesURI, fullURI + "?sections=skills", fullURI},
+			sectioned: true,
+			check: func(t *testing.T, home string) {
+				wantFile(t, filepath.Join(home, ".claude", "commands", "review.md"), "Review the diff twice.")
+			},
+		},
+		{
+			name: "unrequested section changed meanwhile",
+			change: func(d *sectionedDashboard) {
+				d.hooks = newHooks
+				d.onHashes = func() { d.skills, d.onHashes = newSkills, nil }
+			},
+			want:      []string{hashesURI, fullURI + "?sections=hooks", fullURI},
+			sectioned: true,
+			check: func(t *testing.T, home string) {
+				wantFile(t, filepath.Join(home, ".claude", "commands", "review.md"), "Review the diff twice.")
+			},
+		},
+		{
+			name: "dashboard stopped serving sections",
+			change: func(d *sectionedDashboard) {
+				d.skills = newSkills
+				d.sectioned = false
+			},
+			want: []string{hashesURI, fullURI},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newSectionedDashboard(t)
+			e := testEnv(t, "ZEUDE_DASHBOARD_URL", d.URL, "ZEUDE_AGENT_KEY", "zd_test")
+			syncOnce(t, SyncOptions{Env: e})
+			if cached, _ := loadCachedConfig(e); cached == nil || !cached.Sectioned {
+				t.Fatalf("full response with %s not cached as sectioned: %+v", SectionsHeader, cached)
+			}
+
+			d.change(tt.change)
+			e.Advance(CacheTTL + time.Minute)
+			syncOnce(t, SyncOptions{Env: e})
+
+			if got := d.configRequests(); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("requests = %v, want %v", got, tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, e.Home)
+			}
+
+			// The cache must hold what the dashboard has, with hashes to match
+			cached, _ := loadCachedConfig(e)
+			if cached == nil {
+				t.Fatal("no cached config")
+			}
+			c := cached.Config
+			got := ConfigHashes{MCPServers: testHash(c.MCPServers), Skills: testHash(c.Skills), Hooks: testHash(c.Hooks)}
+			got.Root = testHash([]string{got.MCPServers, got.Skills, got.Hooks})
+			if want := d.hashes(); c.Hashes != want || got != want {
+				t.Errorf("cached hashes %+v, content hashes %+v, want %+v", c.Hashes, got, want)
+			}
+			if cached.Sectioned != tt.sectioned {
+				t.Errorf("Sectioned = %v, want %v", cached.Sectioned, tt.sectioned)
+			}
+		})
+	}
+}
+
+// wantFile fails the test unless path exists and contains want.
+func wantFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), want) {
+		t.Errorf("%s lacks %q:\n%s", path, want, data)
+	}
+}
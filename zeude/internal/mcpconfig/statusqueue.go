@@ -0,0 +1,112 @@
+package mcpconfig
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// maxQueuedReports caps the offline queue; the oldest reports go first, as
+// the newest describe the machine best.
+const maxQueuedReports = 100
+
+// queuedReport is one line of ~/.zeude/status-queue.jsonl.
+type queuedReport struct {
+	QueuedAt time.Time       `json:"queuedAt"`
+	Session  string          `json:"session,omitempty"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// queueStatusReport holds an encoded status report until the dashboard is
+// reachable again.
+func queueStatusReport(e env.Env, data []byte) error {
+	if err := ensureZeudeDir(e); err != nil {
+		return err
+	}
+	path, err := paths.StatusQueue(e)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(queuedReport{QueuedAt: e.Now(), Session: e.Getenv(telemetry.SessionIDEnv), Payload: data})
+	if err != nil {
+		return err
+	}
+	if err := appendFile(path, append(line, '\n')); err != nil {
+		return err
+	}
+	logDebug("offline: queued status report")
+	return nil
+}
+
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// flushStatusQueue sends the reports queued while offline, oldest first.
+// The queue is claimed by renaming it, so reports queued meanwhile wait
+// for the next flush; whatever fails to send is put back.
+func flushStatusQueue(ctx context.Context, e env.Env, agentKey string) {
+	path, err := paths.StatusQueue(e)
+	if err != nil {
+		return
+	}
+	claimed := path + ".sending"
+	if err := os.Rename(path, claimed); err != nil {
+		return // nothing queued, or another process is sending it
+	}
+	data, err := os.ReadFile(claimed)
+	if err != nil {
+		return
+	}
+
+	var reports []queuedReport
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, MaxResponseSize)
+	for scanner.Scan() {
+		var r queuedReport
+		if json.Unmarshal(scanner.Bytes(), &r) == nil && len(r.Payload) > 0 {
+			reports = append(reports, r)
+		}
+	}
+	if len(reports) > maxQueuedReports {
+		reports = reports[len(reports)-maxQueuedReports:]
+	}
+
+	sent := 0
+	for _, r := range reports {
+		if err := postStatus(ctx, e, agentKey, r.Payload, r.Session); err != nil {
+			logDebug("failed to send queued status report: %v", err)
+			break
+		}
+		sent++
+	}
+	if sent > 0 {
+		logger.Info("sent queued status reports", "count", sent)
+	}
+	if rest := reports[sent:]; len(rest) > 0 {
+		var buf bytes.Buffer
+		for _, r := range rest {
+			line, _ := json.Marshal(r)
+			buf.Write(append(line, '\n'))
+		}
+		if err := appendFile(path, buf.Bytes()); err != nil {
+			logError("failed to requeue status reports: %v", err)
+		}
+	}
+	removeFile(claimed)
+}
@@ -9,37 +9,56 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/housekeeping"
+	"github.com/zeude/zeude/internal/httpclient"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/project"
+	"github.com/zeude/zeude/internal/provider"
+	"github.com/zeude/zeude/internal/signing"
 )
 
 const (
-	// ConfigFetchTimeout is the maximum time to wait for config fetch.
+	// ConfigFetchTimeout is the default time each dashboard request may
+	// take; see SyncTimeoutKey.
 	ConfigFetchTimeout = 5 * time.Second
 	// CacheFile is the cached config file name.
-	CacheFile = "config-cache.json"
-	// ManagedKeysFile tracks which MCP keys are managed by Zeude.
-	ManagedKeysFile = "managed-keys.json"
-	// ManagedHooksFile tracks which hook files are managed by Zeude.
-	ManagedHooksFile = "managed-hooks.json"
-	// CacheTTL defines how long cached config remains valid.
+	CacheFile = paths.CacheFile
+	// ManifestFile tracks which servers, hooks and skills are managed by Zeude.
+	ManifestFile = paths.ManifestFile
+	// ManagedKeysFile and ManagedHooksFile are the legacy per-kind manifests.
+	ManagedKeysFile  = paths.ManagedKeysFile
+	ManagedHooksFile = paths.ManagedHooksFile
+	// CacheTTL is the default for how long cached config remains valid;
+	// see CacheTTLKey.
 	// Reduced from 48h to 5min since we now use hash-based comparison.
 	// TTL is now just a fallback - primary sync uses configVersion hash.
 	CacheTTL = 5 * time.Minute
+	// StatusReportTimeout bounds install status checks and reporting at the end of Sync.
+	StatusReportTimeout = 2 * time.Second
 	// MaxResponseSize limits API response to prevent DoS (1MB).
 	MaxResponseSize = 1 << 20
 )
 
-// debugLog controls whether debug logging is enabled.
-var debugLog = os.Getenv("ZEUDE_DEBUG") == "1"
+// httpClient is shared by all dashboard requests; timeouts come from contexts.
+var httpClient = httpclient.New(0)
+
+// logger is the sync component logger (stderr filtered by ZEUDE_DEBUG, plus ~/.zeude/logs).
+var logger = logging.Default().Component("sync")
+
+// audit records every change sync makes to the machine.
+var audit = logging.Audit()
 
 // envKeyRegex validates environment variable names.
 // Must start with letter or underscore, followed by letters, digits, or underscores.
@@ -87,16 +106,37 @@ func escapeJSValue(s string) string {
 	return s
 }
 
+// escapeRubyValue escapes special characters for Ruby string literals (single-quoted).
+// Only backslash and the quote itself are special there; newlines are literal.
+func escapeRubyValue(s string) string {
+	// Order matters: escape backslash first
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return s
+}
+
+// escapePowerShellValue escapes special characters for PowerShell string
+// literals (single-quoted), where a quote is escaped by doubling it and
+// nothing else is special. PowerShell also treats the typographic single
+// quotes as quotes, so those are doubled too.
+func escapePowerShellValue(s string) string {
+	return strings.NewReplacer(
+		"'", "''",
+		"\u2018", "\u2018\u2018",
+		"\u2019", "\u2019\u2019",
+		"\u201a", "\u201a\u201a",
+		"\u201b", "\u201b\u201b",
+	).Replace(s)
+}
+
 // logDebug logs a debug message if debug logging is enabled.
 func logDebug(format string, args ...interface{}) {
-	if debugLog {
-		log.Printf("[zeude-sync] "+format, args...)
-	}
+	logger.Debugf(format, args...)
 }
 
 // logError logs an error message.
 func logError(format string, args ...interface{}) {
-	log.Printf("[zeude-sync] ERROR: "+format, args...)
+	logger.Errorf(format, args...)
 }
 
 // AuthError represents an authentication/authorization failure.
@@ -109,22 +149,70 @@ func (e *AuthError) Error() string {
 	return fmt.Sprintf("auth error: %d - %s", e.StatusCode, e.Message)
 }
 
-// MCPServer represents an MCP server configuration.
+// MCP server transports. Local servers are stdio; the dashboard leaves
+// their type empty.
+const (
+	ServerTypeStdio = "stdio"
+	ServerTypeSSE   = "sse"
+	ServerTypeHTTP  = "http"
+)
+
+// MCPServer represents an MCP server configuration: a command claude runs
+// (stdio), or a URL it connects to (sse, http).
 type MCPServer struct {
+	Type    string            `json:"type,omitempty"`
 	Command string            `json:"command"`
 	Args    []string          `json:"args"`
 	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Scope   string            `json:"scope,omitempty"`   // ScopeGlobal (default) or ScopeProject
+	Project *ProjectMatch     `json:"project,omitempty"` // the repositories a project server is for
+}
+
+// Remote reports whether claude connects to the server rather than
+// running it. Types this version doesn't know are assumed remote: every
+// transport added since stdio has been.
+func (s MCPServer) Remote() bool {
+	return s.Type != "" && s.Type != ServerTypeStdio
 }
 
 // Hook represents a Claude Code hook configuration.
 type Hook struct {
-	ID          string            `json:"id"`
+	ID          string            `json:"id,omitempty"`
 	Name        string            `json:"name"`
 	Event       string            `json:"event"`
 	Description string            `json:"description,omitempty"`
 	Script      string            `json:"script"`
-	ScriptType  string            `json:"scriptType"`
+	ScriptType  string            `json:"scriptType"` // a key of hookScriptExts; empty is bash
 	Env         map[string]string `json:"env,omitempty"`
+	Signature   string            `json:"signature,omitempty"` // Ed25519 over Script, see package signing
+	// Matcher limits tool events (PreToolUse, PostToolUse) to the tools it
+	// matches, e.g. "Bash" or "Edit|Write". Empty fires for every tool.
+	Matcher string `json:"matcher,omitempty"`
+	// TimeoutSeconds is how long Claude lets the hook run, within
+	// MinHookTimeout..MaxHookTimeout. 0 leaves Claude's default.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// Bounds for Hook.TimeoutSeconds; values outside are clamped.
+const (
+	MinHookTimeout = 1
+	MaxHookTimeout = 600
+)
+
+// clampHookTimeout returns seconds within the hook timeout bounds, and
+// whether it had to change it. 0 stays 0, Claude's default.
+func clampHookTimeout(seconds int) (int, bool) {
+	switch {
+	case seconds == 0:
+		return 0, false
+	case seconds < MinHookTimeout:
+		return MinHookTimeout, true
+	case seconds > MaxHookTimeout:
+		return MaxHookTimeout, true
+	}
+	return seconds, false
 }
 
 // Skill represents a Claude Code slash command skill.
@@ -133,6 +221,26 @@ type Skill struct {
 	Slug        string `json:"slug"`
 	Description string `json:"description,omitempty"`
 	Content     string `json:"content"`
+	Signature   string `json:"signature,omitempty"` // Ed25519 over Content, see package signing
+	// Namespace installs the skill in a subdirectory of ~/.claude/commands,
+	// so it is invoked as /namespace:slug and can't collide with a personal
+	// command of the same slug. Empty installs it at the top level.
+	Namespace string `json:"namespace,omitempty"`
+	// Frontmatter Claude reads from the command file: the tools the command
+	// may use without asking, a hint shown for its arguments, and the model
+	// it runs with. All optional.
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	ArgumentHint string   `json:"argumentHint,omitempty"`
+	Model        string   `json:"model,omitempty"`
+}
+
+// Command returns what the skill is invoked by, without the leading "/":
+// its slug, or namespace:slug.
+func (s Skill) Command() string {
+	if s.Namespace == "" {
+		return s.Slug
+	}
+	return s.Namespace + ":" + s.Slug
 }
 
 // ConfigHashes contains Merkle-tree style hashes for efficient sync.
@@ -153,9 +261,26 @@ type ConfigResponse struct {
 	ServerCount   int                  `json:"serverCount"`
 	SkillCount    int                  `json:"skillCount"`
 	HookCount     int                  `json:"hookCount"`
-	UserID        string               `json:"userId,omitempty"`    // Supabase UUID
+	UserID        string               `json:"userId,omitempty"` // Supabase UUID
 	UserEmail     string               `json:"userEmail,omitempty"`
 	Team          string               `json:"team,omitempty"`
+	Policy        ConfigPolicy         `json:"policy,omitempty"`
+	Provider      provider.Settings    `json:"provider,omitempty"`   // Bedrock/Vertex and model choice for claude
+	SigningKey    string               `json:"signingKey,omitempty"` // Team public key, offered for trust-on-first-use
+	MOTD          *MOTD                `json:"motd,omitempty"`       // Message for the startup banner
+	// UninstallAll tells the machine to remove everything synced to it,
+	// e.g. when its user is offboarded, instead of applying this config.
+	UninstallAll bool `json:"uninstallAll,omitempty"`
+
+	etag     string   // version from the response's ETag header, if any
+	sections []string // sections the response carries, if the server sends them sectioned
+}
+
+// ConfigPolicy holds dashboard-controlled client behaviour switches.
+type ConfigPolicy struct {
+	SelfTelemetry  bool         `json:"selfTelemetry,omitempty"`  // report Zeude's own metrics via OTLP
+	ErrorReporting bool         `json:"errorReporting,omitempty"` // queue and upload crash/error reports
+	Update         UpdatePolicy `json:"update,omitempty"`         // how long a client may lag behind a release
 }
 
 // CachedConfig wraps ConfigResponse with cache metadata.
@@ -164,81 +289,111 @@ type CachedConfig struct {
 	CachedAt  time.Time      `json:"cachedAt"`
 	ExpiresAt time.Time      `json:"expiresAt"`
 	Version   string         `json:"version"`
+	// Dashboard is the dashboard the config came from; a project file can
+	// point a sync at another one, which must not reuse this cache.
+	Dashboard string `json:"dashboard,omitempty"`
+	// ServerVersion is the version the server reported alongside this config
+	// (its ETag, else configVersion). If it differs from Version, conditional
+	// requests can't match and every sync refetches the full config.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// Sectioned is whether the dashboard answers sectioned requests (see
+	// SectionsHeader), so a changed config can be fetched in part.
+	Sectioned bool `json:"sectioned,omitempty"`
+	// Applied is what the last sync installed, section by section. It
+	// survives refetches, so one changed section doesn't redo the others.
+	Applied AppliedSections `json:"applied"`
 }
 
-// ManagedKeys tracks which MCP server keys are managed by Zeude.
+// ManagedKeys is the format of the legacy managed-keys.json; see Manifest.
 type ManagedKeys struct {
 	Keys      []string  `json:"keys"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-// ManagedHooks tracks which hook files are managed by Zeude.
+// ManagedHooks is the format of the legacy managed-hooks.json; see Manifest.
 type ManagedHooks struct {
 	Hooks     []string  `json:"hooks"` // file paths
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
-// getHomeDir returns the user's home directory or error.
-// [FIX #9] Properly handle UserHomeDir errors.
-func getHomeDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+// getAgentKey returns the key for the dashboard getDashboardURL picks:
+// ZEUDE_AGENT_KEY, the variable a trusted project file names, or
+// ~/.zeude/credentials. A project's own dashboard only ever gets the
+// project's key, never the global one.
+func getAgentKey(e env.Env) string {
+	if p := dashboardProject(e); p != nil {
+		key := p.AgentKey(e)
+		if key == "" {
+			logDebug("%s sets %s but no project agent key", p.Path, project.DashboardURLKey)
+			return ""
+		}
+		warnAgentKeyShape(p.Get(project.AgentKeyEnvKey), key)
+		return key
 	}
-	if home == "" {
-		return "", errors.New("home directory is empty")
+	if key := strings.TrimSpace(e.Getenv(config.AgentKeyEnv)); key != "" {
+		warnAgentKeyShape(config.AgentKeyEnv, key)
+		return key
+	}
+	if key, name := projectAgentKey(e); key != "" {
+		warnAgentKeyShape(name, key)
+		return key
 	}
-	return home, nil
-}
 
-// getAgentKey reads the agent key from ~/.zeude/credentials.
-// [FIX #6] Handle CRLF line endings.
-func getAgentKey() string {
-	home, err := getHomeDir()
+	credPath, err := paths.Credentials(e)
 	if err != nil {
-		logDebug("failed to get home dir: %v", err)
+		logDebug("failed to get credentials path: %v", err)
 		return ""
 	}
 
-	credPath := filepath.Join(home, ".zeude", "credentials")
 	data, err := os.ReadFile(credPath)
 	if err != nil {
 		logDebug("failed to read credentials: %v", err)
 		return ""
 	}
 
-	// Parse credentials file (format: agent_key=zd_xxx)
-	// [FIX #6] Handle both LF and CRLF line endings
-	content := strings.ReplaceAll(string(data), "\r\n", "\n")
-	content = strings.ReplaceAll(content, "\r", "\n")
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "agent_key=") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "agent_key="))
-		}
-		// Also handle "agent_key = value" format
-		if strings.HasPrefix(line, "agent_key") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				return strings.TrimSpace(parts[1])
-			}
-		}
+	key, notes := config.ParseAgentKeyWithNotes(data)
+	for _, note := range notes {
+		logDebug("credentials: %s", note)
+	}
+	if key != "" {
+		warnAgentKeyShape(credPath, key)
+		return key
 	}
 
 	logDebug("no agent_key found in credentials file")
 	return ""
 }
 
-// getDashboardURL returns the dashboard URL from env or default.
-func getDashboardURL() string {
-	if url := os.Getenv("ZEUDE_DASHBOARD_URL"); url != "" {
+// warnAgentKeyShape logs a warning when a key doesn't look dashboard-issued.
+// The key is still used: the dashboard is the authority on validity.
+func warnAgentKeyShape(source, key string) {
+	if problems := config.ValidateAgentKey(key); len(problems) > 0 {
+		logger.Warn("agent key looks malformed", "source", source, "problems", strings.Join(problems, "; "))
+	}
+}
+
+// getDashboardURL returns the dashboard URL: ZEUDE_DASHBOARD_URL, a trusted
+// project file's dashboard_url, or the default.
+func getDashboardURL(e env.Env) string {
+	if url := e.Getenv("ZEUDE_DASHBOARD_URL"); url != "" {
 		return strings.TrimSuffix(url, "/")
 	}
+	if p := dashboardProject(e); p != nil {
+		return strings.TrimSuffix(p.Get(project.DashboardURLKey), "/")
+	}
 	return config.DefaultDashboardURL
 }
 
+// AgentKey returns the configured agent key, or "" if none is set.
+func AgentKey() string {
+	return getAgentKey(env.OS{})
+}
+
+// DashboardURL returns the dashboard base URL without a trailing slash.
+func DashboardURL() string {
+	return getDashboardURL(env.OS{})
+}
+
 // ErrNotModified indicates the config hasn't changed (304 response).
 var ErrNotModified = errors.New("config not modified")
 
@@ -246,30 +401,46 @@ var ErrNotModified = errors.New("config not modified")
 // If cachedVersion is provided, sends If-None-Match header for conditional request.
 // Returns ErrNotModified if server returns 304 (config unchanged).
 // [FIX #7] Limits response size to prevent DoS.
-func fetchConfig(agentKey string, cachedVersion string) (*ConfigResponse, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), ConfigFetchTimeout)
-	defer cancel()
+func fetchConfig(ctx context.Context, e env.Env, agentKey string, cachedVersion string) (*ConfigResponse, error) {
+	var config ConfigResponse
+	header, err := getConfigJSON(ctx, e, agentKey, fmt.Sprintf("%s/api/config/_", getDashboardURL(e)), cachedVersion, &config)
+	if err != nil {
+		return nil, err
+	}
+	config.etag = etagVersion(header.Get("ETag"))
+	config.sections = responseSections(header)
+	logDebug("fetched %d MCP servers (version: %s)", config.ServerCount, config.ConfigVersion)
+	return &config, nil
+}
 
-	url := fmt.Sprintf("%s/api/config/_", getDashboardURL())
+// getConfigJSON GETs url from the config API and decodes the response into
+// v, returning its headers. cachedVersion, if set, makes the request
+// conditional; a 304 is ErrNotModified and a 401 or 403 an *AuthError.
+func getConfigJSON(ctx context.Context, e env.Env, agentKey, url, cachedVersion string, v interface{}) (http.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout(e).Value)
+	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := httpclient.NewRequest(ctx, "GET", url, nil)
 	if err != nil {
 		logDebug("failed to create request: %v", err)
 		return nil, err
 	}
 
-	req.Header.Set("User-Agent", "zeude-cli/1.0")
 	req.Header.Set("Authorization", "Bearer "+agentKey)
+	if p := currentProject(e); p != nil && p.ID() != "" {
+		req.Header.Set(ProjectHeader, p.ID())
+		logDebug("fetching config for project %s", p.ID())
+	}
 
 	// Send If-None-Match header for conditional request (ETag support)
 	if cachedVersion != "" {
 		req.Header.Set("If-None-Match", cachedVersion)
-		logDebug("fetching config with If-None-Match: %s", cachedVersion)
+		logDebug("fetching %s with If-None-Match: %s", url, cachedVersion)
 	} else {
 		logDebug("fetching config from %s", url)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		logDebug("request failed: %v", err)
 		return nil, err
@@ -291,9 +462,13 @@ func fetchConfig(agentKey string, cachedVersion string) (*ConfigResponse, error)
 		}
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, rateLimited(e, resp.Header)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		logDebug("unexpected status code: %d", resp.StatusCode)
-		return nil, fmt.Errorf("config fetch failed: %d", resp.StatusCode)
+		return nil, &statusError{code: resp.StatusCode}
 	}
 
 	// [FIX #7] Limit response size to prevent DoS
@@ -304,55 +479,39 @@ func fetchConfig(agentKey string, cachedVersion string) (*ConfigResponse, error)
 		return nil, err
 	}
 
-	var config ConfigResponse
-	if err := json.Unmarshal(body, &config); err != nil {
+	if err := json.Unmarshal(body, v); err != nil {
 		logDebug("failed to parse response: %v", err)
 		return nil, err
 	}
-
-	logDebug("fetched %d MCP servers (version: %s)", config.ServerCount, config.ConfigVersion)
-	return &config, nil
+	return resp.Header, nil
 }
 
-// getZeudePath returns the path to ~/.zeude directory.
-func getZeudePath() (string, error) {
-	home, err := getHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".zeude"), nil
+// statusError is an unexpected HTTP status from the config API.
+type statusError struct {
+	code int
 }
 
-// getCachePath returns the path to the config cache file.
-func getCachePath() (string, error) {
-	zeudePath, err := getZeudePath()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(zeudePath, CacheFile), nil
+func (e *statusError) Error() string {
+	return fmt.Sprintf("config fetch failed: %d", e.code)
 }
 
-// getManagedKeysPath returns the path to the managed keys file.
-func getManagedKeysPath() (string, error) {
-	zeudePath, err := getZeudePath()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(zeudePath, ManagedKeysFile), nil
+// getZeudePath returns the Zeude data directory (~/.zeude by default).
+func getZeudePath(e env.Env) (string, error) {
+	return paths.Dir(e)
 }
 
-// getManagedHooksPath returns the path to the managed hooks file.
-func getManagedHooksPath() (string, error) {
-	zeudePath, err := getZeudePath()
-	if err != nil {
-		return "", err
+// getCachePath returns the path to the config cache file. A project's own
+// dashboard gets a cache of its own, see dashboardProject.
+func getCachePath(e env.Env) (string, error) {
+	if p := dashboardProject(e); p != nil {
+		return projectStatePath(e, p.Dir, ".cache")
 	}
-	return filepath.Join(zeudePath, ManagedHooksFile), nil
+	return paths.Cache(e)
 }
 
 // ensureZeudeDir creates ~/.zeude directory with proper permissions.
-func ensureZeudeDir() error {
-	zeudePath, err := getZeudePath()
+func ensureZeudeDir(e env.Env) error {
+	zeudePath, err := getZeudePath(e)
 	if err != nil {
 		return err
 	}
@@ -363,10 +522,16 @@ func ensureZeudeDir() error {
 	return os.Chmod(zeudePath, 0700)
 }
 
+// LoadCachedConfig loads the cached config from ~/.zeude.
+// See loadCachedConfig for the meaning of the returned values.
+func LoadCachedConfig() (*CachedConfig, bool) {
+	return loadCachedConfig(env.OS{})
+}
+
 // loadCachedConfig loads the cached config from disk.
 // Returns (config, isExpired). Even expired cache can be used as fallback for offline mode.
-func loadCachedConfig() (*CachedConfig, bool) {
-	cachePath, err := getCachePath()
+func loadCachedConfig(e env.Env) (*CachedConfig, bool) {
+	cachePath, err := getCachePath(e)
 	if err != nil {
 		logDebug("failed to get cache path: %v", err)
 		return nil, false
@@ -385,7 +550,7 @@ func loadCachedConfig() (*CachedConfig, bool) {
 	}
 
 	// Check if cache has expired (TTL is freshness indicator, not hard cutoff)
-	isExpired := time.Now().After(cached.ExpiresAt)
+	isExpired := e.Now().After(cached.ExpiresAt)
 	if isExpired {
 		logDebug("cache expired at %v (will use as fallback if needed)", cached.ExpiresAt)
 	} else {
@@ -414,7 +579,50 @@ func isCacheValid(cached *CachedConfig, serverVersion string) bool {
 // [FIX #4] Uses 0600 permissions.
 // [FIX #5] Uses os.CreateTemp for secure temp files.
 // [FIX #13] Handles cross-filesystem with fallback copy.
+// The parent directory is fsynced after the rename; see fsutil.SyncDir.
 func writeFileAtomic(targetPath string, data []byte, perm os.FileMode) error {
+	return writeFileAtomicWithOptions(targetPath, data, perm, atomicWriteOptions{})
+}
+
+// atomicWriteOptions tunes writeFileAtomicWithOptions.
+type atomicWriteOptions struct {
+	// NoDirSync skips the parent directory fsync. Only for files that are
+	// cheap to regenerate, where losing the latest write in a crash is
+	// harmless and the extra fsync would sit on the startup path.
+	NoDirSync bool
+	// AuditAction and AuditKey describe the write in the audit log;
+	// the default action is a plain file write.
+	AuditAction string
+	AuditKey    string
+}
+
+// syncDir flushes a directory after a rename; a variable so tests can
+// observe or stub it.
+var syncDir = fsutil.SyncDir
+
+// atomicWrite does the write for writeFileAtomicWithOptions; a variable so
+// tests can fail a write partway through a sync.
+var atomicWrite = writeFileAtomicUnaudited
+
+// writeFileAtomicWithOptions is writeFileAtomic with tunable durability.
+// Every attempt, successful or not, is recorded in the audit log.
+func writeFileAtomicWithOptions(targetPath string, data []byte, perm os.FileMode, opts atomicWriteOptions) error {
+	err := atomicWrite(targetPath, data, perm, opts)
+
+	action := opts.AuditAction
+	if action == "" {
+		action = logging.AuditWrite
+	}
+	audit.RecordResult(logging.AuditEvent{
+		Action:      action,
+		Path:        targetPath,
+		Key:         opts.AuditKey,
+		ContentHash: logging.ContentHash(data),
+	}, err)
+	return err
+}
+
+func writeFileAtomicUnaudited(targetPath string, data []byte, perm os.FileMode, opts atomicWriteOptions) error {
 	dir := filepath.Dir(targetPath)
 
 	// Create temp file in the same directory for atomic rename
@@ -463,6 +671,16 @@ func writeFileAtomic(targetPath string, data []byte, perm os.FileMode) error {
 	}
 
 	success = true
+
+	// The temp file's data is on disk, but the rename only updated the
+	// directory in memory. Without this, a crash right after the rename can
+	// surface an empty or missing target. The write itself has succeeded,
+	// so a failure here is logged rather than returned.
+	if !opts.NoDirSync {
+		if err := syncDir(dir); err != nil {
+			logDebug("failed to sync %s: %v", dir, err)
+		}
+	}
 	return nil
 }
 
@@ -495,224 +713,204 @@ func writeFileIfChanged(targetPath string, data []byte, perm os.FileMode) (bool,
 }
 
 // saveCachedConfig saves the config to cache with TTL.
-func saveCachedConfig(config *ConfigResponse) error {
+func saveCachedConfig(e env.Env, config *ConfigResponse) error {
 	if config == nil {
 		return nil
 	}
 
-	if err := ensureZeudeDir(); err != nil {
+	if err := ensureZeudeDir(e); err != nil {
 		return err
 	}
 
 	cached := CachedConfig{
 		Config:    *config,
-		CachedAt:  time.Now(),
-		ExpiresAt: time.Now().Add(CacheTTL),
+		CachedAt:  e.Now(),
+		ExpiresAt: e.Now().Add(cacheTTL(e).Value),
 		Version:   config.ConfigVersion,
+		Dashboard: getDashboardURL(e),
+
+		ServerVersion: config.ConfigVersion,
+		Sectioned:     config.sections != nil,
+	}
+	if config.etag != "" {
+		cached.ServerVersion = config.etag
+	}
+	if old, _ := loadCachedConfig(e); old != nil && old.Dashboard == cached.Dashboard {
+		cached.Applied = old.Applied
+	}
+
+	if err := writeCachedConfig(e, &cached); err != nil {
+		return err
 	}
+	logDebug("saved cache (expires: %v)", cached.ExpiresAt)
+	return nil
+}
 
+// writeCachedConfig writes cached to config-cache.json.
+func writeCachedConfig(e env.Env, cached *CachedConfig) error {
 	data, err := json.MarshalIndent(cached, "", "  ")
 	if err != nil {
 		logError("failed to marshal cache: %v", err)
 		return err
 	}
 
-	cachePath, err := getCachePath()
+	cachePath, err := getCachePath(e)
 	if err != nil {
 		return err
 	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return err
+	}
 
-	if err := writeFileAtomic(cachePath, data, 0600); err != nil {
+	// The cache is refetched when missing, so skip the directory fsync
+	if err := writeFileAtomicWithOptions(cachePath, data, 0600, atomicWriteOptions{NoDirSync: true}); err != nil {
 		logError("failed to write cache: %v", err)
 		return err
 	}
-
-	logDebug("saved cache (expires: %v)", cached.ExpiresAt)
 	return nil
 }
 
+// removeFile deletes a file Zeude manages and records it in the audit log.
+// A file that is already gone is not an error and isn't recorded.
+func removeFile(path string) error {
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return err
+	}
+	audit.RecordResult(logging.AuditEvent{Action: logging.AuditDelete, Path: path}, err)
+	return err
+}
+
 // clearCache removes the cached config (used on auth errors).
-func clearCache() {
-	cachePath, err := getCachePath()
+func clearCache(e env.Env) {
+	cachePath, err := getCachePath(e)
 	if err != nil {
 		return
 	}
-	if err := os.Remove(cachePath); err != nil && !os.IsNotExist(err) {
+	if err := removeFile(cachePath); err != nil && !os.IsNotExist(err) {
 		logError("failed to clear cache: %v", err)
 	} else {
 		logDebug("cache cleared")
 	}
-
-	managedKeysPath, err := getManagedKeysPath()
-	if err != nil {
-		return
-	}
-	if err := os.Remove(managedKeysPath); err != nil && !os.IsNotExist(err) {
-		logError("failed to clear managed keys: %v", err)
+	if dashboardProject(e) != nil {
+		return // the manifest lists what the global dashboard installed
 	}
 
-	managedHooksPath, err := getManagedHooksPath()
-	if err != nil {
-		return
-	}
-	if err := os.Remove(managedHooksPath); err != nil && !os.IsNotExist(err) {
-		logError("failed to clear managed hooks: %v", err)
+	if err := updateManifest(e, func(m *Manifest) { m.Servers, m.Hooks = nil, nil }); err != nil {
+		logError("failed to clear managed keys and hooks: %v", err)
 	}
 }
 
 // loadManagedKeys loads the list of previously synced MCP keys.
-func loadManagedKeys() []string {
-	managedPath, err := getManagedKeysPath()
-	if err != nil {
-		return nil
-	}
-
-	data, err := os.ReadFile(managedPath)
-	if err != nil {
-		return nil
-	}
-
-	var managed ManagedKeys
-	if err := json.Unmarshal(data, &managed); err != nil {
-		return nil
-	}
-
-	return managed.Keys
+func loadManagedKeys(e env.Env) []string {
+	return loadManifest(e).Servers
 }
 
 // saveManagedKeys saves the list of currently synced MCP keys.
-func saveManagedKeys(keys []string) error {
-	if err := ensureZeudeDir(); err != nil {
-		return err
-	}
-
-	managed := ManagedKeys{
-		Keys:      keys,
-		UpdatedAt: time.Now(),
-	}
-
-	data, err := json.MarshalIndent(managed, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	managedPath, err := getManagedKeysPath()
-	if err != nil {
-		return err
-	}
-
-	return writeFileAtomic(managedPath, data, 0600)
+func saveManagedKeys(e env.Env, keys []string) error {
+	return updateManifest(e, func(m *Manifest) { m.Servers = keys })
 }
 
 // loadManagedHooks loads the list of previously synced hook file paths.
-func loadManagedHooks() []string {
-	managedPath, err := getManagedHooksPath()
-	if err != nil {
-		return nil
-	}
-
-	data, err := os.ReadFile(managedPath)
-	if err != nil {
-		return nil
-	}
-
-	var managed ManagedHooks
-	if err := json.Unmarshal(data, &managed); err != nil {
-		return nil
-	}
-
-	return managed.Hooks
+func loadManagedHooks(e env.Env) []string {
+	return loadManifest(e).Hooks
 }
 
 // saveManagedHooks saves the list of currently synced hook file paths.
-func saveManagedHooks(hooks []string) error {
-	if err := ensureZeudeDir(); err != nil {
-		return err
-	}
-
-	managed := ManagedHooks{
-		Hooks:     hooks,
-		UpdatedAt: time.Now(),
-	}
-
-	data, err := json.MarshalIndent(managed, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	managedPath, err := getManagedHooksPath()
-	if err != nil {
-		return err
-	}
-
-	return writeFileAtomic(managedPath, data, 0600)
+func saveManagedHooks(e env.Env, hooks []string) error {
+	return updateManifest(e, func(m *Manifest) { m.Hooks = hooks })
 }
 
-// getClaudeConfigPath returns the path to ~/.claude.json.
-func getClaudeConfigPath() (string, error) {
-	home, err := getHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".claude.json"), nil
+// getClaudeConfigPath returns the path to ~/.claude.json (or under CLAUDE_CONFIG_DIR).
+func getClaudeConfigPath(e env.Env) (string, error) {
+	return paths.ClaudeConfig(e)
 }
 
 // getLockPath returns the path to the lock file.
-func getLockPath() (string, error) {
-	configPath, err := getClaudeConfigPath()
+func getLockPath(e env.Env) (string, error) {
+	configPath, err := getClaudeConfigPath(e)
 	if err != nil {
 		return "", err
 	}
 	return configPath + ".lock", nil
 }
 
-// readClaudeConfig reads and parses ~/.claude.json.
-// [FIX #11] Validates mcpServers type.
-func readClaudeConfig() (map[string]interface{}, error) {
-	configPath, err := getClaudeConfigPath()
+// claudeConfigDoc is ~/.claude.json kept as raw bytes. Only the mcpServers
+// members are located; the rest of the document (often tens of MB of
+// per-project history) is never decoded. See jsonedit.go.
+type claudeConfigDoc struct {
+	data       []byte
+	mcpServers []rawMember
+}
+
+// readClaudeConfig reads ~/.claude.json and locates its mcpServers section.
+func readClaudeConfig(e env.Env) (*claudeConfigDoc, error) {
+	configPath, err := getClaudeConfigPath(e)
 	if err != nil {
 		return nil, err
 	}
+	return readServersFile(configPath)
+}
 
+// readServersFile reads a JSON document with a top-level mcpServers
+// section: ~/.claude.json or a project's .mcp.json. A missing file is an
+// empty document.
+// [FIX #11] Validates mcpServers type.
+func readServersFile(configPath string) (*claudeConfigDoc, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return map[string]interface{}{}, nil
+			return &claudeConfigDoc{}, nil
 		}
 		return nil, err
 	}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("invalid JSON in claude.json: %w", err)
+	doc := &claudeConfigDoc{data: data}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return doc, nil
 	}
 
-	// [FIX #11] Validate mcpServers is a map if it exists
-	if mcpServers, exists := config["mcpServers"]; exists {
-		if _, ok := mcpServers.(map[string]interface{}); !ok {
+	members, _, err := topLevelObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", filepath.Base(configPath), err)
+	}
+
+	// [FIX #11] Validate mcpServers is an object if it exists
+	if m, ok := findMember(members, "mcpServers"); ok {
+		servers, err := objectMembers(data[m.ValueStart:m.ValueEnd])
+		if err != nil {
 			logError("mcpServers is not a map, will be overwritten")
 			// Don't return error, just log and continue
 			// This allows recovery from corrupted state
+		} else {
+			doc.mcpServers = servers
 		}
 	}
 
-	return config, nil
+	return doc, nil
 }
 
-// writeClaudeConfig writes the config back to ~/.claude.json.
-func writeClaudeConfig(config map[string]interface{}) error {
-	data, err := json.MarshalIndent(config, "", "  ")
+// writeClaudeConfig writes doc back to ~/.claude.json with mcpServers
+// replaced. Bytes outside mcpServers are written back unchanged, and the
+// write is skipped entirely when nothing changed.
+func writeClaudeConfig(e env.Env, doc *claudeConfigDoc, mcpServers []rawMember) error {
+	data, err := setTopLevelMember(doc.data, "mcpServers", encodeObject(mcpServers))
 	if err != nil {
 		return err
 	}
+	if bytes.Equal(data, doc.data) {
+		logDebug("claude.json unchanged, skipping write")
+		return nil
+	}
 
-	configPath, err := getClaudeConfigPath()
+	configPath, err := getClaudeConfigPath(e)
 	if err != nil {
 		return err
 	}
+	backupBeforeFirstWrite(e)
 
 	// [FIX #4] Use 0600 permissions for security
-	return writeFileAtomic(configPath, data, 0600)
+	return writeFileAtomicWithOptions(configPath, data, 0600, atomicWriteOptions{AuditAction: logging.AuditClaudeConfig, AuditKey: "mcpServers"})
 }
 
 // contains checks if a string slice contains a value.
@@ -725,103 +923,190 @@ func contains(slice []string, val string) bool {
 	return false
 }
 
-// mergeClaudeConfig merges server MCP configs into ~/.claude.json.
+// mergeClaudeConfig merges server MCP configs into ~/.claude.json, and the
+// project-scoped ones that match proj into its .mcp.json. complete is false
+// when only the .mcp.json update failed.
 // [FIX #3] Write config first, then managed keys.
-// [FIX #10] Clean up lock file after use.
-func mergeClaudeConfig(serverMCPs map[string]MCPServer) error {
-	// Acquire file lock
-	lock, lockPath, err := acquireFileLock()
-	if err != nil {
-		logError("failed to acquire lock: %v", err)
+// An applied plan holds the claude.json lock (withFileLock) across the read
+// and write.
+func mergeClaudeConfig(ctx context.Context, e env.Env, plan *SyncPlan, serverMCPs map[string]MCPServer, proj *mcpProject, changes *SyncChanges) (complete bool, err error) {
+	// A dry run only reads, and writes are atomic, so it skips the lock
+	if !plan.apply() {
+		return mergeServers(e, plan, serverMCPs, proj, changes)
+	}
+	locked := false
+	err = withFileLock(ctx, e, func() (err error) {
+		locked = true
+		complete, err = mergeServers(e, plan, serverMCPs, proj, changes)
 		return err
+	})
+	if !locked {
+		logError("%v", err)
 	}
-	// [FIX #10] Clean up lock file after use
-	defer func() {
-		releaseFileLock(lock)
-		if lockPath != "" {
-			os.Remove(lockPath)
-		}
-	}()
+	return complete, err
+}
 
-	config, err := readClaudeConfig()
+// mergeServers does the work of mergeClaudeConfig. The caller holds the
+// lock when the plan applies.
+func mergeServers(e env.Env, plan *SyncPlan, serverMCPs map[string]MCPServer, proj *mcpProject, changes *SyncChanges) (complete bool, err error) {
+	if p := dashboardProject(e); p != nil {
+		// Whatever a project's own dashboard sends stays in the project,
+		// where claude asks before starting its servers
+		if proj == nil {
+			return false, fmt.Errorf("no project to write the servers from %s to", getDashboardURL(e))
+		}
+		if err := mergeProjectServers(e, plan, proj, serverMCPs, loadOverrides(e), changes); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	doc, err := readClaudeConfig(e)
 	if err != nil {
 		logError("failed to read claude config: %v", err)
-		return err
+		return false, err
 	}
 
-	// Get or create mcpServers section
-	var existingMCPs map[string]interface{}
-	if mcpServers, ok := config["mcpServers"].(map[string]interface{}); ok {
-		existingMCPs = mcpServers
-	} else {
-		existingMCPs = map[string]interface{}{}
-	}
+	// Project-scoped servers go to the project's .mcp.json instead
+	globalMCPs, projectMCPs := splitServers(e, serverMCPs, proj)
 
 	// Load previously managed keys
-	oldManagedKeys := loadManagedKeys()
-	newManagedKeys := make([]string, 0, len(serverMCPs))
+	oldManagedKeys := loadManagedKeys(e)
+	overrides := loadOverrides(e)
+	managed, newManagedKeys, err := encodeServers(globalMCPs, overrides)
+	if err != nil {
+		return false, err
+	}
 
-	// Update or add server MCPs
-	for key, server := range serverMCPs {
-		mcpConfig := map[string]interface{}{
-			"command": server.Command,
-			"args":    server.Args,
-		}
-		if len(server.Env) > 0 {
-			mcpConfig["env"] = server.Env
-		}
-		existingMCPs[key] = mcpConfig
-		newManagedKeys = append(newManagedKeys, key)
+	merged, added, updated, removed := mergeServerMembers(doc.mcpServers, managed, newManagedKeys, oldManagedKeys, overrides.serverDisabled)
+	configPath, _ := getClaudeConfigPath(e)
+	plan.servers(configPath, added, updated, removed, oldManagedKeys, overrides.serverDisabled)
+
+	if len(removed) > 0 {
+		logDebug("removed %d deleted servers", len(removed))
 	}
 
-	// Remove servers that were previously managed but no longer exist
-	removedCount := 0
-	for _, oldKey := range oldManagedKeys {
-		if !contains(newManagedKeys, oldKey) {
-			delete(existingMCPs, oldKey)
-			removedCount++
-			logDebug("removed deleted server: %s", oldKey)
+	if plan.apply() {
+		// [FIX #3] Write config FIRST, then managed keys
+		if err := writeClaudeConfig(e, doc, merged); err != nil {
+			logError("failed to write claude config: %v", err)
+			return false, err
+		}
+		changes.ServersAdded = added
+		changes.ServersUpdated = updated
+		changes.ServersRemoved = removed
+
+		// Only save managed keys AFTER config write succeeds
+		if err := saveManagedKeys(e, newManagedKeys); err != nil {
+			logError("failed to save managed keys: %v", err)
+			// Non-fatal: config is already written
 		}
 	}
 
-	if removedCount > 0 {
-		logDebug("removed %d deleted servers", removedCount)
+	// Under the same lock; a project that can't be written doesn't fail
+	// the sync, since claude.json is already up to date
+	complete = true
+	if proj != nil {
+		if err := mergeProjectServers(e, plan, proj, projectMCPs, overrides, changes); err != nil {
+			logError("failed to update %s: %v", filepath.Join(proj.root, projectServersFile), err)
+			complete = false
+		}
 	}
 
-	config["mcpServers"] = existingMCPs
+	logDebug("merged %d servers into claude.json", len(globalMCPs))
+	return complete, nil
+}
 
-	// [FIX #3] Write config FIRST, then managed keys
-	if err := writeClaudeConfig(config); err != nil {
-		logError("failed to write claude config: %v", err)
-		return err
+// encodeServers encodes servers, indented to sit inside mcpServers, and
+// returns them with their keys sorted. Locally disabled servers stay
+// managed, so enabling them again works, but aren't encoded.
+func encodeServers(servers map[string]MCPServer, overrides localOverrides) (map[string]json.RawMessage, []string, error) {
+	keys := make([]string, 0, len(servers))
+	managed := make(map[string]json.RawMessage, len(servers))
+	for key, server := range servers {
+		keys = append(keys, key)
+		if overrides.serverDisabled(key) {
+			continue
+		}
+		value, err := serverEntry(server)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode server %s: %w", key, err)
+		}
+		managed[key] = value
 	}
+	sort.Strings(keys)
+	return managed, keys, nil
+}
 
-	// Only save managed keys AFTER config write succeeds
-	if err := saveManagedKeys(newManagedKeys); err != nil {
-		logError("failed to save managed keys: %v", err)
-		// Non-fatal: config is already written
+// mergeServerMembers merges the managed entries into current, the
+// mcpServers members of a file in file order: managed servers are updated
+// in place or appended in keys order, ones no longer managed (previously
+// in oldKeys, or now disabled) are dropped, and user servers are kept
+// byte-for-byte.
+func mergeServerMembers(current []rawMember, managed map[string]json.RawMessage, keys, oldKeys []string, disabled func(string) bool) (merged []rawMember, added, updated, removed []string) {
+	merged = make([]rawMember, 0, len(current)+len(managed))
+	index := make(map[string]int, len(current))
+	for _, m := range current {
+		if value, ok := managed[m.Key]; ok {
+			if !bytes.Equal(m.Value, value) {
+				updated = append(updated, m.Key)
+			}
+			m.Value = value
+		} else if contains(oldKeys, m.Key) || disabled(m.Key) {
+			removed = append(removed, m.Key)
+			logDebug("removed deleted server: %s", m.Key)
+			continue
+		}
+		// Repeated keys: last value wins, first position is kept
+		if i, seen := index[m.Key]; seen {
+			merged[i].Value = m.Value
+			continue
+		}
+		index[m.Key] = len(merged)
+		merged = append(merged, m)
+	}
+	for _, key := range keys {
+		value, ok := managed[key]
+		if _, seen := index[key]; ok && !seen {
+			merged = append(merged, rawMember{Key: key, Value: value})
+			added = append(added, key)
+		}
 	}
+	return merged, added, updated, removed
+}
 
-	logDebug("merged %d servers into claude.json", len(serverMCPs))
-	return nil
+// serverEntry encodes server as its mcpServers value in claude.json,
+// indented to sit inside mcpServers.
+func serverEntry(server MCPServer) ([]byte, error) {
+	if server.Remote() {
+		mcpConfig := map[string]interface{}{
+			"type": server.Type,
+			"url":  server.URL,
+		}
+		if len(server.Headers) > 0 {
+			mcpConfig["headers"] = server.Headers
+		}
+		return marshalIndentJSON(mcpConfig, "    ", "  ")
+	}
+	// stdio entries carry no type, as before remote servers existed, so
+	// existing entries aren't rewritten
+	mcpConfig := map[string]interface{}{
+		"command": server.Command,
+		"args":    server.Args,
+	}
+	if len(server.Env) > 0 {
+		mcpConfig["env"] = server.Env
+	}
+	return marshalIndentJSON(mcpConfig, "    ", "  ")
 }
 
 // getClaudeHooksDir returns the path to ~/.claude/hooks directory.
-func getClaudeHooksDir() (string, error) {
-	home, err := getHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".claude", "hooks"), nil
+func getClaudeHooksDir(e env.Env) (string, error) {
+	return paths.ClaudeHooks(e)
 }
 
 // getClaudeSettingsPath returns the path to ~/.claude/settings.json.
-func getClaudeSettingsPath() (string, error) {
-	home, err := getHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".claude", "settings.json"), nil
+func getClaudeSettingsPath(e env.Env) (string, error) {
+	return paths.ClaudeSettings(e)
 }
 
 // sanitizeFilename removes special characters from filename.
@@ -837,8 +1122,9 @@ func sanitizeFilename(name string) string {
 }
 
 // readClaudeSettings reads ~/.claude/settings.json.
-func readClaudeSettings() (map[string]interface{}, error) {
-	settingsPath, err := getClaudeSettingsPath()
+// Numbers decode as json.Number so settings Zeude doesn't own round-trip exactly.
+func readClaudeSettings(e env.Env) (map[string]interface{}, error) {
+	settingsPath, err := getClaudeSettingsPath(e)
 	if err != nil {
 		return nil, err
 	}
@@ -851,8 +1137,8 @@ func readClaudeSettings() (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	var settings map[string]interface{}
-	if err := json.Unmarshal(data, &settings); err != nil {
+	settings, err := decodeJSONObject(data)
+	if err != nil {
 		return nil, fmt.Errorf("invalid JSON in settings.json: %w", err)
 	}
 
@@ -860,189 +1146,146 @@ func readClaudeSettings() (map[string]interface{}, error) {
 }
 
 // writeClaudeSettings writes ~/.claude/settings.json.
-func writeClaudeSettings(settings map[string]interface{}) error {
-	data, err := json.MarshalIndent(settings, "", "  ")
+// The write is skipped when the file already holds the same document, so a
+// no-op sync doesn't race Claude's own writes or churn the file's mtime.
+func writeClaudeSettings(e env.Env, settings map[string]interface{}) error {
+	data, err := marshalIndentJSON(settings, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	settingsPath, err := getClaudeSettingsPath()
+	settingsPath, err := getClaudeSettingsPath(e)
 	if err != nil {
 		return err
 	}
 
+	if existing, err := os.ReadFile(settingsPath); err == nil && sameJSONDocument(existing, data) {
+		logDebug("settings.json unchanged, skipping write")
+		return nil
+	}
+
 	// Ensure .claude directory exists
 	if err := os.MkdirAll(filepath.Dir(settingsPath), 0700); err != nil {
 		return err
 	}
+	backupBeforeFirstWrite(e)
+
+	return writeFileAtomicWithOptions(settingsPath, data, 0600, atomicWriteOptions{AuditAction: logging.AuditSettings})
+}
 
-	return writeFileAtomic(settingsPath, data, 0600)
+// sameJSONDocument reports whether existing, once re-serialized the way
+// writeClaudeSettings does, equals canonical. Comparing canonical forms means
+// key order or indentation in the file on disk doesn't force a rewrite.
+func sameJSONDocument(existing, canonical []byte) bool {
+	if bytes.Equal(existing, canonical) {
+		return true
+	}
+	obj, err := decodeJSONObject(existing)
+	if err != nil {
+		return false
+	}
+	reencoded, err := marshalIndentJSON(obj, "", "  ")
+	return err == nil && bytes.Equal(reencoded, canonical)
 }
 
 // installHooks installs hooks to ~/.claude/hooks/{event}/ and registers in settings.json.
 // Injects environment variables from user config into hook scripts.
 // Also tracks and removes deleted hooks.
-// Returns list of installed hook IDs for status reporting.
-func installHooks(hooks []Hook, agentKey, dashboardURL, userEmail, team string) ([]string, error) {
-	hooksDir, err := getClaudeHooksDir()
+// Hooks whose signature doesn't satisfy the verifier are skipped.
+// Returns per-hook status (installed or rejected) for status reporting.
+func installHooks(e env.Env, plan *SyncPlan, verifier *contentVerifier, changes *SyncChanges, failures *installFailures, hooks []Hook, agentKey, dashboardURL, userEmail, team string) ([]HookInstallStatus, error) {
+	hooksDir, err := getClaudeHooksDir(e)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get hooks dir: %w", err)
 	}
 
 	// Load previously managed hooks
-	oldManagedHooks := loadManagedHooks()
+	oldManagedHooks := loadManagedHooks(e)
 	newManagedHooks := make([]string, 0, len(hooks))
 
-	// Track successfully installed hook IDs for status reporting
-	installedHookIDs := make([]string, 0, len(hooks))
+	// Track installed and rejected hooks for status reporting
+	hookStatus := make([]HookInstallStatus, 0, len(hooks))
 
 	// Track installed hooks for settings.json registration
-	installedHooks := make(map[string][]string) // event -> []scriptPaths
+	installedHooks := make(map[string][]hookRegistration) // event -> registrations
 
+	hookLog := hookLogPath(e)
+	overrides := loadOverrides(e)
+	interpreters := newInterpreterLookup()
+	var disabledHooks, newHooks []string
+	hookNames := make(map[string]string, len(hooks)) // script path -> hook name, for the plan
 	installedCount := 0
 	for _, hook := range hooks {
-		// Create event directory: ~/.claude/hooks/{event}/
-		eventDir := filepath.Join(hooksDir, hook.Event)
-		if err := os.MkdirAll(eventDir, 0755); err != nil {
-			logError("failed to create hook dir %s: %v", eventDir, err)
+		// Verify before anything touches disk
+		if err := verifier.verify(signing.KindHook, hook.Script, hook.Signature); err != nil {
+			logError("skipping unverified hook %s: %v", hook.Name, err)
+			hookStatus = append(hookStatus, HookInstallStatus{HookID: hook.ID, Installed: false, Error: err.Error()})
+			verifier.rejected++
 			continue
 		}
 
-		// Determine file extension based on script type
-		ext := ".sh"
-		shebang := "#!/bin/bash"
-		switch hook.ScriptType {
-		case "python":
-			ext = ".py"
-			shebang = "#!/usr/bin/env python3"
-		case "node":
-			ext = ".js"
-			shebang = "#!/usr/bin/env node"
-		}
-
-		// Build script with injected environment variables
-		var scriptBuilder strings.Builder
-		scriptBuilder.WriteString(shebang + "\n")
-		scriptBuilder.WriteString("# Auto-generated by Zeude - DO NOT EDIT\n")
-		scriptBuilder.WriteString("# Hook: " + hook.Name + "\n")
-		scriptBuilder.WriteString("# Event: " + hook.Event + "\n\n")
-
-		// Inject environment variables with proper escaping based on script type
-		scriptBuilder.WriteString("# Zeude environment variables\n")
-
-		switch hook.ScriptType {
-		case "python":
-			// Python: use os.environ with single-quoted strings
-			scriptBuilder.WriteString("import os\n")
-			scriptBuilder.WriteString("import sys\n")
-			scriptBuilder.WriteString(fmt.Sprintf("os.environ['ZEUDE_API_URL'] = '%s'\n", escapePythonValue(dashboardURL)))
-			scriptBuilder.WriteString(fmt.Sprintf("os.environ['ZEUDE_AGENT_KEY'] = '%s'\n", escapePythonValue(agentKey)))
-			scriptBuilder.WriteString(fmt.Sprintf("os.environ['ZEUDE_USER_EMAIL'] = '%s'\n", escapePythonValue(userEmail)))
-			scriptBuilder.WriteString(fmt.Sprintf("os.environ['ZEUDE_TEAM'] = '%s'\n", escapePythonValue(team)))
-			// Check if agent key is set (exit code 2 = blocking exit for Claude Code hooks)
-			scriptBuilder.WriteString("if not os.environ.get('ZEUDE_AGENT_KEY'):\n")
-			scriptBuilder.WriteString("    print('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.', file=sys.stderr)\n")
-			scriptBuilder.WriteString("    sys.exit(2)\n")
-
-			// Add any additional env vars from hook config
-			for key, value := range hook.Env {
-				// Skip empty values and already-set vars
-				if value == "" || strings.HasPrefix(key, "ZEUDE_") {
-					continue
-				}
-				// Validate environment variable key
-				if !isValidEnvKey(key) {
-					logDebug("skipping invalid env key: %s", key)
-					continue
-				}
-				scriptBuilder.WriteString(fmt.Sprintf("os.environ['%s'] = '%s'\n", key, escapePythonValue(value)))
-			}
+		if !supportedScriptType(hook.ScriptType) {
+			err := fmt.Errorf("unsupported script type %q (want %s)", hook.ScriptType, strings.Join(HookScriptTypes(), ", "))
+			failures.hooksFailed(1, "skipping hook %s: %v", hook.Name, err)
+			hookStatus = append(hookStatus, HookInstallStatus{HookID: hook.ID, Installed: false, Error: err.Error()})
+			continue
+		}
 
-		case "node":
-			// JavaScript: use process.env with single-quoted strings
-			scriptBuilder.WriteString(fmt.Sprintf("process.env.ZEUDE_API_URL = '%s';\n", escapeJSValue(dashboardURL)))
-			scriptBuilder.WriteString(fmt.Sprintf("process.env.ZEUDE_AGENT_KEY = '%s';\n", escapeJSValue(agentKey)))
-			scriptBuilder.WriteString(fmt.Sprintf("process.env.ZEUDE_USER_EMAIL = '%s';\n", escapeJSValue(userEmail)))
-			scriptBuilder.WriteString(fmt.Sprintf("process.env.ZEUDE_TEAM = '%s';\n", escapeJSValue(team)))
-			// Check if agent key is set (exit code 2 = blocking exit for Claude Code hooks)
-			scriptBuilder.WriteString("if (!process.env.ZEUDE_AGENT_KEY) {\n")
-			scriptBuilder.WriteString("  console.error('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.');\n")
-			scriptBuilder.WriteString("  process.exit(2);\n")
-			scriptBuilder.WriteString("}\n")
-
-			// Add any additional env vars from hook config
-			for key, value := range hook.Env {
-				// Skip empty values and already-set vars
-				if value == "" || strings.HasPrefix(key, "ZEUDE_") {
-					continue
-				}
-				// Validate environment variable key
-				if !isValidEnvKey(key) {
-					logDebug("skipping invalid env key: %s", key)
-					continue
-				}
-				scriptBuilder.WriteString(fmt.Sprintf("process.env.%s = '%s';\n", key, escapeJSValue(value)))
+		// Write hook file (only if content changed)
+		hookPath := hookFilePath(hooksDir, hook)
+		content := hookScriptContent(hook, hookLog, agentKey, dashboardURL, userEmail, team)
+		hookNames[hookPath] = hook.Name
+
+		written := false
+		if reason := changeReason(hookPath, content, contains(oldManagedHooks, hookPath)); reason != "" {
+			plan.hook(PlanWrite, hook.Name, hookPath, reason)
+			if reason == ReasonNew {
+				newHooks = append(newHooks, hookPath)
 			}
-
-		default:
-			// Shell (bash): use export with double-quoted strings and proper escaping
-			scriptBuilder.WriteString(fmt.Sprintf("export ZEUDE_API_URL=\"%s\"\n", escapeShellValue(dashboardURL)))
-			scriptBuilder.WriteString(fmt.Sprintf("export ZEUDE_AGENT_KEY=\"%s\"\n", escapeShellValue(agentKey)))
-			scriptBuilder.WriteString(fmt.Sprintf("export ZEUDE_USER_EMAIL=\"%s\"\n", escapeShellValue(userEmail)))
-			scriptBuilder.WriteString(fmt.Sprintf("export ZEUDE_TEAM=\"%s\"\n", escapeShellValue(team)))
-			// Check if agent key is set (exit code 2 = blocking exit for Claude Code hooks)
-			scriptBuilder.WriteString("if [ -z \"$ZEUDE_AGENT_KEY\" ]; then\n")
-			scriptBuilder.WriteString("  echo \"Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.\" >&2\n")
-			scriptBuilder.WriteString("  exit 2\n")
-			scriptBuilder.WriteString("fi\n")
-
-			// Add any additional env vars from hook config
-			for key, value := range hook.Env {
-				// Skip empty values and already-set vars
-				if value == "" || strings.HasPrefix(key, "ZEUDE_") {
-					continue
+			if plan.apply() {
+				// Create event directory: ~/.claude/hooks/{event}/
+				err := os.MkdirAll(filepath.Dir(hookPath), 0755)
+				if err == nil {
+					err = writeFileAtomic(hookPath, content, 0755)
 				}
-				// Validate environment variable key
-				if !isValidEnvKey(key) {
-					logDebug("skipping invalid env key: %s", key)
+				if err != nil {
+					failures.hooksFailed(1, "failed to write hook %s: %v", hookPath, err)
+					hookStatus = append(hookStatus, HookInstallStatus{HookID: hook.ID, Installed: false, Error: err.Error()})
 					continue
 				}
-				scriptBuilder.WriteString(fmt.Sprintf("export %s=\"%s\"\n", key, escapeShellValue(value)))
+				written = true
 			}
 		}
-		scriptBuilder.WriteString("\n")
 
-		// Append the original script (without its shebang if present)
-		script := hook.Script
-		if strings.HasPrefix(script, "#!") {
-			// Remove original shebang line
-			if idx := strings.Index(script, "\n"); idx != -1 {
-				script = script[idx+1:]
+		// Track for settings.json, unless turned off on this machine
+		if overrides.hookDisabled(hook.ID) {
+			disabledHooks = append(disabledHooks, hookPath)
+			logDebug("hook disabled locally, not registering: %s", hook.Name)
+		} else {
+			timeout, clamped := clampHookTimeout(hook.TimeoutSeconds)
+			if clamped {
+				logger.Warn("hook timeout out of range, clamped", "hook", hook.Name, "timeoutSeconds", hook.TimeoutSeconds, "using", timeout)
 			}
+			installedHooks[hook.Event] = append(installedHooks[hook.Event], hookRegistration{Path: hookPath, Matcher: hook.Matcher, Timeout: timeout})
 		}
-		scriptBuilder.WriteString(script)
-
-		// Write hook file (only if content changed)
-		filename := sanitizeFilename(hook.Name) + ext
-		hookPath := filepath.Join(eventDir, filename)
-
-		written, err := writeFileIfChanged(hookPath, []byte(scriptBuilder.String()), 0755)
-		if err != nil {
-			logError("failed to write hook %s: %v", hookPath, err)
-			continue
-		}
-
-		// Track for settings.json
-		installedHooks[hook.Event] = append(installedHooks[hook.Event], hookPath)
 
 		// Track for managed hooks
 		newManagedHooks = append(newManagedHooks, hookPath)
 
-		// Track hook ID for status reporting
-		installedHookIDs = append(installedHookIDs, hook.ID)
+		// Track hook ID for status reporting. The script stays in place
+		// without its interpreter, so it runs once that is installed
+		status := HookInstallStatus{HookID: hook.ID, Installed: true}
+		if !overrides.hookDisabled(hook.ID) {
+			if name := interpreters.missing(hook.ScriptType); name != "" {
+				failures.hookUnrunnable(hook.Name, name)
+				status = HookInstallStatus{HookID: hook.ID, Installed: false, Error: name + " is not installed (not found on PATH)"}
+			}
+		}
+		hookStatus = append(hookStatus, status)
 
 		if written {
 			installedCount++
+			changes.HooksInstalled = append(changes.HooksInstalled, hook.Name)
 			logDebug("installed hook: %s -> %s", hook.Name, hookPath)
 		} else {
 			logDebug("hook unchanged: %s", hook.Name)
@@ -1053,12 +1296,20 @@ func installHooks(hooks []Hook, agentKey, dashboardURL, userEmail, team string)
 	deletedHooks := make([]string, 0)
 	for _, oldHook := range oldManagedHooks {
 		if !contains(newManagedHooks, oldHook) {
+			if _, err := os.Stat(oldHook); err == nil {
+				plan.hook(PlanDelete, filepath.Base(oldHook), oldHook, ReasonRemoved)
+			}
+			if !plan.apply() {
+				deletedHooks = append(deletedHooks, oldHook)
+				continue
+			}
 			// Delete the hook file
-			if err := os.Remove(oldHook); err != nil {
+			if err := removeFile(oldHook); err != nil {
 				if !os.IsNotExist(err) {
 					logError("failed to remove deleted hook %s: %v", oldHook, err)
 				}
 			} else {
+				changes.HooksRemoved = append(changes.HooksRemoved, oldHook)
 				logDebug("removed deleted hook: %s", oldHook)
 			}
 			deletedHooks = append(deletedHooks, oldHook)
@@ -1069,27 +1320,234 @@ func installHooks(hooks []Hook, agentKey, dashboardURL, userEmail, team string)
 		logDebug("removed %d deleted hooks", len(deletedHooks))
 	}
 
-	// Register hooks in ~/.claude/settings.json (also removes deleted hooks)
-	if err := registerHooksInSettings(installedHooks, deletedHooks); err != nil {
-		logError("failed to register hooks in settings: %v", err)
-		// Non-fatal: scripts are still installed
+	// Register hooks in ~/.claude/settings.json (also removes deleted and disabled hooks)
+	registrations, err := registerHooksInSettings(e, plan, installedHooks, append(deletedHooks, disabledHooks...))
+	if err != nil {
+		// Non-fatal, but claude won't run scripts it doesn't know about
+		failures.hooksFailed(len(installedHooks), "failed to register hooks in settings: %v", err)
+	}
+	settingsPath, _ := getClaudeSettingsPath(e)
+	for _, path := range registrations.added {
+		reason := ReasonUnregistered
+		if contains(newHooks, path) {
+			reason = ReasonNew
+		}
+		plan.hook(PlanRegister, hookNames[path], settingsPath, reason)
+	}
+	for _, path := range registrations.updated {
+		plan.hook(PlanRegister, hookNames[path], settingsPath, ReasonChanged)
+	}
+	for _, path := range registrations.removed {
+		name, reason := hookNames[path], ReasonDisabled
+		if !contains(disabledHooks, path) {
+			name, reason = filepath.Base(path), ReasonRemoved
+		}
+		plan.hook(PlanUnregister, name, settingsPath, reason)
+	}
+	if !plan.apply() {
+		return hookStatus, nil
 	}
 
 	// Save managed hooks AFTER successful installation
-	if err := saveManagedHooks(newManagedHooks); err != nil {
+	if err := saveManagedHooks(e, newManagedHooks); err != nil {
 		logError("failed to save managed hooks: %v", err)
 		// Non-fatal: hooks are already installed
 	}
 
 	logDebug("installed %d/%d hooks", installedCount, len(hooks))
-	return installedHookIDs, nil
+	return hookStatus, nil
+}
+
+// hookScriptContent builds the script installHooks writes for hook: a
+// shebang for its script type, the Zeude environment, then the hook's own
+// script. Hook env vars are emitted in sorted order so the output is stable.
+func hookScriptContent(hook Hook, hookLog, agentKey, dashboardURL, userEmail, team string) []byte {
+	envKeys := make([]string, 0, len(hook.Env))
+	for key := range hook.Env {
+		envKeys = append(envKeys, key)
+	}
+	sort.Strings(envKeys)
+
+	// Per script type: shebang, line comment, how to set an environment
+	// variable, and the check that stops the hook when there's no agent key
+	// (exit code 2 = blocking exit for Claude Code hooks)
+	var shebang, comment, preamble, guard string
+	var setenv func(key, value string) string
+	switch hook.ScriptType {
+	case "python":
+		// Python: use os.environ with single-quoted strings
+		shebang, comment, preamble = "#!/usr/bin/env python3", "#", "import os\nimport sys\n"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("os.environ['%s'] = '%s'\n", key, escapePythonValue(value))
+		}
+		guard = "if not os.environ.get('ZEUDE_AGENT_KEY'):\n" +
+			"    print('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.', file=sys.stderr)\n" +
+			"    sys.exit(2)\n"
+	case "node":
+		// JavaScript: use process.env with single-quoted strings
+		shebang, comment = "#!/usr/bin/env node", "//"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("process.env.%s = '%s';\n", key, escapeJSValue(value))
+		}
+		guard = "if (!process.env.ZEUDE_AGENT_KEY) {\n" +
+			"  console.error('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.');\n" +
+			"  process.exit(2);\n" +
+			"}\n"
+	case "deno":
+		// Deno: use Deno.env with single-quoted strings
+		shebang, comment = "#!/usr/bin/env -S deno run -A", "//"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("Deno.env.set('%s', '%s');\n", key, escapeJSValue(value))
+		}
+		guard = "if (!Deno.env.get('ZEUDE_AGENT_KEY')) {\n" +
+			"  console.error('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.');\n" +
+			"  Deno.exit(2);\n" +
+			"}\n"
+	case "ruby":
+		// Ruby: use ENV with single-quoted strings
+		shebang, comment = "#!/usr/bin/env ruby", "#"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("ENV['%s'] = '%s'\n", key, escapeRubyValue(value))
+		}
+		guard = "if ENV['ZEUDE_AGENT_KEY'].to_s.empty?\n" +
+			"  warn 'Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.'\n" +
+			"  exit 2\n" +
+			"end\n"
+	case "powershell":
+		// PowerShell: use $env: with single-quoted strings
+		shebang, comment = "#!/usr/bin/env pwsh", "#"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("$env:%s = '%s'\n", key, escapePowerShellValue(value))
+		}
+		guard = "if (-not $env:ZEUDE_AGENT_KEY) {\n" +
+			"  [Console]::Error.WriteLine('Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.')\n" +
+			"  exit 2\n" +
+			"}\n"
+	default:
+		// Shell (bash): use export with double-quoted strings and proper escaping
+		shebang, comment = "#!/bin/bash", "#"
+		setenv = func(key, value string) string {
+			return fmt.Sprintf("export %s=\"%s\"\n", key, escapeShellValue(value))
+		}
+		guard = "if [ -z \"$ZEUDE_AGENT_KEY\" ]; then\n" +
+			"  echo \"Error: ZEUDE_AGENT_KEY is not configured. Please run zeude setup.\" >&2\n" +
+			"  exit 2\n" +
+			"fi\n"
+	}
+
+	// Build script with injected environment variables
+	var scriptBuilder strings.Builder
+	scriptBuilder.WriteString(shebang + "\n")
+	scriptBuilder.WriteString(comment + " Auto-generated by Zeude - DO NOT EDIT\n")
+	scriptBuilder.WriteString(comment + " Hook: " + hook.Name + "\n")
+	scriptBuilder.WriteString(comment + " Event: " + hook.Event + "\n\n")
+	scriptBuilder.WriteString(hookLoggingWrapper(hook, hookLog))
+
+	scriptBuilder.WriteString(comment + " Zeude environment variables\n")
+	scriptBuilder.WriteString(preamble)
+	scriptBuilder.WriteString(setenv("ZEUDE_API_URL", dashboardURL))
+	scriptBuilder.WriteString(setenv("ZEUDE_AGENT_KEY", agentKey))
+	scriptBuilder.WriteString(setenv("ZEUDE_USER_EMAIL", userEmail))
+	scriptBuilder.WriteString(setenv("ZEUDE_TEAM", team))
+	scriptBuilder.WriteString(guard)
+
+	// Add any additional env vars from hook config
+	for _, key := range envKeys {
+		value := hook.Env[key]
+		// Skip empty values and already-set vars
+		if value == "" || strings.HasPrefix(key, "ZEUDE_") {
+			continue
+		}
+		// Validate environment variable key
+		if !isValidEnvKey(key) {
+			logDebug("skipping invalid env key: %s", key)
+			continue
+		}
+		scriptBuilder.WriteString(setenv(key, value))
+	}
+	scriptBuilder.WriteString("\n")
+
+	// Append the original script (without its shebang if present)
+	script := hook.Script
+	if strings.HasPrefix(script, "#!") {
+		// Remove original shebang line
+		if idx := strings.Index(script, "\n"); idx != -1 {
+			script = script[idx+1:]
+		}
+	}
+	scriptBuilder.WriteString(script)
+	return []byte(scriptBuilder.String())
+}
+
+// hookScriptExts maps the hook script types installHooks supports to file
+// extensions. An empty type is bash; installHooks rejects any other.
+var hookScriptExts = map[string]string{
+	"bash":       ".sh",
+	"python":     ".py",
+	"node":       ".js",
+	"deno":       ".ts",
+	"ruby":       ".rb",
+	"powershell": ".ps1",
 }
 
-// registerHooksInSettings adds Zeude hooks to ~/.claude/settings.json and removes deleted hooks.
-func registerHooksInSettings(installedHooks map[string][]string, deletedHooks []string) error {
-	settings, err := readClaudeSettings()
+// supportedScriptType reports whether installHooks can generate a script
+// of type t.
+func supportedScriptType(t string) bool {
+	_, ok := hookScriptExts[t]
+	return ok || t == ""
+}
+
+// hookFilePath returns where a hook's script is installed:
+// {hooksDir}/{event}/{name}.{ext}.
+func hookFilePath(hooksDir string, hook Hook) string {
+	ext, ok := hookScriptExts[hook.ScriptType]
+	if !ok {
+		ext = ".sh"
+	}
+	return filepath.Join(hooksDir, hook.Event, sanitizeFilename(hook.Name)+ext)
+}
+
+// hookRegistration is a settings.json entry for an installed hook.
+type hookRegistration struct {
+	Path    string
+	Matcher string
+	Timeout int // seconds, already clamped; 0 for Claude's default
+}
+
+// entry returns the settings.json hooks entry running r.
+func (r hookRegistration) entry() map[string]interface{} {
+	command := map[string]interface{}{
+		"type":    "command",
+		"command": r.Path,
+	}
+	if r.Timeout > 0 {
+		command["timeout"] = r.Timeout
+	}
+	entry := map[string]interface{}{
+		"hooks": []interface{}{command},
+	}
+	if r.Matcher != "" {
+		entry["matcher"] = r.Matcher
+	}
+	return entry
+}
+
+// registrationChanges lists, by script path, the entries
+// registerHooksInSettings added, rewrote and removed.
+type registrationChanges struct {
+	added, updated, removed []string
+}
+
+// registerHooksInSettings adds Zeude hooks to ~/.claude/settings.json and
+// removes deleted hooks. An entry that no longer matches its registration,
+// such as a changed matcher or timeout, is rewritten where it stands rather
+// than added a second time. With a dry-run plan, settings.json is left as
+// it is.
+func registerHooksInSettings(e env.Env, plan *SyncPlan, installedHooks map[string][]hookRegistration, deletedHooks []string) (registrationChanges, error) {
+	var changes registrationChanges
+	settings, err := readClaudeSettings(e)
 	if err != nil {
-		return fmt.Errorf("failed to read settings: %w", err)
+		return changes, fmt.Errorf("failed to read settings: %w", err)
 	}
 
 	// Get or create hooks section
@@ -1104,22 +1562,104 @@ func registerHooksInSettings(installedHooks map[string][]string, deletedHooks []
 		deletedSet[path] = true
 	}
 
-	// Build set of all new hook paths
-	newHookPaths := make(map[string]bool)
-	for _, paths := range installedHooks {
-		for _, path := range paths {
-			newHookPaths[path] = true
+	// Build set of all new hook registrations
+	wanted := make(map[string]hookRegistration)
+	for _, regs := range installedHooks {
+		for _, reg := range regs {
+			wanted[reg.Path] = reg
+		}
+	}
+
+	// Remove deleted hooks and duplicates, and bring the first entry for
+	// each registration up to date in place
+	kept := make(map[string]bool)
+	dropped := dropZeudeHooks(e, hooksSection, func(cmd string, entry interface{}) bool {
+		reg, ok := wanted[cmd]
+		if !ok {
+			return deletedSet[cmd]
+		}
+		if kept[cmd] {
+			return true
+		}
+		kept[cmd] = true
+		if want := reg.entry(); !sameJSONValue(entry, want) {
+			changes.updated = append(changes.updated, cmd)
+			m := entry.(map[string]interface{}) // dropZeudeHooks only passes objects
+			for key := range m {
+				delete(m, key)
+			}
+			for key, value := range want {
+				m[key] = value
+			}
+		}
+		return false
+	})
+	for _, cmd := range dropped {
+		if _, ok := wanted[cmd]; !ok && !contains(changes.removed, cmd) {
+			changes.removed = append(changes.removed, cmd)
+		}
+	}
+
+	// For each event type, add new Zeude hooks
+	for event, regs := range installedHooks {
+		// Get existing hooks for this event (non-Zeude hooks already filtered above)
+		var eventHooks []interface{}
+		if existing, ok := hooksSection[event].([]interface{}); ok {
+			eventHooks = existing
+		}
+
+		// Add Zeude hooks
+		for _, reg := range regs {
+			if kept[reg.Path] {
+				continue
+			}
+			changes.added = append(changes.added, reg.Path)
+			eventHooks = append(eventHooks, reg.entry())
 		}
+
+		hooksSection[event] = eventHooks
+	}
+
+	settings["hooks"] = hooksSection
+	if !plan.apply() {
+		return changes, nil
+	}
+
+	if err := writeClaudeSettings(e, settings); err != nil {
+		return registrationChanges{}, fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	logDebug("registered hooks in settings.json")
+	return changes, nil
+}
+
+// sameJSONValue reports whether a and b encode to the same JSON. Object
+// keys are sorted when encoding, so key order doesn't matter.
+func sameJSONValue(a, b interface{}) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
 	}
+	y, err := json.Marshal(b)
+	return err == nil && bytes.Equal(x, y)
+}
 
-	// Process all event types in settings to remove deleted hooks
+// dropZeudeHooks removes the Zeude hook entries in a settings.json hooks
+// section that drop selects, given each one's command and the entry itself,
+// returning the commands removed. User hooks are never touched.
+func dropZeudeHooks(e env.Env, hooksSection map[string]interface{}, drop func(cmd string, entry interface{}) bool) []string {
+	// Zeude hooks live under the hooks dir, which moves with CLAUDE_CONFIG_DIR
+	hooksDir, _ := getClaudeHooksDir(e)
+
+	var dropped []string
 	for event := range hooksSection {
 		existing, ok := hooksSection[event].([]interface{})
 		if !ok {
 			continue
 		}
 
-		var eventHooks []interface{}
+		// Non-nil so an event left empty stays [] rather than becoming null
+		eventHooks := make([]interface{}, 0, len(existing))
 		for _, h := range existing {
 			hookMap, ok := h.(map[string]interface{})
 			if !ok {
@@ -1138,10 +1678,10 @@ func registerHooksInSettings(installedHooks map[string][]string, deletedHooks []
 				continue
 			}
 			cmd, _ := firstHook["command"].(string)
-			// Skip if it's a Zeude hook (contains .claude/hooks/)
-			if strings.Contains(cmd, ".claude/hooks/") {
-				// Skip deleted hooks or hooks that will be re-added
-				if deletedSet[cmd] || newHookPaths[cmd] {
+			// Only Zeude hooks (contains .claude/hooks/ or lives in hooksDir) are dropped
+			if strings.Contains(cmd, ".claude/hooks/") || (hooksDir != "" && strings.HasPrefix(cmd, hooksDir+string(filepath.Separator))) {
+				if drop(cmd, h) {
+					dropped = append(dropped, cmd)
 					continue
 				}
 			}
@@ -1149,59 +1689,26 @@ func registerHooksInSettings(installedHooks map[string][]string, deletedHooks []
 		}
 		hooksSection[event] = eventHooks
 	}
-
-	// For each event type, add new Zeude hooks
-	for event, scriptPaths := range installedHooks {
-		// Get existing hooks for this event (non-Zeude hooks already filtered above)
-		var eventHooks []interface{}
-		if existing, ok := hooksSection[event].([]interface{}); ok {
-			eventHooks = existing
-		}
-
-		// Add Zeude hooks
-		for _, scriptPath := range scriptPaths {
-			zeudeHook := map[string]interface{}{
-				"hooks": []interface{}{
-					map[string]interface{}{
-						"type":    "command",
-						"command": scriptPath,
-					},
-				},
-			}
-			eventHooks = append(eventHooks, zeudeHook)
-		}
-
-		hooksSection[event] = eventHooks
-	}
-
-	settings["hooks"] = hooksSection
-
-	if err := writeClaudeSettings(settings); err != nil {
-		return fmt.Errorf("failed to write settings: %w", err)
-	}
-
-	logDebug("registered hooks in settings.json")
-	return nil
+	return dropped
 }
 
 // installSkills installs skills to ~/.claude/commands/ as markdown files.
 // Returns error if installation fails.
-func installSkills(skills []Skill) error {
-	homeDir, err := os.UserHomeDir()
+func installSkills(e env.Env, plan *SyncPlan, verifier *contentVerifier, changes *SyncChanges, failures *installFailures, skills []Skill) error {
+	commandsDir, err := paths.ClaudeCommands(e)
 	if err != nil {
-		return fmt.Errorf("failed to get home dir: %w", err)
+		return fmt.Errorf("failed to get commands dir: %w", err)
 	}
 
-	commandsDir := filepath.Join(homeDir, ".claude", "commands")
-
 	// Create commands directory if needed
-	if err := os.MkdirAll(commandsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create commands dir: %w", err)
+	if plan.apply() {
+		if err := os.MkdirAll(commandsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create commands dir: %w", err)
+		}
 	}
 
 	// Load previously managed skills
-	managedSkillsFile := filepath.Join(homeDir, ".zeude", "managed_skills.json")
-	oldManagedSkills := loadManagedSkills(managedSkillsFile)
+	oldManagedSkills := loadManagedSkills(e)
 	newManagedSkills := make([]string, 0, len(skills))
 
 	installedCount := 0
@@ -1212,30 +1719,41 @@ func installSkills(skills []Skill) error {
 			logDebug("skipping skill with empty slug or content: %s", skill.Name)
 			continue
 		}
-
-		// Build skill file content with frontmatter
-		var content strings.Builder
-		content.WriteString("---\n")
-		content.WriteString(fmt.Sprintf("name: %s\n", skill.Name))
-		if skill.Description != "" {
-			content.WriteString(fmt.Sprintf("description: %s\n", skill.Description))
+		if err := verifier.verify(signing.KindSkill, skill.Content, skill.Signature); err != nil {
+			logError("skipping unverified skill %s: %v", skill.Name, err)
+			verifier.rejected++
+			continue
 		}
-		content.WriteString("---\n\n")
-		content.WriteString(skill.Content)
 
 		// Write skill file (only if content changed)
-		filename := sanitizeFilename(skill.Slug) + ".md"
-		skillPath := filepath.Join(commandsDir, filename)
-
-		written, err := writeFileIfChanged(skillPath, []byte(content.String()), 0644)
-		if err != nil {
-			logError("failed to write skill %s: %v", skillPath, err)
+		skillPath := skillFilePath(commandsDir, skill)
+		if contains(newManagedSkills, skillPath) {
+			failures.skillsFailed(1, "skipping skill %s: another skill is already installed as %s", skill.Name, skillPath)
 			continue
 		}
 
+		content := skillFileContent(skill)
+
+		written := false
+		if reason := changeReason(skillPath, content, contains(oldManagedSkills, skillPath)); reason != "" {
+			plan.skill(PlanWrite, skill.Name, skillPath, reason)
+			if plan.apply() {
+				err := os.MkdirAll(filepath.Dir(skillPath), 0755)
+				if err == nil {
+					err = writeFileAtomic(skillPath, content, 0644)
+				}
+				if err != nil {
+					failures.skillsFailed(1, "failed to write skill %s: %v", skillPath, err)
+					continue
+				}
+				written = true
+			}
+		}
+
 		newManagedSkills = append(newManagedSkills, skillPath)
 		if written {
 			installedCount++
+			changes.SkillsWritten = append(changes.SkillsWritten, skill.Name)
 			logDebug("installed skill: %s -> %s", skill.Name, skillPath)
 		} else {
 			logDebug("skill unchanged: %s", skill.Name)
@@ -1246,19 +1764,35 @@ func installSkills(skills []Skill) error {
 	deletedCount := 0
 	for _, oldSkill := range oldManagedSkills {
 		if !contains(newManagedSkills, oldSkill) {
-			if err := os.Remove(oldSkill); err != nil {
+			if _, err := os.Stat(oldSkill); err == nil {
+				plan.skill(PlanDelete, filepath.Base(oldSkill), oldSkill, ReasonRemoved)
+			}
+			if !plan.apply() {
+				continue
+			}
+			if err := removeFile(oldSkill); err != nil {
 				if !os.IsNotExist(err) {
 					logError("failed to remove deleted skill %s: %v", oldSkill, err)
 				}
 			} else {
+				changes.SkillsRemoved = append(changes.SkillsRemoved, oldSkill)
 				logDebug("removed deleted skill: %s", oldSkill)
 				deletedCount++
+				// Drop a namespace directory once its last skill is gone;
+				// one still holding files is left alone
+				if dir := filepath.Dir(oldSkill); dir != commandsDir {
+					os.Remove(dir)
+				}
 			}
 		}
 	}
 
+	if !plan.apply() {
+		return nil
+	}
+
 	// Save new managed skills list
-	if err := saveManagedSkills(managedSkillsFile, newManagedSkills); err != nil {
+	if err := saveManagedSkills(e, newManagedSkills); err != nil {
 		logError("failed to save managed skills: %v", err)
 	}
 
@@ -1269,48 +1803,75 @@ func installSkills(skills []Skill) error {
 	return nil
 }
 
-// loadManagedSkills loads the list of managed skill paths.
-func loadManagedSkills(path string) []string {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil
+// skillFilePath returns where a skill is installed:
+// {commandsDir}/[{namespace}/]{slug}.md.
+func skillFilePath(commandsDir string, skill Skill) string {
+	name := sanitizeFilename(skill.Slug) + ".md"
+	if skill.Namespace == "" {
+		return filepath.Join(commandsDir, name)
 	}
+	return filepath.Join(commandsDir, sanitizeFilename(skill.Namespace), name)
+}
 
-	var skills []string
-	if err := json.Unmarshal(data, &skills); err != nil {
-		return nil
+// skillFileContent renders a skill as a command file with frontmatter.
+// Fields left empty are omitted, and values are quoted only when YAML
+// needs it, so the output for a given skill never changes between syncs.
+func skillFileContent(skill Skill) []byte {
+	var content strings.Builder
+	content.WriteString("---\n")
+	content.WriteString(fmt.Sprintf("%s: %s\n", frontmatterName, yamlScalar(skill.Name)))
+	if skill.Description != "" {
+		content.WriteString(fmt.Sprintf("%s: %s\n", frontmatterDescription, yamlScalar(skill.Description)))
+	}
+	var tools []string
+	for _, tool := range skill.AllowedTools {
+		if tool = strings.TrimSpace(tool); tool != "" {
+			tools = append(tools, tool)
+		}
+	}
+	if len(tools) > 0 {
+		content.WriteString(frontmatterAllowedTools + ":\n")
+		for _, tool := range tools {
+			content.WriteString(fmt.Sprintf("  - %s\n", yamlScalar(tool)))
+		}
 	}
+	if skill.ArgumentHint != "" {
+		content.WriteString(fmt.Sprintf("%s: %s\n", frontmatterArgumentHint, yamlScalar(skill.ArgumentHint)))
+	}
+	if skill.Model != "" {
+		content.WriteString(fmt.Sprintf("%s: %s\n", frontmatterModel, yamlScalar(skill.Model)))
+	}
+	content.WriteString("---\n\n")
+	content.WriteString(skill.Content)
+	return []byte(content.String())
+}
 
-	return skills
+// loadManagedSkills loads the list of managed skill paths.
+func loadManagedSkills(e env.Env) []string {
+	return loadManifest(e).Skills
 }
 
 // saveManagedSkills saves the list of managed skill paths.
-func saveManagedSkills(path string, skills []string) error {
-	data, err := json.Marshal(skills)
-	if err != nil {
-		return err
-	}
-
-	return writeFileAtomic(path, data, 0644)
+func saveManagedSkills(e env.Env, skills []string) error {
+	return updateManifest(e, func(m *Manifest) { m.Skills = skills })
 }
 
 // syncSkillRules fetches skill-rules.json from dashboard API and saves to ~/.claude/skill-rules.json.
 // This file is used by the Skill Hint hook for fast local keyword matching.
-func syncSkillRules(agentKey string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), ConfigFetchTimeout)
+func syncSkillRules(ctx context.Context, e env.Env, agentKey string) error {
+	ctx, cancel := context.WithTimeout(ctx, syncTimeout(e).Value)
 	defer cancel()
 
-	url := fmt.Sprintf("%s/api/skill-rules", getDashboardURL())
+	url := fmt.Sprintf("%s/api/skill-rules", getDashboardURL(e))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := httpclient.NewRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "zeude-cli/1.0")
 	req.Header.Set("Authorization", "Bearer "+agentKey)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -1334,17 +1895,14 @@ func syncSkillRules(agentKey string) error {
 	}
 
 	// Write to ~/.claude/skill-rules.json
-	home, err := getHomeDir()
+	rulesPath, err := paths.ClaudeSkillRules(e)
 	if err != nil {
 		return err
 	}
-
-	claudeDir := filepath.Join(home, ".claude")
-	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
 		return fmt.Errorf("failed to create .claude dir: %w", err)
 	}
 
-	rulesPath := filepath.Join(claudeDir, "skill-rules.json")
 	written, err := writeFileIfChanged(rulesPath, data, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write skill-rules: %w", err)
@@ -1366,91 +1924,407 @@ type SyncResult struct {
 	UserEmail   string
 	Team        string
 	Success     bool
-	ServerCount int
+	ServerCount int // excludes DisabledServerCount
 	SkillCount  int
 	HookCount   int
 	FromCache   bool
 	NoAgentKey  bool // True when agent key is not configured
+	Paused      bool // 'zeude pause' is in effect; nothing was fetched or written
+	PausedUntil time.Time
+	TornDown    bool // The dashboard sent uninstallAll; Changes lists what was removed
+
+	SelfTelemetry bool              // Dashboard policy enables self-telemetry
+	Provider      provider.Settings // Dashboard's provider settings, before local overrides
+	MOTD          *MOTD             // Dashboard's message of the day, if any
+
+	UnverifiedCount     int // Hooks and skills skipped by signature verification
+	DisabledServerCount int // Servers turned off with 'zeude servers disable'
+
+	// Hooks and skills that could not be written. The sync still counts as
+	// a success; FirstError says what went wrong first.
+	HooksFailed  int
+	SkillsFailed int
+	FirstError   string
+
+	// Hooks installed whose interpreter (python3, node, ...) isn't on PATH,
+	// so they fail until it is; MissingInterpreters names those programs.
+	HooksUnrunnable     int
+	MissingInterpreters []string
+
+	// RateLimitedUntil is set while backing off after a 429: the cached
+	// config, if any, was applied without asking.
+	RateLimitedUntil time.Time
+
+	NotModified bool           // Dashboard answered 304; the cached config was reapplied
+	Offline     bool           // ZEUDE_OFFLINE or OfflineOnly: the cached config was applied without asking
+	Overdue     *UpdateOverdue // An update has failed to install for too long; nil if not
+	Changes     SyncChanges    // What this sync changed on disk
+	Plan        *SyncPlan      // The same changes, with why each was made
+	Timings     SyncTimings    // How long each phase took
+	Err         error          // Why the sync failed or was incomplete, if it did
+}
+
+// SyncTimings is how long each phase of a sync took. Phases that didn't
+// run are zero.
+type SyncTimings struct {
+	Fetch  time.Duration // config request, or reading the cache offline
+	Merge  time.Duration // writing MCP servers into ~/.claude.json
+	Hooks  time.Duration
+	Skills time.Duration
+	Status time.Duration // install status, hook status and queued reports
+}
+
+// installFailures tallies what installHooks and installSkills could not
+// write, logging each failure.
+type installFailures struct {
+	hooks, skills int
+	first         string
+
+	unrunnable   int      // hooks written without their interpreter on PATH
+	interpreters []string // the interpreters missing
+}
+
+// hooksFailed records n hooks lost to one error.
+func (f *installFailures) hooksFailed(n int, format string, args ...interface{}) {
+	f.hooks += n
+	f.record(format, args...)
+}
+
+// hookUnrunnable records a hook installed without its interpreter.
+func (f *installFailures) hookUnrunnable(hook, interpreter string) {
+	logger.Warn("hook interpreter not found on PATH", "hook", hook, "interpreter", interpreter)
+	f.unrunnable++
+	if !contains(f.interpreters, interpreter) {
+		f.interpreters = append(f.interpreters, interpreter)
+	}
+}
+
+// skillsFailed records n skills lost to one error.
+func (f *installFailures) skillsFailed(n int, format string, args ...interface{}) {
+	f.skills += n
+	f.record(format, args...)
+}
+
+func (f *installFailures) record(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logError("%s", msg)
+	if f.first == "" {
+		f.first = msg
+	}
 }
 
-// Sync fetches and merges MCP configuration.
+// SyncChanges lists what a sync changed on disk. Unchanged items are omitted.
+type SyncChanges struct {
+	ServersAdded   []string // MCP server keys added to claude.json
+	ServersUpdated []string
+	ServersRemoved []string
+	HooksInstalled []string // hook names whose script was (re)written
+	HooksRemoved   []string // script paths deleted
+	SkillsWritten  []string // skill names whose file was (re)written
+	SkillsRemoved  []string // skill file paths deleted
+}
+
+// Empty reports whether nothing changed.
+func (c SyncChanges) Empty() bool {
+	return len(c.ServersAdded)+len(c.ServersUpdated)+len(c.ServersRemoved)+
+		len(c.HooksInstalled)+len(c.HooksRemoved)+len(c.SkillsWritten)+len(c.SkillsRemoved) == 0
+}
+
+// CachedResult returns the user info of the last synced config, for
+// telemetry when this run's sync isn't available. Nothing is fetched or
+// written; FromCache is false when there is no cache.
+func CachedResult() SyncResult {
+	return cachedResult(env.OS{})
+}
+
+func cachedResult(e env.Env) SyncResult {
+	var result SyncResult
+	if cached, _ := loadCachedConfig(e); cached != nil {
+		result.UserID = cached.Config.UserID
+		result.UserEmail = cached.Config.UserEmail
+		result.Team = cached.Config.Team
+		result.FromCache = true
+	}
+	return result
+}
+
+// SyncOptions customizes a sync run. The zero value syncs the real machine.
+type SyncOptions struct {
+	// Env supplies the home directory, environment variables, and clock.
+	// nil means the real process environment.
+	Env env.Env
+	// Version is the shim version reported in heartbeats; "" means "dev".
+	Version string
+	// ForceRefresh ignores the cached config version so the dashboard
+	// returns the full payload instead of 304.
+	ForceRefresh bool
+	// SkipStatusReport sends nothing back: no install or hook status, no
+	// heartbeat, and queued reports stay queued.
+	SkipStatusReport bool
+	// OfflineOnly applies the cached config without any request, as
+	// ZEUDE_OFFLINE does.
+	OfflineOnly bool
+	// Progress, if set, gets a line as each phase starts.
+	Progress io.Writer
+}
+
+// progress writes a line to Progress.
+func (o SyncOptions) progress(format string, args ...interface{}) {
+	if o.Progress != nil {
+		fmt.Fprintf(o.Progress, format+"\n", args...)
+	}
+}
+
+// Sync fetches and merges MCP configuration, bounded by ctx: cancelling it
+// aborts in-flight requests, lock waits, and status reporting.
 // Returns SyncResult with user info for OTEL injection.
 // Uses Merkle-tree style hash comparison for efficient sync.
 // [FIX #1] Always call merge even with empty server list.
 // [FIX #8] Use errors.As for error type checking.
-// [FIX #14] Use WaitGroup to ensure goroutine completes before exit.
-func Sync() SyncResult {
-	agentKey := getAgentKey()
+// [FIX #14] Status reporting runs inline under a bounded context (no leaked goroutine).
+func Sync(ctx context.Context, opts SyncOptions) SyncResult {
+	plan := &SyncPlan{}
+	result := runSync(ctx, opts, plan)
+	result.Plan = plan
+	return result
+}
+
+// runSync does the work of Sync and Plan, recording each change in plan.
+// A dry-run plan skips every write, including the config cache and status
+// reports.
+func runSync(ctx context.Context, opts SyncOptions, plan *SyncPlan) SyncResult {
+	e := env.OrDefault(opts.Env)
+	if opts.OfflineOnly {
+		// Everything that checks ZEUDE_OFFLINE, status reports included
+		e = env.Override{Env: e, Vars: map[string]string{config.OfflineEnv: "1"}}
+	}
+	reports := plan.apply() && !opts.SkipStatusReport
+	// A project's own dashboard gets that project's servers and nothing
+	// else: no hooks or skills in ~/.claude, and no status reports, which
+	// describe this machine's global setup
+	scoped := dashboardProject(e)
+	if scoped != nil {
+		reports = false
+	}
+
+	if pause := pauseState(e); pause.Paused {
+		logDebug("sync paused, skipping")
+		// Keep user info for telemetry without touching anything on disk
+		result := cachedResult(e)
+		result.Paused, result.PausedUntil = true, pause.Until
+		return result
+	}
+
+	agentKey := getAgentKey(e)
 	if agentKey == "" {
 		logDebug("no agent key configured, skipping sync")
 		return SyncResult{NoAgentKey: true}
 	}
 
+	// Fold manifests written by older versions into managed.json before the
+	// merge reads them. loadManifest reads them either way, so a failure
+	// here only delays the cleanup.
+	if plan.apply() && len(legacyManifestFiles(e)) > 0 {
+		if _, err := MigrateManifest(ctx, MigrateOptions{Env: e}); err != nil {
+			logError("failed to migrate managed lists: %v", err)
+		}
+	}
+
 	// Load cached config first for ETag comparison
 	// Even expired cache can be used as fallback for offline mode
-	cachedConfig, cacheExpired := loadCachedConfig()
+	cachedConfig, cacheExpired := loadCachedConfig(e)
+	if cachedConfig != nil && cachedConfig.Dashboard != "" && cachedConfig.Dashboard != getDashboardURL(e) {
+		logDebug("cache is from %s, not %s; ignoring it", cachedConfig.Dashboard, getDashboardURL(e))
+		cachedConfig, cacheExpired = nil, false
+	}
+	offline := config.Offline(e)
 
 	fromCache := false
+	notModified := false
+	var limitedUntil time.Time // backing off after a 429
 	var config *ConfigResponse
 
 	// Get cached version for If-None-Match header (ETag)
 	cachedVersion := ""
-	if cachedConfig != nil {
+	if cachedConfig != nil && !opts.ForceRefresh {
 		cachedVersion = cachedConfig.Version
 	}
 
-	serverConfig, err := fetchConfig(agentKey, cachedVersion)
-	if err != nil {
-		// Handle 304 Not Modified - config unchanged, use cached config
-		// Still run merge/install to repair local drift (e.g., user deleted ~/.claude.json)
-		// writeFileIfChanged uses bytes.Equal, so no actual I/O if files are intact
-		if errors.Is(err, ErrNotModified) {
-			logDebug("config unchanged (304), using cached config for local sync")
-			if cachedConfig != nil {
+	var timings SyncTimings
+	fetchStart := time.Now()
+	if offline {
+		// ZEUDE_OFFLINE: no request at all; the cache, however old, is it
+		if cachedConfig == nil {
+			return SyncResult{Offline: true, Err: errors.New("offline and no cached config yet")}
+		}
+		logDebug("offline, applying cached config")
+		opts.progress("Offline, using the cached config")
+		config = &cachedConfig.Config
+		fromCache = true
+	} else if until := rateLimitedUntil(e); !until.IsZero() {
+		// The dashboard asked for no requests before then
+		if cachedConfig == nil {
+			return SyncResult{Err: &RateLimitError{Until: until}, RateLimitedUntil: until}
+		}
+		logDebug("rate limited until %v, applying cached config", until)
+		opts.progress("Rate limited until %s, using the cached config", until.Local().Format("15:04:05"))
+		config = &cachedConfig.Config
+		fromCache = true
+		limitedUntil = until
+		timings.Fetch = time.Since(fetchStart)
+	} else {
+		var serverConfig *ConfigResponse
+		var err error
+		opts.progress("Fetching config from %s", getDashboardURL(e))
+		if cachedVersion != "" && cachedConfig.Sectioned {
+			serverConfig, err = fetchChangedSections(ctx, e, agentKey, cachedConfig)
+		} else {
+			serverConfig, err = fetchConfig(ctx, e, agentKey, cachedVersion)
+		}
+		timings.Fetch = time.Since(fetchStart)
+		if err != nil {
+			// Handle 304 Not Modified - config unchanged, use cached config
+			// Still run merge/install to repair local drift (e.g., user deleted ~/.claude.json)
+			// writeFileIfChanged uses bytes.Equal, so no actual I/O if files are intact
+			if errors.Is(err, ErrNotModified) {
+				if plan.apply() {
+					clearRateLimit(e)
+				}
+				logDebug("config unchanged (304), using cached config for local sync")
+				if cachedConfig != nil {
+					config = &cachedConfig.Config
+					fromCache = true
+					notModified = true
+					// Fall through to merge/install to ensure local files are correct
+				} else {
+					// 304 but no cache - shouldn't happen, but handle gracefully
+					logDebug("304 received but no cache available")
+					return SyncResult{Err: errors.New("dashboard returned 304 but no cached config exists"), Timings: timings}
+				}
+			} else if authErr := (*AuthError)(nil); errors.As(err, &authErr) {
+				// [FIX #8] Use errors.As() for wrapped errors
+				logError("access revoked (HTTP %d), clearing cache", authErr.StatusCode)
+				if plan.apply() {
+					clearCache(e)
+				}
+				return SyncResult{Err: err, Timings: timings}
+			} else {
+				// Network error - try cached config (even if expired for offline mode)
+				logDebug("fetch failed, trying cache: %v", err)
+				if rateErr := (*RateLimitError)(nil); errors.As(err, &rateErr) {
+					limitedUntil = rateErr.Until
+				}
+				if cachedConfig == nil {
+					logDebug("no cache available, skipping sync")
+					return SyncResult{Err: err, RateLimitedUntil: limitedUntil, Timings: timings}
+				}
 				config = &cachedConfig.Config
+				if cacheExpired {
+					logDebug("using expired cached config (offline mode)")
+				} else {
+					logDebug("using cached config")
+				}
 				fromCache = true
-				// Fall through to merge/install to ensure local files are correct
-			} else {
-				// 304 but no cache - shouldn't happen, but handle gracefully
-				logDebug("304 received but no cache available")
-				return SyncResult{}
 			}
-		} else if authErr := (*AuthError)(nil); errors.As(err, &authErr) {
-			// [FIX #8] Use errors.As() for wrapped errors
-			logError("access revoked (HTTP %d), clearing cache", authErr.StatusCode)
-			clearCache()
-			return SyncResult{}
 		} else {
-			// Network error - try cached config (even if expired for offline mode)
-			logDebug("fetch failed, trying cache: %v", err)
-			if cachedConfig == nil {
-				logDebug("no cache available, skipping sync")
-				return SyncResult{}
-			}
-			config = &cachedConfig.Config
-			if cacheExpired {
-				logDebug("using expired cached config (offline mode)")
-			} else {
-				logDebug("using cached config")
+			// Config changed - use new config from server
+			config = serverConfig
+			logDebug("config updated (old: %s, new: %s)",
+				func() string {
+					if cachedConfig != nil {
+						return cachedConfig.Version
+					}
+					return "none"
+				}(),
+				serverConfig.ConfigVersion)
+
+			if plan.apply() {
+				clearRateLimit(e)
+				if err := saveCachedConfig(e, config); err != nil {
+					logError("failed to save cache: %v", err)
+				}
 			}
-			fromCache = true
 		}
-	} else {
-		// Config changed - use new config from server
-		config = serverConfig
-		logDebug("config updated (old: %s, new: %s)",
-			func() string {
-				if cachedConfig != nil {
-					return cachedConfig.Version
-				}
-				return "none"
-			}(),
-			serverConfig.ConfigVersion)
+	}
+	plan.ConfigVersion, plan.FromCache = config.ConfigVersion, fromCache
+
+	if offline {
+		timings.Fetch = time.Since(fetchStart)
+	}
 
-		if err := saveCachedConfig(config); err != nil {
-			logError("failed to save cache: %v", err)
+	// Reports queued while offline go out once the dashboard answers
+	statusStart := time.Now()
+	if reports && !offline && (!fromCache || notModified) {
+		flushStatusQueue(ctx, e, agentKey)
+	}
+	timings.Status = time.Since(statusStart)
+
+	// Tag everything written from here on with the config being applied
+	if plan.apply() {
+		audit.SetConfigVersion(config.ConfigVersion)
+	}
+
+	// From here to the manifest writes, one sync at a time: the fetch and
+	// the status reports don't need it
+	var release func()
+	if plan.apply() {
+		var err error
+		if release, err = acquireSyncLock(ctx, e); err != nil {
+			logError("failed to acquire sync lock: %v", err)
+			return SyncResult{
+				UserID:    config.UserID,
+				UserEmail: config.UserEmail,
+				Team:      config.Team,
+				FromCache: fromCache,
+				Err:       fmt.Errorf("another sync is still running: %w", err),
+				Timings:   timings,
+			}
+		}
+	}
+	unlock := func() {
+		if release != nil {
+			release()
+			release = nil
 		}
 	}
+	defer unlock()
+
+	if config.UninstallAll && scoped != nil {
+		// Only what that dashboard installed, the project's servers, goes
+		logger.Warn("project dashboard requested uninstall; removing its servers", "project", scoped.Path)
+		config.MCPServers = nil
+	} else if config.UninstallAll {
+		var removed UninstallResult
+		var err error
+		if plan.apply() {
+			logger.Warn("dashboard requested uninstall; removing synced servers, hooks and skills")
+			opts.progress("Removing synced servers, hooks and skills, as the dashboard asked")
+			removed, err = tearDown(ctx, e, agentKey, !opts.SkipStatusReport)
+		} else {
+			removed, err = removeManaged(e, true)
+		}
+		plan.uninstall(e, removed)
+		result := SyncResult{
+			Success:  err == nil,
+			TornDown: true,
+			Changes: SyncChanges{
+				ServersRemoved: removed.Servers,
+				HooksRemoved:   removed.HookFiles,
+				SkillsRemoved:  removed.SkillFiles,
+			},
+			Timings: timings,
+		}
+		if err != nil {
+			result.Err = fmt.Errorf("uninstall failed: %w", err)
+		}
+		return result
+	}
+
+	// Locally disabled servers, and project servers for other projects,
+	// aren't counted or checked
+	proj := currentMCPProject(e)
+	applicable := applicableServers(e, config.MCPServers)
+	enabledServers := loadOverrides(e).enabledServers(applicable)
 
 	// Build result with user info for OTEL injection and status display
 	result := SyncResult{
@@ -1458,10 +2332,21 @@ func Sync() SyncResult {
 		UserEmail:   config.UserEmail,
 		Team:        config.Team,
 		Success:     true,
-		ServerCount: len(config.MCPServers),
-		SkillCount:  len(config.Skills),
-		HookCount:   len(config.Hooks),
-		FromCache:   fromCache,
+		ServerCount: len(enabledServers),
+
+		DisabledServerCount: len(applicable) - len(enabledServers),
+		SkillCount:          len(config.Skills),
+		HookCount:           len(config.Hooks),
+		FromCache:           fromCache,
+		NotModified:         notModified,
+		Offline:             offline,
+		RateLimitedUntil:    limitedUntil,
+
+		SelfTelemetry: config.Policy.SelfTelemetry,
+		Provider:      config.Provider,
+		MOTD:          config.MOTD,
+
+		Timings: timings,
 	}
 
 	// [FIX #1] ALWAYS call merge, even with empty server list
@@ -1470,83 +2355,176 @@ func Sync() SyncResult {
 		config.MCPServers = map[string]MCPServer{}
 	}
 
-	if err := mergeClaudeConfig(config.MCPServers); err != nil {
-		logError("merge failed: %v", err)
-		return result // Still return user info even if merge fails
+	// Sections whose hash matches what was last applied, with the files it
+	// wrote untouched since, are skipped. zeude sync --force redoes them all.
+	var applied AppliedSections
+	if cachedConfig != nil && !opts.ForceRefresh {
+		applied = cachedConfig.Applied
+	}
+	hashes := config.Hashes
+
+	mergeStart := time.Now()
+	serversPrint := serversFingerprint(e, proj, opts.Version)
+	if applied.Servers.unchanged(hashes.MCPServers, serversPrint) {
+		logDebug("servers unchanged (%s), skipping merge", hashes.MCPServers)
+	} else {
+		opts.progress("Merging %d MCP servers", len(applicable))
+		complete, err := mergeClaudeConfig(ctx, e, plan, config.MCPServers, proj, &result.Changes)
+		if err != nil {
+			result.Timings.Merge = time.Since(mergeStart)
+			logError("merge failed: %v", err)
+			result.Err = fmt.Errorf("merge failed: %w", err)
+			return result // Still return user info even if merge fails
+		}
+		applied.Servers = AppliedSection{}
+		if complete {
+			applied.Servers = newAppliedSection(hashes.MCPServers, func() string { return serversFingerprint(e, proj, opts.Version) }, 0)
+		}
 	}
+	result.Timings.Merge = time.Since(mergeStart)
 
 	// Install hooks to ~/.claude/hooks/{event}/
 	// Always call installHooks even with empty hook list to clean up deleted hooks
 	if config.Hooks == nil {
 		config.Hooks = []Hook{}
 	}
-	dashboardURL := getDashboardURL()
-	installedHookIDs, err := installHooks(config.Hooks, agentKey, dashboardURL, config.UserEmail, config.Team)
-	if err != nil {
-		logError("hook install failed: %v", err)
-		// Non-fatal: continue with sync
+	dashboardURL := getDashboardURL(e)
+	verifier := newContentVerifier(e)
+	hookKey := agentKey
+	result.Overdue = config.Policy.Update.evaluate(e)
+	if result.Overdue.Withheld() {
+		// Past the deadline, hooks from this build stop reporting as the org
+		logger.Warn("update overdue; hooks installed without the agent key", "version", result.Overdue.Version, "since", result.Overdue.Since)
+		hookKey = ""
+	}
+	var failures installFailures
+	hooksStart := time.Now()
+	var hookStatus []HookInstallStatus
+	hooksPrint := hooksFingerprint(e, verifier, opts.Version, hookKey, dashboardURL, config.UserEmail, config.Team)
+	if scoped != nil {
+		if len(config.Hooks) > 0 || len(config.Skills) > 0 {
+			logger.Warn("not installing hooks and skills from a project dashboard", "project", scoped.Path, "hooks", len(config.Hooks), "skills", len(config.Skills))
+		}
+		result.HookCount, result.SkillCount = 0, 0
+	} else if applied.Hooks.unchanged(hashes.Hooks, hooksPrint) {
+		// Install status went to the dashboard when they were installed
+		logDebug("hooks unchanged (%s), skipping install", hashes.Hooks)
+		verifier.rejected += applied.Hooks.Rejected
+	} else {
+		opts.progress("Installing %d hooks", len(config.Hooks))
+		rejected := verifier.rejected
+		var err error
+		hookStatus, err = installHooks(e, plan, verifier, &result.Changes, &failures, config.Hooks, hookKey, dashboardURL, config.UserEmail, config.Team)
+		if err != nil {
+			// Non-fatal: continue with sync
+			failures.hooksFailed(len(config.Hooks), "hook install failed: %v", err)
+		}
+		applied.Hooks = AppliedSection{}
+		// Hooks missing an interpreter are checked again next sync, so
+		// their status is reported once it is installed
+		if failures.hooks == 0 && failures.unrunnable == 0 {
+			applied.Hooks = newAppliedSection(hashes.Hooks, func() string {
+				return hooksFingerprint(e, verifier, opts.Version, hookKey, dashboardURL, config.UserEmail, config.Team)
+			}, verifier.rejected-rejected)
+		}
 	}
+	result.Timings.Hooks = time.Since(hooksStart)
 
 	// Install skills to ~/.claude/commands/
 	// Always call installSkills even with empty list to clean up deleted skills
 	if config.Skills == nil {
 		config.Skills = []Skill{}
 	}
-	if err := installSkills(config.Skills); err != nil {
-		logError("skill install failed: %v", err)
-		// Non-fatal: continue with sync
+	skillsStart := time.Now()
+	skillsPrint := skillsFingerprint(e, verifier, opts.Version)
+	switch {
+	case scoped != nil:
+		// Not from a project dashboard, like the hooks above
+	case applied.Skills.unchanged(hashes.Skills, skillsPrint):
+		logDebug("skills unchanged (%s), skipping install", hashes.Skills)
+		verifier.rejected += applied.Skills.Rejected
+	default:
+		opts.progress("Installing %d skills", len(config.Skills))
+		rejected := verifier.rejected
+		if err := installSkills(e, plan, verifier, &result.Changes, &failures, config.Skills); err != nil {
+			// Non-fatal: continue with sync
+			failures.skillsFailed(len(config.Skills), "skill install failed: %v", err)
+		}
+		applied.Skills = AppliedSection{}
+		if failures.skills == 0 {
+			applied.Skills = newAppliedSection(hashes.Skills, func() string { return skillsFingerprint(e, verifier, opts.Version) }, verifier.rejected-rejected)
+		}
 	}
+	result.Timings.Skills = time.Since(skillsStart)
+	result.HooksFailed, result.SkillsFailed, result.FirstError = failures.hooks, failures.skills, failures.first
+	result.HooksUnrunnable, result.MissingInterpreters = failures.unrunnable, failures.interpreters
+	result.UnverifiedCount = verifier.rejected
+	if !plan.apply() {
+		return result
+	}
+	if err := saveAppliedSections(e, applied); err != nil {
+		logDebug("failed to record applied sections: %v", err)
+	}
+	unlock()
 
-	// Sync skill-rules.json for Skill Hint hook
-	if err := syncSkillRules(agentKey); err != nil {
-		logDebug("skill-rules sync failed: %v", err)
-		// Non-fatal: hook will work without rules (just no hints)
+	// Sync skill-rules.json for Skill Hint hook; offline or rate limited,
+	// the last rules stay
+	if !offline && limitedUntil.IsZero() && scoped == nil {
+		if err := syncSkillRules(ctx, e, agentKey); err != nil {
+			logDebug("skill-rules sync failed: %v", err)
+			// Non-fatal: hook will work without rules (just no hints)
+		}
 	}
 
 	logDebug("sync complete: %d servers, %d hooks, %d skills", len(config.MCPServers), len(config.Hooks), len(config.Skills))
 
-	// Report hook install status
-	if len(installedHookIDs) > 0 {
-		hookStatus := make([]HookInstallStatus, 0, len(installedHookIDs))
-		for _, hookID := range installedHookIDs {
-			hookStatus = append(hookStatus, HookInstallStatus{
-				HookID:    hookID,
-				Installed: true,
-			})
-		}
-		if err := ReportHookInstallStatus(agentKey, hookStatus); err != nil {
+	if !reports {
+		housekeeping.MaybeClean(e)
+		return result
+	}
+
+	// Report hook install status (rejected hooks included)
+	opts.progress("Reporting status")
+	statusStart = time.Now()
+	if len(hookStatus) > 0 {
+		if err := reportHookInstallStatus(ctx, e, agentKey, hookStatus); err != nil {
 			logDebug("failed to report hook install status: %v", err)
 		}
 	}
 
+	// A due heartbeat rides along with the install status report, or is
+	// sent on its own when there are no servers to report
+	heartbeat, claim := claimHeartbeat(ctx, e, opts.Version, result)
+
 	// Check and report installation status
-	// [FIX #14] Use WaitGroup to ensure this completes before process exits
-	if len(config.MCPServers) > 0 {
-		var wg sync.WaitGroup
-		wg.Add(1)
-
-		go func() {
-			defer wg.Done()
-			installStatus := CheckInstallStatus(config.MCPServers)
-			if err := ReportInstallStatus(agentKey, installStatus); err != nil {
-				logDebug("failed to report install status: %v", err)
+	// [FIX #14] Runs inline under a bounded child context, so the package
+	// checks and the request are cancelled on timeout instead of left running.
+	if len(enabledServers) > 0 || heartbeat != nil {
+		statusCtx, cancel := context.WithTimeout(ctx, StatusReportTimeout)
+		var err error
+		if len(enabledServers) > 0 {
+			installStatus := CheckInstallStatusContext(statusCtx, enabledServers)
+			if err := saveInstallStatus(e, installStatus); err != nil {
+				logDebug("failed to save install status: %v", err)
 			}
-		}()
-
-		// Wait for status reporting to complete (with timeout)
-		done := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(done)
-		}()
-
-		select {
-		case <-done:
+			err = reportInstallStatus(statusCtx, e, agentKey, installStatus, heartbeat)
+		} else {
+			err = reportHeartbeat(statusCtx, e, agentKey, heartbeat)
+		}
+		if err != nil {
+			logDebug("failed to report install status: %v", err)
+			if claim != nil {
+				claim.release()
+			}
+		} else {
 			logDebug("install status reporting completed")
-		case <-time.After(2 * time.Second):
-			logDebug("install status reporting timed out - proceeding")
 		}
+		cancel()
 	}
+	result.Timings.Status += time.Since(statusStart)
+
+	// Opportunistic cleanup of the data dir, at most once a day
+	housekeeping.MaybeClean(e)
 
 	return result
 }
@@ -0,0 +1,468 @@
+package mcpconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeude/zeude/internal/provider"
+)
+
+func TestSameJSONDocument(t *testing.T) {
+	canonical := "{\n  \"a\": 1,\n  \"b\": {\n    \"c\": [\n      true\n    ]\n  }\n}"
+	tests := []struct {
+		name     string
+		existing string
+		want     bool
+	}{
+		{"identical", canonical, true},
+		{"compact", `{"a":1,"b":{"c":[true]}}`, true},
+		{"keys reordered", `{"b": {"c": [true]}, "a": 1}`, true},
+		{"trailing newline", canonical + "\n", true},
+		{"value changed", `{"a": 2, "b": {"c": [true]}}`, false},
+		{"number spelled differently", `{"a": 1.0, "b": {"c": [true]}}`, false},
+		{"member added", `{"a": 1, "b": {"c": [true]}, "d": null}`, false},
+		{"invalid", `{"a": 1,`, false},
+		{"empty", ``, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameJSONDocument([]byte(tt.existing), []byte(canonical)); got != tt.want {
+				t.Errorf("sameJSONDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteClaudeSettingsSkipsSameDocument(t *testing.T) {
+	e := testEnv(t)
+	settings := map[string]interface{}{"model": "opus", "env": map[string]interface{}{"A": "1"}}
+	if err := writeClaudeSettings(e, settings); err != nil {
+		t.Fatalf("writeClaudeSettings: %v", err)
+	}
+	path, _ := getClaudeSettingsPath(e)
+	// Same document, different layout: not rewritten
+	if err := os.WriteFile(path, []byte(`{"env":{"A":"1"},"model":"opus"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(path)
+	if err := writeClaudeSettings(e, settings); err != nil {
+		t.Fatalf("writeClaudeSettings: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if !os.SameFile(before, after) {
+		t.Error("settings.json was replaced although the document was the same")
+	}
+
+	settings["model"] = "sonnet"
+	if err := writeClaudeSettings(e, settings); err != nil {
+		t.Fatalf("writeClaudeSettings: %v", err)
+	}
+	changed, _ := os.Stat(path)
+	if os.SameFile(before, changed) {
+		t.Error("settings.json was not rewritten after a change")
+	}
+}
+
+const hookConfig = `{
+  "configVersion": "v1",
+  "hooks": [
+    {"id": "h1", "name": "log-prompt", "event": "UserPromptSubmit", "script": "echo hi"},
+    {"id": "h2", "name": "guard", "event": "PreToolUse", "matcher": "Bash", "script": "exit 0"}
+  ]
+}`
+
+// TestSyncTwiceLeavesSettingsAlone runs two syncs with the same input and
+// checks the second doesn't write settings.json.
+func TestSyncTwiceLeavesSettingsAlone(t *testing.T) {
+	d := newFakeDashboard(t, hookConfig)
+	e := syncEnv(t, d)
+	path, _ := getClaudeSettingsPath(e)
+
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success {
+		t.Fatalf("first sync failed: %+v", r)
+	}
+	first, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("first sync didn't write settings.json: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "UserPromptSubmit") {
+		t.Fatalf("first sync didn't register the hooks:\n%s", data)
+	}
+
+	for _, force := range []bool{false, true} {
+		if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true, ForceRefresh: force}); !r.Success {
+			t.Fatalf("sync (force %v) failed: %+v", force, r)
+		}
+		again, _ := os.Stat(path)
+		if !os.SameFile(first, again) {
+			t.Errorf("sync (force %v) rewrote settings.json with identical input", force)
+		}
+	}
+}
+
+// TestConcurrentSyncsKeepSettingsConsistent runs several syncs at once
+// against one home: settings.json must end up valid with each hook
+// registered once.
+func TestConcurrentSyncsKeepSettingsConsistent(t *testing.T) {
+	d := newFakeDashboard(t, hookConfig)
+	e := syncEnv(t, d)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true, ForceRefresh: true}); !r.Success {
+				t.Errorf("sync failed: %+v", r)
+			}
+		}()
+	}
+	wg.Wait()
+
+	settings, err := readClaudeSettings(e)
+	if err != nil {
+		t.Fatalf("settings.json unreadable after concurrent syncs: %v", err)
+	}
+	hooksDir, _ := getClaudeHooksDir(e)
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	for _, h := range []Hook{{Name: "log-prompt", Event: "UserPromptSubmit"}, {Name: "guard", Event: "PreToolUse"}} {
+		path := hookFilePath(hooksDir, h)
+		entries, _ := hooks[h.Event].([]interface{})
+		count := 0
+		for _, entry := range entries {
+			data, _ := json.Marshal(entry)
+			count += strings.Count(string(data), filepath.Base(path))
+		}
+		if count != 1 {
+			t.Errorf("%s is registered %d times, want once", h.Name, count)
+		}
+	}
+}
+
+func TestWriteFileAtomicSyncsDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    atomicWriteOptions
+		syncErr error
+		synced  bool
+	}{
+		{"default", atomicWriteOptions{}, nil, true},
+		{"opted out", atomicWriteOptions{NoDirSync: true}, nil, false},
+		{"sync fails", atomicWriteOptions{}, errors.New("EIO"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dirs []string
+			old := syncDir
+			syncDir = func(dir string) error {
+				dirs = append(dirs, dir)
+				return tt.syncErr
+			}
+			t.Cleanup(func() { syncDir = old })
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "settings.json")
+			// The file is in place by the time the directory is synced, so
+			// a sync failure doesn't fail the write
+			if err := writeFileAtomicWithOptions(path, []byte("{}"), 0600, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != "{}" {
+				t.Errorf("content = %q", data)
+			}
+			if tt.synced && (len(dirs) != 1 || dirs[0] != dir) {
+				t.Errorf("synced %q, want [%s]", dirs, dir)
+			}
+			if !tt.synced && len(dirs) > 0 {
+				t.Errorf("synced %q despite NoDirSync", dirs)
+			}
+		})
+	}
+}
+
+func TestSyncSurfacesProvider(t *testing.T) {
+	d := newFakeDashboard(t, `{"configVersion": "v1", "provider": {"provider": "bedrock", "awsRegion": "us-west-2", "model": "claude-opus"}}`)
+	e := syncEnv(t, d)
+	want := provider.Settings{Provider: provider.Bedrock, AWSRegion: "us-west-2", Model: "claude-opus"}
+
+	// Fetched, then unchanged (304) and served from the cache
+	for i := 0; i < 2; i++ {
+		r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true})
+		if !r.Success || r.Provider != want {
+			t.Errorf("sync %d: provider = %+v, want %+v", i+1, r.Provider, want)
+		}
+	}
+}
+
+// mixedServers is a config with stdio and remote servers, a type this
+// version doesn't know, and fields a later API might add.
+const mixedServers = `{
+  "configVersion": "v1",
+  "rolloutWave": 3,
+  "mcpServers": {
+    "local": {"command": "npx", "args": ["-y", "@acme/mcp"], "env": {"TOKEN": "t"}, "timeoutMs": 5000},
+    "typed": {"type": "stdio", "command": "uvx", "args": ["tool"]},
+    "events": {"type": "sse", "url": "https://mcp.example.com/sse", "headers": {"Authorization": "Bearer x"}},
+    "api": {"type": "http", "url": "https://mcp.example.com/mcp", "oauth": {"clientId": "abc"}},
+    "future": {"type": "websocket", "url": "wss://mcp.example.com/ws"}
+  }
+}`
+
+func TestMCPServerRoundTrip(t *testing.T) {
+	var cfg ConfigResponse
+	if err := json.Unmarshal([]byte(mixedServers), &cfg); err != nil {
+		t.Fatalf("unknown fields broke unmarshalling: %v", err)
+	}
+	if len(cfg.MCPServers) != 5 {
+		t.Fatalf("got %d servers, want 5", len(cfg.MCPServers))
+	}
+	data, err := json.Marshal(cfg.MCPServers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again map[string]MCPServer
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range cfg.MCPServers {
+		got := again[name]
+		if got.Type != want.Type || got.URL != want.URL || got.Command != want.Command ||
+			strings.Join(got.Args, " ") != strings.Join(want.Args, " ") ||
+			len(got.Env) != len(want.Env) || len(got.Headers) != len(want.Headers) {
+			t.Errorf("%s: round trip gave %+v, want %+v", name, got, want)
+		}
+	}
+
+	remote := map[string]bool{"local": false, "typed": false, "events": true, "api": true, "future": true}
+	for name, want := range remote {
+		if got := cfg.MCPServers[name].Remote(); got != want {
+			t.Errorf("%s: Remote() = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestSyncMixedServers checks each kind of server lands in claude.json in
+// the shape claude expects: command and args for stdio, type and url for
+// remote servers.
+func TestSyncMixedServers(t *testing.T) {
+	d := newFakeDashboard(t, mixedServers)
+	e := syncEnv(t, d)
+	if r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true}); !r.Success {
+		t.Fatalf("sync failed: %+v", r)
+	}
+
+	path, _ := getClaudeConfigPath(e)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("claude.json is not valid JSON: %v\n%s", err, data)
+	}
+
+	tests := []struct {
+		name string
+		want []string // the entry's keys, sorted
+	}{
+		{"local", []string{"args", "command", "env"}},
+		{"typed", []string{"args", "command"}},
+		{"events", []string{"headers", "type", "url"}},
+		{"api", []string{"type", "url"}},
+		{"future", []string{"type", "url"}},
+	}
+	for _, tt := range tests {
+		entry, ok := doc.MCPServers[tt.name]
+		if !ok {
+			t.Errorf("%s missing from claude.json", tt.name)
+			continue
+		}
+		keys := make([]string, 0, len(entry))
+		for k := range entry {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s has keys %v, want %v", tt.name, keys, tt.want)
+		}
+	}
+	if got := doc.MCPServers["events"]["url"]; got != "https://mcp.example.com/sse" {
+		t.Errorf("events url = %v", got)
+	}
+
+	// The cached copy keeps the remote fields, so an offline sync writes
+	// the same entries
+	cached, _ := loadCachedConfig(e)
+	if cached == nil || cached.Config.MCPServers["events"].Headers["Authorization"] != "Bearer x" {
+		t.Errorf("cached config lost the remote fields: %+v", cached)
+	}
+}
+
+func TestEscapeScriptValues(t *testing.T) {
+	tests := []struct {
+		in, ruby, powershell string
+	}{
+		{"plain", "plain", "plain"},
+		{"it's", `it\'s`, "it''s"},
+		{`C:\hooks\`, `C:\\hooks\\`, `C:\hooks\`},
+		{`\'`, `\\\'`, `\''`},
+		{"two\nlines\r\n", "two\nlines\r\n", "two\nlines\r\n"},
+		{"it\u2019s", "it\u2019s", "it\u2019\u2019s"},
+	}
+	for _, tt := range tests {
+		if got := escapeRubyValue(tt.in); got != tt.ruby {
+			t.Errorf("escapeRubyValue(%q) = %q, want %q", tt.in, got, tt.ruby)
+		}
+		if got := escapePowerShellValue(tt.in); got != tt.powershell {
+			t.Errorf("escapePowerShellValue(%q) = %q, want %q", tt.in, got, tt.powershell)
+		}
+	}
+}
+
+func TestHookScriptContent(t *testing.T) {
+	const team = "it's C:\\x\nnext"
+	tests := []struct {
+		scriptType string
+		ext        string
+		shebang    string
+		team       string   // how the ZEUDE_TEAM assignment is written
+		guard      []string // the agent key check, in order
+	}{
+		{"", ".sh", "#!/bin/bash", "export ZEUDE_TEAM=\"it's C:\\\\x\nnext\"",
+			[]string{`if [ -z "$ZEUDE_AGENT_KEY" ]; then`, "exit 2", "fi"}},
+		{"python", ".py", "#!/usr/bin/env python3", `os.environ['ZEUDE_TEAM'] = 'it\'s C:\\x\nnext'`,
+			[]string{"if not os.environ.get('ZEUDE_AGENT_KEY'):", "sys.exit(2)"}},
+		{"node", ".js", "#!/usr/bin/env node", `process.env.ZEUDE_TEAM = 'it\'s C:\\x\nnext';`,
+			[]string{"if (!process.env.ZEUDE_AGENT_KEY) {", "process.exit(2);"}},
+		{"deno", ".ts", "#!/usr/bin/env -S deno run -A", `Deno.env.set('ZEUDE_TEAM', 'it\'s C:\\x\nnext');`,
+			[]string{"if (!Deno.env.get('ZEUDE_AGENT_KEY')) {", "Deno.exit(2);"}},
+		{"ruby", ".rb", "#!/usr/bin/env ruby", "ENV['ZEUDE_TEAM'] = 'it\\'s C:\\\\x\nnext'",
+			[]string{"if ENV['ZEUDE_AGENT_KEY'].to_s.empty?", "exit 2", "end"}},
+		{"powershell", ".ps1", "#!/usr/bin/env pwsh", "$env:ZEUDE_TEAM = 'it''s C:\\x\nnext'",
+			[]string{"if (-not $env:ZEUDE_AGENT_KEY) {", "exit 2"}},
+	}
+	for _, tt := range tests {
+		name := tt.scriptType
+		if name == "" {
+			name = "default"
+		}
+		t.Run(name, func(t *testing.T) {
+			hook := Hook{Name: "check", Event: "PreToolUse", ScriptType: tt.scriptType, Script: "#!/original/shebang\nHOOK BODY\n"}
+			script := string(hookScriptContent(hook, "", "zd_test", "https://dashboard.example.com", "dev@example.com", team))
+
+			if first, _, _ := strings.Cut(script, "\n"); first != tt.shebang {
+				t.Errorf("shebang = %q, want %q", first, tt.shebang)
+			}
+			if strings.Contains(script, "/original/shebang") {
+				t.Error("the hook's own shebang was kept")
+			}
+			if !strings.Contains(script, tt.team) {
+				t.Errorf("ZEUDE_TEAM not written as %q:\n%s", tt.team, script)
+			}
+
+			// The guard comes after the key is set and before the hook runs
+			pos := strings.Index(script, "ZEUDE_AGENT_KEY")
+			for _, part := range append(tt.guard, "HOOK BODY") {
+				i := strings.Index(script[pos+1:], part)
+				if i < 0 {
+					t.Fatalf("%q missing after the agent key is set:\n%s", part, script)
+				}
+				pos += 1 + i
+			}
+
+			if ext, ok := hookScriptExts[tt.scriptType]; tt.scriptType != "" && (!ok || ext != tt.ext) {
+				t.Errorf("hookScriptExts[%q] = %q, want %q", tt.scriptType, ext, tt.ext)
+			}
+			if got := filepath.Ext(hookFilePath("/hooks", hook)); got != tt.ext {
+				t.Errorf("hookFilePath extension = %q, want %q", got, tt.ext)
+			}
+			if !supportedScriptType(tt.scriptType) {
+				t.Errorf("supportedScriptType(%q) = false", tt.scriptType)
+			}
+		})
+	}
+
+	for _, unknown := range []string{"lua", "sh", "Python", "javascript"} {
+		if supportedScriptType(unknown) {
+			t.Errorf("supportedScriptType(%q) = true", unknown)
+		}
+	}
+}
+
+// TestSyncReportsUnsupportedScriptType checks a hook of an unknown script
+// type is counted as an install failure, not written as a bash script.
+func TestSyncReportsUnsupportedScriptType(t *testing.T) {
+	config, _ := json.Marshal(ConfigResponse{
+		ConfigVersion: "v1",
+		Hooks: []Hook{
+			{ID: "ok", Name: "fine", Event: "Stop", ScriptType: "bash", Script: "echo fine"},
+			{ID: "bad", Name: "moon", Event: "Stop", ScriptType: "lua", Script: "print('hi')"},
+		},
+	})
+	d := newFakeDashboard(t, string(config))
+	e := syncEnv(t, d)
+
+	r := Sync(context.Background(), SyncOptions{Env: e, SkipStatusReport: true})
+	if !r.Success || r.HooksFailed != 1 {
+		t.Fatalf("Success = %v, HooksFailed = %d; want a sync with one failed hook: %+v", r.Success, r.HooksFailed, r)
+	}
+	if !strings.Contains(r.FirstError, `unsupported script type "lua"`) {
+		t.Errorf("FirstError = %q, want the unsupported type", r.FirstError)
+	}
+
+	hooksDir, _ := getClaudeHooksDir(e)
+	if _, err := os.Stat(hookFilePath(hooksDir, Hook{Name: "fine", Event: "Stop", ScriptType: "bash"})); err != nil {
+		t.Errorf("supported hook not installed: %v", err)
+	}
+	if _, err := os.Stat(hookFilePath(hooksDir, Hook{Name: "moon", Event: "Stop", ScriptType: "lua"})); !os.IsNotExist(err) {
+		t.Errorf("unsupported hook written anyway: %v", err)
+	}
+	settingsPath, _ := getClaudeSettingsPath(e)
+	if settings, _ := os.ReadFile(settingsPath); strings.Contains(string(settings), "moon") {
+		t.Errorf("settings.json registers the unsupported hook:\n%s", settings)
+	}
+}
+
+// TestHookGuardStopsWithoutKey runs generated hooks with no agent key: each
+// exits 2 before the hook's own script runs.
+func TestHookGuardStopsWithoutKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks here are run with Unix interpreters")
+	}
+	for _, lang := range hookLogLangs {
+		t.Run(lang.name, func(t *testing.T) {
+			interpreter, err := exec.LookPath(lang.interpreter)
+			if err != nil {
+				t.Skipf("%s not installed", lang.interpreter)
+			}
+			dir := t.TempDir()
+			if lang.esm {
+				if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"type": "module"}`), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			hook := Hook{Name: "guarded", Event: "Stop", ScriptType: lang.scriptType, Script: fmt.Sprintf(lang.body, 0)}
+			path := filepath.Join(dir, "guarded"+hookScriptExts[lang.scriptType])
+			if err := os.WriteFile(path, hookScriptContent(hook, "", "", "", "", ""), 0700); err != nil {
+				t.Fatal(err)
+			}
+
+			rc, stdout, stderr := runLoggedHook(t, interpreter, path)
+			if rc != 2 || stdout != "" || !strings.Contains(stderr, "ZEUDE_AGENT_KEY is not configured") {
+				t.Errorf("exit %d, stdout %q, stderr %q; want a blocking exit before the hook runs", rc, stdout, stderr)
+			}
+		})
+	}
+}
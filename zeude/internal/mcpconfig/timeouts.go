@@ -0,0 +1,74 @@
+package mcpconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+)
+
+const (
+	// SyncTimeoutKey sets how long each dashboard request may take, as a
+	// Go duration in ~/.zeude/config (sync_timeout=20s). Slow corporate
+	// proxies are the usual reason to raise it.
+	SyncTimeoutKey = "sync_timeout"
+	// SyncTimeoutEnv overrides SyncTimeoutKey.
+	SyncTimeoutEnv = "ZEUDE_SYNC_TIMEOUT"
+	// CacheTTLKey sets how long a fetched config counts as fresh
+	// (cache_ttl=1h).
+	CacheTTLKey = "cache_ttl"
+	// CacheTTLEnv overrides CacheTTLKey.
+	CacheTTLEnv = "ZEUDE_CACHE_TTL"
+)
+
+// DurationSetting is the effective value of a duration setting.
+type DurationSetting struct {
+	Value  time.Duration
+	Source string // the env var or config key it came from, or "default"
+	// Invalid is the value that was ignored in favour of the default, if any.
+	Invalid string
+}
+
+// EffectiveSyncTimeout returns the dashboard request timeout in effect.
+func EffectiveSyncTimeout() DurationSetting {
+	return syncTimeout(env.OS{})
+}
+
+func syncTimeout(e env.Env) DurationSetting {
+	return durationSetting(e, SyncTimeoutEnv, SyncTimeoutKey, ConfigFetchTimeout)
+}
+
+// EffectiveCacheTTL returns the config cache lifetime in effect.
+func EffectiveCacheTTL() DurationSetting {
+	return cacheTTL(env.OS{})
+}
+
+func cacheTTL(e env.Env) DurationSetting {
+	return durationSetting(e, CacheTTLEnv, CacheTTLKey, CacheTTL)
+}
+
+// warnedSettings holds the invalid values already warned about, so a sync
+// making several requests warns once.
+var warnedSettings sync.Map
+
+// durationSetting reads a positive duration from envName, else from key in
+// the config, else returns def. An invalid value warns and falls back to
+// def rather than to the next source, so the typo is noticed.
+func durationSetting(e env.Env, envName, key string, def time.Duration) DurationSetting {
+	source, v := envName, e.Getenv(envName)
+	if v == "" {
+		source, v = key, config.Get(key)
+	}
+	if v == "" {
+		return DurationSetting{Value: def, Source: "default"}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		if _, warned := warnedSettings.LoadOrStore(source+"="+v, true); !warned {
+			logger.Warn("invalid duration, using default", "setting", source, "value", v, "default", def)
+		}
+		return DurationSetting{Value: def, Source: "default", Invalid: source + "=" + v}
+	}
+	return DurationSetting{Value: d, Source: source}
+}
@@ -0,0 +1,230 @@
+package mcpconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// UninstallOptions customizes an uninstall. The zero value uninstalls from
+// the real machine.
+type UninstallOptions struct {
+	// Env supplies the home directory and environment. nil means the real
+	// process environment.
+	Env env.Env
+	// DryRun computes the result without changing anything.
+	DryRun bool
+}
+
+// UninstallResult lists what Zeude removed from Claude's files, or would
+// remove on a dry run.
+type UninstallResult struct {
+	Servers      []string // MCP server keys removed from claude.json
+	ProjectFiles []string // project .mcp.json files servers were removed from
+	HookEntries  int      // hook registrations removed from settings.json
+	HookFiles    []string // hook scripts deleted
+	SkillFiles   []string // skill files deleted
+}
+
+// Uninstall removes everything Zeude synced into Claude, as recorded in the
+// manifest (managed.json): those MCP servers
+// from ~/.claude.json and project .mcp.json files, those hook registrations
+// from settings.json, and the hook and skill files. Anything the user added is left alone. It runs under
+// the same lock as a sync, and clears the managed lists once done so a later
+// sync starts from scratch.
+//
+// Files in the Zeude data directory itself are not touched; the caller
+// decides what to keep there.
+func Uninstall(ctx context.Context, opts UninstallOptions) (UninstallResult, error) {
+	e := env.OrDefault(opts.Env)
+
+	var result UninstallResult
+	err := withFileLock(ctx, e, func() (err error) {
+		result, err = removeManaged(e, opts.DryRun)
+		return err
+	})
+	return result, err
+}
+
+// removeManaged does the work of Uninstall. The caller holds the lock.
+func removeManaged(e env.Env, dryRun bool) (UninstallResult, error) {
+	var result UninstallResult
+
+	// 1. MCP servers
+	managedKeys := loadManagedKeys(e)
+	doc, err := readClaudeConfig(e)
+	if err != nil {
+		return result, err
+	}
+	kept := make([]rawMember, 0, len(doc.mcpServers))
+	for _, m := range doc.mcpServers {
+		if contains(managedKeys, m.Key) {
+			if !contains(result.Servers, m.Key) {
+				result.Servers = append(result.Servers, m.Key)
+			}
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if !dryRun && len(result.Servers) > 0 {
+		if err := writeClaudeConfig(e, doc, kept); err != nil {
+			return result, fmt.Errorf("failed to update claude.json: %w", err)
+		}
+	}
+
+	result.ProjectFiles = removeProjectServers(e, dryRun)
+
+	// 2. Hook registrations, then the scripts they point at
+	managedHooks := loadManagedHooks(e)
+	settings, err := readClaudeSettings(e)
+	if err != nil {
+		return result, err
+	}
+	result.HookEntries = unregisterHooks(settings, managedHooks)
+	if result.HookEntries > 0 && !dryRun {
+		if err := writeClaudeSettings(e, settings); err != nil {
+			return result, fmt.Errorf("failed to update settings.json: %w", err)
+		}
+	}
+	result.HookFiles = removeManagedFiles(managedHooks, dryRun)
+	if !dryRun {
+		// Per-event hook directories go too once empty; Remove fails otherwise
+		for _, path := range result.HookFiles {
+			os.Remove(filepath.Dir(path))
+		}
+	}
+
+	// 3. Skills
+	result.SkillFiles = removeManagedFiles(loadManagedSkills(e), dryRun)
+
+	if dryRun {
+		return result, nil
+	}
+
+	// Forget what was managed: nothing is left to reconcile
+	removeManifest(e)
+	return result, nil
+}
+
+// Teardown is reported to the dashboard after a config with uninstallAll
+// removed what was synced to this machine.
+type Teardown struct {
+	Servers int       `json:"servers"` // removed from claude.json
+	Hooks   int       `json:"hooks"`
+	Skills  int       `json:"skills"`
+	Error   string    `json:"error,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// TeardownReport is the payload sent to the dashboard for a teardown.
+type TeardownReport struct {
+	Teardown *Teardown `json:"teardown"`
+}
+
+// tearDown removes everything synced here, as Uninstall does, and clears
+// the cache, then reports what it removed unless report is false. Once
+// nothing is left, repeated calls remove nothing and report zeros.
+func tearDown(ctx context.Context, e env.Env, agentKey string, report bool) (UninstallResult, error) {
+	var result UninstallResult
+	err := withFileLock(ctx, e, func() (err error) {
+		result, err = removeManaged(e, false)
+		if err != nil {
+			return err
+		}
+		clearCache(e)
+		return nil
+	})
+
+	if !report {
+		return result, err
+	}
+	teardown := Teardown{
+		Servers: len(result.Servers),
+		Hooks:   len(result.HookFiles),
+		Skills:  len(result.SkillFiles),
+		At:      e.Now().UTC(),
+	}
+	if err != nil {
+		teardown.Error = err.Error()
+	}
+	if err := reportStatusToAPI(ctx, e, agentKey, TeardownReport{Teardown: &teardown}); err != nil {
+		logDebug("failed to report teardown: %v", err)
+	}
+	return result, err
+}
+
+// unregisterHooks removes settings.json hook entries whose command is one
+// of the managed scripts, and returns how many it removed. An event left
+// without hooks is dropped entirely.
+func unregisterHooks(settings map[string]interface{}, managed []string) int {
+	hooksSection, ok := settings["hooks"].(map[string]interface{})
+	if !ok || len(managed) == 0 {
+		return 0
+	}
+
+	removed := 0
+	for event, value := range hooksSection {
+		existing, ok := value.([]interface{})
+		if !ok {
+			continue
+		}
+		eventHooks := make([]interface{}, 0, len(existing))
+		for _, h := range existing {
+			if contains(managed, hookCommand(h)) {
+				removed++
+				continue
+			}
+			eventHooks = append(eventHooks, h)
+		}
+		if len(eventHooks) == len(existing) {
+			continue
+		}
+		if len(eventHooks) == 0 {
+			delete(hooksSection, event)
+		} else {
+			hooksSection[event] = eventHooks
+		}
+	}
+	return removed
+}
+
+// hookCommand returns the command of a settings.json hook entry in the
+// shape Zeude registers, or "" for anything else.
+func hookCommand(entry interface{}) string {
+	hookMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	innerHooks, ok := hookMap["hooks"].([]interface{})
+	if !ok || len(innerHooks) != 1 {
+		return ""
+	}
+	first, ok := innerHooks[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	cmd, _ := first["command"].(string)
+	return cmd
+}
+
+// removeManagedFiles deletes the files that still exist and returns them.
+func removeManagedFiles(files []string, dryRun bool) []string {
+	var removed []string
+	for _, path := range files {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if !dryRun {
+			if err := removeFile(path); err != nil {
+				logError("failed to remove %s: %v", path, err)
+				continue
+			}
+		}
+		removed = append(removed, path)
+	}
+	return removed
+}
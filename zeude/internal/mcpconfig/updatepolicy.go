@@ -0,0 +1,63 @@
+package mcpconfig
+
+import (
+	"time"
+
+	"github.com/zeude/zeude/internal/autoupdate"
+	"github.com/zeude/zeude/internal/env"
+)
+
+// defaultUpdateDeadline is how long an enforced policy waits past the
+// warning before withholding the agent key, when it doesn't say.
+const defaultUpdateDeadline = 7 * 24 * time.Hour
+
+// UpdatePolicy is the dashboard's grace period for clients whose update
+// keeps failing to install. Hours are counted from the last time the
+// client was up to date.
+type UpdatePolicy struct {
+	WarnAfterHours int  `json:"warnAfterHours,omitempty"` // default: autoupdate.ForceUpdateInterval
+	DeadlineHours  int  `json:"deadlineHours,omitempty"`  // default: 7 days
+	Enforce        bool `json:"enforce,omitempty"`        // withhold the agent key from hooks past the deadline
+}
+
+// UpdateOverdue describes a client that has lagged behind a release for
+// longer than the policy allows.
+type UpdateOverdue struct {
+	Version  string    // the release that failed to install
+	Since    time.Time // when the client was last up to date
+	Deadline time.Time // when hooks lose the agent key; zero if not enforced
+	Expired  bool      // the deadline has passed
+}
+
+// Withheld reports whether hooks were installed without the agent key. It
+// is safe to call on nil.
+func (o *UpdateOverdue) Withheld() bool {
+	return o != nil && o.Expired
+}
+
+// evaluate returns how overdue this client is, or nil if it is up to date
+// or still within the warning period.
+func (p UpdatePolicy) evaluate(e env.Env) *UpdateOverdue {
+	pending := autoupdate.PendingUpdate(e)
+	if pending == nil {
+		return nil
+	}
+	warnAfter := autoupdate.ForceUpdateInterval
+	if p.WarnAfterHours > 0 {
+		warnAfter = time.Duration(p.WarnAfterHours) * time.Hour
+	}
+	now := e.Now()
+	if now.Sub(pending.LastSuccess) <= warnAfter {
+		return nil
+	}
+	overdue := &UpdateOverdue{Version: pending.Version, Since: pending.LastSuccess}
+	if p.Enforce {
+		deadline := defaultUpdateDeadline
+		if p.DeadlineHours > 0 {
+			deadline = time.Duration(p.DeadlineHours) * time.Hour
+		}
+		overdue.Deadline = pending.LastSuccess.Add(deadline)
+		overdue.Expired = !now.Before(overdue.Deadline)
+	}
+	return overdue
+}
@@ -0,0 +1,63 @@
+package mcpconfig
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/signing"
+)
+
+// contentVerifier applies the signing policy to hooks and skills before
+// they are written to disk.
+//
+// Unsigned content is accepted unless require_signed_content=true. A
+// signature that is present but doesn't verify against a pinned key is
+// always rejected: that is tampered content, not a compatibility gap.
+type contentVerifier struct {
+	keys     []ed25519.PublicKey
+	require  bool
+	rejected int // items skipped this sync
+}
+
+func newContentVerifier(e env.Env) *contentVerifier {
+	keys, err := signing.LoadTrusted(e)
+	if err != nil {
+		logError("failed to load trusted signing keys: %v", err)
+	}
+	return &contentVerifier{
+		keys:    keys,
+		require: config.IsTruthy(config.Get(signing.RequireSignedKey)),
+	}
+}
+
+// state identifies the keys and policy verify applies, so content
+// accepted under one isn't assumed accepted under another.
+func (v *contentVerifier) state() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "require=%t\n", v.require)
+	for _, key := range v.keys {
+		h.Write(key)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verify returns nil when content may be installed.
+func (v *contentVerifier) verify(kind, content, signature string) error {
+	err := signing.Verify(v.keys, kind, content, signature)
+	switch {
+	case err == nil:
+		return nil
+	case v.require:
+		return err
+	case errors.Is(err, signing.ErrUnsigned), errors.Is(err, signing.ErrNoTrustedKeys):
+		// Unsigned mode: nothing to check against
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,142 @@
+package paths
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Migration describes what MigrateToXDG did. Either way ~/.zeude ends up a
+// symlink to the XDG data directory.
+type Migration struct {
+	From, To string
+	Moved    bool // ~/.zeude was renamed
+	Copied   bool // rename wasn't possible, so contents were copied
+}
+
+// Filesystem operations MigrateToXDG uses; tests replace them.
+var (
+	rename  = os.Rename
+	symlink = os.Symlink
+)
+
+// MigrateToXDG moves an existing ~/.zeude into the XDG data directory once
+// the user has opted in. The old location is replaced by a symlink so the
+// shim directory already on PATH, and the use_xdg setting in ~/.zeude/config,
+// keep pointing at the live copy; when a rename isn't possible (e.g. across
+// filesystems) the contents are copied first. Without symlink support
+// nothing is migrated and ~/.zeude stays in use, since two live data
+// directories would disagree about config and the shim directory.
+// It returns nil when there is nothing to do.
+func MigrateToXDG(e env.Env) (*Migration, error) {
+	if e.Getenv(HomeEnv) != "" || !XDGEnabled(e) {
+		return nil, nil
+	}
+
+	legacy, err := LegacyDir(e)
+	if err != nil {
+		return nil, err
+	}
+	xdg, err := XDGDir(e)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(legacy)
+	if err != nil || !info.IsDir() {
+		// Missing, or already a symlink from a previous migration
+		return nil, nil
+	}
+	if _, err := os.Lstat(xdg); err == nil {
+		// Never merge into an existing directory
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(xdg), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(xdg), err)
+	}
+
+	m := &Migration{From: legacy, To: xdg}
+	if err := rename(legacy, xdg); err == nil {
+		if err := symlink(xdg, legacy); err != nil {
+			if rerr := rename(xdg, legacy); rerr != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", legacy, rerr)
+			}
+			return nil, fmt.Errorf("failed to link %s to %s, staying on %s: %w", legacy, xdg, legacy, err)
+		}
+		m.Moved = true
+		return m, nil
+	}
+
+	if err := copyTree(legacy, xdg); err != nil {
+		os.RemoveAll(xdg)
+		return nil, fmt.Errorf("failed to copy %s to %s: %w", legacy, xdg, err)
+	}
+	// Swap ~/.zeude for the symlink, keeping the original until it is in place
+	old := legacy + ".migrating"
+	if err := rename(legacy, old); err != nil {
+		os.RemoveAll(xdg)
+		return nil, fmt.Errorf("failed to move %s aside: %w", legacy, err)
+	}
+	if err := symlink(xdg, legacy); err != nil {
+		if rerr := rename(old, legacy); rerr != nil {
+			return nil, fmt.Errorf("failed to restore %s from %s: %w", legacy, old, rerr)
+		}
+		os.RemoveAll(xdg)
+		return nil, fmt.Errorf("failed to link %s to %s, staying on %s: %w", legacy, xdg, legacy, err)
+	}
+	os.RemoveAll(old)
+	m.Copied = true
+	return m, nil
+}
+
+// copyTree copies a directory tree, preserving file modes and symlinks.
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyRegular(path, target, info.Mode().Perm())
+		default:
+			// Sockets, pipes, and the like are never part of the data dir
+			return nil
+		}
+	})
+}
+
+func copyRegular(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
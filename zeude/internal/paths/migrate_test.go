@@ -0,0 +1,260 @@
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// legacyHome returns a temp home with a populated ~/.zeude: a config, a
+// credentials file and the shim in bin.
+func legacyHome(t *testing.T, config string) *env.Fake {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and file modes are not reliable on Windows")
+	}
+	home := t.TempDir()
+	legacy := filepath.Join(home, LegacyDirName)
+	for name, content := range map[string]string{
+		ConfigFile:                          config,
+		CredentialsFile:                     "agent_key=zd_test\n",
+		filepath.Join(BinDirName, "claude"): "#!/bin/sh\n",
+	} {
+		path := filepath.Join(legacy, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &env.Fake{Home: home, Vars: map[string]string{}}
+}
+
+// assertMigrated checks ~/.zeude is a symlink to the XDG dir holding the data.
+func assertMigrated(t *testing.T, e *env.Fake) {
+	t.Helper()
+	legacy := filepath.Join(e.Home, LegacyDirName)
+	xdg := filepath.Join(e.Home, ".local", "share", "zeude")
+
+	if info, err := os.Lstat(legacy); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("~/.zeude is not a symlink after migration: %v, %v", info, err)
+	}
+	if target, _ := os.Readlink(legacy); target != xdg {
+		t.Errorf("~/.zeude links to %q, want %q", target, xdg)
+	}
+	data, err := os.ReadFile(filepath.Join(xdg, CredentialsFile))
+	if err != nil || string(data) != "agent_key=zd_test\n" {
+		t.Errorf("credentials in XDG dir = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(xdg, BinDirName, "claude")); err != nil || info.Mode().Perm() != 0700 {
+		t.Errorf("shim in XDG dir: %v, %v", info, err)
+	}
+	if dir, _ := Dir(e); dir != xdg {
+		t.Errorf("Dir() after migration = %q, want %q", dir, xdg)
+	}
+	if bin, _ := Bin(e); bin != filepath.Join(xdg, BinDirName) {
+		t.Errorf("Bin() after migration = %q", bin)
+	}
+}
+
+// failRename makes rename fail with err until the test ends.
+func failRename(t *testing.T, err error) {
+	t.Helper()
+	orig := rename
+	rename = func(string, string) error { return err }
+	t.Cleanup(func() { rename = orig })
+}
+
+// failSymlink makes symlink fail until the test ends.
+func failSymlink(t *testing.T) {
+	t.Helper()
+	orig := symlink
+	symlink = func(string, string) error { return errors.New("operation not permitted") }
+	t.Cleanup(func() { symlink = orig })
+}
+
+func TestDirPrecedence(t *testing.T) {
+	e := legacyHome(t, "")
+	legacy := filepath.Join(e.Home, LegacyDirName)
+	xdg := filepath.Join(e.Home, ".local", "share", "zeude")
+
+	if dir, _ := Dir(e); dir != legacy {
+		t.Errorf("Dir() without opt-in = %q, want %q", dir, legacy)
+	}
+
+	e.Vars[XDGOptInEnv] = "1"
+	if dir, _ := Dir(e); dir != legacy {
+		t.Errorf("Dir() opted in but unmigrated = %q, want %q", dir, legacy)
+	}
+
+	e.Vars["XDG_DATA_HOME"] = filepath.Join(e.Home, "data")
+	if got, _ := XDGDir(e); got != filepath.Join(e.Home, "data", "zeude") {
+		t.Errorf("XDGDir() with XDG_DATA_HOME = %q", got)
+	}
+	e.Vars["XDG_DATA_HOME"] = "relative/data"
+	if got, _ := XDGDir(e); got != xdg {
+		t.Errorf("XDGDir() ignores a relative XDG_DATA_HOME, got %q", got)
+	}
+	delete(e.Vars, "XDG_DATA_HOME")
+
+	e.Vars[HomeEnv] = filepath.Join(e.Home, "custom")
+	if dir, _ := Dir(e); dir != filepath.Join(e.Home, "custom") {
+		t.Errorf("Dir() with ZEUDE_HOME = %q", dir)
+	}
+	delete(e.Vars, HomeEnv)
+
+	if _, err := MigrateToXDG(e); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := Dir(e); dir != xdg {
+		t.Errorf("Dir() after migration = %q, want %q", dir, xdg)
+	}
+
+	// A fresh install with the opt-in goes straight to XDG
+	fresh := &env.Fake{Home: t.TempDir(), Vars: map[string]string{XDGOptInEnv: "true"}}
+	if dir, _ := Dir(fresh); dir != filepath.Join(fresh.Home, ".local", "share", "zeude") {
+		t.Errorf("Dir() on a fresh install = %q", dir)
+	}
+}
+
+func TestMigrateRenamesAndLinks(t *testing.T) {
+	e := legacyHome(t, "use_xdg=true\n")
+
+	m, err := MigrateToXDG(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || !m.Moved || m.Copied {
+		t.Fatalf("MigrateToXDG() = %+v, want a move", m)
+	}
+	assertMigrated(t, e)
+
+	// The opt-in is read through the symlink, and the config is one file
+	if !XDGEnabled(e) {
+		t.Error("XDGEnabled() = false after migration")
+	}
+}
+
+func TestMigrateCopiesAcrossFilesystems(t *testing.T) {
+	e := legacyHome(t, "use_xdg=true\n")
+	legacy := filepath.Join(e.Home, LegacyDirName)
+
+	// Only renames of ~/.zeude itself fail, like a move across devices
+	orig := rename
+	rename = func(from, to string) error {
+		if from == legacy && to != legacy+".migrating" {
+			return errors.New("invalid cross-device link")
+		}
+		return orig(from, to)
+	}
+	t.Cleanup(func() { rename = orig })
+
+	m, err := MigrateToXDG(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || !m.Copied || m.Moved {
+		t.Fatalf("MigrateToXDG() = %+v, want a copy", m)
+	}
+	assertMigrated(t, e)
+	if _, err := os.Lstat(legacy + ".migrating"); !os.IsNotExist(err) {
+		t.Errorf("original left behind after the copy: %v", err)
+	}
+}
+
+func TestMigrateWithoutSymlinksKeepsLegacy(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		crossDevice bool
+	}{
+		{"rename", false},
+		{"copy", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := legacyHome(t, "use_xdg=true\n")
+			legacy := filepath.Join(e.Home, LegacyDirName)
+			xdg := filepath.Join(e.Home, ".local", "share", "zeude")
+			failSymlink(t)
+			if tt.crossDevice {
+				orig := rename
+				rename = func(from, to string) error {
+					if to == xdg {
+						return errors.New("invalid cross-device link")
+					}
+					return orig(from, to)
+				}
+				t.Cleanup(func() { rename = orig })
+			}
+
+			if m, err := MigrateToXDG(e); err == nil || m != nil {
+				t.Fatalf("MigrateToXDG() = %+v, %v; want an error", m, err)
+			}
+			if info, err := os.Lstat(legacy); err != nil || !info.IsDir() {
+				t.Fatalf("~/.zeude not restored: %v, %v", info, err)
+			}
+			if _, err := os.Stat(filepath.Join(legacy, BinDirName, "claude")); err != nil {
+				t.Errorf("shim missing from ~/.zeude: %v", err)
+			}
+			if _, err := os.Lstat(xdg); !os.IsNotExist(err) {
+				t.Errorf("XDG dir left behind: %v", err)
+			}
+			if dir, _ := Dir(e); dir != legacy {
+				t.Errorf("Dir() = %q, want %q", dir, legacy)
+			}
+		})
+	}
+}
+
+func TestMigrateNoOps(t *testing.T) {
+	t.Run("XDG dir exists", func(t *testing.T) {
+		e := legacyHome(t, "use_xdg=true\n")
+		xdg := filepath.Join(e.Home, ".local", "share", "zeude")
+		if err := os.MkdirAll(xdg, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if m, err := MigrateToXDG(e); err != nil || m != nil {
+			t.Errorf("MigrateToXDG() = %+v, %v; want nothing done", m, err)
+		}
+		if info, err := os.Lstat(filepath.Join(e.Home, LegacyDirName)); err != nil || !info.IsDir() {
+			t.Errorf("~/.zeude changed: %v, %v", info, err)
+		}
+		if entries, _ := os.ReadDir(xdg); len(entries) != 0 {
+			t.Errorf("merged into the existing XDG dir: %v", entries)
+		}
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		e := legacyHome(t, "use_xdg=true\n")
+		if _, err := MigrateToXDG(e); err != nil {
+			t.Fatal(err)
+		}
+		if m, err := MigrateToXDG(e); err != nil || m != nil {
+			t.Errorf("second MigrateToXDG() = %+v, %v; want nothing done", m, err)
+		}
+		assertMigrated(t, e)
+	})
+
+	t.Run("ZEUDE_HOME", func(t *testing.T) {
+		e := legacyHome(t, "use_xdg=true\n")
+		e.Vars[HomeEnv] = filepath.Join(e.Home, "custom")
+		failRename(t, errors.New("rename called"))
+		if m, err := MigrateToXDG(e); err != nil || m != nil {
+			t.Errorf("MigrateToXDG() = %+v, %v; want nothing done", m, err)
+		}
+		if info, err := os.Lstat(filepath.Join(e.Home, LegacyDirName)); err != nil || !info.IsDir() {
+			t.Errorf("~/.zeude changed: %v, %v", info, err)
+		}
+	})
+
+	t.Run("not opted in", func(t *testing.T) {
+		e := legacyHome(t, "")
+		if m, err := MigrateToXDG(e); err != nil || m != nil {
+			t.Errorf("MigrateToXDG() = %+v, %v; want nothing done", m, err)
+		}
+	})
+}
@@ -0,0 +1,336 @@
+// Package paths resolves every file and directory Zeude reads or writes.
+//
+// The Zeude data directory is, in order of precedence:
+//  1. $ZEUDE_HOME
+//  2. $XDG_DATA_HOME/zeude (default ~/.local/share/zeude), only when opted in
+//     via ZEUDE_USE_XDG=1 or use_xdg=true in ~/.zeude/config
+//  3. ~/.zeude
+//
+// Claude-side paths follow Claude's own conventions: ~/.claude and
+// ~/.claude.json, or $CLAUDE_CONFIG_DIR when it is set.
+package paths
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Environment variables and config keys that affect resolution.
+const (
+	HomeEnv            = "ZEUDE_HOME"
+	XDGOptInEnv        = "ZEUDE_USE_XDG"
+	XDGConfigKey       = "use_xdg"
+	ClaudeConfigDirEnv = "CLAUDE_CONFIG_DIR"
+)
+
+// File and directory names inside the Zeude data directory.
+const (
+	LegacyDirName       = ".zeude"
+	CredentialsFile     = "credentials"
+	ConfigFile          = "config"
+	CacheFile           = "config-cache.json"
+	ManifestFile        = "managed.json"
+	ManagedKeysFile     = "managed-keys.json"   // legacy, folded into ManifestFile
+	ManagedHooksFile    = "managed-hooks.json"  // legacy
+	ManagedSkillsFile   = "managed_skills.json" // legacy
+	ProjectsDirName     = "projects"
+	RealBinaryPathFile  = "real_binary_path"
+	CurrentVersionFile  = "current_version"
+	LastUpdateFile      = "last_successful_update"
+	UpdateChannelFile   = "update_channel"
+	UpdateHoldFile      = "update_hold.json"
+	UpdatePendingFile   = "update_pending"
+	PausedFile          = "paused"
+	RateLimitFile       = "rate_limited"
+	SyncLockFile        = "sync.lock"
+	StatusFile          = "status.json"
+	StatusQueueFile     = "status-queue.jsonl"
+	ErrorsFile          = "errors.jsonl"
+	ErrorsLockFile      = "errors.lock"
+	LastHeartbeatFile   = "last_heartbeat"
+	LastSessionFile     = "last_session"
+	SessionFile         = "session.json"
+	OnboardingDoneFile  = "onboarding_done"
+	TrustedKeysFile     = "trusted_keys"
+	TrustedProjectsFile = "trusted_projects"
+	EventsFile          = "events.jsonl"
+	LastCleanupFile     = "last_cleanup"
+	BackupsDirName      = "backups"
+	PreLaunchDirName    = "pre-launch.d"
+	CollectorHealthFile = "collector-health.json"
+	CollectorStatusFile = "collector_status"
+	ClaudeVersionFile   = "claude_version.json"
+	OverridesFile       = "overrides.json"
+	MOTDSeenFile        = "motd_seen.json"
+	LogsDirName         = "logs"
+	PanicLogFile        = "panic.log"
+	BinDirName          = "bin"
+)
+
+// homeDir returns the user's home directory or an error if it is unknown.
+func homeDir(e env.Env) (string, error) {
+	home, err := e.HomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	if home == "" {
+		return "", errors.New("home directory is empty")
+	}
+	return home, nil
+}
+
+// LegacyDir returns ~/.zeude regardless of ZEUDE_HOME or XDG settings.
+func LegacyDir(e env.Env) (string, error) {
+	home, err := homeDir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, LegacyDirName), nil
+}
+
+// XDGDir returns $XDG_DATA_HOME/zeude, defaulting to ~/.local/share/zeude.
+func XDGDir(e env.Env) (string, error) {
+	if data := e.Getenv("XDG_DATA_HOME"); data != "" && filepath.IsAbs(data) {
+		return filepath.Join(data, "zeude"), nil
+	}
+	home, err := homeDir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "zeude"), nil
+}
+
+// XDGEnabled reports whether the user opted into the XDG layout.
+// The legacy config is consulted because it is the only file whose
+// location doesn't depend on this answer.
+func XDGEnabled(e env.Env) bool {
+	if v := e.Getenv(XDGOptInEnv); v != "" {
+		return v == "1" || v == "true"
+	}
+	legacy, err := LegacyDir(e)
+	if err != nil {
+		return false
+	}
+	return readKey(filepath.Join(legacy, ConfigFile), XDGConfigKey) == "true"
+}
+
+// Dir returns the Zeude data directory (see package doc for precedence).
+// An opted-in XDG layout is only used once it exists or there is nothing to
+// migrate, so an unmigrated ~/.zeude keeps working until MigrateToXDG runs.
+func Dir(e env.Env) (string, error) {
+	if dir := e.Getenv(HomeEnv); dir != "" {
+		return filepath.Clean(dir), nil
+	}
+
+	legacy, err := LegacyDir(e)
+	if err != nil {
+		return "", err
+	}
+	if !XDGEnabled(e) {
+		return legacy, nil
+	}
+
+	xdg, err := XDGDir(e)
+	if err != nil {
+		return "", err
+	}
+	if isDir(xdg) || !isDir(legacy) {
+		return xdg, nil
+	}
+	return legacy, nil
+}
+
+// File returns the path of name inside the Zeude data directory.
+func File(e env.Env, name string) (string, error) {
+	dir, err := Dir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Credentials returns the agent key file path.
+func Credentials(e env.Env) (string, error) { return File(e, CredentialsFile) }
+
+// Config returns the key=value config file path.
+func Config(e env.Env) (string, error) { return File(e, ConfigFile) }
+
+// Cache returns the cached dashboard config path.
+func Cache(e env.Env) (string, error) { return File(e, CacheFile) }
+
+// Manifest returns the path tracking Zeude-managed servers, hooks and skills.
+func Manifest(e env.Env) (string, error) { return File(e, ManifestFile) }
+
+// Projects returns the directory of per-project manifests of the MCP
+// servers Zeude wrote to each project's .mcp.json.
+func Projects(e env.Env) (string, error) { return File(e, ProjectsDirName) }
+
+// ManagedKeys returns the legacy path tracking Zeude-managed MCP server keys.
+func ManagedKeys(e env.Env) (string, error) { return File(e, ManagedKeysFile) }
+
+// ManagedHooks returns the legacy path tracking Zeude-managed hook files.
+func ManagedHooks(e env.Env) (string, error) { return File(e, ManagedHooksFile) }
+
+// ManagedSkills returns the legacy path tracking Zeude-managed skill files.
+func ManagedSkills(e env.Env) (string, error) { return File(e, ManagedSkillsFile) }
+
+// RealBinaryPath returns the file storing the real claude binary location.
+func RealBinaryPath(e env.Env) (string, error) { return File(e, RealBinaryPathFile) }
+
+// CurrentVersion returns the file the shim writes its version to.
+func CurrentVersion(e env.Env) (string, error) { return File(e, CurrentVersionFile) }
+
+// LastUpdate returns the marker touched after a successful update check.
+func LastUpdate(e env.Env) (string, error) { return File(e, LastUpdateFile) }
+
+// LastSession returns the file holding the session ID of the last launch.
+func LastSession(e env.Env) (string, error) { return File(e, LastSessionFile) }
+
+// Session returns the metadata file of the last launched session, for hooks.
+func Session(e env.Env) (string, error) { return File(e, SessionFile) }
+
+// OnboardingDone returns the marker that stops the shim offering to set up
+// an agent key.
+func OnboardingDone(e env.Env) (string, error) { return File(e, OnboardingDoneFile) }
+
+// Status returns the path of the last-run status file.
+func Status(e env.Env) (string, error) { return File(e, StatusFile) }
+
+// StatusQueue returns the file of status reports held back while offline.
+func StatusQueue(e env.Env) (string, error) { return File(e, StatusQueueFile) }
+
+// Errors returns the crash/error report queue path.
+func Errors(e env.Env) (string, error) { return File(e, ErrorsFile) }
+
+// ErrorsLock returns the lock shims take to rewrite the error report queue.
+func ErrorsLock(e env.Env) (string, error) { return File(e, ErrorsLockFile) }
+
+// LastHeartbeat returns the file recording when the last heartbeat was sent.
+func LastHeartbeat(e env.Env) (string, error) { return File(e, LastHeartbeatFile) }
+
+// TrustedKeys returns the file pinning content signing keys.
+func TrustedKeys(e env.Env) (string, error) { return File(e, TrustedKeysFile) }
+
+// TrustedProjects returns the list of project files the user trusted.
+func TrustedProjects(e env.Env) (string, error) { return File(e, TrustedProjectsFile) }
+
+// Paused returns the path of the marker written by 'zeude pause'.
+func Paused(e env.Env) (string, error) { return File(e, PausedFile) }
+
+// RateLimit returns the marker holding when the dashboard said to sync
+// again after rate limiting.
+func RateLimit(e env.Env) (string, error) { return File(e, RateLimitFile) }
+
+// SyncLock returns the lock held while a sync writes Claude's files.
+func SyncLock(e env.Env) (string, error) { return File(e, SyncLockFile) }
+
+// Events returns the audit log of changes Zeude made to the machine.
+func Events(e env.Env) (string, error) { return File(e, EventsFile) }
+
+// LastCleanup returns the marker touched after each housekeeping pass.
+func LastCleanup(e env.Env) (string, error) { return File(e, LastCleanupFile) }
+
+// Backups returns the directory holding backups of files Zeude rewrites.
+func Backups(e env.Env) (string, error) { return File(e, BackupsDirName) }
+
+// PreLaunch returns the directory of local scripts the shim runs before
+// claude.
+func PreLaunch(e env.Env) (string, error) { return File(e, PreLaunchDirName) }
+
+// CollectorHealth returns the cached collector failover selection.
+func CollectorHealth(e env.Env) (string, error) { return File(e, CollectorHealthFile) }
+
+// CollectorStatus returns the cached result of the shim's collector probe.
+func CollectorStatus(e env.Env) (string, error) { return File(e, CollectorStatusFile) }
+
+// ClaudeVersion returns the cached version of the real claude binary.
+func ClaudeVersion(e env.Env) (string, error) { return File(e, ClaudeVersionFile) }
+
+// Overrides returns the local opt-outs from the team's config.
+func Overrides(e env.Env) (string, error) { return File(e, OverridesFile) }
+
+// MOTDSeen returns the record of dashboard messages already shown today.
+func MOTDSeen(e env.Env) (string, error) { return File(e, MOTDSeenFile) }
+
+// Logs returns the log directory.
+func Logs(e env.Env) (string, error) { return File(e, LogsDirName) }
+
+// PanicLog returns the log of panics the shim recovered from, in Logs.
+func PanicLog(e env.Env) (string, error) { return File(e, filepath.Join(LogsDirName, PanicLogFile)) }
+
+// Bin returns the directory holding the shim and CLI binaries.
+func Bin(e env.Env) (string, error) { return File(e, BinDirName) }
+
+// ClaudeDir returns Claude's config directory: $CLAUDE_CONFIG_DIR or ~/.claude.
+func ClaudeDir(e env.Env) (string, error) {
+	if dir := e.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Clean(dir), nil
+	}
+	home, err := homeDir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude"), nil
+}
+
+// ClaudeConfig returns Claude's global config file: ~/.claude.json, or
+// $CLAUDE_CONFIG_DIR/.claude.json when the config dir is overridden.
+func ClaudeConfig(e env.Env) (string, error) {
+	if dir := e.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Join(filepath.Clean(dir), ".claude.json"), nil
+	}
+	home, err := homeDir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude.json"), nil
+}
+
+// ClaudeSettings returns Claude's settings.json path.
+func ClaudeSettings(e env.Env) (string, error) { return claudeFile(e, "settings.json") }
+
+// ClaudeHooks returns the directory Zeude installs hook scripts into.
+func ClaudeHooks(e env.Env) (string, error) { return claudeFile(e, "hooks") }
+
+// ClaudeCommands returns the directory Zeude installs skills into.
+func ClaudeCommands(e env.Env) (string, error) { return claudeFile(e, "commands") }
+
+// ClaudeSkillRules returns the skill-rules.json path used by the hint hook.
+func ClaudeSkillRules(e env.Env) (string, error) { return claudeFile(e, "skill-rules.json") }
+
+func claudeFile(e env.Env, name string) (string, error) {
+	dir, err := ClaudeDir(e)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// readKey returns the value of a key= line in a config file, or "".
+func readKey(path, key string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	prefix := key + "="
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(line, prefix))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+// Package project finds per-repository Zeude configuration: a .zeude file
+// (key=value, the same format as ~/.zeude/config) in the working directory
+// or at the root of the enclosing git repository.
+//
+// It is a file rather than a directory because ~/.zeude is Zeude's own data
+// directory, and a project may well be the home directory.
+//
+// The file comes with whatever repository was cloned, so its overrides only
+// apply once the user trusted it, as it is now, with `zeude trust` (or wrote
+// it with `zeude init`). Changing it revokes the trust.
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// FileName is the project configuration file.
+const FileName = ".zeude"
+
+// IgnoreEnv, when truthy, makes Find ignore project files altogether.
+const IgnoreEnv = "ZEUDE_NO_PROJECT"
+
+// Keys read from the project file.
+const (
+	// IDKey is the identifier the dashboard assigned at `zeude init`.
+	IDKey = "project_id"
+	// AgentKeyEnvKey names an environment variable holding a project-scoped
+	// agent key. The key itself never goes in the file, which is committed.
+	AgentKeyEnvKey = "agent_key_env"
+	// DashboardURLKey and EndpointKey point this project at another
+	// dashboard and collector than the global config does.
+	DashboardURLKey = "dashboard_url"
+	EndpointKey     = "endpoint"
+)
+
+// overrideKeys are the keys that replace a global setting, checked the
+// way `zeude config set` checks them.
+var overrideKeys = []string{DashboardURLKey, EndpointKey}
+
+// Config is a parsed project file.
+type Config struct {
+	Dir     string // directory holding the file
+	Path    string
+	Entries []config.Entry
+	Notes   []string // lines ParseKeyValues repaired or skipped
+	hash    string   // SHA-256 of the content, as recorded when trusted
+}
+
+// Get returns the top-level value for key, or "".
+func (c *Config) Get(key string) string {
+	entry, _ := config.Lookup(c.Entries, "", strings.ToLower(key))
+	return entry.Value
+}
+
+// ID returns the project identifier, or "".
+func (c *Config) ID() string { return c.Get(IDKey) }
+
+// AgentKey returns the project-scoped key from the variable the file names
+// in agent_key_env, or "".
+func (c *Config) AgentKey(e env.Env) string {
+	name := c.Get(AgentKeyEnvKey)
+	if name == "" {
+		return ""
+	}
+	return strings.TrimSpace(e.Getenv(name))
+}
+
+// Overrides reports whether the file replaces any global setting.
+func (c *Config) Overrides() bool {
+	for _, key := range append(overrideKeys, AgentKeyEnvKey) {
+		if c.Get(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Find looks for a project file in the working directory, then at the git
+// root enclosing it. It returns nil, nil when there is none, and an error
+// for a file that can't be read or is malformed: callers then use the
+// global settings alone rather than half of a broken file.
+func Find(e env.Env) (*Config, error) {
+	if config.IsTruthy(e.Getenv(IgnoreEnv)) {
+		return nil, nil
+	}
+	wd, err := e.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{wd}
+	if root := GitRoot(wd); root != "" && root != wd {
+		dirs = append(dirs, root)
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, FileName)
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue // a directory here is ~/.zeude, not a project
+		}
+		return Load(path)
+	}
+	return nil, nil
+}
+
+// Load parses the project file at path. Lines that had to be skipped, a
+// literal agent key, or an invalid override value make it malformed.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, notes := config.ParseKeyValues(data)
+	for _, note := range notes {
+		if strings.HasSuffix(note, "ignored") {
+			return nil, fmt.Errorf("%s: %s", path, note)
+		}
+	}
+	sum := sha256.Sum256(data)
+	c := &Config{Dir: filepath.Dir(path), Path: path, Entries: entries, Notes: notes, hash: hex.EncodeToString(sum[:])}
+	if c.Get(config.AgentKeyName) != "" {
+		return nil, fmt.Errorf("%s: %s doesn't belong in a committed file; use %s", path, config.AgentKeyName, AgentKeyEnvKey)
+	}
+	for _, key := range overrideKeys {
+		if value := c.Get(key); value != "" {
+			if err := config.ValidateValue(key, value); err != nil {
+				return nil, fmt.Errorf("%s: invalid %s: %v", path, key, err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Trusted reports whether the user trusted the file with its current
+// content.
+func (c *Config) Trusted(e env.Env) bool {
+	trusted, _ := loadTrusted(e)
+	return trusted[c.Path] == c.hash
+}
+
+// Trust records the file, with its current content, as trusted.
+func Trust(e env.Env, c *Config) error {
+	trusted, err := loadTrusted(e)
+	if err != nil {
+		return err
+	}
+	trusted[c.Path] = c.hash
+
+	var b strings.Builder
+	b.WriteString("# Project files trusted with 'zeude trust': SHA-256 and path\n")
+	listed := make([]string, 0, len(trusted))
+	for path := range trusted {
+		listed = append(listed, path)
+	}
+	sort.Strings(listed)
+	for _, path := range listed {
+		fmt.Fprintf(&b, "%s  %s\n", trusted[path], path)
+	}
+	listPath, err := paths.TrustedProjects(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(listPath), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(listPath, []byte(b.String()), 0600)
+}
+
+// loadTrusted reads the trust list into a map from path to content hash,
+// in the format of sha256sum.
+func loadTrusted(e env.Env) (map[string]string, error) {
+	trusted := map[string]string{}
+	listPath, err := paths.TrustedProjects(e)
+	if err != nil {
+		return trusted, err
+	}
+	data, err := os.ReadFile(listPath)
+	if os.IsNotExist(err) {
+		return trusted, nil
+	}
+	if err != nil {
+		return trusted, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		hash, path, ok := strings.Cut(scanner.Text(), "  ")
+		if ok && !strings.HasPrefix(hash, "#") {
+			trusted[path] = hash
+		}
+	}
+	return trusted, scanner.Err()
+}
+
+// GitRoot returns the nearest directory at or above dir containing .git,
+// or "" outside a repository.
+func GitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// GitRemote returns the origin URL of the repository rooted at root, read
+// from .git/config without running git, or "" if there is none.
+func GitRemote(root string) string {
+	gitDir := filepath.Join(root, ".git")
+	// Worktrees and submodules have a .git file pointing at the real dir
+	if data, err := os.ReadFile(gitDir); err == nil {
+		target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(root, target)
+		}
+		gitDir = target
+		if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+			gitDir = filepath.Join(gitDir, strings.TrimSpace(string(common)))
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return ""
+	}
+	entries, _ := config.ParseKeyValues(data)
+	entry, _ := config.Lookup(entries, `remote "origin"`, "url")
+	return entry.Value
+}
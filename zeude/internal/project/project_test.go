@@ -0,0 +1,102 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+// writeProject writes a .zeude file with content into a new repository
+// and returns an environment working there, with its own Zeude home.
+func writeProject(t *testing.T, content string) *env.Fake {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "src")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return &env.Fake{Home: t.TempDir(), Wd: sub, Vars: map[string]string{}}
+}
+
+func TestTrust(t *testing.T) {
+	e := writeProject(t, "dashboard_url=https://client.example.com\n")
+	find := func() *Config {
+		t.Helper()
+		p, err := Find(e)
+		if err != nil || p == nil {
+			t.Fatalf("Find() = %v, %v", p, err)
+		}
+		return p
+	}
+
+	p := find()
+	if p.Trusted(e) {
+		t.Fatal("a cloned file is trusted before 'zeude trust'")
+	}
+	if err := Trust(e, p); err != nil {
+		t.Fatal(err)
+	}
+	if !find().Trusted(e) {
+		t.Fatal("not trusted after Trust")
+	}
+
+	// Another home doesn't share the trust
+	other := *e
+	other.Home = t.TempDir()
+	if find().Trusted(&other) {
+		t.Error("trusted in a home that never trusted it")
+	}
+
+	// A pull that changes the file revokes it
+	if err := os.WriteFile(p.Path, []byte("dashboard_url=https://evil.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if find().Trusted(e) {
+		t.Error("still trusted after the file changed")
+	}
+}
+
+func TestTrustKeepsOtherProjects(t *testing.T) {
+	a := writeProject(t, "endpoint=https://a.example.com\n")
+	b := writeProject(t, "endpoint=https://b.example.com\n")
+	b.Home = a.Home
+	for _, e := range []*env.Fake{a, b} {
+		p, _ := Find(e)
+		if err := Trust(e, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []*env.Fake{a, b} {
+		if p, _ := Find(e); !p.Trusted(e) {
+			t.Errorf("%s lost its trust", p.Path)
+		}
+	}
+}
+
+func TestFindIgnored(t *testing.T) {
+	e := writeProject(t, "dashboard_url=https://client.example.com\n")
+	e.Vars[IgnoreEnv] = "1"
+	if p, err := Find(e); p != nil || err != nil {
+		t.Errorf("Find() with %s = %v, %v, want nothing", IgnoreEnv, p, err)
+	}
+}
+
+func TestAgentKey(t *testing.T) {
+	e := writeProject(t, "agent_key_env=CLIENT_KEY\n")
+	p, _ := Find(e)
+	if got := p.AgentKey(e); got != "" {
+		t.Errorf("AgentKey() = %q with the variable unset", got)
+	}
+	e.Vars["CLIENT_KEY"] = " zd_client \n"
+	if got := p.AgentKey(e); got != "zd_client" {
+		t.Errorf("AgentKey() = %q, want zd_client", got)
+	}
+}
@@ -0,0 +1,105 @@
+// Package provider chooses the model provider claude talks to (Anthropic,
+// Amazon Bedrock or Google Vertex AI) from the dashboard and local config,
+// so users don't keep their own shell exports for it.
+package provider
+
+import (
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// Providers claude can use.
+const (
+	Anthropic = "anthropic"
+	Bedrock   = "bedrock"
+	Vertex    = "vertex"
+)
+
+// Config file keys. Each overrides the dashboard's setting of the same
+// field; an exported variable overrides both.
+const (
+	ProviderKey       = "provider"
+	AWSRegionKey      = "aws_region"
+	AWSProfileKey     = "aws_profile"
+	VertexProjectKey  = "vertex_project"
+	VertexRegionKey   = "vertex_region"
+	ModelKey          = "model"
+	SmallFastModelKey = "small_fast_model"
+)
+
+// Claude Code's switches for the non-Anthropic providers.
+const (
+	UseBedrockEnv = "CLAUDE_CODE_USE_BEDROCK"
+	UseVertexEnv  = "CLAUDE_CODE_USE_VERTEX"
+)
+
+// Settings is the provider block of the dashboard config. Empty fields are
+// left to claude's defaults.
+type Settings struct {
+	Provider       string `json:"provider,omitempty"` // anthropic, bedrock or vertex
+	AWSRegion      string `json:"awsRegion,omitempty"`
+	AWSProfile     string `json:"awsProfile,omitempty"`
+	VertexProject  string `json:"vertexProject,omitempty"`
+	VertexRegion   string `json:"vertexRegion,omitempty"`
+	Model          string `json:"model,omitempty"`
+	SmallFastModel string `json:"smallFastModel,omitempty"`
+}
+
+// Resolve returns dashboard with any field set in ~/.zeude/config
+// replaced by the local value.
+func Resolve(dashboard Settings) Settings {
+	s := dashboard
+	for _, field := range []struct {
+		key   string
+		value *string
+	}{
+		{ProviderKey, &s.Provider},
+		{AWSRegionKey, &s.AWSRegion},
+		{AWSProfileKey, &s.AWSProfile},
+		{VertexProjectKey, &s.VertexProject},
+		{VertexRegionKey, &s.VertexRegion},
+		{ModelKey, &s.Model},
+		{SmallFastModelKey, &s.SmallFastModel},
+	} {
+		if v := config.Get(field.key); v != "" {
+			*field.value = v
+		}
+	}
+	return s
+}
+
+// ClaudeEnv computes the provider environment the shim gives claude for
+// the dashboard's settings, after local overrides. Like the telemetry
+// variables it is fail-open: anything the user exported is kept and
+// reported as Skipped. A user who exported the other provider's switch
+// gets none of this provider's settings, so a local CLAUDE_CODE_USE_VERTEX
+// isn't met with Zeude's CLAUDE_CODE_USE_BEDROCK.
+func ClaudeEnv(e env.Env, dashboard Settings) []telemetry.EnvVar {
+	s := Resolve(dashboard)
+	var vars []telemetry.EnvVar
+	setIfEmpty := func(key, value string) {
+		if value == "" {
+			return
+		}
+		if existing := e.Getenv(key); existing != "" {
+			vars = append(vars, telemetry.EnvVar{Key: key, Value: existing, UserSet: true, Skipped: true})
+			return
+		}
+		vars = append(vars, telemetry.EnvVar{Key: key, Value: value})
+	}
+	// A user who switched to the other provider keeps it, unmixed
+	switch {
+	case s.Provider == Bedrock && e.Getenv(UseVertexEnv) == "":
+		setIfEmpty(UseBedrockEnv, "1")
+		setIfEmpty("AWS_REGION", s.AWSRegion)
+		setIfEmpty("AWS_PROFILE", s.AWSProfile)
+	case s.Provider == Vertex && e.Getenv(UseBedrockEnv) == "":
+		setIfEmpty(UseVertexEnv, "1")
+		setIfEmpty("ANTHROPIC_VERTEX_PROJECT_ID", s.VertexProject)
+		setIfEmpty("CLOUD_ML_REGION", s.VertexRegion)
+	}
+	setIfEmpty("ANTHROPIC_MODEL", s.Model)
+	setIfEmpty("ANTHROPIC_SMALL_FAST_MODEL", s.SmallFastModel)
+	return vars
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+	"github.com/zeude/zeude/internal/telemetry"
+)
+
+// useConfig points the Zeude data directory at a temp dir whose config
+// file holds lines.
+func useConfig(t *testing.T, lines string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv(paths.HomeEnv, dir)
+	t.Setenv("ZEUDE_USE_XDG", "")
+	if lines != "" {
+		if err := os.WriteFile(filepath.Join(dir, paths.ConfigFile), []byte(lines), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	useConfig(t, "provider=vertex\nvertex_project=local-proj\nmodel=\n")
+	got := Resolve(Settings{Provider: Bedrock, AWSRegion: "us-east-1", VertexProject: "dash-proj", Model: "dash-model"})
+	want := Settings{Provider: Vertex, AWSRegion: "us-east-1", VertexProject: "local-proj", Model: "dash-model"}
+	if got != want {
+		t.Errorf("Resolve = %+v, want %+v", got, want)
+	}
+}
+
+func TestClaudeEnv(t *testing.T) {
+	bedrock := Settings{Provider: Bedrock, AWSRegion: "us-west-2", AWSProfile: "eng", Model: "claude-opus"}
+	vertex := Settings{Provider: Vertex, VertexProject: "proj", VertexRegion: "us-east5"}
+	set := func(key, value string) telemetry.EnvVar { return telemetry.EnvVar{Key: key, Value: value} }
+	kept := func(key, value string) telemetry.EnvVar {
+		return telemetry.EnvVar{Key: key, Value: value, UserSet: true, Skipped: true}
+	}
+
+	tests := []struct {
+		name      string
+		dashboard Settings
+		config    string
+		exported  map[string]string
+		want      []telemetry.EnvVar
+	}{
+		{"nothing configured", Settings{}, "", nil, nil},
+		{"anthropic only sets models", Settings{Provider: Anthropic, Model: "m", AWSRegion: "ignored"}, "", nil,
+			[]telemetry.EnvVar{set("ANTHROPIC_MODEL", "m")}},
+		{"bedrock", bedrock, "", nil, []telemetry.EnvVar{
+			set(UseBedrockEnv, "1"), set("AWS_REGION", "us-west-2"), set("AWS_PROFILE", "eng"), set("ANTHROPIC_MODEL", "claude-opus"),
+		}},
+		{"vertex", vertex, "", nil, []telemetry.EnvVar{
+			set(UseVertexEnv, "1"), set("ANTHROPIC_VERTEX_PROJECT_ID", "proj"), set("CLOUD_ML_REGION", "us-east5"),
+		}},
+		{"from local config alone", Settings{}, "provider=bedrock\naws_region=eu-west-1\nsmall_fast_model=haiku\n", nil, []telemetry.EnvVar{
+			set(UseBedrockEnv, "1"), set("AWS_REGION", "eu-west-1"), set("ANTHROPIC_SMALL_FAST_MODEL", "haiku"),
+		}},
+		{"local config overrides dashboard", bedrock, "aws_region=eu-west-1\n", nil, []telemetry.EnvVar{
+			set(UseBedrockEnv, "1"), set("AWS_REGION", "eu-west-1"), set("AWS_PROFILE", "eng"), set("ANTHROPIC_MODEL", "claude-opus"),
+		}},
+		{"exports win", bedrock, "", map[string]string{"AWS_REGION": "ap-south-1", "ANTHROPIC_MODEL": "mine"}, []telemetry.EnvVar{
+			set(UseBedrockEnv, "1"), kept("AWS_REGION", "ap-south-1"), set("AWS_PROFILE", "eng"), kept("ANTHROPIC_MODEL", "mine"),
+		}},
+		{"exported switch kept", bedrock, "", map[string]string{UseBedrockEnv: "true"}, []telemetry.EnvVar{
+			kept(UseBedrockEnv, "true"), set("AWS_REGION", "us-west-2"), set("AWS_PROFILE", "eng"), set("ANTHROPIC_MODEL", "claude-opus"),
+		}},
+		{"user on vertex gets no bedrock", bedrock, "", map[string]string{UseVertexEnv: "1"}, []telemetry.EnvVar{
+			set("ANTHROPIC_MODEL", "claude-opus"),
+		}},
+		{"user on bedrock gets no vertex", vertex, "", map[string]string{UseBedrockEnv: "1"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.config)
+			got := ClaudeEnv(&env.Fake{Vars: tt.exported}, tt.dashboard)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClaudeEnv =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package resolver
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// InstallCommand installs the real Claude CLI.
+const InstallCommand = "npm install -g @anthropic-ai/claude-code"
+
+// npmPrefixTimeout bounds `npm prefix -g`, which is slow on some setups.
+const npmPrefixTimeout = 3 * time.Second
+
+// Candidates probes the places Claude's installers put the binary, for
+// when it is not on PATH: npm's global prefix, ~/.claude/local, Homebrew
+// and bun. It returns the executables found, resolved and without the shim.
+func Candidates(opts Options) []string {
+	e := env.OrDefault(opts.Env)
+	var dirs []string
+	if prefix := npmPrefix(); prefix != "" {
+		dirs = append(dirs, filepath.Join(prefix, "bin"))
+	}
+	if home, err := e.HomeDir(); err == nil && home != "" {
+		dirs = append(dirs, filepath.Join(home, ".claude", "local"))
+		if bun := e.Getenv("BUN_INSTALL"); bun != "" {
+			dirs = append(dirs, filepath.Join(bun, "bin"))
+		}
+		dirs = append(dirs,
+			filepath.Join(home, ".bun", "bin"),
+			filepath.Join(home, ".npm-global", "bin"),
+			filepath.Join(home, ".local", "bin"),
+		)
+	}
+	dirs = append(dirs, "/opt/homebrew/bin", "/usr/local/bin", "/home/linuxbrew/.linuxbrew/bin")
+
+	shimDir, _ := paths.Bin(e)
+	shim, _ := filepath.EvalSymlinks(filepath.Join(shimDir, "claude"))
+	seen := make(map[string]bool)
+	var found []string
+	for _, dir := range dirs {
+		real, err := resolveSymlinks(filepath.Join(dir, "claude"))
+		if err != nil || real == shim || seen[real] || verifyExecutable(real) != nil {
+			continue
+		}
+		seen[real] = true
+		found = append(found, real)
+	}
+	return found
+}
+
+// npmPrefix returns npm's global prefix, or "" without npm.
+func npmPrefix() string {
+	ctx, cancel := context.WithTimeout(context.Background(), npmPrefixTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "npm", "prefix", "-g").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SaveRealBinary records path in ~/.zeude/real_binary_path, where
+// FindRealBinary looks first.
+func SaveRealBinary(opts Options, path string) error {
+	e := env.OrDefault(opts.Env)
+	file, err := paths.RealBinaryPath(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(file, []byte(path+"\n"), 0644)
+}
+
+// ForgetRealBinary removes the recorded path, so FindRealBinary searches
+// PATH again. A missing record is not an error.
+func ForgetRealBinary(opts Options) error {
+	file, err := paths.RealBinaryPath(env.OrDefault(opts.Env))
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -6,13 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-)
 
-const (
-	// shimDirName is the directory where the shim binary is installed
-	shimDirName = ".zeude/bin"
-	// storedPathFile stores the path to the real claude binary
-	storedPathFile = ".zeude/real_binary_path"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
 )
 
 // ErrBinaryNotFound is returned when the real claude binary cannot be located.
@@ -23,20 +19,52 @@ var ErrBinaryNotFound = errors.New("real claude binary not found in PATH")
 //  1. Stored path in ~/.zeude/real_binary_path (fastest)
 //  2. Search PATH, excluding the shim directory
 func FindRealBinary() (string, error) {
-	home, err := os.UserHomeDir()
+	return FindRealBinaryWithOptions(Options{})
+}
+
+// Options customizes binary resolution. The zero value uses the real environment.
+type Options struct {
+	// Env supplies the home directory and PATH. nil means the process environment.
+	Env env.Env
+}
+
+// FindRealBinaryWithOptions is FindRealBinary with an explicit environment.
+func FindRealBinaryWithOptions(opts Options) (string, error) {
+	e := env.OrDefault(opts.Env)
+
+	// Try stored path first (set during installation)
+	storedPath, err := paths.RealBinaryPath(e)
 	if err != nil {
 		return "", err
 	}
-
-	// Try stored path first (set during installation)
-	storedPath := filepath.Join(home, storedPathFile)
 	if path, err := readStoredPath(storedPath); err == nil {
 		return path, nil
 	}
 
 	// Fallback: search PATH, excluding our shim directory
-	shimDir := filepath.Join(home, shimDirName)
-	return searchPATH("claude", shimDir)
+	return SearchPATH(opts)
+}
+
+// SearchPATH looks for claude on PATH, skipping the shim directory and
+// ignoring any stored path. Installation uses it to find the binary to record.
+func SearchPATH(opts Options) (string, error) {
+	e := env.OrDefault(opts.Env)
+	shimDir, err := paths.Bin(e)
+	if err != nil {
+		return "", err
+	}
+	return searchPATH(e.Getenv("PATH"), "claude", shimDir)
+}
+
+// SearchPATHAll is SearchPATH returning every claude on PATH, in PATH
+// order, for when the first one found fails to run.
+func SearchPATHAll(opts Options) []string {
+	e := env.OrDefault(opts.Env)
+	shimDir, err := paths.Bin(e)
+	if err != nil {
+		return nil
+	}
+	return searchPATHAll(e.Getenv("PATH"), "claude", shimDir)
 }
 
 // readStoredPath reads and validates the stored binary path.
@@ -65,17 +93,33 @@ func readStoredPath(storedPath string) (string, error) {
 	return realPath, nil
 }
 
-// searchPATH searches the PATH environment variable for the named binary,
+// searchPATH searches the given PATH value for the named binary,
 // excluding the specified directory to avoid finding our own shim.
-func searchPATH(name, excludeDir string) (string, error) {
-	pathEnv := os.Getenv("PATH")
-	if pathEnv == "" {
+func searchPATH(pathEnv, name, excludeDir string) (string, error) {
+	found := searchPATHAll(pathEnv, name, excludeDir)
+	if len(found) == 0 {
 		return "", ErrBinaryNotFound
 	}
+	return found[0], nil
+}
+
+// searchPATHAll returns every executable named name on pathEnv outside
+// excludeDir, resolved, without duplicates.
+func searchPATHAll(pathEnv, name, excludeDir string) []string {
+	if pathEnv == "" {
+		return nil
+	}
 
-	// Normalize the exclude directory for comparison
+	// Normalize the exclude directory for comparison. Symlinks are resolved
+	// too, since a migrated ~/.zeude links to the XDG data dir.
 	excludeDir, _ = filepath.Abs(excludeDir)
+	excludeReal, err := filepath.EvalSymlinks(excludeDir)
+	if err != nil {
+		excludeReal = excludeDir
+	}
 
+	var found []string
+	seen := make(map[string]bool)
 	paths := strings.Split(pathEnv, string(os.PathListSeparator))
 	for _, dir := range paths {
 		// Skip empty entries
@@ -93,21 +137,25 @@ func searchPATH(name, excludeDir string) (string, error) {
 		if absDir == excludeDir {
 			continue
 		}
+		if realDir, err := filepath.EvalSymlinks(absDir); err == nil && realDir == excludeReal {
+			continue
+		}
 
 		candidate := filepath.Join(dir, name)
 
 		// Resolve symlinks and verify
 		realPath, err := resolveSymlinks(candidate)
-		if err != nil {
+		if err != nil || seen[realPath] {
 			continue
 		}
 
 		if err := verifyExecutable(realPath); err == nil {
-			return realPath, nil
+			seen[realPath] = true
+			found = append(found, realPath)
 		}
 	}
 
-	return "", ErrBinaryNotFound
+	return found
 }
 
 // resolveSymlinks follows symlinks to get the real file path.
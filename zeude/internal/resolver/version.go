@@ -0,0 +1,55 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// claudeVersionCache remembers what `claude --version` printed for one
+// build of the binary, so it isn't run at every launch.
+type claudeVersionCache struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Version string    `json:"version"`
+}
+
+// ClaudeVersion returns what `realClaude --version` prints, asking the
+// binary only when it changed since the last time. It returns "" if the
+// binary can't tell within ctx.
+func ClaudeVersion(ctx context.Context, opts Options, realClaude string) string {
+	e := env.OrDefault(opts.Env)
+	info, err := os.Stat(realClaude)
+	if err != nil {
+		return ""
+	}
+	cachePath, err := paths.ClaudeVersion(e)
+	if err != nil {
+		return ""
+	}
+	var cached claudeVersionCache
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cached) == nil &&
+		cached.Path == realClaude && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return cached.Version
+	}
+
+	out, err := exec.CommandContext(ctx, realClaude, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	version := strings.TrimSpace(string(out))
+	data, err := json.Marshal(claudeVersionCache{Path: realClaude, Size: info.Size(), ModTime: info.ModTime(), Version: version})
+	if err == nil && os.MkdirAll(filepath.Dir(cachePath), 0700) == nil {
+		fsutil.WriteFileAtomic(cachePath, data, 0600)
+	}
+	return version
+}
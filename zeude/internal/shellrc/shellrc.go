@@ -0,0 +1,209 @@
+// Package shellrc adds and removes the block in shell startup files that
+// puts the Zeude bin directory on PATH.
+//
+// The block is delimited by marker comments so it can be found again on
+// re-install and removed cleanly on uninstall without touching anything
+// else in the file.
+package shellrc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/fsutil"
+)
+
+const (
+	// BeginMarker and EndMarker delimit the Zeude block.
+	BeginMarker = "# >>> zeude >>>"
+	EndMarker   = "# <<< zeude <<<"
+
+	// legacyMarker is the comment written by older install.sh versions,
+	// followed by a single PATH line.
+	legacyMarker = "# Zeude - Claude telemetry shim"
+)
+
+// Shell identifies the syntax used for the PATH line.
+type Shell string
+
+const (
+	Zsh  Shell = "zsh"
+	Bash Shell = "bash"
+	Fish Shell = "fish"
+)
+
+// File is a shell startup file that may carry the Zeude block.
+type File struct {
+	Shell Shell
+	Path  string
+}
+
+// Candidates returns the startup files Zeude knows how to edit, the one for
+// the login shell ($SHELL) first.
+func Candidates(e env.Env) ([]File, error) {
+	home, err := e.HomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	files := []File{
+		{Zsh, filepath.Join(home, ".zshrc")},
+		{Bash, filepath.Join(home, ".bashrc")},
+		{Fish, fishConfig(e, home)},
+	}
+
+	login := Shell(filepath.Base(e.Getenv("SHELL")))
+	for i, f := range files {
+		if f.Shell == login && i > 0 {
+			files[0], files[i] = files[i], files[0]
+			break
+		}
+	}
+	return files, nil
+}
+
+// fishConfig returns fish's config.fish, honoring XDG_CONFIG_HOME like fish does.
+func fishConfig(e env.Env, home string) string {
+	if dir := e.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "fish", "config.fish")
+	}
+	return filepath.Join(home, ".config", "fish", "config.fish")
+}
+
+// Block returns the marker-delimited block that prepends binDir to PATH.
+func Block(shell Shell, binDir string) string {
+	var line string
+	if shell == Fish {
+		line = fmt.Sprintf("fish_add_path --prepend --move %s", quote(binDir))
+	} else {
+		line = fmt.Sprintf("export PATH=%s:\"$PATH\"", quote(binDir))
+	}
+	return BeginMarker + "\n" + line + "\n" + EndMarker + "\n"
+}
+
+// Export returns a line that sets and exports key=value in shell's syntax.
+func Export(shell Shell, key, value string) string {
+	if shell == Fish {
+		return fmt.Sprintf("set -gx %s %s", key, quote(value))
+	}
+	return fmt.Sprintf("export %s=%s", key, quote(value))
+}
+
+// quote single-quotes s for POSIX shells and fish.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Installed reports whether path already has a Zeude block (current or
+// legacy). A missing file has none.
+func Installed(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(data, []byte(BeginMarker)) || bytes.Contains(data, []byte(legacyMarker)), nil
+}
+
+// Append adds the block for f to the end of its file, creating the file
+// (and for fish its directory) if needed. It is a no-op when a block is
+// already present and reports whether it wrote anything.
+func Append(f File, binDir string) (bool, error) {
+	installed, err := Installed(f.Path)
+	if err != nil || installed {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return false, err
+	}
+
+	// Appending in place keeps the file's mode, owner and any symlink
+	// (dotfile managers) intact. Separate the block from existing content
+	// by a blank line.
+	prefix := ""
+	if data, _ := os.ReadFile(f.Path); len(data) > 0 {
+		prefix = "\n"
+		if !bytes.HasSuffix(data, []byte("\n")) {
+			prefix = "\n\n"
+		}
+	}
+	out, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := out.WriteString(prefix + Block(f.Shell, binDir)); err != nil {
+		out.Close()
+		return false, err
+	}
+	return true, out.Close()
+}
+
+// Remove deletes the Zeude block from path, along with a legacy install.sh
+// comment and the PATH line after it. It reports whether the file changed;
+// a missing file is not an error.
+func Remove(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	out, changed := stripBlock(data)
+	if !changed {
+		return false, nil
+	}
+
+	// Write through symlinks so a dotfile manager's link stays a link
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return false, err
+	}
+	if err := fsutil.WriteFileAtomic(target, out, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// stripBlock returns data without Zeude blocks, and whether any were found.
+func stripBlock(data []byte) ([]byte, bool) {
+	lines := strings.SplitAfter(string(data), "\n")
+	var out strings.Builder
+	changed := false
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == BeginMarker:
+			// Skip through the end marker; an unterminated block runs to EOF
+			for i < len(lines) && strings.TrimSpace(lines[i]) != EndMarker {
+				i++
+			}
+			changed = true
+		case strings.HasPrefix(line, legacyMarker):
+			if i+1 < len(lines) && strings.Contains(lines[i+1], ".zeude/bin") {
+				i++
+			}
+			changed = true
+		default:
+			out.WriteString(lines[i])
+		}
+	}
+	if !changed {
+		return data, false
+	}
+	// Drop the blank separator line Append added before the block
+	s := out.String()
+	for strings.HasSuffix(s, "\n\n") {
+		s = strings.TrimSuffix(s, "\n")
+	}
+	return []byte(s), true
+}
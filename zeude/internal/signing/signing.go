@@ -0,0 +1,151 @@
+// Package signing verifies dashboard-delivered executable content (hook
+// scripts, skills) against team signing keys pinned on this machine.
+//
+// Signatures are Ed25519 over a domain-separated message,
+// "zeude-<kind>-v1\n" followed by the content exactly as delivered, so a
+// skill signature can't be replayed as a hook script. Pinned public keys live
+// in ~/.zeude/trusted_keys, one base64 key per line with an optional comment.
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// Content kinds covered by signatures.
+const (
+	KindHook  = "hook"
+	KindSkill = "skill"
+)
+
+// RequireSignedKey is the ~/.zeude/config switch that rejects unsigned content.
+const RequireSignedKey = "require_signed_content"
+
+var (
+	// ErrUnsigned means content carried no signature.
+	ErrUnsigned = errors.New("content is not signed")
+	// ErrNoTrustedKeys means there is no pinned key to verify against.
+	ErrNoTrustedKeys = errors.New("no trusted signing key pinned (run: zeude trust-key)")
+	// ErrBadSignature means no pinned key verifies the signature.
+	ErrBadSignature = errors.New("signature does not match any trusted key")
+)
+
+// Message returns the bytes that are signed for content of the given kind.
+func Message(kind, content string) []byte {
+	return []byte("zeude-" + kind + "-v1\n" + content)
+}
+
+// Verify checks a base64 signature over content against the pinned keys.
+func Verify(keys []ed25519.PublicKey, kind, content, signature string) error {
+	if signature == "" {
+		return ErrUnsigned
+	}
+	if len(keys) == 0 {
+		return ErrNoTrustedKeys
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrBadSignature
+	}
+	msg := Message(kind, content)
+	for _, key := range keys {
+		if ed25519.Verify(key, msg, sig) {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid signing key: %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Fingerprint returns a short printable identity for a key, for confirmation
+// prompts ("SHA256:..." like ssh).
+func Fingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// LoadTrusted returns the pinned keys. A missing file means none are pinned;
+// malformed lines are skipped.
+func LoadTrusted(e env.Env) ([]ed25519.PublicKey, error) {
+	path, err := paths.TrustedKeys(e)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []ed25519.PublicKey
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if key, err := ParsePublicKey(fields[0]); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// Pin adds key to the trusted keys file. It reports false when the key was
+// already pinned.
+func Pin(e env.Env, key ed25519.PublicKey, comment string) (bool, error) {
+	existing, err := LoadTrusted(e)
+	if err != nil {
+		return false, err
+	}
+	for _, k := range existing {
+		if k.Equal(key) {
+			return false, nil
+		}
+	}
+
+	path, err := paths.TrustedKeys(e)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return false, err
+	}
+	line := base64.StdEncoding.EncodeToString(key)
+	if comment = strings.TrimSpace(comment); comment != "" {
+		line += " " + comment
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close()
+		return false, err
+	}
+	return true, f.Close()
+}
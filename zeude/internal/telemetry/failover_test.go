@@ -0,0 +1,205 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+func TestMain(m *testing.M) {
+	// Keep test runs out of the real ~/.zeude logs
+	logger = logging.New(logging.Options{}).Component("telemetry")
+	os.Exit(m.Run())
+}
+
+// testEnv returns a fake environment with a fresh temp home and a fixed
+// clock, and points the process home (read by config.Get) at a temp dir.
+func testEnv(t *testing.T) *env.Fake {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	t.Setenv("ZEUDE_HOME", "")
+	return &env.Fake{
+		Home: t.TempDir(),
+		Vars: map[string]string{},
+		Time: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// liveCollector returns the endpoint of a server accepting connections.
+func liveCollector(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// deadCollector returns the endpoint of a server that has shut down.
+func deadCollector(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func readHealth(t *testing.T, e env.Env) collectorHealth {
+	t.Helper()
+	h, ok := loadHealth(e)
+	if !ok {
+		t.Fatal("no collector health cached")
+	}
+	return h
+}
+
+func TestSelectEndpoint(t *testing.T) {
+	primary, secondary := liveCollector(t), liveCollector(t)
+	dead, alsoDead := deadCollector(t), deadCollector(t)
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      string
+		cached    bool
+		status    []EndpointStatus
+	}{
+		{"none configured", nil, config.DefaultCollectorEndpoint, false, nil},
+		{"single endpoint not probed", []string{dead}, dead, false, nil},
+		{"healthy primary", []string{primary, secondary}, primary, true, []EndpointStatus{{primary, true}}},
+		{"dead primary", []string{dead, secondary}, secondary, true, []EndpointStatus{{dead, false}, {secondary, true}}},
+		{"first reachable wins", []string{dead, alsoDead, primary, secondary}, primary, true,
+			[]EndpointStatus{{dead, false}, {alsoDead, false}, {primary, true}}},
+		{"all dead uses primary", []string{dead, alsoDead}, dead, true, []EndpointStatus{{dead, false}, {alsoDead, false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEnv(t)
+			if got := SelectEndpoint(context.Background(), e, tt.endpoints); got != tt.want {
+				t.Errorf("SelectEndpoint = %s, want %s", got, tt.want)
+			}
+			h, ok := loadHealth(e)
+			if ok != tt.cached {
+				t.Fatalf("cached = %v, want %v", ok, tt.cached)
+			}
+			if !ok {
+				return
+			}
+			if h.Selected != tt.want || !h.CheckedAt.Equal(e.Now()) || !sameList(h.Endpoints, tt.endpoints) {
+				t.Errorf("cached health = %+v", h)
+			}
+			if len(h.Status) != len(tt.status) {
+				t.Fatalf("status = %+v, want %+v", h.Status, tt.status)
+			}
+			for i := range tt.status {
+				if h.Status[i] != tt.status[i] {
+					t.Errorf("status[%d] = %+v, want %+v", i, h.Status[i], tt.status[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectEndpointCache(t *testing.T) {
+	e := testEnv(t)
+	dead := deadCollector(t)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	secondary := srv.URL
+	endpoints := []string{dead, secondary}
+	if got := SelectEndpoint(context.Background(), e, endpoints); got != secondary {
+		t.Fatalf("SelectEndpoint = %s, want the secondary", got)
+	}
+
+	// Within the TTL the cached selection is used without probing, so the
+	// secondary going down isn't noticed yet
+	srv.Close()
+	e.Advance(HealthTTL - time.Second)
+	if got := SelectEndpoint(context.Background(), e, endpoints); got != secondary {
+		t.Errorf("within the TTL = %s, want the cached secondary", got)
+	}
+	if h := readHealth(t, e); !h.CheckedAt.Equal(e.Now().Add(-HealthTTL + time.Second)) {
+		t.Errorf("re-probed within the TTL: %+v", h)
+	}
+
+	// After the TTL the list is probed again from the primary
+	e.Advance(time.Second)
+	if got := SelectEndpoint(context.Background(), e, endpoints); got != dead {
+		t.Errorf("after the TTL = %s, want the primary with nothing reachable", got)
+	}
+	h := readHealth(t, e)
+	if !h.CheckedAt.Equal(e.Now()) || len(h.Status) != 2 || h.Status[0] != (EndpointStatus{dead, false}) || h.Status[1] != (EndpointStatus{secondary, false}) {
+		t.Errorf("after the TTL: %+v", h)
+	}
+
+	// A different list is a new selection
+	live := liveCollector(t)
+	if got := SelectEndpoint(context.Background(), e, []string{dead, live}); got != live {
+		t.Errorf("changed list = %s, want %s", got, live)
+	}
+}
+
+func TestSelectEndpointIgnoresBadCache(t *testing.T) {
+	dead, secondary := deadCollector(t), liveCollector(t)
+	endpoints := []string{dead, secondary}
+	tests := []struct {
+		name   string
+		health collectorHealth
+	}{
+		{"selection not in the list", collectorHealth{Endpoints: endpoints, Selected: "http://elsewhere:4317"}},
+		{"checked in the future", collectorHealth{Endpoints: endpoints, Selected: dead, CheckedAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{"expired", collectorHealth{Endpoints: endpoints, Selected: dead, CheckedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEnv(t)
+			saveHealth(e, tt.health)
+			if got := SelectEndpoint(context.Background(), e, endpoints); got != secondary {
+				t.Errorf("SelectEndpoint = %s, want a fresh probe choosing %s", got, secondary)
+			}
+		})
+	}
+
+	e := testEnv(t)
+	path, _ := paths.CollectorHealth(e)
+	dir, _ := paths.Dir(e)
+	os.MkdirAll(dir, 0700)
+	os.WriteFile(path, []byte("{not json"), 0600)
+	if got := SelectEndpoint(context.Background(), e, endpoints); got != secondary {
+		t.Errorf("with a corrupt cache = %s", got)
+	}
+}
+
+func TestSelectEndpointOffline(t *testing.T) {
+	e := testEnv(t)
+	e.Vars[config.OfflineEnv] = "1"
+	dead, secondary := deadCollector(t), liveCollector(t)
+	if got := SelectEndpoint(context.Background(), e, []string{dead, secondary}); got != dead {
+		t.Errorf("offline SelectEndpoint = %s, want the primary unprobed", got)
+	}
+	if _, ok := loadHealth(e); ok {
+		t.Error("offline selection was cached")
+	}
+}
+
+func TestProbeEndpoints(t *testing.T) {
+	testEnv(t)
+	live, dead := liveCollector(t), deadCollector(t)
+	got := ProbeEndpoints(context.Background(), []string{dead, live, "http://[bad"})
+	want := []EndpointStatus{{dead, false}, {live, true}, {"http://[bad", false}}
+	data, _ := json.Marshal(got)
+	if len(got) != len(want) {
+		t.Fatalf("ProbeEndpoints = %s", data)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ProbeEndpoints = %s", data)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Identity is the Zeude user info added to Claude's resource attributes.
+type Identity struct {
+	UserID    string
+	UserEmail string
+	Team      string
+	SessionID string // this launch's, see SessionID
+
+	// AgentKey authenticates Claude's exports to the collector. It is only
+	// sent as a header, never as a resource attribute.
+	AgentKey string
+}
+
+// OTLPHeadersKey in ~/.zeude/config adds headers to Claude's OTLP exports,
+// in the OTEL_EXPORTER_OTLP_HEADERS format (X-Org=acme,X-Env=prod).
+const OTLPHeadersKey = "otlp_headers"
+
+// exportHeaders returns the OTLP headers for agentKey: a bearer token plus
+// any configured under OTLPHeadersKey, which win on a clash.
+func exportHeaders(agentKey string) string {
+	headers := ParseHeaders(config.Get(OTLPHeadersKey))
+	if _, ok := headers["Authorization"]; !ok {
+		headers["Authorization"] = "Bearer " + agentKey
+	}
+	return FormatHeaders(headers)
+}
+
+// EnvVar is one variable the shim sets for Claude's native telemetry.
+type EnvVar struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`   // what Claude will see
+	UserSet bool   `json:"userSet"` // already set before Zeude ran
+	Skipped bool   `json:"skipped"` // left exactly as the user set it
+}
+
+// ClaudeEnv computes the OTel environment the shim gives Claude. It is
+// fail-open: variables the user already set are kept and reported as
+// Skipped, except OTEL_RESOURCE_ATTRIBUTES, which Zeude adds its user and
+// session info to. The user info is for Bedrock users, who don't have email
+// in their native telemetry, and for matching ClickHouse data with Supabase
+// users.
+//
+// With telemetry turned off (config.TelemetryDisabled) the environment is
+// just the session ID, which hooks use locally.
+func ClaudeEnv(ctx context.Context, e env.Env, id Identity) []EnvVar {
+	var vars []EnvVar
+	if id.SessionID != "" {
+		vars = append(vars, EnvVar{Key: SessionIDEnv, Value: id.SessionID, UserSet: e.Getenv(SessionIDEnv) != ""})
+	}
+	if config.TelemetryDisabled(e) {
+		return vars
+	}
+
+	setIfEmpty := func(key string, value func() string) {
+		if existing := e.Getenv(key); existing != "" {
+			vars = append(vars, EnvVar{Key: key, Value: existing, UserSet: true, Skipped: true})
+			return
+		}
+		vars = append(vars, EnvVar{Key: key, Value: value()})
+	}
+	literal := func(v string) func() string { return func() string { return v } }
+
+	// Enable Claude Code telemetry
+	setIfEmpty("CLAUDE_CODE_ENABLE_TELEMETRY", literal("1"))
+
+	// With a failover list, the first reachable collector is used (cached)
+	setIfEmpty("OTEL_EXPORTER_OTLP_ENDPOINT", func() string {
+		return SelectEndpoint(ctx, e, config.GetCollectorEndpoints())
+	})
+
+	// Use http/protobuf instead of grpc for better compatibility
+	setIfEmpty("OTEL_EXPORTER_OTLP_PROTOCOL", literal("http/protobuf"))
+	setIfEmpty("OTEL_METRICS_EXPORTER", literal("otlp"))
+	setIfEmpty("OTEL_LOGS_EXPORTER", literal("otlp"))
+	setIfEmpty("OTEL_TRACES_EXPORTER", literal("otlp"))
+
+	// The collector authenticates exports with the agent key
+	if id.AgentKey != "" {
+		setIfEmpty(HeadersEnv, func() string { return exportHeaders(id.AgentKey) })
+	}
+
+	// Set, not appended: a claude started inside another already has them
+	existing := e.Getenv(ResourceAttributesEnv)
+	attrs := existing
+	for _, attr := range [][2]string{
+		{"zeude.user.id", id.UserID},
+		{"zeude.user.email", id.UserEmail},
+		{"zeude.team", id.Team},
+		{SessionAttribute, id.SessionID},
+	} {
+		if attr[1] != "" {
+			attrs = SetResourceAttribute(attrs, attr[0], attr[1])
+		}
+	}
+	if attrs != existing {
+		vars = append(vars, EnvVar{Key: ResourceAttributesEnv, Value: attrs, UserSet: existing != ""})
+	}
+	return vars
+}
+
+// ApplyEnv sets every variable that wasn't skipped in this process's
+// environment, so the exec'd claude inherits them.
+func ApplyEnv(vars []EnvVar) {
+	for _, v := range vars {
+		if !v.Skipped {
+			os.Setenv(v.Key, v.Value)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/httpclient"
+)
+
+// Standard OTLP exporter variables, shared with what the shim injects for Claude.
+const (
+	EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	ProtocolEnv = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	HeadersEnv  = "OTEL_EXPORTER_OTLP_HEADERS"
+)
+
+// scopeName identifies Zeude's own records in the collector.
+const scopeName = "zeude"
+
+// Record is a single OTLP log record. Attribute values may be string,
+// bool, int, int64, or float64; anything else is sent as its string form.
+type Record struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]interface{}
+}
+
+// Exporter is a minimal OTLP/HTTP logs exporter. It posts the JSON encoding,
+// which collector HTTP receivers accept alongside protobuf, so Zeude doesn't
+// need the full SDK.
+type Exporter struct {
+	Endpoint string            // base endpoint; /v1/logs is appended
+	Headers  map[string]string // e.g. from OTEL_EXPORTER_OTLP_HEADERS
+	Client   *http.Client
+}
+
+// ExporterFromEnv builds an exporter from the OTLP environment, falling back
+// to the configured collector endpoint. gRPC endpoints are mapped to their
+// HTTP equivalent since only OTLP/HTTP is implemented.
+func ExporterFromEnv() *Exporter {
+	endpoint := os.Getenv(EndpointEnv)
+	if endpoint == "" {
+		endpoint = config.GetCollectorEndpoint(config.DefaultCollectorEndpoint)
+	}
+	if os.Getenv(ProtocolEnv) == "grpc" {
+		endpoint = config.GetHTTPEndpoint(endpoint)
+	}
+
+	return &Exporter{
+		Endpoint: endpoint,
+		Headers:  ParseHeaders(os.Getenv(HeadersEnv)),
+	}
+}
+
+// ParseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format
+// (comma-separated key=value pairs with percent-encoded values).
+// Malformed members are skipped.
+func ParseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		eq := strings.IndexByte(member, '=')
+		if eq <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(member[:eq])
+		val, err := url.PathUnescape(strings.TrimSpace(member[eq+1:]))
+		if key == "" || err != nil {
+			continue
+		}
+		headers[key] = val
+	}
+	return headers
+}
+
+// FormatHeaders is the inverse of ParseHeaders, with keys sorted.
+func FormatHeaders(headers map[string]string) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	members := make([]string, len(keys))
+	for i, k := range keys {
+		members[i] = k + "=" + url.PathEscape(headers[k])
+	}
+	return strings.Join(members, ",")
+}
+
+// ExportLogs sends records with the given resource attributes in one request.
+func (x *Exporter) ExportLogs(ctx context.Context, resource map[string]string, records []Record) error {
+	payload, err := BuildLogsPayload(resource, records)
+	if err != nil {
+		return err
+	}
+
+	req, err := httpclient.NewRequest(ctx, http.MethodPost, strings.TrimRight(x.Endpoint, "/")+"/v1/logs", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range x.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := x.Client
+	if client == nil {
+		client = httpclient.New(0)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP/JSON wire types (ExportLogsServiceRequest), limited to what Zeude sends.
+type (
+	otlpLogsRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		SeverityText string         `json:"severityText"`
+		Body         otlpAnyValue   `json:"body"`
+		Attributes   []otlpKeyValue `json:"attributes"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"` // int64 is a string in OTLP/JSON
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// BuildLogsPayload encodes records as an OTLP/JSON logs export request.
+// Each record's Name is used as the body and as the event.name attribute.
+func BuildLogsPayload(resource map[string]string, records []Record) ([]byte, error) {
+	resAttrs := make([]otlpKeyValue, 0, len(resource))
+	for _, k := range sortedKeys(resource) {
+		resAttrs = append(resAttrs, otlpKeyValue{Key: k, Value: anyValue(resource[k])})
+	}
+
+	logRecords := make([]otlpLogRecord, 0, len(records))
+	for _, r := range records {
+		attrs := []otlpKeyValue{{Key: "event.name", Value: anyValue(r.Name)}}
+		keys := make([]string, 0, len(r.Attributes))
+		for k := range r.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			attrs = append(attrs, otlpKeyValue{Key: k, Value: anyValue(r.Attributes[k])})
+		}
+
+		ts := r.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		logRecords = append(logRecords, otlpLogRecord{
+			TimeUnixNano: strconv.FormatInt(ts.UnixNano(), 10),
+			SeverityText: "INFO",
+			Body:         anyValue(r.Name),
+			Attributes:   attrs,
+		})
+	}
+
+	return json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: resAttrs},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: scopeName, Version: resource["service.version"]},
+				LogRecords: logRecords,
+			}},
+		}},
+	})
+}
+
+func anyValue(v interface{}) otlpAnyValue {
+	switch t := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &t}
+	case bool:
+		return otlpAnyValue{BoolValue: &t}
+	case int:
+		s := strconv.Itoa(t)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(t, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &t}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/paths"
+)
+
+// CollectorStatusTTL is how long a reachability result is trusted before
+// the shim probes the collector again.
+const CollectorStatusTTL = 5 * time.Minute
+
+// CollectorStatus is the last reachability probe, kept in
+// ~/.zeude/collector_status.
+type CollectorStatus struct {
+	Endpoint  string    `json:"endpoint"`
+	Reachable bool      `json:"reachable"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// CheckCollector reports whether endpoint accepts connections, probing it
+// (within ProbeTimeout) only when the cached result is for another
+// endpoint or older than CollectorStatusTTL.
+func CheckCollector(ctx context.Context, e env.Env, endpoint string) CollectorStatus {
+	path, err := paths.CollectorStatus(e)
+	if err != nil {
+		return CollectorStatus{Endpoint: endpoint, Reachable: probeEndpoint(ctx, endpoint), CheckedAt: e.Now()}
+	}
+	now := e.Now()
+	var cached CollectorStatus
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil &&
+		cached.Endpoint == endpoint && now.Sub(cached.CheckedAt) < CollectorStatusTTL && !cached.CheckedAt.After(now) {
+		return cached
+	}
+
+	status := CollectorStatus{Endpoint: endpoint, Reachable: probeEndpoint(ctx, endpoint), CheckedAt: now}
+	// A probe the caller cut short says nothing about the collector
+	if ctx.Err() == nil {
+		saveCache(path, status)
+	}
+	return status
+}
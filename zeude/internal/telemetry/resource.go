@@ -0,0 +1,199 @@
+// Package telemetry provides helpers for the OTel environment Zeude injects.
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResourceAttributesEnv is the variable OTel SDKs read resource attributes from.
+const ResourceAttributesEnv = "OTEL_RESOURCE_ATTRIBUTES"
+
+// Limits follow the W3C baggage format OTEL_RESOURCE_ATTRIBUTES is based on.
+// SDKs commonly drop the whole set when these are exceeded.
+const (
+	MaxResourceAttributesLength = 8192
+	MaxResourceAttributeLength  = 4096
+	MaxResourceAttributeCount   = 180
+)
+
+// ResourceAttribute is a single key=value member of OTEL_RESOURCE_ATTRIBUTES.
+type ResourceAttribute struct {
+	Key   string
+	Value string // still percent-encoded
+}
+
+// AttributeProblem describes a malformed or suspicious member.
+// Index is the zero-based position of the comma-separated segment, or -1
+// for problems with the variable as a whole.
+type AttributeProblem struct {
+	Index   int
+	Message string
+}
+
+func (p AttributeProblem) String() string {
+	if p.Index < 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("segment %d: %s", p.Index, p.Message)
+}
+
+// EscapeAttributeValue percent-encodes characters that are not allowed
+// unencoded in a resource attribute value. '%' and '=' are also encoded so
+// values round-trip through parsers that split on the first '='.
+func EscapeAttributeValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isValueOctet(c) && c != '%' && c != '=' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// AppendResourceAttribute adds key=value to an existing attribute string.
+func AppendResourceAttribute(existing, key, value string) string {
+	attr := key + "=" + EscapeAttributeValue(value)
+	if existing == "" {
+		return attr
+	}
+	return existing + "," + attr
+}
+
+// SetResourceAttribute is AppendResourceAttribute that first drops any
+// member already using key, so a nested shim replaces its parent's value
+// instead of adding a duplicate.
+func SetResourceAttribute(existing, key, value string) string {
+	var kept []string
+	if existing != "" {
+		for _, seg := range strings.Split(existing, ",") {
+			if k, _, _ := strings.Cut(seg, "="); k != key {
+				kept = append(kept, seg)
+			}
+		}
+	}
+	return AppendResourceAttribute(strings.Join(kept, ","), key, value)
+}
+
+// ParseResourceAttributes strictly parses an OTEL_RESOURCE_ATTRIBUTES value.
+// Well-formed members are returned even when problems are found elsewhere.
+func ParseResourceAttributes(value string) ([]ResourceAttribute, []AttributeProblem) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var attrs []ResourceAttribute
+	var problems []AttributeProblem
+
+	if len(value) > MaxResourceAttributesLength {
+		problems = append(problems, AttributeProblem{-1, fmt.Sprintf("total length %d exceeds %d", len(value), MaxResourceAttributesLength)})
+	}
+
+	segments := strings.Split(value, ",")
+	if len(segments) > MaxResourceAttributeCount {
+		problems = append(problems, AttributeProblem{-1, fmt.Sprintf("%d attributes exceeds limit of %d", len(segments), MaxResourceAttributeCount)})
+	}
+
+	seen := make(map[string]int)
+	for i, seg := range segments {
+		if seg == "" {
+			if i == len(segments)-1 {
+				problems = append(problems, AttributeProblem{i, "trailing comma"})
+			} else {
+				problems = append(problems, AttributeProblem{i, "empty segment"})
+			}
+			continue
+		}
+		if len(seg) > MaxResourceAttributeLength {
+			problems = append(problems, AttributeProblem{i, fmt.Sprintf("length %d exceeds %d", len(seg), MaxResourceAttributeLength)})
+		}
+
+		eq := strings.IndexByte(seg, '=')
+		if eq < 0 {
+			problems = append(problems, AttributeProblem{i, "missing '='"})
+			continue
+		}
+
+		key, val := seg[:eq], seg[eq+1:]
+		if key == "" {
+			problems = append(problems, AttributeProblem{i, "empty key"})
+			continue
+		}
+		if msg := validateKey(key); msg != "" {
+			problems = append(problems, AttributeProblem{i, msg})
+			continue
+		}
+		if msg := validateValue(val); msg != "" {
+			problems = append(problems, AttributeProblem{i, fmt.Sprintf("key %q: %s", key, msg)})
+			continue
+		}
+
+		if first, dup := seen[key]; dup {
+			msg := fmt.Sprintf("duplicate key %q (first at segment %d)", key, first)
+			if strings.HasPrefix(key, "zeude.") {
+				msg += "; the shim may have been run twice"
+			}
+			problems = append(problems, AttributeProblem{i, msg})
+		} else {
+			seen[key] = i
+		}
+
+		attrs = append(attrs, ResourceAttribute{Key: key, Value: val})
+	}
+
+	return attrs, problems
+}
+
+// validateKey checks a key contains only token characters.
+func validateKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == ' ' || c == '\t' {
+			return fmt.Sprintf("key %q contains whitespace", key)
+		}
+		if !isTokenChar(c) {
+			return fmt.Sprintf("key %q contains invalid character %q", key, c)
+		}
+	}
+	return ""
+}
+
+// validateValue checks a value only uses allowed octets and valid escapes.
+func validateValue(val string) string {
+	for i := 0; i < len(val); i++ {
+		c := val[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(val) || !isHex(val[i+1]) || !isHex(val[i+2]) {
+				return "invalid percent-encoding"
+			}
+			i += 2
+		case c == ' ' || c == '\t':
+			return "unescaped whitespace in value"
+		case !isValueOctet(c):
+			return fmt.Sprintf("unescaped character %q in value", c)
+		}
+	}
+	return ""
+}
+
+// isValueOctet reports whether c may appear unencoded in a value
+// (baggage-octet: printable ASCII except space, '"', ',', ';', '\').
+func isValueOctet(c byte) bool {
+	return c > 0x20 && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\'
+}
+
+// isTokenChar reports whether c is an RFC 7230 token character.
+func isTokenChar(c byte) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseResourceAttributes(t *testing.T) {
+	long := strings.Repeat("v", MaxResourceAttributeLength)
+	var keys []string
+	for i := 0; i <= MaxResourceAttributeCount; i++ {
+		keys = append(keys, fmt.Sprintf("k%d=v", i))
+	}
+	many := strings.Join(keys, ",")
+
+	tests := []struct {
+		value    string
+		attrs    int
+		problems []string
+	}{
+		{"", 0, nil},
+		{"service.name=claude", 1, nil},
+		{"a=1,b=2,c=", 3, nil},
+		{"team=eng%2Cdata,path=%2Fhome", 2, nil},
+		{"a=1,", 1, []string{"segment 1: trailing comma"}},
+		{"a=1,,b=2", 2, []string{"segment 1: empty segment"}},
+		{",a=1", 1, []string{"segment 0: empty segment"}},
+		{"a=1,b", 1, []string{"segment 1: missing '='"}},
+		{"=1", 0, []string{"segment 0: empty key"}},
+		{"my key=1", 0, []string{`segment 0: key "my key" contains whitespace`}},
+		{"k(1)=1", 0, []string{`segment 0: key "k(1)" contains invalid character '('`}},
+		{"name=Jane Doe", 0, []string{`segment 0: key "name": unescaped whitespace in value`}},
+		{`q="x"`, 0, []string{`segment 0: key "q": unescaped character '"' in value`}},
+		{"p=a;b", 0, []string{`segment 0: key "p": unescaped character ';' in value`}},
+		{"e=caf\xc3\xa9", 0, []string{`segment 0: key "e": unescaped character 'Ã' in value`}},
+		{"pct=50%", 0, []string{`segment 0: key "pct": invalid percent-encoding`}},
+		{"pct=%4", 0, []string{`segment 0: key "pct": invalid percent-encoding`}},
+		{"pct=%zz", 0, []string{`segment 0: key "pct": invalid percent-encoding`}},
+		{"a=1,a=2", 2, []string{`segment 1: duplicate key "a" (first at segment 0)`}},
+		{"zeude.team=a,x=1,zeude.team=b", 3, []string{`segment 2: duplicate key "zeude.team" (first at segment 0); the shim may have been run twice`}},
+		{"a=1,b,c=,=2,", 2, []string{"segment 1: missing '='", "segment 3: empty key", "segment 4: trailing comma"}},
+		{"big=" + long, 1, []string{fmt.Sprintf("segment 0: length %d exceeds %d", len(long)+4, MaxResourceAttributeLength)}},
+		{many, MaxResourceAttributeCount + 1, []string{
+			fmt.Sprintf("%d attributes exceeds limit of %d", MaxResourceAttributeCount+1, MaxResourceAttributeCount),
+		}},
+		{strings.Repeat("k", 10) + "=" + strings.Repeat("v", MaxResourceAttributesLength), 1, []string{
+			fmt.Sprintf("total length %d exceeds %d", MaxResourceAttributesLength+11, MaxResourceAttributesLength),
+			fmt.Sprintf("segment 0: length %d exceeds %d", MaxResourceAttributesLength+11, MaxResourceAttributeLength),
+		}},
+	}
+	for _, tt := range tests {
+		name := tt.value
+		if len(name) > 40 {
+			name = name[:40] + "..."
+		}
+		t.Run(name, func(t *testing.T) {
+			attrs, problems := ParseResourceAttributes(tt.value)
+			if len(attrs) != tt.attrs {
+				t.Errorf("%d attributes, want %d", len(attrs), tt.attrs)
+			}
+			var got []string
+			for _, p := range problems {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.problems, "\n") {
+				t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.problems, "\n"))
+			}
+		})
+	}
+}
+
+// TestEscapeAttributeValue checks every byte value survives escaping as a
+// well-formed attribute.
+func TestEscapeAttributeValue(t *testing.T) {
+	var all []byte
+	for c := 0; c < 256; c++ {
+		all = append(all, byte(c))
+	}
+	for _, value := range []string{"", "plain", "a b,c;d=e%f\"g\\h", "café", string(all)} {
+		attr := AppendResourceAttribute("", "k", value)
+		attrs, problems := ParseResourceAttributes(attr)
+		if len(problems) > 0 || len(attrs) != 1 {
+			t.Errorf("escaped %q: %v", value, problems)
+		}
+	}
+	if got := EscapeAttributeValue("x=1 50%"); got != "x%3D1%2050%25" {
+		t.Errorf("EscapeAttributeValue = %q", got)
+	}
+}
+
+func TestSetResourceAttribute(t *testing.T) {
+	tests := []struct {
+		existing, key, value, want string
+	}{
+		{"", "zeude.team", "eng", "zeude.team=eng"},
+		{"service.name=claude", "zeude.team", "eng", "service.name=claude,zeude.team=eng"},
+		{"zeude.team=old,service.name=claude", "zeude.team", "new", "service.name=claude,zeude.team=new"},
+		{"zeude.team=a,zeude.team=b", "zeude.team", "c", "zeude.team=c"},
+		{"zeude.team.lead=x", "zeude.team", "eng", "zeude.team.lead=x,zeude.team=eng"},
+		{"", "zeude.user.email", "a b@example.com", "zeude.user.email=a%20b@example.com"},
+	}
+	for _, tt := range tests {
+		if got := SetResourceAttribute(tt.existing, tt.key, tt.value); got != tt.want {
+			t.Errorf("SetResourceAttribute(%q, %s, %q) = %q, want %q", tt.existing, tt.key, tt.value, got, tt.want)
+		}
+	}
+}
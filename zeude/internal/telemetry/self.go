@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+	"github.com/zeude/zeude/internal/logging"
+)
+
+// SelfTelemetryKey is the ~/.zeude/config key that opts into reporting
+// Zeude's own operational metrics (self_telemetry=true).
+const SelfTelemetryKey = "self_telemetry"
+
+// SelfTelemetryTimeout bounds how long reporting may delay the shim.
+const SelfTelemetryTimeout = 500 * time.Millisecond
+
+var logger = logging.Default().Component("telemetry")
+
+// SelfTelemetryEnabled reports whether self-telemetry is on, either by local
+// config or because the dashboard policy enabled it for this user. The
+// user's opt-out from all telemetry, or offline mode, overrides both.
+func SelfTelemetryEnabled(policy bool) bool {
+	if config.TelemetryDisabled(env.OS{}) || config.Offline(env.OS{}) {
+		return false
+	}
+	return policy || config.Get(SelfTelemetryKey) == "true"
+}
+
+// ReportSelf sends Zeude's own records to the collector. It never returns
+// an error: failures are debug-logged and must not affect the user.
+func ReportSelf(resource map[string]string, records []Record) {
+	if len(records) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SelfTelemetryTimeout)
+	defer cancel()
+
+	if err := ExporterFromEnv().ExportLogs(ctx, resource, records); err != nil {
+		logger.Debug("self-telemetry export failed", "error", err)
+		return
+	}
+	logger.Debug("self-telemetry exported", "records", len(records))
+}
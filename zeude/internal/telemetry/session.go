@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+const (
+	// SessionIDEnv carries the ID of the claude session the shim launched,
+	// for hooks and for shims started from inside that session.
+	SessionIDEnv = "ZEUDE_SESSION_ID"
+	// SessionFileEnv points hooks at the session file the shim wrote for
+	// that session.
+	SessionFileEnv = "ZEUDE_SESSION_FILE"
+	// SessionAttribute is the resource attribute holding the same ID.
+	SessionAttribute = "zeude.session.id"
+)
+
+// SessionID returns the ID already in the environment, so a claude spawned
+// from within a session stays in it, or a new one. inherited reports which.
+func SessionID(e env.Env) (id string, inherited bool) {
+	if id := e.Getenv(SessionIDEnv); id != "" {
+		return id, true
+	}
+	return NewSessionID(), false
+}
+
+// NewSessionID returns a random (version 4) UUID.
+func NewSessionID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,60 @@
+// Package term decides whether Zeude's binaries color their output and
+// hands out the ANSI codes, or empty strings when they shouldn't.
+package term
+
+import (
+	"github.com/zeude/zeude/internal/config"
+	"github.com/zeude/zeude/internal/env"
+)
+
+// Environment variables that turn colors off.
+const (
+	// NoColorEnv is the https://no-color.org convention: any non-empty value.
+	NoColorEnv = "NO_COLOR"
+	// ZeudeNoColorEnv turns colors off for Zeude only.
+	ZeudeNoColorEnv = "ZEUDE_NO_COLOR"
+)
+
+// Palette holds the escape codes the CLIs print. The zero Palette is all
+// empty strings, so output written with it is plain text.
+type Palette struct {
+	Reset  string
+	Blue   string
+	Green  string
+	Yellow string
+	Red    string
+	Gray   string
+}
+
+// ANSI is the palette of real escape codes.
+var ANSI = Palette{
+	Reset:  "\033[0m",
+	Blue:   "\033[1;34m",
+	Green:  "\033[1;32m",
+	Yellow: "\033[1;33m",
+	Red:    "\033[1;31m",
+	Gray:   "\033[0;90m",
+}
+
+// ColorEnabled reports whether colors may be used: not when NO_COLOR or
+// ZEUDE_NO_COLOR is set, or the terminal is dumb.
+func ColorEnabled(e env.Env) bool {
+	if e.Getenv(NoColorEnv) != "" || config.IsTruthy(e.Getenv(ZeudeNoColorEnv)) {
+		return false
+	}
+	return !Dumb(e)
+}
+
+// Dumb reports whether TERM says the terminal can't handle escape codes
+// at all, colors or cursor movement.
+func Dumb(e env.Env) bool {
+	return e.Getenv("TERM") == "dumb"
+}
+
+// Colors returns ANSI if enabled, else the empty Palette.
+func Colors(enabled bool) Palette {
+	if enabled {
+		return ANSI
+	}
+	return Palette{}
+}
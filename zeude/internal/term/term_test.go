@@ -0,0 +1,59 @@
+package term
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zeude/zeude/internal/env"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want bool
+	}{
+		{"nothing set", nil, true},
+		{"xterm", map[string]string{"TERM": "xterm-256color"}, true},
+		{"NO_COLOR", map[string]string{NoColorEnv: "1"}, false},
+		{"NO_COLOR any value", map[string]string{NoColorEnv: "0"}, false},
+		{"NO_COLOR empty", map[string]string{NoColorEnv: ""}, true},
+		{"ZEUDE_NO_COLOR", map[string]string{ZeudeNoColorEnv: "true"}, false},
+		{"ZEUDE_NO_COLOR false", map[string]string{ZeudeNoColorEnv: "0"}, true},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &env.Fake{Vars: tt.vars}
+			if got := ColorEnabled(e); got != tt.want {
+				t.Errorf("ColorEnabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDumb(t *testing.T) {
+	for term, want := range map[string]bool{"dumb": true, "": false, "xterm": false, "vt100": false} {
+		if got := Dumb(&env.Fake{Vars: map[string]string{"TERM": term}}); got != want {
+			t.Errorf("Dumb(TERM=%q) = %v, want %v", term, got, want)
+		}
+	}
+}
+
+func TestColors(t *testing.T) {
+	if Colors(true) != ANSI {
+		t.Error("Colors(true) isn't the ANSI palette")
+	}
+	off := reflect.ValueOf(Colors(false))
+	on := reflect.ValueOf(ANSI)
+	for i := 0; i < off.NumField(); i++ {
+		name := off.Type().Field(i).Name
+		if code := off.Field(i).String(); code != "" {
+			t.Errorf("disabled %s = %q, want empty", name, code)
+		}
+		if code := on.Field(i).String(); !strings.HasPrefix(code, "\033[") {
+			t.Errorf("ANSI %s = %q, not an escape code", name, code)
+		}
+	}
+}
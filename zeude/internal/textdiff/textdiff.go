@@ -0,0 +1,145 @@
+// Package textdiff renders line-based unified diffs for small text files
+// such as hook scripts, skills and JSON snippets.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is how many unchanged lines surround each hunk.
+const contextLines = 3
+
+// maxCells bounds the LCS table so huge inputs can't exhaust memory.
+const maxCells = 4 << 20
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	text string
+	a, b int // 0-based line numbers in from/to before this op
+}
+
+// Unified returns a unified diff from a to b, or "" if they are equal.
+func Unified(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	al, bl := splitLines(a), splitLines(b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	if (len(al)+1)*(len(bl)+1) > maxCells {
+		fmt.Fprintf(&out, "@@ -1,%d +1,%d @@ (too large to diff)\n", len(al), len(bl))
+		return out.String()
+	}
+
+	ops := diffLines(al, bl)
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		lo := first - contextLines
+		if lo < start {
+			lo = start
+		}
+		hi := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				hi = i + 1
+			} else if i-hi >= 2*contextLines {
+				break
+			}
+		}
+		end := hi + contextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(&out, ops[lo:end])
+		start = end
+	}
+	return out.String()
+}
+
+// writeHunk writes one @@ hunk covering ops.
+func writeHunk(out *strings.Builder, ops []op) {
+	aCount, bCount := 0, 0
+	for _, o := range ops {
+		if o.kind != '+' {
+			aCount++
+		}
+		if o.kind != '-' {
+			bCount++
+		}
+	}
+	aStart, bStart := ops[0].a+1, ops[0].b+1
+	if aCount == 0 {
+		aStart--
+	}
+	if bCount == 0 {
+		bStart--
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+	for _, o := range ops {
+		out.WriteByte(o.kind)
+		out.WriteString(o.text)
+		out.WriteByte('\n')
+	}
+}
+
+// diffLines returns the edit script turning a into b via a longest common
+// subsequence, preferring deletions before insertions.
+func diffLines(a, b []string) []op {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i], i, j})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits s into lines without their newlines. A missing final
+// newline is shown the way diff(1) does.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n\\ No newline at end of file"
+	return lines
+}
@@ -6,11 +6,18 @@ SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 PROJECT_DIR="$SCRIPT_DIR/.."
 OUTPUT_DIR="$PROJECT_DIR/releases"
 
+VERSION="${VERSION:-$(git describe --tags --always --dirty 2>/dev/null || echo dev)}"
+VERSION="${VERSION#v}"
+COMMIT="$(git rev-parse HEAD 2>/dev/null || echo unknown)"
+BUILD_DATE="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+PKG="github.com/zeude/zeude/internal/autoupdate"
+LDFLAGS="-s -w -X $PKG.Version=$VERSION -X $PKG.Commit=$COMMIT -X $PKG.BuildDate=$BUILD_DATE"
+
 # Colors
 GREEN='\033[0;32m'
 NC='\033[0m'
 
-echo "Building Zeude release binaries..."
+echo "Building Zeude release binaries ($VERSION)..."
 echo "======================================"
 
 mkdir -p "$OUTPUT_DIR"
@@ -33,7 +40,7 @@ for platform in "${PLATFORMS[@]}"; do
     OUTPUT_NAME="claude-${GOOS}-${GOARCH}"
 
     echo -n "  $OUTPUT_NAME... "
-    GOOS=$GOOS GOARCH=$GOARCH go build -ldflags="-s -w" -o "$OUTPUT_DIR/$OUTPUT_NAME" ./cmd/claude
+    GOOS=$GOOS GOARCH=$GOARCH go build -ldflags="$LDFLAGS" -o "$OUTPUT_DIR/$OUTPUT_NAME" ./cmd/claude
     echo -e "${GREEN}OK${NC}"
 done
 
@@ -46,7 +53,7 @@ for platform in "${PLATFORMS[@]}"; do
     OUTPUT_NAME="zeude-${GOOS}-${GOARCH}"
 
     echo -n "  $OUTPUT_NAME... "
-    GOOS=$GOOS GOARCH=$GOARCH go build -ldflags="-s -w" -o "$OUTPUT_DIR/$OUTPUT_NAME" ./cmd/doctor
+    GOOS=$GOOS GOARCH=$GOARCH go build -ldflags="$LDFLAGS" -o "$OUTPUT_DIR/$OUTPUT_NAME" ./cmd/doctor
     echo -e "${GREEN}OK${NC}"
 done
 